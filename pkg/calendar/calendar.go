@@ -0,0 +1,234 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultTimezone is used when business_timezone is not set
+	DefaultTimezone = "UTC"
+	// DefaultHours is used when business_hours is not set
+	DefaultHours = "mon-fri 09:00-18:00"
+
+	dateLayout = "2006-01-02"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Range is a working period of a day, in minutes since midnight
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Calendar defines the working time of the support team.
+// A nil calendar considers that every minute is worked
+type Calendar struct {
+	Location *time.Location
+	Hours    map[time.Weekday][]Range
+	Holidays map[string]bool
+}
+
+// Configure creates the calendar from the business_timezone, business_hours and business_holidays_path parameters
+func Configure() (*Calendar, error) {
+	viper.SetDefault("business_timezone", DefaultTimezone)
+	viper.SetDefault("business_hours", DefaultHours)
+
+	var holidays []string
+	if path := viper.GetString("business_holidays_path"); path != "" {
+		var err error
+		holidays, err = LoadHolidays(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return New(viper.GetString("business_timezone"), viper.GetString("business_hours"), holidays)
+}
+
+// New creates a calendar in the given timezone.
+// Hours are a list of days and ranges separated by semicolons, e.g. "mon-thu 09:00-12:30,13:30-18:00; fri 09:00-17:00".
+// Holidays are dates formatted as 2006-01-02
+func New(timezone string, hours string, holidays []string) (*Calendar, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %s : %s", timezone, err)
+	}
+	c := &Calendar{
+		Location: location,
+		Hours:    make(map[time.Weekday][]Range),
+		Holidays: make(map[string]bool),
+	}
+
+	for _, spec := range strings.Split(hours, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		fields := strings.Fields(spec)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid business hours %q, expected days and ranges", spec)
+		}
+		days, err := parseDays(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		ranges, err := parseRanges(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			c.Hours[day] = append(c.Hours[day], ranges...)
+		}
+	}
+
+	for _, holiday := range holidays {
+		if _, err := time.Parse(dateLayout, holiday); err != nil {
+			return nil, fmt.Errorf("invalid holiday %s : %s", holiday, err)
+		}
+		c.Holidays[holiday] = true
+	}
+	return c, nil
+}
+
+// LoadHolidays reads the holidays from a file containing one date (2006-01-02) per line.
+// Empty lines and lines starting with # are ignored, text after the date is a free description
+func LoadHolidays(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var holidays []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		holidays = append(holidays, strings.Fields(line)[0])
+	}
+	return holidays, scanner.Err()
+}
+
+// IsHoliday checks if the day of t is a holiday
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	if c == nil {
+		return false
+	}
+	return c.Holidays[t.In(c.Location).Format(dateLayout)]
+}
+
+// Duration returns the working time between start and end
+func (c *Calendar) Duration(start time.Time, end time.Time) time.Duration {
+	if !end.After(start) {
+		return 0
+	}
+	if c == nil {
+		return end.Sub(start)
+	}
+
+	start = start.In(c.Location)
+	end = end.In(c.Location)
+	var total time.Duration
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, c.Location)
+	for !day.After(end) {
+		if !c.IsHoliday(day) {
+			for _, r := range c.Hours[day.Weekday()] {
+				// minutes are normalized by time.Date, which keeps the ranges right on DST changes
+				from := time.Date(day.Year(), day.Month(), day.Day(), 0, r.Start, 0, 0, c.Location)
+				to := time.Date(day.Year(), day.Month(), day.Day(), 0, r.End, 0, 0, c.Location)
+				if from.Before(start) {
+					from = start
+				}
+				if to.After(end) {
+					to = end
+				}
+				if to.After(from) {
+					total += to.Sub(from)
+				}
+			}
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, c.Location)
+	}
+	return total
+}
+
+func parseDays(spec string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(strings.ToLower(spec), ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, ok := weekdays[bounds[0]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %s", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdays[bounds[1]]; !ok {
+				return nil, fmt.Errorf("invalid day %s", bounds[1])
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			days = append(days, day)
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseRanges(spec string) ([]Range, error) {
+	var ranges []Range
+	for _, part := range strings.Split(spec, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid range %s, expected 09:00-18:00", part)
+		}
+		start, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, fmt.Errorf("invalid range %s, end is before start", part)
+		}
+		ranges = append(ranges, Range{Start: start, End: end})
+	}
+	return ranges, nil
+}
+
+// parseClock returns the minutes since midnight of a 15:04 formatted time, 24:00 being the end of the day
+func parseClock(clock string) (int, error) {
+	parts := strings.SplitN(clock, ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %s, expected 15:04", clock)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %s, expected 15:04", clock)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, fmt.Errorf("invalid time %s, expected 15:04", clock)
+	}
+	return hours*60 + minutes, nil
+}
@@ -0,0 +1,80 @@
+package calendar_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/calendar"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustCalendar(t *testing.T, hours string, holidays ...string) *calendar.Calendar {
+	c, err := calendar.New("Europe/Paris", hours, holidays)
+	assert.Equal(t, nil, err, "creating the calendar shall not return errors")
+	return c
+}
+
+func TestDurationWithinDay(t *testing.T) {
+	c := mustCalendar(t, "mon-fri 09:00-12:30,13:30-18:00")
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	// Monday 11:00 to 14:00, with a lunch break
+	start := time.Date(2020, 6, 15, 11, 0, 0, 0, paris)
+	assert.Equal(t, 2*time.Hour, c.Duration(start, start.Add(3*time.Hour)))
+	// Monday 19:00 to 20:00, out of business hours
+	start = time.Date(2020, 6, 15, 19, 0, 0, 0, paris)
+	assert.Equal(t, time.Duration(0), c.Duration(start, start.Add(time.Hour)))
+}
+
+func TestDurationOverWeekend(t *testing.T) {
+	c := mustCalendar(t, "mon-fri 09:00-18:00")
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	// Friday 17:30 to Monday 09:30
+	start := time.Date(2020, 6, 12, 17, 30, 0, 0, paris)
+	end := time.Date(2020, 6, 15, 9, 30, 0, 0, paris)
+	assert.Equal(t, time.Hour, c.Duration(start, end))
+	assert.Equal(t, 63*time.Hour+time.Hour, end.Sub(start))
+}
+
+func TestDurationWithHolidays(t *testing.T) {
+	c := mustCalendar(t, "mon-fri 09:00-18:00", "2020-07-14")
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	// Monday 17:00 to Wednesday 10:00, Tuesday being a holiday
+	start := time.Date(2020, 7, 13, 17, 0, 0, 0, paris)
+	end := time.Date(2020, 7, 15, 10, 0, 0, 0, paris)
+	assert.Equal(t, 2*time.Hour, c.Duration(start, end))
+	assert.True(t, c.IsHoliday(time.Date(2020, 7, 14, 12, 0, 0, 0, paris)))
+}
+
+func TestDurationWithoutCalendar(t *testing.T) {
+	var c *calendar.Calendar
+	start := time.Date(2020, 6, 12, 17, 30, 0, 0, time.UTC)
+	assert.Equal(t, 72*time.Hour, c.Duration(start, start.Add(72*time.Hour)))
+	assert.Equal(t, time.Duration(0), c.Duration(start, start.Add(-time.Hour)))
+}
+
+func TestInvalidHours(t *testing.T) {
+	for _, hours := range []string{"mon-fri", "mon-fri 18:00-09:00", "monday 09:00-18:00", "mon 9h-18h", "mon 09:00-25:00"} {
+		_, err := calendar.New("UTC", hours, nil)
+		assert.NotEqual(t, nil, err, "hours %q shall be invalid", hours)
+	}
+	_, err := calendar.New("Mars/Olympus", calendar.DefaultHours, nil)
+	assert.NotEqual(t, nil, err, "timezone shall be invalid")
+}
+
+func TestLoadHolidays(t *testing.T) {
+	file, err := ioutil.TempFile("", "holidays")
+	assert.Equal(t, nil, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("# french public holidays\n2020-07-14 Bastille day\n\n2020-08-15\n")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, file.Close())
+
+	holidays, err := calendar.LoadHolidays(file.Name())
+	assert.Equal(t, nil, err, "loading holidays shall not return errors")
+	assert.Equal(t, []string{"2020-07-14", "2020-08-15"}, holidays)
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/i18n"
+	"github.com/mitchellh/mapstructure"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// DefaultReminderInterval is the delay after which the fireman is reminded of an inactive thread
+const DefaultReminderInterval = 1 * time.Hour
+
+// DefaultWaitingReminder is the delay after which the requester of a thread waiting for their answer is reminded
+const DefaultWaitingReminder = 24 * time.Hour
+
+// DefaultWaitingTimeout is the delay after which a thread waiting for an answer of its requester is closed
+const DefaultWaitingTimeout = 72 * time.Hour
+
+// DefaultSnoozeDuration is the delay the reminders of a thread are postponed by when snoozed
+const DefaultSnoozeDuration = 24 * time.Hour
+
+// DefaultMaxAnswers is the number of predefined answers sent to a message at most, the most useful ones
+const DefaultMaxAnswers = 3
+
+// DefaultSimilarThreads is the number of similar resolved threads suggested to a new message at most
+const DefaultSimilarThreads = 3
+
+const (
+	// HandoverDirect sends the handover digest to the new fireman in a direct message, the default
+	HandoverDirect = "dm"
+	// HandoverChannel posts the handover digest in the support channel
+	HandoverChannel = "channel"
+	// HandoverDisabled sends no handover digest
+	HandoverDisabled = "none"
+)
+
+// Channel holds the settings of one of the support channels watched by the bot
+type Channel struct {
+	ID               string        `mapstructure:"id" json:"id"`
+	Name             string        `mapstructure:"name" json:"name"`
+	Webhook          string        `mapstructure:"webhook" json:"webhook"`
+	Welcome          string        `mapstructure:"welcome" json:"welcome"`
+	ReminderInterval time.Duration `mapstructure:"reminder_interval" json:"reminder_interval"`
+	DisableReminders bool          `mapstructure:"disable_reminders" json:"disable_reminders"`
+	HandoverDigest   string        `mapstructure:"handover_digest" json:"handover_digest"`
+	WaitingReminder  time.Duration `mapstructure:"waiting_reminder" json:"waiting_reminder"`
+	WaitingTimeout   time.Duration `mapstructure:"waiting_timeout" json:"waiting_timeout"`
+	SnoozeDuration   time.Duration `mapstructure:"snooze_duration" json:"snooze_duration"`
+	Locale           string        `mapstructure:"locale" json:"locale"`
+	UserLocale       bool          `mapstructure:"user_locale" json:"user_locale"`
+	MaxAnswers       int           `mapstructure:"max_answers" json:"max_answers"`
+	// SimilarThreads is the number of resolved threads suggested to a new message, scoring at least SimilarScore.
+	// The scores depend on the storage, the default score of the storage applies when SimilarScore is 0
+	SimilarThreads        int     `mapstructure:"similar_threads" json:"similar_threads"`
+	SimilarScore          float64 `mapstructure:"similar_score" json:"similar_score"`
+	DisableSimilarThreads bool    `mapstructure:"disable_similar_threads" json:"disable_similar_threads"`
+}
+
+var (
+	channelsMutex  sync.RWMutex
+	cachedChannels []Channel
+)
+
+// Channels returns the list of the support channels defined in the configuration, parsed on the first call.
+// When slack_channels is not set, the legacy slack_id and slack_webhook parameters are used
+func Channels() []Channel {
+	channelsMutex.RLock()
+	parsed := cachedChannels
+	channelsMutex.RUnlock()
+	if parsed != nil {
+		return parsed
+	}
+
+	parsed = parseChannels()
+	channelsMutex.Lock()
+	cachedChannels = parsed
+	channelsMutex.Unlock()
+	return parsed
+}
+
+// ResetChannels drops the parsed channels, so that the next call to Channels reads the configuration again.
+// It shall be called whenever the channels settings are changed, as the tests do
+func ResetChannels() {
+	channelsMutex.Lock()
+	cachedChannels = nil
+	channelsMutex.Unlock()
+}
+
+// parseChannels reads the support channels from the configuration
+func parseChannels() []Channel {
+	var channels []Channel
+	raw := viper.Get("slack_channels")
+
+	// Environment variables can only hold strings, the list is then expected as JSON
+	if s, ok := raw.(string); ok && s != "" {
+		var decoded []map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+			log.Errorf("Could not parse slack_channels : %s", err)
+			return legacyChannels()
+		}
+		raw = decoded
+	}
+
+	if raw == nil {
+		return legacyChannels()
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     &channels,
+	})
+	if err != nil {
+		log.Errorf("Could not create slack_channels decoder : %s", err)
+		return legacyChannels()
+	}
+	if err := decoder.Decode(raw); err != nil {
+		log.Errorf("Could not decode slack_channels : %s", err)
+		return legacyChannels()
+	}
+	if len(channels) == 0 {
+		return legacyChannels()
+	}
+
+	for i := range channels {
+		if channels[i].ReminderInterval <= 0 {
+			channels[i].ReminderInterval = DefaultReminderInterval
+		}
+		if channels[i].HandoverDigest == "" {
+			channels[i].HandoverDigest = HandoverDirect
+		}
+		channels[i].setWaitingDefaults()
+		if channels[i].MaxAnswers <= 0 {
+			channels[i].MaxAnswers = DefaultMaxAnswers
+		}
+		if channels[i].SimilarThreads <= 0 {
+			channels[i].SimilarThreads = DefaultSimilarThreads
+		}
+		if channels[i].Locale == "" {
+			channels[i].Locale = defaultLocale()
+		}
+	}
+	return channels
+}
+
+// GetChannel returns the settings of the channel matching the given ID, and whether the channel is configured.
+// The first configured channel is returned when the ID is empty, an unknown channel gets the default settings
+func GetChannel(id string) (Channel, bool) {
+	channels := Channels()
+	if id == "" {
+		return channels[0], true
+	}
+	for _, channel := range channels {
+		if channel.ID == id {
+			return channel, true
+		}
+	}
+	log.WithFields(log.Fields{"channel": id}).Debug("Channel is not configured, using the default settings")
+	return defaultChannel(id), false
+}
+
+// defaultChannel returns the default settings of a channel
+func defaultChannel(id string) Channel {
+	channel := Channel{
+		ID:               id,
+		ReminderInterval: DefaultReminderInterval,
+		HandoverDigest:   HandoverDirect,
+		Locale:           defaultLocale(),
+		MaxAnswers:       DefaultMaxAnswers,
+		SimilarThreads:   DefaultSimilarThreads,
+	}
+	channel.setWaitingDefaults()
+	return channel
+}
+
+func legacyChannels() []Channel {
+	channel := defaultChannel(viper.GetString("slack_id"))
+	channel.Webhook = viper.GetString("slack_webhook")
+	return []Channel{channel}
+}
+
+// setWaitingDefaults sets the delays of the threads waiting for their requester and of the snoozes when not given.
+// A thread is never closed before its requester is reminded
+func (c *Channel) setWaitingDefaults() {
+	if c.WaitingReminder <= 0 {
+		c.WaitingReminder = DefaultWaitingReminder
+	}
+	if c.WaitingTimeout <= c.WaitingReminder {
+		c.WaitingTimeout = c.WaitingReminder + DefaultWaitingTimeout - DefaultWaitingReminder
+	}
+	if c.SnoozeDuration <= 0 {
+		c.SnoozeDuration = DefaultSnoozeDuration
+	}
+}
+
+// defaultLocale returns the locale of the channels without one, from the locale parameter
+func defaultLocale() string {
+	if locale := viper.GetString("locale"); locale != "" {
+		return locale
+	}
+	return i18n.DefaultLocale
+}
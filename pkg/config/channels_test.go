@@ -0,0 +1,99 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/i18n"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegacyChannel(t *testing.T) {
+	viper.Reset()
+	config.ResetChannels()
+	viper.Set("slack_id", "CLK7MCUS3")
+	viper.Set("slack_webhook", "https://hooks.slack.com/services/legacy")
+
+	channels := config.Channels()
+	assert.Equal(t, 1, len(channels), "legacy configuration shall define one channel")
+	assert.Equal(t, "CLK7MCUS3", channels[0].ID, "channel shall be read from slack_id")
+	assert.Equal(t, config.DefaultReminderInterval, channels[0].ReminderInterval, "default reminder interval shall be used")
+	assert.Equal(t, config.DefaultWaitingTimeout, channels[0].WaitingTimeout, "default waiting timeout shall be used")
+	assert.Equal(t, i18n.DefaultLocale, channels[0].Locale, "default locale shall be used")
+}
+
+func TestChannelsList(t *testing.T) {
+	viper.Reset()
+	config.ResetChannels()
+	viper.Set("slack_id", "CLEGACY")
+	viper.Set("slack_channels", []interface{}{
+		map[string]interface{}{"id": "C1", "name": "support-engprod", "reminder_interval": "30m"},
+		map[string]interface{}{"id": "C2", "name": "support-data", "welcome": "Hello", "disable_reminders": true, "handover_digest": "channel", "locale": "fr", "user_locale": true, "similar_threads": 5, "similar_score": 1.5, "max_answers": 1},
+	})
+	viper.Set("locale", "en")
+
+	channels := config.Channels()
+	assert.Equal(t, 2, len(channels), "every configured channel shall be returned")
+	assert.Equal(t, 30*time.Minute, channels[0].ReminderInterval, "reminder interval shall be parsed")
+	assert.Equal(t, "Hello", channels[1].Welcome, "welcome text shall be read")
+	assert.Equal(t, true, channels[1].DisableReminders, "reminder policy shall be read")
+	assert.Equal(t, config.DefaultReminderInterval, channels[1].ReminderInterval, "default reminder interval shall be used")
+	assert.Equal(t, config.HandoverDirect, channels[0].HandoverDigest, "handover digest shall be sent to the fireman by default")
+	assert.Equal(t, config.HandoverChannel, channels[1].HandoverDigest, "handover digest delivery shall be read")
+	assert.Equal(t, "en", channels[0].Locale, "locale parameter shall be the default locale")
+	assert.Equal(t, "fr", channels[1].Locale, "locale shall be read")
+	assert.Equal(t, true, channels[1].UserLocale, "user locale policy shall be read")
+	assert.Equal(t, config.DefaultMaxAnswers, channels[0].MaxAnswers, "default number of answers shall be used")
+	assert.Equal(t, 1, channels[1].MaxAnswers, "number of answers shall be read")
+	assert.Equal(t, config.DefaultSimilarThreads, channels[0].SimilarThreads, "default number of similar threads shall be used")
+	assert.Equal(t, 5, channels[1].SimilarThreads, "number of similar threads shall be read")
+	assert.Equal(t, 1.5, channels[1].SimilarScore, "minimum score of the similar threads shall be read")
+}
+
+func TestChannelsFromEnvironment(t *testing.T) {
+	viper.Reset()
+	config.ResetChannels()
+	viper.Set("slack_channels", `[{"id": "C1", "reminder_interval": "2h", "waiting_reminder": "48h"}, {"id": "C2", "snooze_duration": "2h"}]`)
+
+	channels := config.Channels()
+	assert.Equal(t, 2, len(channels), "channels shall be parsed from json")
+	assert.Equal(t, 2*time.Hour, channels[0].ReminderInterval, "reminder interval shall be parsed")
+	assert.Equal(t, 48*time.Hour, channels[0].WaitingReminder, "waiting reminder shall be parsed")
+	assert.Equal(t, 96*time.Hour, channels[0].WaitingTimeout, "waiting timeout shall follow the waiting reminder")
+	assert.Equal(t, 2*time.Hour, channels[1].SnoozeDuration, "snooze duration shall be parsed")
+}
+
+func TestGetChannel(t *testing.T) {
+	viper.Reset()
+	config.ResetChannels()
+	viper.Set("slack_channels", `[{"id": "C1"}, {"id": "C2", "welcome": "Hello"}]`)
+
+	channel, ok := config.GetChannel("C2")
+	assert.True(t, ok, "matching channel shall be configured")
+	assert.Equal(t, "Hello", channel.Welcome, "matching channel shall be returned")
+
+	channel, ok = config.GetChannel("")
+	assert.True(t, ok, "default channel shall be configured")
+	assert.Equal(t, "C1", channel.ID, "default channel shall be the first one")
+
+	channel, ok = config.GetChannel("unknown")
+	assert.False(t, ok, "unknown channel shall not be configured")
+	assert.Equal(t, "unknown", channel.ID, "unknown channel shall keep its ID")
+	assert.Empty(t, channel.Welcome, "unknown channel shall not get the settings of another channel")
+	assert.Equal(t, config.DefaultReminderInterval, channel.ReminderInterval, "unknown channel shall get the default settings")
+}
+
+func TestChannelsParsedOnce(t *testing.T) {
+	viper.Reset()
+	config.ResetChannels()
+	viper.Set("slack_channels", `[{"id": "C1"}]`)
+	assert.Equal(t, "C1", config.Channels()[0].ID, "channels shall be parsed")
+
+	viper.Set("slack_channels", `[{"id": "C2"}]`)
+	assert.Equal(t, "C1", config.Channels()[0].ID, "parsed channels shall be kept")
+
+	config.ResetChannels()
+	assert.Equal(t, "C2", config.Channels()[0].ID, "channels shall be parsed again once reset")
+}
@@ -22,4 +22,5 @@ func Initialize() {
 	if err := viper.ReadInConfig(); err != nil { // Handle errors reading the config file
 		log.Errorf("Fatal error config file: %s", err)
 	}
+	ResetChannels()
 }
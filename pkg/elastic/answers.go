@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/olivere/elastic"
+	"github.com/olivere/elastic/v7"
 	log "github.com/sirupsen/logrus"
 	"github.com/leboncoin/subot/pkg/globals"
 )
@@ -22,7 +22,6 @@ func (es ES) AddAnswer(answer globals.Answer) error {
 
 	_, err = es.Client.Index().
 		Index("answers").
-		Type("_doc").
 		BodyString(string(b)).
 		Refresh("true").
 		Do(es.Context)
@@ -65,7 +64,6 @@ func (es ES) checkAnswer(answer globals.Answer) error {
 func (es ES) DeleteAnswer(documentID string) error {
 	_, err := es.Client.Delete().
 		Index("answers").
-		Type("_doc").
 		Id(documentID).
 		Refresh("true").
 		Do(es.Context)
@@ -95,7 +93,6 @@ func (es ES) EditAnswer(documentID string, answer globals.Answer) error {
 
 	_, err = es.Client.Index().
 		Index("answers").
-		Type("_doc").
 		Id(documentID).
 		Refresh("true").
 		BodyString(string(b)).
@@ -107,40 +104,30 @@ func (es ES) EditAnswer(documentID string, answer globals.Answer) error {
 	return nil
 }
 
-// GetAnswers returns all of the answers stored in elasticsearch
-func (es ES) GetAnswers() ([]globals.Answer, error) {
-	query := elastic.NewMatchAllQuery()
-	searchResult, err := es.Client.Search().
-		Index("answers").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
-
+// GetAnswers returns all of the answers of the channel stored in elasticsearch
+func (es ES) GetAnswers(channel string) ([]globals.Answer, error) {
+	query := filterChannel(elastic.NewBoolQuery().Must(elastic.NewMatchAllQuery()), channel)
 	var answers []globals.Answer
-	if searchResult.Hits.TotalHits > 0 {
-		fmt.Printf("Found a total of %d answers\n", searchResult.Hits.TotalHits)
-
-		for _, hit := range searchResult.Hits.Hits {
-			var a globals.Answer
-			err := json.Unmarshal(*hit.Source, &a)
-			a.ID = hit.Id
-			if err != nil {
-				log.Errorf("Unable to deserialize source into answer : %s", err)
-			}
-			answers = append(answers, a)
+	total, err := es.searchAll("answers", query, func(hit *elastic.SearchHit) error {
+		var a globals.Answer
+		err := json.Unmarshal(hit.Source, &a)
+		a.ID = hit.Id
+		if err != nil {
+			log.Errorf("Unable to deserialize source into answer : %s", err)
 		}
+		answers = append(answers, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d answers\n", total)
 
 	return answers, nil
 }
 
-// QueryAnswers returns all answers matching at least one tool or one label from given parameters
-func (es ES) QueryAnswers(tools []string, labels []string) ([]globals.Answer, error) {
+// QueryAnswers returns all answers of the channel matching at least one tool or one label from given parameters
+func (es ES) QueryAnswers(channel string, tools []string, labels []string) ([]globals.Answer, error) {
 	l := stringToInterface(labels)
 	t := stringToInterface(tools)
 	query := elastic.NewBoolQuery()
@@ -154,98 +141,70 @@ func (es ES) QueryAnswers(tools []string, labels []string) ([]globals.Answer, er
 	} else {
 		query = query.Should().MustNot(elastic.NewExistsQuery("label"))
 	}
+	filterChannel(query, channel)
 
-	searchResult, err := es.Client.Search().
-		Index("answers").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
+	var answers []globals.Answer
+	total, err := es.searchAll("answers", query, func(hit *elastic.SearchHit) error {
+		var a globals.Answer
+		err := json.Unmarshal(hit.Source, &a)
+		if err != nil {
+			log.Errorf("unable to deserialize source into answer : %s", err)
+		}
+		a.ID = hit.Id
+		answers = append(answers, a)
+		return nil
+	})
 	if err != nil {
 		log.Errorf("Error while querying elastic %s", err)
 		return nil, err
 	}
-
-	var answers []globals.Answer
-	log.Debugf("Found a total of %d answers\n", searchResult.Hits.TotalHits)
-	if searchResult.Hits.TotalHits > 0 {
-
-		for _, hit := range searchResult.Hits.Hits {
-			var a globals.Answer
-			err := json.Unmarshal(*hit.Source, &a)
-			if err != nil {
-				log.Errorf("unable to deserialize source into answer : %s", err)
-			}
-			answers = append(answers, a)
-		}
-	}
+	log.Debugf("Found a total of %d answers\n", total)
 
 	return answers, nil
 }
 
 // QueryAnswersByLabel returns all answers matching the given label
 func (es ES) QueryAnswersByLabel(label string) ([]globals.Answer, error) {
-	query := elastic.NewTermQuery("label", label)
-
-	searchResult, err := es.Client.Search().
-		Index("answers").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
+	query := elastic.NewTermQuery("label.keyword", label)
 
+	var answers []globals.Answer
+	total, err := es.searchAll("answers", query, func(hit *elastic.SearchHit) error {
+		var a globals.Answer
+		err := json.Unmarshal(hit.Source, &a)
+		if err != nil {
+			log.Errorf("unable to deserialize source into answer : %s", err)
+		}
+		answers = append(answers, a)
+		return nil
+	})
 	if err != nil {
 		log.Errorf("Error while querying elastic %s", err)
 		return nil, err
 	}
-
-	var answers []globals.Answer
-	log.Debugf("Found a total of %d answers\n", searchResult.Hits.TotalHits)
-	if searchResult.Hits.TotalHits > 0 {
-
-		for _, hit := range searchResult.Hits.Hits {
-			var a globals.Answer
-			err := json.Unmarshal(*hit.Source, &a)
-			if err != nil {
-				log.Errorf("unable to deserialize source into answer : %s", err)
-			}
-			answers = append(answers, a)
-		}
-	}
+	log.Debugf("Found a total of %d answers\n", total)
 
 	return answers, nil
 }
 
 // QueryAnswersByTool returns all answers matching at the given tool
 func (es ES) QueryAnswersByTool(tool string) ([]globals.Answer, error) {
-	query := elastic.NewTermQuery("tool", tool)
-
-	searchResult, err := es.Client.Search().
-		Index("answers").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
+	query := elastic.NewTermQuery("tool.keyword", tool)
 
+	var answers []globals.Answer
+	total, err := es.searchAll("answers", query, func(hit *elastic.SearchHit) error {
+		var a globals.Answer
+		err := json.Unmarshal(hit.Source, &a)
+		if err != nil {
+			log.Errorf("unable to deserialize source into answer : %s", err)
+		}
+		answers = append(answers, a)
+		return nil
+	})
 	if err != nil {
 		log.Errorf("Error while querying elastic %s", err)
 		return nil, err
 	}
-
-	var answers []globals.Answer
-	log.Debugf("Found a total of %d answers\n", searchResult.Hits.TotalHits)
-	if searchResult.Hits.TotalHits > 0 {
-
-		for _, hit := range searchResult.Hits.Hits {
-			var a globals.Answer
-			err := json.Unmarshal(*hit.Source, &a)
-			if err != nil {
-				log.Errorf("unable to deserialize source into answer : %s", err)
-			}
-			answers = append(answers, a)
-		}
-	}
+	log.Debugf("Found a total of %d answers\n", total)
 
 	return answers, nil
 }
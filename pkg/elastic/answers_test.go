@@ -2,7 +2,7 @@ package elastic_test
 
 import (
 	"encoding/json"
-	olivere "github.com/olivere/elastic"
+	olivere "github.com/olivere/elastic/v7"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"net/http"
@@ -15,8 +15,8 @@ import (
 func TestQueryAnswersVaultRights(t *testing.T) {
 	tools := []string{"vault"}
 	labels := []string{"rights"}
-	expectedPath := "/answers/_search?pretty=true"
-	expectedQuery := `{"from":0,"query":{"bool":{"filter":[{"terms":{"tool.keyword":["vault"]}},{"terms":{"label.keyword":["rights"]}}]}},"size":1000}`
+	expectedPath := "/answers/_search"
+	expectedQuery := `{"query":{"bool":{"filter":[{"terms":{"tool.keyword":["vault"]}},{"terms":{"label.keyword":["rights"]}}]}},"size":1000}`
 	expectedResponse := elastic.Match{
 		Took:     6,
 		TimedOut: false,
@@ -54,10 +54,11 @@ func TestQueryAnswersVaultRights(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	results, err := e.QueryAnswers(tools, labels)
+	results, err := e.QueryAnswers("", tools, labels)
 	assert.Equal(t, nil, err, "function shall not return errors")
 	assert.Equal(t, 1, len(results), "function shall not return no hits")
 	assert.Equal(t, []globals.Answer{{
+		ID:     "vault-rights",
 		Tool:   "vault",
 		Label:  "rights",
 		Answer: "As-tu bien vérifié le path de ton secret ?\nFormat: `apps/team-<team name>/<app name>/<environment>/<secret name>`\n(sans `/` en début de path :wink:)\nPlus d information disponible dans cette <https://confluence.mpi-internal.com/display/LBCCORE/Vault|documentation>",
@@ -67,8 +68,8 @@ func TestQueryAnswersVaultRights(t *testing.T) {
 func TestQueryAnswersHello(t *testing.T) {
 	var tools []string
 	labels := []string{"hello"}
-	expectedPath := "/answers/_search?pretty=true"
-	expectedQuery := `{"from":0,"query":{"bool":{"filter":{"terms":{"label.keyword":["hello"]}},"must_not":{"exists":{"field":"tool"}}}},"size":1000}`
+	expectedPath := "/answers/_search"
+	expectedQuery := `{"query":{"bool":{"filter":{"terms":{"label.keyword":["hello"]}},"must_not":{"exists":{"field":"tool"}}}},"size":1000}`
 	expectedResponse := elastic.Match{
 		Took:     6,
 		TimedOut: false,
@@ -106,18 +107,39 @@ func TestQueryAnswersHello(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	results, err := e.QueryAnswers(tools, labels)
+	results, err := e.QueryAnswers("", tools, labels)
 	assert.Equal(t, nil, err, "function shall not return errors")
 	assert.Equal(t, 1, len(results), "function shall not return no hits")
 	assert.Equal(t, []globals.Answer{{
+		ID:     "XEXXEF",
 		Tool:   "",
 		Label:  "hello",
 		Answer: "Merci de nous exposer ton problème dans ton message",
 	}}, results, "function shall return expected response")
 }
 
+func TestQueryAnswersByLabelAndTool(t *testing.T) {
+	var bodies []string
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		res.Header().Set("Content-Type", "application/json")
+		_, _ = res.Write([]byte(`{"hits":{"total":0,"hits":[]}}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	_, err := e.QueryAnswersByLabel("rights")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	_, err = e.QueryAnswersByTool("vault")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Len(t, bodies, 2)
+	assert.Contains(t, bodies[0], `{"term":{"label.keyword":"rights"}}`, "query shall match the whole label")
+	assert.Contains(t, bodies[1], `{"term":{"tool.keyword":"vault"}}`, "query shall match the whole tool")
+}
+
 func TestGetAnswers(t *testing.T) {
-	expectedPath := "/answers/_search?pretty=true"
+	expectedPath := "/answers/_search"
 	expectedResponse := elastic.Match{
 		Took:     6,
 		TimedOut: false,
@@ -164,7 +186,7 @@ func TestGetAnswers(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	hits, err := e.GetAnswers()
+	hits, err := e.GetAnswers("")
 	assert.Equal(t, err, nil, "function shall not return errors")
 	assert.Equal(t, len(hits), 2, "function shall not return no hits")
 	assert.Equal(t, globals.Answer{
@@ -193,17 +215,17 @@ func TestAddAnswer(t *testing.T) {
 
 	expectedTools := olivere.SearchResult{
 		Hits: &olivere.SearchHits{
-			TotalHits: 1,
+			TotalHits: &olivere.TotalHits{Value: 1},
 			Hits: []*olivere.SearchHit{{
 				Id:     "Dont care",
-				Source: &h,
+				Source: h,
 			}},
 		},
 	}
 	expectedToolsJSON, err := json.Marshal(expectedTools)
 	assert.Equal(t, nil, err, "Parsing json shall not return errorsn err")
 
-	expectedPaths := []string{"/tools/_search?pretty=true", "/labels/_search?pretty=true", "/answers/_doc/?refresh=true"}
+	expectedPaths := []string{"/tools/_search", "/labels/_search", "/answers/_doc/?refresh=true"}
 
 	serverResponse := map[string]interface{}{
 		"_index":         "firemen",
@@ -253,7 +275,7 @@ func TestEditAnswer(t *testing.T) {
 		Feedback: false,
 	}
 
-	expectedPaths := []string{"/tools/_search?pretty=true", "/labels/_search?pretty=true", "/answers/_doc/I-LEfXQBBlaSKk1R5bDF?refresh=true"}
+	expectedPaths := []string{"/tools/_search", "/labels/_search", "/answers/_doc/I-LEfXQBBlaSKk1R5bDF?refresh=true"}
 
 	serverResponse := map[string]interface{}{
 		"_index":         "firemen",
@@ -275,10 +297,10 @@ func TestEditAnswer(t *testing.T) {
 
 	expectedTools := olivere.SearchResult{
 		Hits: &olivere.SearchHits{
-			TotalHits: 1,
+			TotalHits: &olivere.TotalHits{Value: 1},
 			Hits: []*olivere.SearchHit{{
 				Id:     "Dont care",
-				Source: &h,
+				Source: h,
 			}},
 		},
 	}
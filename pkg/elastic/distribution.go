@@ -0,0 +1,75 @@
+package elastic
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Distribution is the search engine storing the documents
+type Distribution string
+
+const (
+	// Elasticsearch6 is elasticsearch 6.8, its APIs still expecting the _doc mapping type
+	Elasticsearch6 Distribution = "elasticsearch6"
+	// Elasticsearch7 is elasticsearch 7.x
+	Elasticsearch7 Distribution = "elasticsearch7"
+	// Elasticsearch8 is elasticsearch 8.x, spoken to through its compatibility with the 7.x APIs
+	Elasticsearch8 Distribution = "elasticsearch8"
+	// OpenSearch is opensearch 1.x and 2.x
+	OpenSearch Distribution = "opensearch"
+)
+
+// DefaultDistribution is the distribution used when none is configured.
+// It is the elasticsearch 6.8 the deployments ran on before the distribution could be configured
+const DefaultDistribution = Elasticsearch6
+
+// Distributions are the supported search engines
+var Distributions = []Distribution{Elasticsearch6, Elasticsearch7, Elasticsearch8, OpenSearch}
+
+// compatibleWith7 is the media type asking elasticsearch 8 to handle a request as the 7.x APIs would
+const compatibleWith7 = "application/vnd.elasticsearch+json;compatible-with=7"
+
+// ParseDistribution returns the distribution of the given name
+func ParseDistribution(name string) (Distribution, error) {
+	for _, d := range Distributions {
+		if strings.EqualFold(name, string(d)) {
+			return d, nil
+		}
+	}
+	return "", fmt.Errorf("unknown elasticsearch distribution %s, shall be one of %v", name, Distributions)
+}
+
+// Typed tells whether the mappings of the distribution are still under the _doc type
+func (d Distribution) Typed() bool {
+	return d == Elasticsearch6
+}
+
+// CalendarIntervals tells whether the date histograms of the distribution take a calendar_interval
+// instead of the interval removed from elasticsearch 8
+func (d Distribution) CalendarIntervals() bool {
+	return d != Elasticsearch6
+}
+
+// Transport returns the http transport speaking to the distribution
+func (d Distribution) Transport(base http.RoundTripper) http.RoundTripper {
+	if d != Elasticsearch8 {
+		return base
+	}
+	return compatibilityTransport{base: base}
+}
+
+// compatibilityTransport sends the requests of the 7.x client to elasticsearch 8 with the compatibility headers
+type compatibilityTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sets the compatibility headers on a copy of the request and sends it
+func (t compatibilityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept", compatibleWith7)
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		req.Header.Set("Content-Type", compatibleWith7)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,103 @@
+package elastic_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDistribution(t *testing.T) {
+	for _, d := range elastic.Distributions {
+		parsed, err := elastic.ParseDistribution(strings.ToUpper(string(d)))
+		assert.Equal(t, nil, err, "function shall not return errors")
+		assert.Equal(t, d, parsed)
+	}
+	_, err := elastic.ParseDistribution("solr")
+	assert.Error(t, err, "unknown distributions shall be rejected")
+	assert.Equal(t, elastic.Elasticsearch6, elastic.DefaultDistribution, "deployments without a distribution shall keep their elasticsearch 6.8 mappings")
+}
+
+func TestComposeDistribution(t *testing.T) {
+	b, err := ioutil.ReadFile("../../docker-compose.yml")
+	assert.Equal(t, nil, err, "docker-compose.yml shall be read")
+	compose := string(b)
+
+	image := regexp.MustCompile(`image: "elasticsearch:(\d+)\.`).FindStringSubmatch(compose)
+	if assert.Len(t, image, 2, "docker-compose.yml shall run elasticsearch") {
+		distribution := elastic.DefaultDistribution
+		if setting := regexp.MustCompile(`ELASTIC_DISTRIBUTION: (\S+)`).FindStringSubmatch(compose); setting != nil {
+			distribution, err = elastic.ParseDistribution(setting[1])
+			assert.Equal(t, nil, err, "the distribution of docker-compose.yml shall be known")
+		}
+		assert.Equal(t, elastic.Distribution("elasticsearch"+image[1]), distribution, "the distribution shall match the elasticsearch image of docker-compose.yml")
+	}
+}
+
+func TestTemplatesByDistribution(t *testing.T) {
+	for _, d := range elastic.Distributions {
+		cluster := &mockCluster{aliases: map[string]string{}, templates: map[string]string{}}
+		mockESServer := httptest.NewServer(cluster)
+
+		err := MockDistributionClient(t, mockESServer, d).Migrate()
+		assert.Equal(t, nil, err, "function shall not return errors on %s", d)
+		assert.Empty(t, cluster.invalid, "templates shall be valid json on %s", d)
+		for _, template := range elastic.Templates {
+			body := cluster.templates["subot-"+template.Index]
+			assert.Contains(t, body, `"index_patterns": ["`+template.Index+`-*"]`)
+			assert.Equal(t, d == elastic.Elasticsearch6, strings.Contains(body, `"mappings": {"_doc": {"properties"`),
+				"only elasticsearch 6 mappings shall have a type on %s", d)
+		}
+		mockESServer.Close()
+	}
+}
+
+func TestHeadersByDistribution(t *testing.T) {
+	for _, d := range elastic.Distributions {
+		var accept, contentType string
+		mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			accept = req.Header.Get("Accept")
+			contentType = req.Header.Get("Content-Type")
+			res.Header().Set("Content-Type", "application/json")
+			_, _ = res.Write([]byte(`{"_index":"messages","_id":"C1-1592208201.000100","result":"created"}`))
+		}))
+
+		err := MockDistributionClient(t, mockESServer, d).AddMessage(globals.Message{Channel: "C1", Timestamp: "1592208201.000100"})
+		assert.Equal(t, nil, err, "function shall not return errors on %s", d)
+		if d == elastic.Elasticsearch8 {
+			assert.Equal(t, "application/vnd.elasticsearch+json;compatible-with=7", accept)
+			assert.Equal(t, "application/vnd.elasticsearch+json;compatible-with=7", contentType)
+		} else {
+			assert.NotContains(t, accept, "compatible-with", "only elasticsearch 8 shall be asked for compatibility on %s", d)
+			assert.Equal(t, "application/json", contentType)
+		}
+		mockESServer.Close()
+	}
+}
+
+func TestTimeSeriesIntervalByDistribution(t *testing.T) {
+	for _, d := range elastic.Distributions {
+		var body string
+		mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			b, _ := ioutil.ReadAll(req.Body)
+			body = string(b)
+			res.Header().Set("Content-Type", "application/json")
+			_, _ = res.Write([]byte(`{"hits":{"total":{"value":0,"relation":"eq"},"hits":[]},"aggregations":{"dates":{"buckets":[]}}}`))
+		}))
+
+		_, err := MockDistributionClient(t, mockESServer, d).QueryTimeSeries(globals.TimeSeriesQuery{Start: "1592172000", End: "1592776800", Interval: "week"})
+		assert.Equal(t, nil, err, "function shall not return errors on %s", d)
+		if d == elastic.Elasticsearch6 {
+			assert.Contains(t, body, `"interval":"week"`)
+		} else {
+			assert.Contains(t, body, `"calendar_interval":"week"`, "the interval removed from elasticsearch 8 shall not be used on %s", d)
+		}
+		mockESServer.Close()
+	}
+}
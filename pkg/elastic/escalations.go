@@ -0,0 +1,99 @@
+package elastic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/olivere/elastic/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddEscalationPolicy stores the escalation policy in the elastic search index
+func (es ES) AddEscalationPolicy(policy globals.EscalationPolicy) error {
+	if len(policy.Steps) == 0 {
+		return errors.New("cannot create escalation policy without steps")
+	}
+
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index("escalations").
+		Id(policy.ID).
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+
+	if err != nil {
+		return fmt.Errorf("error creating document : %s", err.Error())
+	}
+	return nil
+}
+
+// EditEscalationPolicy Modifies the escalation policy matching the given documentID
+func (es ES) EditEscalationPolicy(documentID string, policy globals.EscalationPolicy) error {
+	if documentID == "" {
+		return errors.New("cannot edit escalation policy without documentID")
+	}
+	if len(policy.Steps) == 0 {
+		return errors.New("cannot edit escalation policy without steps")
+	}
+
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index("escalations").
+		Id(documentID).
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+
+	return err
+}
+
+// DeleteEscalationPolicy removes the escalation policy from the elastic search index
+func (es ES) DeleteEscalationPolicy(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+
+	_, err := es.Client.Delete().
+		Index("escalations").
+		Refresh("true").
+		Id(documentID).
+		Do(es.Context)
+
+	return err
+}
+
+// GetEscalationPolicies returns the escalation policies of the channel and the ones shared by every channel
+func (es ES) GetEscalationPolicies(channel string) ([]globals.EscalationPolicy, error) {
+	query := filterChannel(elastic.NewBoolQuery().Must(elastic.NewMatchAllQuery()), channel)
+	var policies []globals.EscalationPolicy
+	_, err := es.searchAll("escalations", query, func(hit *elastic.SearchHit) error {
+		var policy globals.EscalationPolicy
+		if err := json.Unmarshal(hit.Source, &policy); err != nil {
+			log.Errorf("Unable to deserialize source into escalation policy : %s", err)
+			return nil
+		}
+		policy.ID = hit.Id
+		policies = append(policies, policy)
+		return nil
+	})
+	if elastic.IsNotFound(err) {
+		log.Debug("No escalations index, no escalation policy defined")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,98 @@
+package elastic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	olivere "github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEscalationPolicies(t *testing.T) {
+	expectedPath := "/escalations/_search"
+	vault := json.RawMessage(`{"channel": "CLK7MCUS3", "tool": "vault",
+		"steps": [{"after": "1h", "target": "fireman"}, {"after": "4h", "target": "group", "slack_id": "STEAM"}]}`)
+	expectedResponse := olivere.SearchResult{
+		Hits: &olivere.SearchHits{
+			TotalHits: &olivere.TotalHits{Value: 1},
+			Hits:      []*olivere.SearchHit{{Id: "policy0", Source: vault}},
+		},
+	}
+	expectedJSONResponse, err := json.Marshal(expectedResponse)
+	assert.Equal(t, nil, err, "Parsing json shall not return errors")
+
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		res.WriteHeader(200)
+		_, err := res.Write(expectedJSONResponse)
+		assert.Equal(t, nil, err, "Parsing json shall not return errors")
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	policies, err := e.GetEscalationPolicies("CLK7MCUS3")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, []globals.EscalationPolicy{{
+		ID:      "policy0",
+		Channel: "CLK7MCUS3",
+		Tool:    "vault",
+		Steps: []globals.EscalationStep{
+			{After: "1h", Target: globals.EscalateFireman},
+			{After: "4h", Target: globals.EscalateGroup, SlackID: "STEAM"},
+		},
+	}}, policies, "function shall return expected response")
+}
+
+func TestGetEscalationPoliciesWithoutIndex(t *testing.T) {
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(404)
+		_, _ = res.Write([]byte(`{"error": {"type": "index_not_found_exception"}, "status": 404}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	policies, err := e.GetEscalationPolicies("")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, 0, len(policies), "function shall not return any escalation policy")
+}
+
+func TestAddEscalationPolicy(t *testing.T) {
+	expectedPath := "/escalations/_doc/I-LEfXQBBlaSKk1R5bDF?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		var policy globals.EscalationPolicy
+		assert.Equal(t, nil, json.NewDecoder(req.Body).Decode(&policy), "body shall be an escalation policy")
+		assert.Equal(t, 1, len(policy.Steps), "body shall contain the steps")
+		res.WriteHeader(201)
+		_, _ = res.Write([]byte(`{"_index": "escalations", "_type": "_doc", "_id": "I-LEfXQBBlaSKk1R5bDF", "result": "created"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	err := e.AddEscalationPolicy(globals.EscalationPolicy{
+		ID:    "I-LEfXQBBlaSKk1R5bDF",
+		Steps: []globals.EscalationStep{{After: "1h", Target: globals.EscalateFireman}},
+	})
+	assert.Equal(t, nil, err, "function shall not return errors")
+
+	err = e.AddEscalationPolicy(globals.EscalationPolicy{Tool: "vault"})
+	assert.NotEqual(t, nil, err, "function shall refuse a policy without steps")
+}
+
+func TestDeleteEscalationPolicy(t *testing.T) {
+	expectedPath := "/escalations/_doc/I-LEfXQBBlaSKk1R5bDF?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		assert.Equal(t, "DELETE", req.Method, "Wrong method")
+		res.WriteHeader(200)
+		_, _ = res.Write([]byte(`{"_index": "escalations", "_type": "_doc", "_id": "I-LEfXQBBlaSKk1R5bDF", "result": "deleted"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	assert.Equal(t, nil, e.DeleteEscalationPolicy("I-LEfXQBBlaSKk1R5bDF"), "function shall not return errors")
+	assert.NotEqual(t, nil, e.DeleteEscalationPolicy(""), "function shall refuse an empty id")
+}
@@ -5,7 +5,7 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/olivere/elastic"
+	"github.com/olivere/elastic/v7"
 	log "github.com/sirupsen/logrus"
 	"github.com/leboncoin/subot/pkg/globals"
 )
@@ -19,7 +19,6 @@ func (es ES) AddFireman(message globals.Message) error {
 
 	_, err = es.Client.Index().
 		Index("firemen").
-		Type("_doc").
 		BodyString(string(b)).
 		Do(es.Context)
 
@@ -31,7 +30,7 @@ func (es ES) AddFireman(message globals.Message) error {
 }
 
 // QueryRangeFireman returns the fireman of the chan for the range
-func (es ES) QueryRangeFireman(start string, end string) ([]globals.Message, error) {
+func (es ES) QueryRangeFireman(channel string, start string, end string) ([]globals.Message, error) {
 	startTs, err := time.Parse("2006-01-02", start)
 	if err != nil {
 		return nil, err
@@ -45,28 +44,24 @@ func (es ES) QueryRangeFireman(start string, end string) ([]globals.Message, err
 		Gte(strconv.FormatInt(startTs.Unix(), 10)).
 		Lte(strconv.FormatInt(endTs.Unix(), 10))
 
-	searchResult, err := es.Client.Search().
-		Index("firemen").
-		Query(rangeQuery).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
+	query := elastic.NewBoolQuery()
+	query.Filter(rangeQuery)
+	filterChannel(query, channel)
 
 	var messages []globals.Message
-	if searchResult.Hits.TotalHits > 0 {
-		for _, hit := range searchResult.Hits.Hits {
-			var m globals.Message
-			err := json.Unmarshal(*hit.Source, &m)
-			if err != nil {
-				log.Errorf("unable to deserialize source into message : %s", err)
-			}
-			messages = append(messages, m)
+	total, err := es.searchAll("firemen", query, func(hit *elastic.SearchHit) error {
+		var m globals.Message
+		err := json.Unmarshal(hit.Source, &m)
+		if err != nil {
+			log.Errorf("unable to deserialize source into message : %s", err)
 		}
+		messages = append(messages, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d firemen\n", total)
 
 	return messages, nil
 }
\ No newline at end of file
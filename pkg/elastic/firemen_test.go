@@ -14,7 +14,7 @@ import (
 func TestQueryRangeFireman(t *testing.T) {
 	start := "2020-01-01"
 	end := "2020-01-07"
-	expectedPath := "/firemen/_search?pretty=true"
+	expectedPath := "/firemen/_search"
 	expectedMessage := globals.Message{
 		Type:     "topic",
 		Status:   "",
@@ -83,7 +83,7 @@ func TestQueryRangeFireman(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	hits, err := e.QueryRangeFireman(start, end)
+	hits, err := e.QueryRangeFireman("", start, end)
 	assert.Equal(t, nil, err, "function shall not return errors")
 	assert.Equal(t, 1, len(hits), "function shall not return no hits")
 	assert.Equal(t, expectedMessage, hits[0], "function shall return expected response")
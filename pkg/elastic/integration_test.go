@@ -0,0 +1,112 @@
+//go:build integration
+// +build integration
+
+package elastic_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage/storagetest"
+	olivere "github.com/olivere/elastic/v7"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// integrationClient returns the storage of the cluster of ELASTIC_URL, of the distribution given by ELASTIC_DISTRIBUTION
+func integrationClient(t *testing.T) elastic.ES {
+	if os.Getenv("ELASTIC_URL") == "" {
+		t.Skip("ELASTIC_URL is not set")
+	}
+	viper.Set("elastic_url", os.Getenv("ELASTIC_URL"))
+	if distribution := os.Getenv("ELASTIC_DISTRIBUTION"); distribution != "" {
+		viper.Set("elastic_distribution", distribution)
+	}
+	es, err := elastic.Configure(true)
+	require.NoError(t, err)
+	require.NoError(t, es.Migrate(), "indices shall be created")
+	require.NoError(t, es.Migrate(), "migrating twice shall not fail")
+	return es
+}
+
+// TestIntegration runs the storage against the empty cluster of ELASTIC_URL. See make test-storage
+func TestIntegration(t *testing.T) {
+	es := integrationClient(t)
+
+	ts := time.Now().Add(-time.Hour)
+	for i, status := range []globals.MessageStatus{globals.StatusFixed, globals.StatusUnresponded} {
+		require.NoError(t, es.AddMessage(globals.Message{
+			Type:           globals.MessageType("user"),
+			Channel:        "C1",
+			Status:         status,
+			Labels:         []string{"rights"},
+			Text:           "please give me the rights on vault",
+			Timestamp:      strconv.FormatInt(ts.Unix()+int64(i*60), 10) + ".000100",
+			ResponseTime:   10,
+			ResolutionTime: 20,
+			Replies:        []globals.Reply{{UserID: "U2", Text: "done"}},
+		}))
+	}
+	require.NoError(t, es.AddLabel(globals.Perco{ID: "rights", Name: "rights", Query: globals.Query{Regexp: globals.Regexp{Input: "rights?"}}}))
+	_, err := es.Client.Refresh("messages", "labels").Do(es.Context)
+	require.NoError(t, err)
+
+	start := ts.Add(-time.Hour).Format(globals.DateLayout)
+	end := time.Now().Add(24 * time.Hour).Format(globals.DateLayout)
+	messages, err := es.QueryRangeMessages("C1", start, end)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+
+	page, err := es.QueryMessagesPage(globals.MessageQuery{Channel: "C1", Start: start, End: end, Search: "vault", Size: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 1)
+	page, err = es.QueryMessagesPage(globals.MessageQuery{Channel: "C1", Start: start, End: end, Search: "vault", Size: 1, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 1)
+
+	message, err := es.GetMessage("C1", messages[0].Timestamp)
+	assert.NoError(t, err)
+	assert.Equal(t, "done", message.Replies[0].Text)
+
+	labels, err := es.QueryLabels("C1", "I need some rights")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rights"}, labels)
+
+	aggregations, err := es.AggregateMessages("C1", start, end, []globals.MessageStatus{globals.StatusFixed})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, aggregations.Statuses[globals.StatusFixed])
+
+	series, err := es.QueryTimeSeries(globals.TimeSeriesQuery{Channel: "C1", Start: start, End: end, Interval: "day", GroupBy: "status"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, series.Buckets)
+}
+
+// TestContract runs the storage contract against the cluster of ELASTIC_URL, whose documents are deleted
+func TestContract(t *testing.T) {
+	es := integrationClient(t)
+	var indices []string
+	for _, template := range elastic.Templates {
+		indices = append(indices, template.Index)
+	}
+
+	storagetest.Suite{
+		Open: func(t *testing.T) elastic.Interface {
+			_, err := es.Client.DeleteByQuery(indices...).
+				Query(olivere.NewMatchAllQuery()).
+				ProceedOnVersionConflict().
+				Refresh("true").
+				Do(es.Context)
+			require.NoError(t, err)
+			return es
+		},
+		Refresh: func(t *testing.T) {
+			_, err := es.Client.Refresh(indices...).Do(es.Context)
+			require.NoError(t, err)
+		},
+	}.Run(t)
+}
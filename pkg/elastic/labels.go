@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/olivere/elastic"
+	"github.com/olivere/elastic/v7"
 	log "github.com/sirupsen/logrus"
 	"github.com/leboncoin/subot/pkg/globals"
 )
@@ -23,7 +23,6 @@ func (es ES) AddLabel(label globals.Perco) error {
 	_, err = es.Client.Index().
 		Index("labels").
 		Id(label.ID).
-		Type("_doc").
 		Refresh("true").
 		BodyString(string(b)).
 		Do(es.Context)
@@ -55,7 +54,6 @@ func (es ES) DeleteLabel(documentID string) error {
 
 	_, err = es.Client.Delete().
 		Index("labels").
-		Type("_doc").
 		Refresh("true").
 		Id(documentID).
 		Do(es.Context)
@@ -80,7 +78,6 @@ func (es ES) EditLabel(documentID string, label globals.Perco) error {
 
 	_, err = es.Client.Index().
 		Index("labels").
-		Type("_doc").
 		Id(documentID).
 		Refresh("true").
 		BodyString(string(b)).
@@ -92,67 +89,49 @@ func (es ES) EditLabel(documentID string, label globals.Perco) error {
 	return nil
 }
 
-// GetLabels returns the list of all the labels (using the match_all query)
-func (es ES) GetLabels() ([]globals.Perco, error) {
-	query := elastic.NewMatchAllQuery()
-	searchResult, err := es.Client.Search().
-		Index("labels").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
-
+// GetLabels returns the list of all the labels of the channel (using the match_all query)
+func (es ES) GetLabels(channel string) ([]globals.Perco, error) {
+	query := filterChannel(elastic.NewBoolQuery().Must(elastic.NewMatchAllQuery()), channel)
 	var labels []globals.Perco
-	if searchResult.Hits.TotalHits > 0 {
-		fmt.Printf("Found a total of %d labels\n", searchResult.Hits.TotalHits)
-
-		for _, hit := range searchResult.Hits.Hits {
-			var p globals.Perco
-			err := json.Unmarshal(*hit.Source, &p)
-			p.ID = hit.Id
-			if err != nil {
-				log.Errorf("Unable to deserialize source into perco : %s", err)
-			}
-			labels = append(labels, p)
+	total, err := es.searchAll("labels", query, func(hit *elastic.SearchHit) error {
+		var p globals.Perco
+		err := json.Unmarshal(hit.Source, &p)
+		p.ID = hit.Id
+		if err != nil {
+			log.Errorf("Unable to deserialize source into perco : %s", err)
 		}
+		labels = append(labels, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d labels\n", total)
 
 	return labels, nil
 }
 
-// QueryLabels returns all the labels that match the input text based on percolate search
-func (es ES) QueryLabels(text string) ([]string, error) {
+// QueryLabels returns all the labels of the channel that match the input text based on percolate search
+func (es ES) QueryLabels(channel string, text string) ([]string, error) {
 	pq := elastic.NewPercolatorQuery().
 		Field("query").
 		Document(map[string]interface{}{"input": text})
-
-	searchResult, err := es.Client.Search().
-		Index("labels").
-		Query(pq).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
+	query := filterChannel(elastic.NewBoolQuery().Must(pq), channel)
 
 	var labels []string
-	if searchResult.Hits.TotalHits > 0 {
-		fmt.Printf("Found a total of %d labels\n", searchResult.Hits.TotalHits)
-		for _, hit := range searchResult.Hits.Hits {
-			var label globals.Perco
-			err := json.Unmarshal(*hit.Source, &label)
-			if err != nil {
-				return nil, err
-			}
-			labels = append(labels, label.Name)
+	total, err := es.searchAll("labels", query, func(hit *elastic.SearchHit) error {
+		var label globals.Perco
+		err := json.Unmarshal(hit.Source, &label)
+		if err != nil {
+			return err
 		}
+		labels = append(labels, label.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d labels\n", total)
 
 	return labels, nil
 }
@@ -171,7 +150,7 @@ func (es ES) QueryLabelByID(id string) (globals.Perco, error) {
 
 	if searchResult.Found {
 		log.Debug("Found a label matching this ID")
-		err := json.Unmarshal(*searchResult.Source, &label)
+		err := json.Unmarshal(searchResult.Source, &label)
 		label.ID = searchResult.Id
 		if err != nil {
 			return label, err
@@ -185,30 +164,21 @@ func (es ES) QueryLabelByID(id string) (globals.Perco, error) {
 func (es ES) QueryLabelByName(name string) ([]globals.Perco, error) {
 	query := elastic.NewTermQuery("name.keyword", name)
 
-	searchResult, err := es.Client.Search().
-		Index("labels").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
-
 	var labels []globals.Perco
-	if searchResult.Hits.TotalHits > 0 {
-		fmt.Printf("Found a total of %d labels\n", searchResult.Hits.TotalHits)
-		for _, hit := range searchResult.Hits.Hits {
-			var label globals.Perco
-			err := json.Unmarshal(*hit.Source, &label)
-			label.ID = hit.Id
-			if err != nil {
-				log.Errorf("Unable to deserialize source into answer : %s", err)
-			}
-			labels = append(labels, label)
+	total, err := es.searchAll("labels", query, func(hit *elastic.SearchHit) error {
+		var label globals.Perco
+		err := json.Unmarshal(hit.Source, &label)
+		label.ID = hit.Id
+		if err != nil {
+			log.Errorf("Unable to deserialize source into answer : %s", err)
 		}
+		labels = append(labels, label)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d labels\n", total)
 
 	return labels, nil
 }
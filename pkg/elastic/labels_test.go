@@ -2,7 +2,7 @@ package elastic_test
 
 import (
 	"encoding/json"
-	olivere "github.com/olivere/elastic"
+	olivere "github.com/olivere/elastic/v7"
 	"net/http"
 	"net/http/httptest"
 	"github.com/leboncoin/subot/pkg/elastic"
@@ -14,16 +14,16 @@ import (
 
 func TestQueryLabels(t *testing.T) {
 	label := "rights"
-	expectedPath := "/labels/_search?pretty=true"
+	expectedPath := "/labels/_search"
 
 	h := json.RawMessage(`{"name": "mock"}`)
 
 	expectedLabels := olivere.SearchResult{
 		Hits: &olivere.SearchHits{
-			TotalHits: 1,
+			TotalHits: &olivere.TotalHits{Value: 1},
 			Hits: []*olivere.SearchHit{{
 				Id:     "Dont care",
-				Source: &h,
+				Source: h,
 			}},
 		},
 	}
@@ -41,7 +41,7 @@ func TestQueryLabels(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	hits, err := e.QueryLabels(label)
+	hits, err := e.QueryLabels("", label)
 	assert.Equal(t,  nil, err,"function shall not return errors")
 	assert.Equal(t, 1, len(hits), "function shall not return no hits")
 	assert.Equal(t, "mock", hits[0], "function shall return expected response")
@@ -95,7 +95,7 @@ func TestAddLabel(t *testing.T) {
 }
 
 func TestGetLabels(t *testing.T) {
-	expectedPath := "/labels/_search?pretty=true"
+	expectedPath := "/labels/_search"
 	expectedResponse := elastic.Match{
 		Took: 6,
 		TimedOut: false,
@@ -148,7 +148,7 @@ func TestGetLabels(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	hits, err := e.GetLabels()
+	hits, err := e.GetLabels("")
 	assert.Equal(t, err, nil, "function shall not return errors")
 	assert.Equal(t, len(hits), 3, "function shall not return no hits")
 	assert.Equal(t,  expectedResponse.Hits.Hits[0].ID, hits[0].ID, "function shall return expected response")
@@ -2,15 +2,17 @@ package elastic
 
 import (
 	"context"
-	olivere "github.com/olivere/elastic"
+	"net/http"
+	olivere "github.com/olivere/elastic/v7"
 	"github.com/spf13/viper"
 	"log"
 )
 
 // ES is a struct representing the elasticsearch instance
 type ES struct {
-	Client  *olivere.Client `json:"Client"`
-	Context context.Context `json:"context"`
+	Client       *olivere.Client `json:"Client"`
+	Context      context.Context `json:"context"`
+	Distribution Distribution    `json:"distribution"`
 }
 
 // Configure returns an instance of the ES struct
@@ -18,9 +20,16 @@ func Configure(testing bool) (instance ES, err error) {
 	host := viper.GetString("elastic_url")
 	ctx := context.Background()
 
+	viper.SetDefault("elastic_distribution", string(DefaultDistribution))
+	distribution, err := ParseDistribution(viper.GetString("elastic_distribution"))
+	if err != nil {
+		return instance, err
+	}
+
 	client, err := olivere.NewClient(
 		olivere.SetURL(host),
 		olivere.SetSniff(false), olivere.SetHealthcheck(false),
+		olivere.SetHttpClient(&http.Client{Transport: distribution.Transport(http.DefaultTransport)}),
 	)
 	if err != nil {
 		// Handle error
@@ -34,17 +43,15 @@ func Configure(testing bool) (instance ES, err error) {
 			// Handle error
 			log.Fatalf("Unable to ping elasticsearch : %s", err)
 		}
+	}
 
-		for _, index := range []string{"answers", "firemen", "ignores", "labels", "messages", "overrides", "team", "tools"} {
-			exists, err := client.IndexExists("answers").Do(ctx)
-			if err != nil || !exists {
-				// Handle error
-				log.Fatalf("Missing index %s", index)
-			}
+	instance = ES{Client: client, Context: ctx, Distribution: distribution}
+
+	if !testing {
+		if err := instance.Migrate(); err != nil {
+			log.Fatalf("Unable to migrate the elasticsearch indices : %s", err)
 		}
 	}
 
-	instance = ES{Client: client, Context: ctx}
-
 	return
 }
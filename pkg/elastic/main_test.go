@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/viper"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	log "github.com/sirupsen/logrus"
@@ -28,7 +29,17 @@ func TestConfigure(t *testing.T) {
 	}
 }
 
+// MockClient returns a client of the mocked server, for the distribution given by ELASTIC_DISTRIBUTION if any
 func MockClient(t *testing.T, testServer *httptest.Server) elastic.ES {
+	return MockDistributionClient(t, testServer, elastic.Distribution(os.Getenv("ELASTIC_DISTRIBUTION")))
+}
+
+// MockDistributionClient returns a client of the mocked server for the given distribution, the default one when empty
+func MockDistributionClient(t *testing.T, testServer *httptest.Server, distribution elastic.Distribution) elastic.ES {
+	if distribution == "" {
+		distribution = elastic.DefaultDistribution
+	}
+	viper.Set("elastic_distribution", string(distribution))
 	viper.Set("elastic_url", testServer.URL)
 	c, err := elastic.Configure(true)
 	assert.Equal(t, nil, err, "initializing es client should not return errors")
@@ -0,0 +1,77 @@
+package elastic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/olivere/elastic/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+// SetMessageTemplate stores the template of the message in the elastic search index,
+// replacing the one of the same locale and key
+func (es ES) SetMessageTemplate(template globals.MessageTemplate) error {
+	if template.Locale == "" || template.Key == "" {
+		return errors.New("cannot store message template without locale and key")
+	}
+
+	b, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index("message_templates").
+		Id(template.DocumentID()).
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+
+	if err != nil {
+		return fmt.Errorf("error creating document : %s", err.Error())
+	}
+	return nil
+}
+
+// DeleteMessageTemplate removes the template of the message in the locale from the elastic search index
+func (es ES) DeleteMessageTemplate(locale string, key string) error {
+	if locale == "" || key == "" {
+		return errors.New("cannot delete message template without locale and key")
+	}
+
+	_, err := es.Client.Delete().
+		Index("message_templates").
+		Refresh("true").
+		Id(globals.MessageTemplate{Locale: locale, Key: key}.DocumentID()).
+		Do(es.Context)
+
+	if elastic.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// GetMessageTemplates returns the templates of the messages edited through the api, in every locale
+func (es ES) GetMessageTemplates() ([]globals.MessageTemplate, error) {
+	var templates []globals.MessageTemplate
+	_, err := es.searchAll("message_templates", elastic.NewMatchAllQuery(), func(hit *elastic.SearchHit) error {
+		var template globals.MessageTemplate
+		if err := json.Unmarshal(hit.Source, &template); err != nil {
+			log.Errorf("Unable to deserialize source into message template : %s", err)
+			return nil
+		}
+		templates = append(templates, template)
+		return nil
+	})
+	if elastic.IsNotFound(err) {
+		log.Debug("No message_templates index, no message template edited")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,90 @@
+package elastic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	olivere "github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMessageTemplates(t *testing.T) {
+	expectedPath := "/message_templates/_search"
+	fireman := json.RawMessage(`{"locale": "fr", "key": "reminder.fireman", "text": "Alors {{mention .Fireman}} ?"}`)
+	expectedResponse := olivere.SearchResult{
+		Hits: &olivere.SearchHits{
+			TotalHits: &olivere.TotalHits{Value: 1},
+			Hits:      []*olivere.SearchHit{{Id: "fr.reminder.fireman", Source: fireman}},
+		},
+	}
+	expectedJSONResponse, err := json.Marshal(expectedResponse)
+	assert.Equal(t, nil, err, "Parsing json shall not return errors")
+
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		res.WriteHeader(200)
+		_, err := res.Write(expectedJSONResponse)
+		assert.Equal(t, nil, err, "Parsing json shall not return errors")
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	templates, err := e.GetMessageTemplates()
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, []globals.MessageTemplate{{
+		Locale: "fr",
+		Key:    "reminder.fireman",
+		Text:   "Alors {{mention .Fireman}} ?",
+	}}, templates, "function shall return expected response")
+}
+
+func TestGetMessageTemplatesWithoutIndex(t *testing.T) {
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(404)
+		_, _ = res.Write([]byte(`{"error": {"type": "index_not_found_exception"}, "status": 404}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	templates, err := e.GetMessageTemplates()
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, 0, len(templates), "function shall not return any message template")
+}
+
+func TestSetMessageTemplate(t *testing.T) {
+	expectedPath := "/message_templates/_doc/en.reminder.fireman?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		var template globals.MessageTemplate
+		assert.Equal(t, nil, json.NewDecoder(req.Body).Decode(&template), "body shall be a message template")
+		assert.Equal(t, "So?", template.Text, "body shall contain the text")
+		res.WriteHeader(201)
+		_, _ = res.Write([]byte(`{"_index": "message_templates", "_type": "_doc", "_id": "en.reminder.fireman", "result": "created"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	err := e.SetMessageTemplate(globals.MessageTemplate{Locale: "en", Key: "reminder.fireman", Text: "So?"})
+	assert.Equal(t, nil, err, "function shall not return errors")
+
+	err = e.SetMessageTemplate(globals.MessageTemplate{Key: "reminder.fireman", Text: "So?"})
+	assert.NotEqual(t, nil, err, "function shall refuse a template without locale")
+}
+
+func TestDeleteMessageTemplate(t *testing.T) {
+	expectedPath := "/message_templates/_doc/en.reminder.fireman?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		assert.Equal(t, "DELETE", req.Method, "Wrong method")
+		res.WriteHeader(200)
+		_, _ = res.Write([]byte(`{"_index": "message_templates", "_type": "_doc", "_id": "en.reminder.fireman", "result": "deleted"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	assert.Equal(t, nil, e.DeleteMessageTemplate("en", "reminder.fireman"), "function shall not return errors")
+	assert.NotEqual(t, nil, e.DeleteMessageTemplate("en", ""), "function shall refuse an empty key")
+}
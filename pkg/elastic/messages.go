@@ -3,8 +3,9 @@ package elastic
 import (
 	"encoding/json"
 	"errors"
-	"github.com/olivere/elastic"
+	"github.com/olivere/elastic/v7"
 	"strconv"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -12,7 +13,8 @@ import (
 	"github.com/leboncoin/subot/pkg/globals"
 )
 
-// AddMessage stores a message in ES index
+// AddMessage stores a message in ES index.
+// The document ID is computed from the channel and the timestamp when not given
 func (es ES) AddMessage(message globals.Message, id ...string) (err error) {
 	var documentID string
 	body := message
@@ -24,11 +26,13 @@ func (es ES) AddMessage(message globals.Message, id ...string) (err error) {
 	if len(id) > 0 {
 		documentID = id[0]
 	}
+	if documentID == "" {
+		documentID = globals.MessageID(message.Channel, message.Timestamp)
+	}
 
 	_, err = es.Client.Index().
 		Index("messages").
 		Id(documentID).
-		Type("_doc").
 		BodyString(string(b)).
 		Do(es.Context)
 
@@ -38,27 +42,30 @@ func (es ES) AddMessage(message globals.Message, id ...string) (err error) {
 	return nil
 }
 
-// DeleteMessage stores a message in ES index
-func (es ES) DeleteMessage(messageTs string) (err error) {
-	query := elastic.NewTermQuery("ts", messageTs)
+// DeleteMessage removes the message posted at the timestamp in the channel from the ES index
+func (es ES) DeleteMessage(channel string, messageTs string) error {
+	documentID := globals.MessageID(channel, messageTs)
+	if documentID == "" {
+		return errors.New("cannot delete message without timestamp")
+	}
 
-	_, err = es.Client.DeleteByQuery().
+	_, err := es.Client.Delete().
 		Index("messages").
-		Type("_doc").
-		Query(query).
+		Id(documentID).
 		Do(es.Context)
 
-	if err != nil {
+	if err != nil && !elastic.IsNotFound(err) {
 		return err
 	}
 
 	return nil
 }
 
-// EditMessage updates a message in the elasticsearch database
-func (es ES) EditMessage(documentID string, message globals.Message) error {
+// EditMessage replaces the message posted at the timestamp in the channel in the elasticsearch database
+func (es ES) EditMessage(channel string, messageTs string, message globals.Message) error {
+	documentID := globals.MessageID(channel, messageTs)
 	if documentID == "" {
-		return errors.New("cannot edit tool without documentID")
+		return errors.New("cannot edit message without timestamp")
 	}
 
 	b, err := json.Marshal(message)
@@ -68,7 +75,6 @@ func (es ES) EditMessage(documentID string, message globals.Message) error {
 
 	_, err = es.Client.Index().
 		Index("messages").
-		Type("_doc").
 		Refresh("true").
 		Id(documentID).
 		BodyString(string(b)).
@@ -80,12 +86,12 @@ func (es ES) EditMessage(documentID string, message globals.Message) error {
 	return nil
 }
 
-// QueryLastUserMessages returns the last messages of the given user in the last 2 minutes
-func (es ES) QueryLastUserMessages(userID string) ([]globals.Message, error) {
+// QueryLastUserMessages returns the last messages of the given user in the channel in the last 2 minutes
+func (es ES) QueryLastUserMessages(channel string, userID string) ([]globals.Message, error) {
 	end := time.Now()
 	start := end.Add(-2 * time.Minute)
 
-	termQuery := elastic.NewTermQuery("userID", userID)
+	termQuery := elastic.NewTermQuery("user.keyword", userID)
 	rangeQuery := elastic.NewRangeQuery("ts").
 		Gte(strconv.FormatInt(start.Unix(), 10)).
 		Lte(strconv.FormatInt(end.Unix(), 10))
@@ -93,86 +99,75 @@ func (es ES) QueryLastUserMessages(userID string) ([]globals.Message, error) {
 	query := elastic.NewBoolQuery()
 	query.Filter(termQuery)
 	query.Filter(rangeQuery)
-
-	searchResult, err := es.Client.Search().
-		Index("messages").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
+	filterChannel(query, channel)
 
 	var messages []globals.Message
-	log.Debugf("Found a total of %d messages\n", searchResult.Hits.TotalHits)
-	if searchResult.Hits.TotalHits > 0 {
-
-		for _, hit := range searchResult.Hits.Hits {
-			var m globals.Message
-			err := json.Unmarshal(*hit.Source, &m)
-			m.ID = hit.Id
-			if err != nil {
-				log.Errorf("unable to deserialize source into answer : %s", err)
-			}
-			messages = append(messages, m)
+	total, err := es.searchAll("messages", query, func(hit *elastic.SearchHit) error {
+		var m globals.Message
+		err := json.Unmarshal(hit.Source, &m)
+		m.ID = hit.Id
+		if err != nil {
+			log.Errorf("unable to deserialize source into answer : %s", err)
 		}
+		messages = append(messages, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d messages\n", total)
 
 	return messages, nil
 }
 
-// QueryReminderMessages returns a list of messages in a timestamp range
+// QueryReminderMessages returns a list of messages in a timestamp range.
+// The messages closed according to the workflow of their channel are left out
 func (es ES) QueryReminderMessages() ([]globals.Message, error) {
 	start := strconv.FormatInt(time.Now().Add(-1*time.Minute).Unix(), 10)
 	end := strconv.FormatInt(time.Now().Unix(), 10)
 
+	// the rules of every channel
+	workflow, err := es.GetWorkflow("")
+	if err != nil {
+		return nil, err
+	}
+
 	typeUserQuery := elastic.NewTermQuery("type", "user")
-	statusFixedQuery := elastic.NewTermQuery("status", "fixed")
-	statusDeletedQuery := elastic.NewTermQuery("status", "deleted")
+	statusClosedQuery := elastic.NewTermsQuery("status", string(globals.StatusDeleted))
 	rangeQuery := elastic.NewRangeQuery("remind_at").
 		Gte(start).
 		Lte(end)
 
 	query := elastic.NewBoolQuery()
 	query.Filter(typeUserQuery)
-	query.MustNot(statusFixedQuery)
-	query.MustNot(statusDeletedQuery)
+	query.MustNot(statusClosedQuery)
 	query.Filter(rangeQuery)
 
 
-	searchResult, err := es.Client.Search().
-		Index("messages").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
-
 	var messages []globals.Message
-	log.Debugf("Found a total of %d messages\n", searchResult.Hits.TotalHits)
-	if searchResult.Hits.TotalHits > 0 {
-
-		for _, hit := range searchResult.Hits.Hits {
-			var m globals.Message
-			err := json.Unmarshal(*hit.Source, &m)
-			m.ID = hit.Id
-			if err != nil {
-				log.Errorf("unable to deserialize source into answer : %s", err)
-			}
+	total, err := es.scrollAll("messages", query, func(hit *elastic.SearchHit) error {
+		var m globals.Message
+		err := json.Unmarshal(hit.Source, &m)
+		m.ID = hit.Id
+		if err != nil {
+			log.Errorf("unable to deserialize source into answer : %s", err)
+		}
+		if !workflow.ForChannel(m.Channel).IsClosing(m.Status) {
 			messages = append(messages, m)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d messages\n", total)
 
 	return messages, nil
 }
 
-// QueryRangeMessages returns a list of messages in a timestamp range
-func (es ES) QueryRangeMessages(start string, end string) ([]globals.Message, error) {
+// QueryRangeMessages returns a list of messages of the channel in a timestamp range.
+// The messages of a range are scrolled, a single search looks up the message of a timestamp
+func (es ES) QueryRangeMessages(channel string, start string, end string) ([]globals.Message, error) {
 	termQuery := elastic.NewTermQuery("type", "user")
 	rangeQuery := elastic.NewRangeQuery("ts").
 		Gte(start).
@@ -181,32 +176,188 @@ func (es ES) QueryRangeMessages(start string, end string) ([]globals.Message, er
 	query := elastic.NewBoolQuery()
 	query.Filter(termQuery)
 	query.Filter(rangeQuery)
+	filterChannel(query, channel)
+
+	search := es.scrollAll
+	if start == end {
+		search = es.searchAll
+	}
+	var messages []globals.Message
+	total, err := search("messages", query, func(hit *elastic.SearchHit) error {
+		var m globals.Message
+		err := json.Unmarshal(hit.Source, &m)
+		m.ID = hit.Id
+		if err != nil {
+			log.Errorf("unable to deserialize source into answer : %s", err)
+		}
+		messages = append(messages, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found a total of %d messages\n", total)
+
+	return messages, nil
+}
+
+// ErrMessageNotFound is returned when no user message has the given timestamp
+var ErrMessageNotFound = errors.New("message not found")
+
+// GetMessage returns the user message of the channel posted at the timestamp, with its replies and reactions
+func (es ES) GetMessage(channel string, messageTs string) (globals.Message, error) {
+	query := elastic.NewBoolQuery()
+	query.Filter(elastic.NewTermQuery("type", "user"))
+	query.Filter(elastic.NewTermQuery("ts.keyword", messageTs))
+	filterChannel(query, channel)
 
 	searchResult, err := es.Client.Search().
 		Index("messages").
 		Query(query).
-		From(0).Size(1000).
+		Size(1).
 		Pretty(true).
 		Do(es.Context)
+	if err != nil {
+		return globals.Message{}, err
+	}
+	if len(searchResult.Hits.Hits) == 0 {
+		return globals.Message{}, ErrMessageNotFound
+	}
+
+	hit := searchResult.Hits.Hits[0]
+	var m globals.Message
+	if err := json.Unmarshal(hit.Source, &m); err != nil {
+		return globals.Message{}, err
+	}
+	m.ID = hit.Id
+
+	return m, nil
+}
+
+// messagesSortFields are the fields sorting the messages for each of globals.MessageSorts
+var messagesSortFields = map[string]string{
+	"ts":              "ts.keyword",
+	"response_time":   "response_time",
+	"resolution_time": "resolution_time",
+	"relevance":       "_score",
+}
+
+// messagesPageQuery restricts the user messages to the filters of the query and searches its text in the messages and their replies
+func messagesPageQuery(q globals.MessageQuery) *elastic.BoolQuery {
+	query := userMessagesQuery(q.Channel, q.Start, q.End)
+	terms := []struct{ field, value string }{
+		{"status.keyword", string(q.Status)},
+		{"labels.keyword", q.Label},
+		{"tools.keyword", q.Tool},
+		{"user.keyword", q.User},
+		{"replies.user.keyword", q.Fireman},
+	}
+	for _, term := range terms {
+		if term.value != "" {
+			query.Filter(elastic.NewTermQuery(term.field, term.value))
+		}
+	}
+	if q.HasFeedback != nil {
+		answered := elastic.NewTermsQuery("feedback_status.keyword", string(globals.UsefulFeedback), string(globals.UselessFeedback))
+		if *q.HasFeedback {
+			query.Filter(answered)
+		} else {
+			query.MustNot(answered)
+		}
+	}
+	if q.Search != "" {
+		query.Must(elastic.NewMultiMatchQuery(q.Search, "text", "replies.text", "resolution_note"))
+	}
+	return query
+}
+
+// QueryMessagesPage returns a page of the user messages matching the query, most recent first unless sorted otherwise
+func (es ES) QueryMessagesPage(q globals.MessageQuery) (globals.MessagePage, error) {
+	field, ok := messagesSortFields[q.Sort]
+	if !ok {
+		field = messagesSortFields["ts"]
+	}
+	// the document ID is computed from the channel and the timestamp, they break the ties without sorting on _id,
+	// which elasticsearch 8 does not allow
+	sorters := []elastic.Sorter{elastic.NewFieldSort(field).Order(q.Ascending)}
+	if field != "ts.keyword" {
+		sorters = append(sorters, elastic.NewFieldSort("ts.keyword").Order(q.Ascending))
+	}
+	sorters = append(sorters, elastic.NewFieldSort("channel.keyword").Order(q.Ascending).Missing(""))
+
+	page := globals.MessagePage{Messages: []globals.Message{}}
+	next, err := es.searchPage("messages", messagesPageQuery(q), q.Cursor, q.Size, func(hit *elastic.SearchHit) error {
+		var m globals.Message
+		err := json.Unmarshal(hit.Source, &m)
+		m.ID = hit.Id
+		if err != nil {
+			log.Errorf("unable to deserialize source into message : %s", err)
+		}
+		page.Messages = append(page.Messages, m)
+		return nil
+	}, sorters...)
+	if err != nil {
+		return page, err
+	}
+	page.NextCursor = next
+
+	return page, nil
+}
 
+// DefaultSimilarScore is the BM25 score of the similar messages at least, when none is given
+const DefaultSimilarScore = 5.0
+
+// QuerySimilarMessages returns the closed user messages of the channel most similar to the text, most similar first.
+// The text is compared to the messages, their replies and their resolution notes, the score is the BM25 one
+// and shall be at least minScore, DefaultSimilarScore when 0
+func (es ES) QuerySimilarMessages(channel string, text string, size int, minScore float64) ([]globals.SimilarMessage, error) {
+	workflow, err := es.GetWorkflow(channel)
 	if err != nil {
 		return nil, err
 	}
+	closing := workflow.ClosingStatuses()
+	if len(closing) == 0 || strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
 
-	var messages []globals.Message
-	log.Debugf("Found a total of %d messages\n", searchResult.Hits.TotalHits)
-	if searchResult.Hits.TotalHits > 0 {
-
-		for _, hit := range searchResult.Hits.Hits {
-			var m globals.Message
-			err := json.Unmarshal(*hit.Source, &m)
-			m.ID = hit.Id
-			if err != nil {
-				log.Errorf("unable to deserialize source into answer : %s", err)
-			}
-			messages = append(messages, m)
-		}
+	if minScore == 0 {
+		minScore = DefaultSimilarScore
 	}
 
+	// the support channels have few messages, the terms found in a single one of them are kept
+	// but the common words are not, a single one of them would be enough to match
+	like := elastic.NewMoreLikeThisQuery().
+		Field("text", "replies.text", "resolution_note").
+		LikeText(text).
+		MinTermFreq(1).
+		MinDocFreq(1).
+		StopWord(globals.StopWords...)
+	query := elastic.NewBoolQuery()
+	query.Filter(elastic.NewTermQuery("type", "user"))
+	query.Filter(closedQuery(closing))
+	query.Must(like)
+	filterChannel(query, channel)
+
+	searchResult, err := es.Client.Search().
+		Index("messages").
+		Query(query).
+		MinScore(minScore).
+		Size(size).
+		Do(es.Context)
+	if err != nil {
+		return nil, err
+	}
+	var messages []globals.SimilarMessage
+	for _, hit := range searchResult.Hits.Hits {
+		var m globals.SimilarMessage
+		if err := json.Unmarshal(hit.Source, &m.Message); err != nil {
+			log.Errorf("unable to deserialize source into message : %s", err)
+		}
+		m.ID = hit.Id
+		if hit.Score != nil {
+			m.Score = *hit.Score
+		}
+		messages = append(messages, m)
+	}
 	return messages, nil
 }
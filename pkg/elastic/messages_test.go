@@ -2,10 +2,12 @@ package elastic_test
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"github.com/leboncoin/subot/pkg/elastic"
 	"github.com/leboncoin/subot/pkg/globals"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,7 +15,7 @@ import (
 
 func TestQueryLastUserMessages(t *testing.T) {
 	userID := "mock"
-	expectedPath := "/messages/_search?pretty=true"
+	expectedPath := "/messages/_search"
 	expectedResponse := elastic.Match{
 		Took:     6,
 		TimedOut: false,
@@ -81,16 +83,31 @@ func TestQueryLastUserMessages(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	hits, err := e.QueryLastUserMessages(userID)
+	hits, err := e.QueryLastUserMessages("", userID)
 	assert.Equal(t, nil, err, "function shall not return errors")
 	assert.Equal(t, 1, len(hits), "function shall not return no hits")
 	assert.Equal(t, expectedResponse.Hits.Hits[0].Source.Message, hits[0], "function shall return expected response")
 }
 
+func TestQueryLastUserMessagesOfUser(t *testing.T) {
+	var body string
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		body = string(b)
+		res.Header().Set("Content-Type", "application/json")
+		_, _ = res.Write([]byte(`{"hits":{"total":0,"hits":[]}}`))
+	}))
+	defer mockESServer.Close()
+
+	_, err := MockClient(t, mockESServer).QueryLastUserMessages("", "UB210NGRK")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Contains(t, body, `{"term":{"user.keyword":"UB210NGRK"}}`, "query shall match the user of the messages")
+}
+
 func TestQueryRangeMessages(t *testing.T) {
 	start := "2020-01-01"
 	end := "2020-01-07"
-	expectedPath := "/messages/_search?pretty=true"
+	expectedPath := "/messages/_search?scroll=1m&size=1000"
 	expectedResponse := elastic.Match{
 		Took:     6,
 		TimedOut: false,
@@ -203,7 +220,7 @@ func TestQueryRangeMessages(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	hits, err := e.QueryRangeMessages(start, end)
+	hits, err := e.QueryRangeMessages("", start, end)
 	assert.Equal(t, nil, err, "function shall not return errors")
 	assert.Equal(t, 2, len(hits), "function shall not return no hits")
 	assert.Equal(t, expectedResponse.Hits.Hits[0].Source.Message, hits[0], "function shall return expected response")
@@ -253,7 +270,7 @@ func TestQueryReminderMessages(t *testing.T) {
 	message2 := globals.Message{
 		ID:       "fzubzEZF49NFEziohzf",
 		Type:     "topic",
-		Status:   "",
+		Status:   globals.StatusFixed,
 		Labels:   nil,
 		Tools:    nil,
 		Text:     "Bonjour, pouvez-vous me donner les droits en lecture sur vault au path apps/team-engprod/support-analytics/prod/slack svp ?",
@@ -289,7 +306,7 @@ func TestQueryReminderMessages(t *testing.T) {
 		FeedbackTs:     "",
 	}
 
-	expectedPath := "/messages/_search?pretty=true"
+	expectedPath := "/messages/_search?scroll=1m&size=1000"
 	expectedResponse := elastic.Match{
 		Took:     6,
 		TimedOut: false,
@@ -320,19 +337,25 @@ func TestQueryReminderMessages(t *testing.T) {
 
 	var mockESServer *httptest.Server
 	mockESServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.RequestURI == "/workflow/_search" {
+			res.WriteHeader(404)
+			return
+		}
 		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		body, err := ioutil.ReadAll(req.Body)
+		assert.Equal(t, nil, err, "Reading body shall not return errors")
+		assert.Contains(t, string(body), `"terms":{"status":["deleted"]}`, "Deleted messages shall not be reminded")
 		res.WriteHeader(200)
 
-		_, err := res.Write(expectedJSONResponse)
+		_, err = res.Write(expectedJSONResponse)
 		assert.Equal(t, nil, err, "Parsing json shall not return errors")
 	}))
 
 	e := MockClient(t, mockESServer)
 	hits, err := e.QueryReminderMessages()
 	assert.Equal(t, nil, err, "function shall not return errors")
-	assert.Equal(t, 2, len(hits), "function shall not return no hits")
+	assert.Equal(t, 1, len(hits), "closed messages shall not be reminded")
 	assert.Equal(t, message1, hits[0], "function shall return expected response")
-	assert.Equal(t, message2, hits[1], "function shall return expected response")
 }
 
 func TestAddMessage(t *testing.T) {
@@ -393,7 +416,7 @@ func TestAddMessage(t *testing.T) {
 	expectedJSONResponse, err := json.Marshal(serverResponse)
 	assert.Equal(t, nil, err, "Parsing json shall not return errors")
 
-	expectedPath := "/messages/_doc/"
+	expectedPath := "/messages/_doc/1592208201.000100"
 
 	var mockESServer *httptest.Server
 	mockESServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
@@ -407,78 +430,145 @@ func TestAddMessage(t *testing.T) {
 	e := MockClient(t, mockESServer)
 	err = e.AddMessage(message)
 	assert.Equal(t, nil, err, "function shall not return errors")
+
+	message.Channel = "CLK7MCUS3"
+	expectedPath = "/messages/_doc/CLK7MCUS3-1592208201.000100"
+	err = e.AddMessage(message)
+	assert.Equal(t, nil, err, "function shall not return errors")
+
+	expectedPath = "/messages/_doc/I-LEfXQBBlaSKk1R5bDF"
+	err = e.AddMessage(message, "I-LEfXQBBlaSKk1R5bDF")
+	assert.Equal(t, nil, err, "function shall not return errors")
 }
 
 func TestDeleteMessage(t *testing.T) {
-	ts := "1592208201.000100"
+	var paths []string
+	found := true
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.Method+" "+req.URL.Path)
+		res.Header().Set("Content-Type", "application/json")
+		if !found {
+			res.WriteHeader(404)
+			_, _ = res.Write([]byte(`{"_index":"messages","_type":"_doc","_id":"C1-1592208201.000100","result":"not_found"}`))
+			return
+		}
+		_, _ = res.Write([]byte(`{"_index":"messages","_type":"_doc","_id":"C1-1592208201.000100","result":"deleted"}`))
+	}))
+	defer mockESServer.Close()
 
-	expectedPath := "/messages/_doc/_delete_by_query"
-	expectedResponse := elastic.Match{
-		Took:     6,
-		TimedOut: false,
-		Hits: elastic.HitList{
-			Total:    1,
-			MaxScore: 1.0,
-			Hits: []elastic.Hit{{
-				Index: "messages",
-				Type:  "_doc",
-				ID:    "chuz&fzofzo23R92I",
-				Score: 1.0,
-				Source: elastic.HitSource{
-					Message: globals.Message{
-						Type:     "topic",
-						Status:   "",
-						Labels:   nil,
-						Tools:    nil,
-						Text:     "Bonjour, pouvez-vous me donner les droits en lecture sur vault au path apps/team-engprod/support-analytics/prod/slack svp ?",
-						UserID:   "UB210NGRK",
-						UserName: "clement.mondion",
-						UserInfo: globals.User{
-							ID:         "UB210NGRK",
-							SlackID:    0,
-							Avatar:     "",
-							TeamMember: false,
-							Name:       "",
-							Profile: globals.UserProfile{
-								Email:                 "clement.mondion@adevinta.com",
-								Avatar:                "https://avatars.slack-edge.com/2019-01-17/525636925408_32c9a24b43103a77c711_32.jpg",
-								Avatar512:             "https://avatars.slack-edge.com/2019-01-17/525636925408_32c9a24b43103a77c711_512.jpg",
-								RealName:              "Clément Mondion",
-								LastName:              "Mondion",
-								FirstName:             "Clément",
-								DisplayName:           "clem",
-								RealNameNormalized:    "Clement Mondion",
-								DisplayNameNormalized: "clem",
-							},
-						},
-						Timestamp:      "1592208201.000100",
-						Reactions:      nil,
-						Replies:        nil,
-						EditedTs:       "",
-						DeletedTs:      "",
-						RemindAt:       "",
-						ResponseTime:   0,
-						ResolutionTime: 0,
-						FeedbackStatus: "",
-						FeedbackTs:     "",
-					},
-				},
-			}},
-		},
-	}
-	expectedJSONResponse, err := json.Marshal(expectedResponse)
-	assert.Equal(t, nil, err, "Parsing json shall not return errors")
+	e := MockClient(t, mockESServer)
+	err := e.DeleteMessage("C1", "1592208201.000100")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, "DELETE /messages/_doc/C1-1592208201.000100", paths[len(paths)-1], "the message of the channel shall be deleted")
 
-	var mockESServer *httptest.Server
-	mockESServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
-		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
-		res.WriteHeader(200)
+	found = false
+	assert.Equal(t, nil, e.DeleteMessage("C1", "1592208201.000100"), "a missing message shall not return errors")
+	assert.NotNil(t, e.DeleteMessage("C1", ""), "a timestamp shall be required")
+}
 
-		_, err := res.Write(expectedJSONResponse)
-		assert.Equal(t, nil, err, "Parsing json shall not return errors")
+func TestEditMessage(t *testing.T) {
+	var paths []string
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.Method+" "+req.URL.Path)
+		res.Header().Set("Content-Type", "application/json")
+		_, _ = res.Write([]byte(`{"_index":"messages","_type":"_doc","_id":"C1-1592208201.000100","result":"updated"}`))
 	}))
+	defer mockESServer.Close()
 
 	e := MockClient(t, mockESServer)
-	err = e.DeleteMessage(ts)
+	err := e.EditMessage("C1", "1592208201.000100", globals.Message{Channel: "C1", Timestamp: "1592208201.000100", Status: globals.StatusFixed})
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, "PUT /messages/_doc/C1-1592208201.000100", paths[len(paths)-1], "the stored message shall be replaced")
+	assert.NotNil(t, e.EditMessage("C1", "", globals.Message{}), "a timestamp shall be required")
+}
+
+func TestGetMessage(t *testing.T) {
+	var body string
+	found := true
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/messages/_search?pretty=true", req.RequestURI, "Wrong path")
+		b, _ := ioutil.ReadAll(req.Body)
+		body = string(b)
+		res.Header().Set("Content-Type", "application/json")
+		if !found {
+			_, _ = res.Write([]byte(`{"hits":{"total":0,"hits":[]}}`))
+			return
+		}
+		_, _ = res.Write([]byte(`{"hits":{"total":1,"hits":[{"_index":"messages","_type":"_doc","_id":"C1-1592208201.000100",
+			"_source":{"ts":"1592208201.000100","replies":[{"user":"U2","text":"done"}],"reactions":[{"name":"heavy_check_mark","count":1}]}}]}}`))
+	}))
+	defer mockESServer.Close()
+	e := MockClient(t, mockESServer)
+
+	message, err := e.GetMessage("C1", "1592208201.000100")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Contains(t, body, `{"term":{"ts.keyword":"1592208201.000100"}}`)
+	assert.Equal(t, "C1-1592208201.000100", message.ID)
+	assert.Equal(t, "done", message.Replies[0].Text)
+	assert.Equal(t, "heavy_check_mark", message.Reactions[0].Name)
+
+	found = false
+	_, err = e.GetMessage("C1", "1592208201.000100")
+	assert.Equal(t, elastic.ErrMessageNotFound, err, "function shall return a not found error")
+}
+
+func TestQueryMessagesPageFilters(t *testing.T) {
+	var body string
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		body = string(b)
+		res.Header().Set("Content-Type", "application/json")
+		_, _ = res.Write([]byte(`{"hits":{"total":0,"hits":[]}}`))
+	}))
+	defer mockESServer.Close()
+
+	hasFeedback := false
+	page, err := MockClient(t, mockESServer).QueryMessagesPage(globals.MessageQuery{
+		Start:       "2020-01-01",
+		End:         "2020-12-31",
+		Status:      globals.StatusFixed,
+		Label:       "access",
+		Fireman:     "U2",
+		HasFeedback: &hasFeedback,
+		Search:      "vault",
+		Sort:        "resolution_time",
+		Ascending:   true,
+		Size:        10,
+	})
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Empty(t, page.Messages)
+	assert.Empty(t, page.NextCursor)
+	assert.Contains(t, body, `{"term":{"status.keyword":"fixed"}}`)
+	assert.Contains(t, body, `{"term":{"labels.keyword":"access"}}`)
+	assert.Contains(t, body, `{"term":{"replies.user.keyword":"U2"}}`)
+	assert.NotContains(t, body, `tools.keyword`)
+	assert.Contains(t, body, `"must_not":{"terms":{"feedback_status.keyword":["feedback_useful","feedback_useless"]}}`)
+	assert.Contains(t, body, `{"multi_match":{"fields":["text","replies.text","resolution_note"],"query":"vault"}}`)
+	assert.Contains(t, body, `"sort":[{"resolution_time":{"order":"asc"}},{"ts.keyword":{"order":"asc"}},{"channel.keyword":{"missing":"","order":"asc"}}]`)
+}
+
+func TestQuerySimilarMessages(t *testing.T) {
+	var bodies []string
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		res.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req.URL.Path, "messages") {
+			_, _ = res.Write([]byte(`{"hits":{"total":1,"hits":[{"_id":"C1-1592208201.000100","_score":2.5,"_source":{"text":"my vault token expired","status":"fixed"}}]}}`))
+			return
+		}
+		_, _ = res.Write([]byte(`{"hits":{"total":0,"hits":[]}}`))
+	}))
+	defer mockESServer.Close()
+
+	messages, err := MockClient(t, mockESServer).QuerySimilarMessages("C1", "vault token", 3, 0)
 	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "C1-1592208201.000100", messages[0].ID)
+	assert.Equal(t, 2.5, messages[0].Score)
+	body := bodies[len(bodies)-1]
+	assert.Contains(t, body, `"more_like_this":{"fields":["text","replies.text","resolution_note"],"like":["vault token"],"min_doc_freq":1,"min_term_freq":1,"stop_words":["a",`)
+	assert.Contains(t, body, `"min_score":5`, "the default score shall be required")
+	assert.Contains(t, body, `{"terms":{"status":["fixed"]}}`)
+	assert.Contains(t, body, `"size":3`)
 }
@@ -0,0 +1,220 @@
+package elastic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+// Migration moves the documents of indices into new versioned indices created with the current templates.
+// The documents can be transformed on the way by a painless script
+type Migration struct {
+	Version     int
+	Description string
+	Indexes     []string
+	Script      string
+}
+
+// Migrations are applied in order, each one only once. Never edit nor remove one, add a new one instead
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Move the indices behind aliases, with the mappings of the templates",
+		Indexes:     []string{"answers", "firemen", "labels", "messages", "team", "tools", "workflow"},
+	},
+	{
+		Version:     2,
+		Description: "Map the resolution notes, the similar threads and the answers of the messages",
+		Indexes:     []string{"messages"},
+	},
+	{
+		Version:     3,
+		Description: "Map the feedback on the similar threads of the messages",
+		Indexes:     []string{"messages"},
+	},
+}
+
+// schemaIndex stores the version of the last applied migration
+const schemaIndex = "schema"
+
+// schema is the document recording the applied migrations
+type schema struct {
+	Version    int    `json:"version"`
+	MigratedAt string `json:"migrated_at"`
+}
+
+// SchemaVersion is the version of the last migration
+func SchemaVersion() int {
+	return Migrations[len(Migrations)-1].Version
+}
+
+// Migrate puts the index templates, creates the missing indices and applies the pending migrations.
+// It shall not be run by several instances at the same time
+func (es ES) Migrate() error {
+	if err := es.putTemplates(); err != nil {
+		return err
+	}
+
+	for _, t := range Templates {
+		current, err := es.indexBehind(t.Index)
+		if err != nil {
+			return err
+		}
+		if current != "" {
+			continue
+		}
+		// a new index already has the mappings of the last migration
+		if err := es.createIndex(versionedIndex(t.Index, SchemaVersion()), t.Index); err != nil {
+			return err
+		}
+		log.Infof("Created index %s", t.Index)
+	}
+
+	applied, err := es.schemaVersion()
+	if err != nil {
+		return err
+	}
+	for _, m := range Migrations {
+		if m.Version <= applied {
+			continue
+		}
+		for _, index := range m.Indexes {
+			if err := es.reindex(index, m.Version, m.Script); err != nil {
+				return fmt.Errorf("migration %d failed on %s : %s", m.Version, index, err)
+			}
+		}
+		if err := es.setSchemaVersion(m.Version); err != nil {
+			return err
+		}
+		log.Infof("Applied migration %d : %s", m.Version, m.Description)
+	}
+	return nil
+}
+
+// versionedIndex is the name of the index holding the documents of the alias from the given migration
+func versionedIndex(alias string, version int) string {
+	return fmt.Sprintf("%s-%d", alias, version)
+}
+
+// versionOf returns the migration of a versioned index, 0 for an index not created by the service
+func versionOf(index string) int {
+	i := strings.LastIndex(index, "-")
+	if i < 0 {
+		return 0
+	}
+	version, err := strconv.Atoi(index[i+1:])
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// indexBehind returns the index the alias points to, the alias itself when it is a plain index
+// or an empty string when it does not exist
+func (es ES) indexBehind(alias string) (string, error) {
+	aliases, err := es.Client.Aliases().Index(alias).Do(es.Context)
+	if elastic.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for index := range aliases.Indices {
+		return index, nil
+	}
+	return "", nil
+}
+
+// createIndex creates an index with the mappings of its template, behind the alias when given
+func (es ES) createIndex(index string, alias string) error {
+	body := `{}`
+	if alias != "" {
+		body = fmt.Sprintf(`{"aliases": {"%s": {}}}`, alias)
+	}
+	_, err := es.Client.CreateIndex(index).BodyString(body).Do(es.Context)
+	return err
+}
+
+// reindex copies the documents behind the alias into the index of the migration and moves the alias onto it
+func (es ES) reindex(alias string, version int, script string) error {
+	source, err := es.indexBehind(alias)
+	if err != nil {
+		return err
+	}
+	target := versionedIndex(alias, version)
+	if source == "" || versionOf(source) >= version {
+		return nil
+	}
+
+	if err := es.createIndex(target, ""); err != nil {
+		return err
+	}
+	reindex := es.Client.Reindex().
+		SourceIndex(source).
+		DestinationIndex(target).
+		Refresh("true").
+		WaitForCompletion(true)
+	if script != "" {
+		reindex = reindex.Script(elastic.NewScript(script))
+	}
+	res, err := reindex.Do(es.Context)
+	if err != nil {
+		return err
+	}
+	if len(res.Failures) > 0 {
+		return fmt.Errorf("unable to reindex %d documents into %s", len(res.Failures), target)
+	}
+
+	// the alias is moved in a single request, an index not created by the service is deleted at the same time
+	aliases := es.Client.Alias().Action(elastic.NewAliasAddAction(alias).Index(target))
+	if source == alias {
+		_, err = aliases.Action(elastic.NewAliasRemoveIndexAction(source)).Do(es.Context)
+		return err
+	}
+	if _, err := aliases.Action(elastic.NewAliasRemoveAction(alias).Index(source)).Do(es.Context); err != nil {
+		return err
+	}
+	_, err = es.Client.DeleteIndex(source).Do(es.Context)
+	return err
+}
+
+// schemaVersion returns the version of the last applied migration, 0 when none was applied
+func (es ES) schemaVersion() (int, error) {
+	res, err := es.Client.Get().
+		Index(schemaIndex).
+		Id("version").
+		Do(es.Context)
+	if elastic.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var s schema
+	if err := json.Unmarshal(res.Source, &s); err != nil {
+		return 0, err
+	}
+	return s.Version, nil
+}
+
+// setSchemaVersion records the version of the last applied migration
+func (es ES) setSchemaVersion(version int) error {
+	b, err := json.Marshal(schema{Version: version, MigratedAt: time.Now().UTC().Format(time.RFC3339)})
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index(schemaIndex).
+		Id("version").
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+	return err
+}
@@ -0,0 +1,146 @@
+package elastic_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockCluster is an elasticsearch server keeping its aliases and its schema version in memory
+type mockCluster struct {
+	aliases   map[string]string
+	schema    string
+	requests  []string
+	invalid   []string
+	templates map[string]string
+}
+
+func (m *mockCluster) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	b, _ := ioutil.ReadAll(req.Body)
+	path := req.URL.Path
+	m.requests = append(m.requests, fmt.Sprintf("%s %s", req.Method, path))
+	if len(b) > 0 && !json.Valid(b) {
+		m.invalid = append(m.invalid, path)
+	}
+	res.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case strings.HasPrefix(path, "/_template/"):
+		if m.templates != nil {
+			m.templates[strings.TrimPrefix(path, "/_template/")] = string(b)
+		}
+		_, _ = res.Write([]byte(`{"acknowledged":true}`))
+	case path == "/_reindex":
+		_, _ = res.Write([]byte(`{"acknowledged":true,"failures":[]}`))
+	case path == "/schema/_doc/version" && req.Method == "GET":
+		if m.schema == "" {
+			res.WriteHeader(404)
+			_, _ = res.Write([]byte(`{"_index":"schema","_type":"_doc","_id":"version","found":false}`))
+			return
+		}
+		_, _ = res.Write([]byte(`{"_index":"schema","_type":"_doc","_id":"version","found":true,"_source":` + m.schema + `}`))
+	case path == "/schema/_doc/version":
+		m.schema = string(b)
+		_, _ = res.Write([]byte(`{"_index":"schema","_type":"_doc","_id":"version","result":"created"}`))
+	case strings.HasSuffix(path, "/_alias"):
+		alias := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/_alias")
+		index, ok := m.aliases[alias]
+		if !ok {
+			res.WriteHeader(404)
+			_, _ = res.Write([]byte(`{"error":"alias [` + alias + `] missing","status":404}`))
+			return
+		}
+		if index == alias {
+			_, _ = res.Write([]byte(`{"` + index + `":{"aliases":{}}}`))
+			return
+		}
+		_, _ = res.Write([]byte(`{"` + index + `":{"aliases":{"` + alias + `":{}}}}`))
+	case path == "/_aliases":
+		var body struct {
+			Actions []map[string]map[string]string `json:"actions"`
+		}
+		_ = json.Unmarshal(b, &body)
+		for _, action := range body.Actions {
+			if add, ok := action["add"]; ok {
+				m.aliases[add["alias"]] = add["index"]
+			}
+		}
+		_, _ = res.Write([]byte(`{"acknowledged":true}`))
+	case req.Method == "PUT":
+		var body struct {
+			Aliases map[string]interface{} `json:"aliases"`
+		}
+		_ = json.Unmarshal(b, &body)
+		for alias := range body.Aliases {
+			m.aliases[alias] = strings.TrimPrefix(path, "/")
+		}
+		_, _ = res.Write([]byte(`{"acknowledged":true}`))
+	default:
+		_, _ = res.Write([]byte(`{"acknowledged":true}`))
+	}
+}
+
+func TestMigrateFreshCluster(t *testing.T) {
+	cluster := &mockCluster{aliases: map[string]string{}}
+	mockESServer := httptest.NewServer(cluster)
+	defer mockESServer.Close()
+
+	err := MockClient(t, mockESServer).Migrate()
+	assert.Equal(t, nil, err, "function shall not return errors")
+	for _, template := range elastic.Templates {
+		assert.Contains(t, cluster.requests, "PUT /_template/subot-"+template.Index)
+		assert.Contains(t, cluster.requests, "PUT /"+template.Index+"-3")
+		assert.Equal(t, template.Index+"-3", cluster.aliases[template.Index])
+	}
+	assert.Empty(t, cluster.invalid, "templates shall be valid json")
+	assert.NotContains(t, cluster.requests, "POST /_reindex", "new indices shall not be reindexed")
+	assert.Contains(t, cluster.schema, `"version":3`)
+}
+
+func TestMigrateLegacyIndex(t *testing.T) {
+	cluster := &mockCluster{aliases: map[string]string{}}
+	for _, template := range elastic.Templates {
+		cluster.aliases[template.Index] = template.Index
+	}
+	mockESServer := httptest.NewServer(cluster)
+	defer mockESServer.Close()
+
+	err := MockClient(t, mockESServer).Migrate()
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Contains(t, cluster.requests, "POST /_reindex")
+	assert.Equal(t, "team-1", cluster.aliases["team"])
+	assert.Equal(t, "messages-3", cluster.aliases["messages"])
+	assert.Contains(t, cluster.schema, `"version":3`)
+
+	cluster.requests = nil
+	err = MockClient(t, mockESServer).Migrate()
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.NotContains(t, cluster.requests, "POST /_reindex", "applied migrations shall not run again")
+}
+
+func TestMigrateMessagesMappings(t *testing.T) {
+	cluster := &mockCluster{aliases: map[string]string{}, schema: `{"version":1}`}
+	for _, template := range elastic.Templates {
+		cluster.aliases[template.Index] = template.Index + "-1"
+	}
+	mockESServer := httptest.NewServer(cluster)
+	defer mockESServer.Close()
+
+	err := MockClient(t, mockESServer).Migrate()
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Contains(t, cluster.requests, "PUT /messages-2")
+	assert.Contains(t, cluster.requests, "DELETE /messages-1")
+	assert.Contains(t, cluster.requests, "PUT /messages-3")
+	assert.Contains(t, cluster.requests, "DELETE /messages-2")
+	assert.Equal(t, "messages-3", cluster.aliases["messages"])
+	assert.Equal(t, "answers-1", cluster.aliases["answers"], "the other indices shall not be reindexed")
+	assert.NotContains(t, cluster.requests, "PUT /answers-2")
+	assert.Contains(t, cluster.schema, `"version":3`)
+}
@@ -57,33 +57,59 @@ type AnswerHitSource struct {
 // Interface set of wrapper around elastic package to ease mocking
 type Interface interface {
 	AddAnswer(globals.Answer) error
+	AddEscalationPolicy(globals.EscalationPolicy) error
 	AddFireman(globals.Message) error
 	AddLabel(globals.Perco) error
 	AddMessage(globals.Message, ...string) error
+	AddRotation(globals.Rotation) error
+	AddStatusRule(globals.StatusRule) error
 	AddTeamMember(globals.TeamMember) error
 	AddTool(globals.Perco) error
+	AddWelcomeAcknowledgement(globals.WelcomeAcknowledgement) error
+	AggregateAnswers(string) ([]globals.AnswerStatistics, error)
+	AggregateMessages(string, string, string, []globals.MessageStatus) (globals.Aggregations, error)
 	DeleteAnswer(string) error
+	DeleteEscalationPolicy(string) error
 	DeleteLabel(string) error
-	DeleteMessage(string) error
+	DeleteMessage(string, string) error
+	DeleteMessageTemplate(string, string) error
+	DeleteRotation(string) error
+	DeleteStatusRule(string) error
 	DeleteTeamMember(string) error
 	DeleteTool(string) error
+	DeleteWelcomeMessage(string) error
 	EditAnswer(string, globals.Answer) error
+	EditEscalationPolicy(string, globals.EscalationPolicy) error
 	EditLabel(string, globals.Perco) error
-	EditMessage(string, globals.Message) error
+	EditMessage(string, string, globals.Message) error
+	EditRotation(string, globals.Rotation) error
+	EditStatusRule(string, globals.StatusRule) error
 	EditTeamMember(string, globals.TeamMember) error
 	EditTool(string, globals.Perco) error
-	GetAnswers() ([]globals.Answer, error)
-	GetLabels() ([]globals.Perco, error)
-	GetTeamMembers() ([]globals.TeamMember, error)
-	GetTools() ([]globals.Perco, error)
-	IsTeamMember(string) (bool, error)
-	QueryAnswers([]string, []string) ([]globals.Answer, error)
-	QueryLabels(string) ([]string, error)
+	GetAnswers(string) ([]globals.Answer, error)
+	GetEscalationPolicies(string) ([]globals.EscalationPolicy, error)
+	GetLabels(string) ([]globals.Perco, error)
+	GetMessage(string, string) (globals.Message, error)
+	GetMessageTemplates() ([]globals.MessageTemplate, error)
+	GetRotations(string) ([]globals.Rotation, error)
+	GetTeamMembers(string) ([]globals.TeamMember, error)
+	GetTools(string) ([]globals.Perco, error)
+	GetWelcomeAcknowledgements(string) ([]globals.WelcomeAcknowledgement, error)
+	GetWelcomeMessages(string) ([]globals.WelcomeMessage, error)
+	GetWorkflow(string) (globals.Workflow, error)
+	IsTeamMember(string, string) (bool, error)
+	QueryAnswers(string, []string, []string) ([]globals.Answer, error)
+	QueryLabels(string, string) ([]string, error)
 	QueryLabelByName(string) ([]globals.Perco, error)
-	QueryLastUserMessages(string) ([]globals.Message, error)
-	QueryRangeFireman(string, string) ([]globals.Message, error)
-	QueryRangeMessages(string, string) ([]globals.Message, error)
+	QueryLastUserMessages(string, string) ([]globals.Message, error)
+	QueryMessagesPage(globals.MessageQuery) (globals.MessagePage, error)
+	QueryRangeFireman(string, string, string) ([]globals.Message, error)
+	QueryRangeMessages(string, string, string) ([]globals.Message, error)
 	QueryReminderMessages() ([]globals.Message, error)
-	QueryTools(string) ([]string, error)
+	QuerySimilarMessages(string, string, int, float64) ([]globals.SimilarMessage, error)
+	QueryTimeSeries(globals.TimeSeriesQuery) (globals.TimeSeries, error)
+	QueryTools(string, string) ([]string, error)
 	QueryToolByName(string) ([]globals.Perco, error)
+	SetMessageTemplate(globals.MessageTemplate) error
+	SetWelcomeMessage(globals.WelcomeMessage) error
 }
@@ -0,0 +1,149 @@
+package elastic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/olivere/elastic/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+// PageSize is the number of documents fetched by each request of the paginated queries
+var PageSize = 1000
+
+// ErrInvalidCursor is returned when a cursor was not created by a previous page
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ScrollKeepAlive is how long elasticsearch keeps the context of a scroll between two pages
+var ScrollKeepAlive = "1m"
+
+// searchAll calls handle on the documents matching the query and returns the number of documents.
+// It is meant for the lookups of a few documents, answered by a single search of PageSize documents at most.
+// The exports and the batch jobs, which may match more documents, shall use scrollAll
+func (es ES) searchAll(index string, query elastic.Query, handle func(*elastic.SearchHit) error, sorters ...elastic.Sorter) (int64, error) {
+	search := es.Client.Search().
+		Index(index).
+		Query(query).
+		Size(PageSize)
+	if len(sorters) > 0 {
+		search = search.SortBy(sorters...)
+	}
+	searchResult, err := search.Do(es.Context)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, hit := range searchResult.Hits.Hits {
+		if err := handle(hit); err != nil {
+			return total, err
+		}
+		total++
+	}
+	if matching := searchResult.TotalHits(); matching > total {
+		log.WithFields(log.Fields{"index": index, "matching": matching, "returned": total}).Warn("Too many documents match the lookup, only the first page is returned")
+	}
+	return total, nil
+}
+
+// scrollAll calls handle on every document matching the query and returns the number of documents.
+// Documents are fetched page after page with the scroll API, in index order unless sorted by the given fields
+func (es ES) scrollAll(index string, query elastic.Query, handle func(*elastic.SearchHit) error, sorters ...elastic.Sorter) (int64, error) {
+	scroll := es.Client.Scroll(index).
+		Query(query).
+		Size(PageSize).
+		KeepAlive(ScrollKeepAlive)
+	if len(sorters) > 0 {
+		scroll = scroll.SortBy(sorters...)
+	}
+
+	var total int64
+	var scrollID string
+	defer func() {
+		if scrollID != "" {
+			_ = scroll.Clear(es.Context)
+		}
+	}()
+	for {
+		searchResult, err := scroll.Do(es.Context)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+		scrollID = searchResult.ScrollId
+
+		hits := searchResult.Hits.Hits
+		for _, hit := range hits {
+			if err := handle(hit); err != nil {
+				return total, err
+			}
+			total++
+		}
+		// a partial page is the last one, no need to ask for an empty one
+		if len(hits) < PageSize {
+			return total, nil
+		}
+	}
+}
+
+// searchPage calls handle on the documents of the page following the cursor and returns the cursor of the next page.
+// The next cursor is empty on the last page
+func (es ES) searchPage(index string, query elastic.Query, cursor string, size int, handle func(*elastic.SearchHit) error, sorters ...elastic.Sorter) (string, error) {
+	search := es.Client.Search().
+		Index(index).
+		Query(query).
+		SortBy(sorters...).
+		Size(size).
+		Pretty(true)
+	if cursor != "" {
+		searchAfter, err := decodeCursor(cursor)
+		if err != nil {
+			return "", err
+		}
+		search = search.SearchAfter(searchAfter...)
+	}
+	searchResult, err := search.Do(es.Context)
+	if err != nil {
+		return "", err
+	}
+
+	hits := searchResult.Hits.Hits
+	for _, hit := range hits {
+		if err := handle(hit); err != nil {
+			return "", err
+		}
+	}
+	if len(hits) < size || len(hits[len(hits)-1].Sort) == 0 {
+		return "", nil
+	}
+	return encodeCursor(hits[len(hits)-1].Sort)
+}
+
+// encodeCursor turns the sort values of the last document of a page into an opaque cursor
+func encodeCursor(sort []interface{}) (string, error) {
+	b, err := json.Marshal(sort)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(cursor string) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	// numbers are kept as is, long sort values do not fit in a float
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var sort []interface{}
+	if err := decoder.Decode(&sort); err != nil || len(sort) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return sort, nil
+}
@@ -0,0 +1,106 @@
+package elastic_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+const firstPage = `{"hits":{"total":3,"hits":[
+	{"_index":"messages","_type":"_doc","_id":"a","_source":{"ts":"1592208201.000100"},"sort":["1592208201.000100","a"]},
+	{"_index":"messages","_type":"_doc","_id":"b","_source":{"ts":"1592208202.000100"},"sort":["1592208202.000100","b"]}
+]}}`
+
+const lastPage = `{"hits":{"total":3,"hits":[
+	{"_index":"messages","_type":"_doc","_id":"c","_source":{"ts":"1592208203.000100"},"sort":["1592208203.000100","c"]}
+]}}`
+
+func TestQueryRangeMessagesPaginates(t *testing.T) {
+	elastic.PageSize = 2
+	defer func() { elastic.PageSize = 1000 }()
+
+	var requests, bodies []string
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.Method+" "+req.RequestURI)
+		body, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		res.Header().Set("Content-Type", "application/json")
+		switch req.Method + " " + req.URL.Path {
+		case "POST /messages/_search":
+			_, _ = res.Write([]byte(`{"_scroll_id":"page1",` + firstPage[1:]))
+		case "POST /_search/scroll":
+			_, _ = res.Write([]byte(`{"_scroll_id":"page2",` + lastPage[1:]))
+		default:
+			_, _ = res.Write([]byte(`{"succeeded":true,"num_freed":1}`))
+		}
+	}))
+	defer mockESServer.Close()
+
+	messages, err := MockClient(t, mockESServer).QueryRangeMessages("", "2020-01-01", "2020-12-31")
+	assert.NoError(t, err)
+	assert.Len(t, messages, 3)
+	assert.Equal(t, "c", messages[2].ID)
+	assert.Equal(t, []string{
+		"POST /messages/_search?scroll=1m&size=2",
+		"POST /_search/scroll",
+		"DELETE /_search/scroll",
+	}, requests)
+	assert.Contains(t, bodies[1], `"scroll_id":"page1"`)
+	assert.Contains(t, bodies[2], `"page2"`, "the scroll shall be cleared")
+}
+
+func TestQueryRangeMessagesLookup(t *testing.T) {
+	var requests []string
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.Method+" "+req.RequestURI)
+		res.Header().Set("Content-Type", "application/json")
+		_, _ = res.Write([]byte(lastPage))
+	}))
+	defer mockESServer.Close()
+
+	messages, err := MockClient(t, mockESServer).QueryRangeMessages("", "1592208203.000100", "1592208203.000100")
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, []string{"POST /messages/_search"}, requests, "the message of a timestamp shall be looked up without a scroll")
+}
+
+func TestQueryMessagesPage(t *testing.T) {
+	var bodies []string
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		res.Header().Set("Content-Type", "application/json")
+		if strings.Contains(string(body), "search_after") {
+			_, _ = res.Write([]byte(lastPage))
+			return
+		}
+		_, _ = res.Write([]byte(firstPage))
+	}))
+	defer mockESServer.Close()
+	client := MockClient(t, mockESServer)
+
+	query := globals.MessageQuery{Start: "2020-01-01", End: "2020-12-31", Size: 2}
+	page, err := client.QueryMessagesPage(query)
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 2)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.Contains(t, bodies[0], `"sort":[{"ts.keyword":{"order":"desc"}},{"channel.keyword":{"missing":"","order":"desc"}}]`)
+
+	query.Cursor = page.NextCursor
+	page, err = client.QueryMessagesPage(query)
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 1)
+	assert.Empty(t, page.NextCursor)
+	assert.Contains(t, bodies[1], `"search_after":["1592208202.000100","b"]`)
+
+	query.Cursor = "not a cursor"
+	_, err = client.QueryMessagesPage(query)
+	assert.Equal(t, elastic.ErrInvalidCursor, err)
+	assert.Len(t, bodies, 2)
+}
@@ -0,0 +1,99 @@
+package elastic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/olivere/elastic/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddRotation stores the rotation in the elastic search index
+func (es ES) AddRotation(rotation globals.Rotation) error {
+	if len(rotation.Roster) == 0 {
+		return errors.New("cannot create rotation without roster")
+	}
+
+	b, err := json.Marshal(rotation)
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index("rotations").
+		Id(rotation.ID).
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+
+	if err != nil {
+		return fmt.Errorf("error creating document : %s", err.Error())
+	}
+	return nil
+}
+
+// EditRotation Modifies the rotation matching the given documentID
+func (es ES) EditRotation(documentID string, rotation globals.Rotation) error {
+	if documentID == "" {
+		return errors.New("cannot edit rotation without documentID")
+	}
+	if len(rotation.Roster) == 0 {
+		return errors.New("cannot edit rotation without roster")
+	}
+
+	b, err := json.Marshal(rotation)
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index("rotations").
+		Id(documentID).
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+
+	return err
+}
+
+// DeleteRotation removes the rotation from the elastic search index
+func (es ES) DeleteRotation(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+
+	_, err := es.Client.Delete().
+		Index("rotations").
+		Refresh("true").
+		Id(documentID).
+		Do(es.Context)
+
+	return err
+}
+
+// GetRotations returns the rotations of the channel and the ones shared by every channel
+func (es ES) GetRotations(channel string) ([]globals.Rotation, error) {
+	query := filterChannel(elastic.NewBoolQuery().Must(elastic.NewMatchAllQuery()), channel)
+	var rotations []globals.Rotation
+	_, err := es.searchAll("rotations", query, func(hit *elastic.SearchHit) error {
+		var rotation globals.Rotation
+		if err := json.Unmarshal(hit.Source, &rotation); err != nil {
+			log.Errorf("Unable to deserialize source into rotation : %s", err)
+			return nil
+		}
+		rotation.ID = hit.Id
+		rotations = append(rotations, rotation)
+		return nil
+	})
+	if elastic.IsNotFound(err) {
+		log.Debug("No rotations index, no rotation defined")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rotations, nil
+}
@@ -0,0 +1,93 @@
+package elastic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	olivere "github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRotations(t *testing.T) {
+	expectedPath := "/rotations/_search"
+	weekly := json.RawMessage(`{"channel": "CLK7MCUS3", "roster": ["UALICE", "UBOB"], "start": "2020-06-01",
+		"overrides": [{"slack_id": "UBOB", "start": "2020-06-08", "end": "2020-06-10"}]}`)
+	expectedResponse := olivere.SearchResult{
+		Hits: &olivere.SearchHits{
+			TotalHits: &olivere.TotalHits{Value: 1},
+			Hits:      []*olivere.SearchHit{{Id: "rotation0", Source: weekly}},
+		},
+	}
+	expectedJSONResponse, err := json.Marshal(expectedResponse)
+	assert.Equal(t, nil, err, "Parsing json shall not return errors")
+
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		res.WriteHeader(200)
+		_, err := res.Write(expectedJSONResponse)
+		assert.Equal(t, nil, err, "Parsing json shall not return errors")
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	rotations, err := e.GetRotations("CLK7MCUS3")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, []globals.Rotation{{
+		ID:        "rotation0",
+		Channel:   "CLK7MCUS3",
+		Roster:    []string{"UALICE", "UBOB"},
+		Start:     "2020-06-01",
+		Overrides: []globals.RotationOverride{{SlackID: "UBOB", Start: "2020-06-08", End: "2020-06-10"}},
+	}}, rotations, "function shall return expected response")
+}
+
+func TestGetRotationsWithoutIndex(t *testing.T) {
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(404)
+		_, _ = res.Write([]byte(`{"error": {"type": "index_not_found_exception"}, "status": 404}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	rotations, err := e.GetRotations("")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, 0, len(rotations), "function shall not return any rotation")
+}
+
+func TestAddRotation(t *testing.T) {
+	expectedPath := "/rotations/_doc/I-LEfXQBBlaSKk1R5bDF?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		var rotation globals.Rotation
+		assert.Equal(t, nil, json.NewDecoder(req.Body).Decode(&rotation), "body shall be a rotation")
+		assert.Equal(t, []string{"UALICE", "UBOB"}, rotation.Roster, "body shall contain the roster")
+		res.WriteHeader(201)
+		_, _ = res.Write([]byte(`{"_index": "rotations", "_type": "_doc", "_id": "I-LEfXQBBlaSKk1R5bDF", "result": "created"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	err := e.AddRotation(globals.Rotation{ID: "I-LEfXQBBlaSKk1R5bDF", Roster: []string{"UALICE", "UBOB"}, Start: "2020-06-01"})
+	assert.Equal(t, nil, err, "function shall not return errors")
+
+	err = e.AddRotation(globals.Rotation{Start: "2020-06-01"})
+	assert.NotEqual(t, nil, err, "function shall refuse a rotation without roster")
+}
+
+func TestDeleteRotation(t *testing.T) {
+	expectedPath := "/rotations/_doc/I-LEfXQBBlaSKk1R5bDF?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		assert.Equal(t, "DELETE", req.Method, "Wrong method")
+		res.WriteHeader(200)
+		_, _ = res.Write([]byte(`{"_index": "rotations", "_type": "_doc", "_id": "I-LEfXQBBlaSKk1R5bDF", "result": "deleted"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	assert.Equal(t, nil, e.DeleteRotation("I-LEfXQBBlaSKk1R5bDF"), "function shall not return errors")
+	assert.NotEqual(t, nil, e.DeleteRotation(""), "function shall refuse an empty id")
+}
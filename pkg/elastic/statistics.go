@@ -0,0 +1,277 @@
+package elastic
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/olivere/elastic/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+// groupFields are the fields of the messages matching the groups of the time series
+var groupFields = map[string]string{
+	"label":  "labels.keyword",
+	"tool":   "tools.keyword",
+	"status": "status.keyword",
+	"user":   "user.keyword",
+}
+
+// tsMillis converts the slack timestamp of the messages into epoch milliseconds for the date histograms
+const tsMillis = "(long) (Double.parseDouble(doc['ts.keyword'].value) * 1000)"
+
+// answersAggregationSize is the number of answers counted at most
+const answersAggregationSize = 1000
+
+// AggregateAnswers counts the user messages of the channel each answer was sent to, and their useful and useless feedback
+func (es ES) AggregateAnswers(channel string) ([]globals.AnswerStatistics, error) {
+	query := elastic.NewBoolQuery()
+	query.Filter(elastic.NewTermQuery("type", "user"))
+	filterChannel(query, channel)
+
+	searchResult, err := es.Client.Search().
+		Index("messages").
+		Query(query).
+		Size(0).
+		Aggregation("answers", elastic.NewTermsAggregation().Field("answers.keyword").Size(answersAggregationSize).
+			SubAggregation("feedback", elastic.NewTermsAggregation().Field("feedback_status.keyword").Size(10))).
+		Do(es.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	statistics := []globals.AnswerStatistics{}
+	answers, found := searchResult.Aggregations.Terms("answers")
+	if !found {
+		return statistics, nil
+	}
+	for _, bucket := range answers.Buckets {
+		s := globals.AnswerStatistics{AnswerID: fmt.Sprint(bucket.Key), Sent: int(bucket.DocCount)}
+		if feedback, found := bucket.Terms("feedback"); found {
+			for _, status := range feedback.Buckets {
+				switch globals.FeedbackStatus(fmt.Sprint(status.Key)) {
+				case globals.UsefulFeedback:
+					s.Useful = int(status.DocCount)
+				case globals.UselessFeedback:
+					s.Useless = int(status.DocCount)
+				}
+			}
+		}
+		statistics = append(statistics, s)
+	}
+	sort.Slice(statistics, func(i, j int) bool { return statistics[i].AnswerID < statistics[j].AnswerID })
+	return statistics, nil
+}
+
+// AggregateMessages computes the number of user messages, of each status and the distribution
+// of their response and resolution times, for the channel in a timestamp range.
+// Response times are measured on answered messages, resolution times on the ones with a closing status
+func (es ES) AggregateMessages(channel string, start string, end string, closing []globals.MessageStatus) (globals.Aggregations, error) {
+	aggregations := globals.Aggregations{
+		Statuses:      make(map[globals.MessageStatus]int),
+		Distributions: make(map[string]globals.Distribution),
+	}
+
+	answered, closed := answeredQuery(), closedQuery(closing)
+	fields := map[string]elastic.Query{
+		"response_time":            answered,
+		"business_response_time":   answered,
+		"resolution_time":          closed,
+		"business_resolution_time": closed,
+	}
+
+	search := es.Client.Search().
+		Index("messages").
+		Query(userMessagesQuery(channel, start, end)).
+		Size(0).
+		Aggregation("statuses", elastic.NewTermsAggregation().Field("status.keyword").Size(100)).
+		// the total of the hits is only a lower bound from elasticsearch 7, the count of a bucket is exact
+		Aggregation("messages", elastic.NewFilterAggregation().Filter(elastic.NewMatchAllQuery()))
+	for field, filter := range fields {
+		histogram := elastic.NewRangeAggregation().Field(field)
+		var from time.Duration
+		for _, to := range globals.DurationBuckets {
+			histogram.AddRange(float64(from), float64(to))
+			from = to
+		}
+		histogram.AddUnboundedTo(float64(from))
+
+		search.Aggregation(field, elastic.NewFilterAggregation().
+			Filter(filter).
+			SubAggregation("mean", elastic.NewAvgAggregation().Field(field)).
+			SubAggregation("percentiles", percentilesAggregation(field)).
+			SubAggregation("histogram", histogram))
+	}
+
+	searchResult, err := search.Do(es.Context)
+	if err != nil {
+		return aggregations, err
+	}
+
+	if messages, found := searchResult.Aggregations.Filter("messages"); found {
+		aggregations.Count = int(messages.DocCount)
+	}
+	if statuses, found := searchResult.Aggregations.Terms("statuses"); found {
+		for _, bucket := range statuses.Buckets {
+			aggregations.Statuses[globals.MessageStatus(fmt.Sprint(bucket.Key))] = int(bucket.DocCount)
+		}
+	}
+
+	for field := range fields {
+		filtered, found := searchResult.Aggregations.Filter(field)
+		if !found {
+			log.WithFields(log.Fields{"field": field}).Debug("Missing aggregation")
+			continue
+		}
+		distribution := globals.Distribution{
+			Count:       int(filtered.DocCount),
+			Percentiles: parsePercentiles(filtered.Aggregations, "percentiles"),
+			Histogram:   []globals.Bucket{},
+		}
+		if mean, found := filtered.Avg("mean"); found && mean.Value != nil {
+			distribution.Mean = time.Duration(*mean.Value)
+		}
+		if histogram, found := filtered.Range("histogram"); found {
+			for _, bucket := range histogram.Buckets {
+				b := globals.Bucket{Count: int(bucket.DocCount)}
+				if bucket.From != nil {
+					b.From = time.Duration(*bucket.From)
+				}
+				if bucket.To != nil {
+					b.To = time.Duration(*bucket.To)
+				}
+				distribution.Histogram = append(distribution.Histogram, b)
+			}
+		}
+		aggregations.Distributions[field] = distribution
+	}
+
+	return aggregations, nil
+}
+
+// QueryTimeSeries buckets the user messages by date, and optionally by label, tool, status or user,
+// with the percentiles of their response and resolution times
+func (es ES) QueryTimeSeries(q globals.TimeSeriesQuery) (globals.TimeSeries, error) {
+	series := globals.TimeSeries{Interval: q.Interval, GroupBy: q.GroupBy, Buckets: []globals.TimeBucket{}}
+
+	dates := elastic.NewDateHistogramAggregation().
+		Script(elastic.NewScript(tsMillis)).
+		Format("yyyy-MM-dd").
+		MinDocCount(0)
+	if es.Distribution.CalendarIntervals() {
+		dates.CalendarInterval(q.Interval)
+	} else {
+		dates.Interval(q.Interval)
+	}
+	if q.TimeZone != "" {
+		dates.TimeZone(q.TimeZone)
+	}
+	response, resolution := latencyAggregations(q.Closing)
+	dates.SubAggregation("response_time", response).SubAggregation("resolution_time", resolution)
+
+	if q.GroupBy != "" {
+		field, ok := groupFields[q.GroupBy]
+		if !ok {
+			return series, fmt.Errorf("cannot group time series by %s", q.GroupBy)
+		}
+		groups := elastic.NewTermsAggregation().Field(field).Size(100).
+			SubAggregation("response_time", response).
+			SubAggregation("resolution_time", resolution)
+		dates.SubAggregation("groups", groups)
+	}
+
+	searchResult, err := es.Client.Search().
+		Index("messages").
+		Query(userMessagesQuery(q.Channel, q.Start, q.End)).
+		Size(0).
+		Aggregation("dates", dates).
+		Do(es.Context)
+	if err != nil {
+		return series, err
+	}
+
+	histogram, found := searchResult.Aggregations.DateHistogram("dates")
+	if !found {
+		return series, nil
+	}
+	for _, bucket := range histogram.Buckets {
+		b := globals.TimeBucket{
+			Count:          int(bucket.DocCount),
+			ResponseTime:   parseLatency(bucket.Aggregations, "response_time"),
+			ResolutionTime: parseLatency(bucket.Aggregations, "resolution_time"),
+		}
+		if bucket.KeyAsString != nil {
+			b.Date = *bucket.KeyAsString
+		}
+		if groups, found := bucket.Terms("groups"); found {
+			for _, group := range groups.Buckets {
+				b.Groups = append(b.Groups, globals.TimeGroup{
+					Key:            fmt.Sprint(group.Key),
+					Count:          int(group.DocCount),
+					ResponseTime:   parseLatency(group.Aggregations, "response_time"),
+					ResolutionTime: parseLatency(group.Aggregations, "resolution_time"),
+				})
+			}
+		}
+		series.Buckets = append(series.Buckets, b)
+	}
+
+	return series, nil
+}
+
+// userMessagesQuery matches the user messages of the channel in a timestamp range
+func userMessagesQuery(channel string, start string, end string) *elastic.BoolQuery {
+	query := elastic.NewBoolQuery()
+	query.Filter(elastic.NewTermQuery("type", "user"))
+	query.Filter(elastic.NewRangeQuery("ts").Gte(start).Lte(end))
+	return filterChannel(query, channel)
+}
+
+// answeredQuery matches the messages a team member replied to
+func answeredQuery() elastic.Query {
+	return elastic.NewBoolQuery().MustNot(elastic.NewTermQuery("status", globals.StatusUnresponded))
+}
+
+// closedQuery matches the messages having one of the closing statuses
+func closedQuery(closing []globals.MessageStatus) elastic.Query {
+	statuses := make([]string, len(closing))
+	for i, status := range closing {
+		statuses[i] = string(status)
+	}
+	return elastic.NewTermsQuery("status", stringToInterface(statuses)...)
+}
+
+func percentilesAggregation(field string) elastic.Aggregation {
+	return elastic.NewPercentilesAggregation().Field(field).Percentiles(50, 90, 99)
+}
+
+// latencyAggregations returns the percentiles of the response and resolution times, to add to the buckets
+func latencyAggregations(closing []globals.MessageStatus) (response elastic.Aggregation, resolution elastic.Aggregation) {
+	response = elastic.NewFilterAggregation().Filter(answeredQuery()).
+		SubAggregation("percentiles", percentilesAggregation("response_time"))
+	resolution = elastic.NewFilterAggregation().Filter(closedQuery(closing)).
+		SubAggregation("percentiles", percentilesAggregation("resolution_time"))
+	return response, resolution
+}
+
+// parseLatency reads the percentiles of the latencyAggregations
+func parseLatency(aggregations elastic.Aggregations, name string) globals.Percentiles {
+	filtered, found := aggregations.Filter(name)
+	if !found {
+		return globals.Percentiles{}
+	}
+	return parsePercentiles(filtered.Aggregations, "percentiles")
+}
+
+func parsePercentiles(aggregations elastic.Aggregations, name string) globals.Percentiles {
+	percentiles, found := aggregations.Percentiles(name)
+	if !found {
+		return globals.Percentiles{}
+	}
+	return globals.Percentiles{
+		P50: time.Duration(percentiles.Values["50.0"]),
+		P90: time.Duration(percentiles.Values["90.0"]),
+		P99: time.Duration(percentiles.Values["99.0"]),
+	}
+}
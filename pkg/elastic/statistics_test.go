@@ -0,0 +1,156 @@
+package elastic_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateMessages(t *testing.T) {
+	expectedPath := "/messages/_search"
+	distribution := `{
+		"doc_count": 3,
+		"mean": {"value": 604.5},
+		"percentiles": {"values": {"50.0": 12.5, "90.0": 300, "99.0": 1500}},
+		"histogram": {"buckets": [
+			{"key": "0.0-15.0", "from": 0, "to": 15, "doc_count": 2},
+			{"key": "15.0-60.0", "from": 15, "to": 60, "doc_count": 0},
+			{"key": "60.0-240.0", "from": 60, "to": 240, "doc_count": 0},
+			{"key": "240.0-480.0", "from": 240, "to": 480, "doc_count": 0},
+			{"key": "480.0-1440.0", "from": 480, "to": 1440, "doc_count": 0},
+			{"key": "1440.0-*", "from": 1440, "doc_count": 1}
+		]}
+	}`
+	response := `{
+		"hits": {"total": 5, "hits": []},
+		"aggregations": {
+			"messages": {"doc_count": 5},
+			"statuses": {"buckets": [{"key": "fixed", "doc_count": 3}, {"key": "unresponded", "doc_count": 2}]},
+			"response_time": ` + distribution + `,
+			"business_response_time": ` + distribution + `,
+			"resolution_time": ` + distribution + `,
+			"business_resolution_time": ` + distribution + `
+		}
+	}`
+
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		body, err := ioutil.ReadAll(req.Body)
+		assert.Equal(t, nil, err, "Reading body shall not return errors")
+		assert.Contains(t, string(body), `"size":0`, "Messages shall not be loaded")
+		assert.Contains(t, string(body), `"terms":{"status":["fixed"]}`, "Resolution times shall be measured on closed messages")
+		assert.Contains(t, string(body), `"percents":[50,90,99]`, "Percentiles shall be requested")
+		assert.Contains(t, string(body), `"avg":{"field":"resolution_time"}`, "Means shall be requested")
+		res.WriteHeader(200)
+		_, _ = res.Write([]byte(response))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	aggregations, err := e.AggregateMessages("CLK7MCUS3", "1592172000", "1592776800", []globals.MessageStatus{globals.StatusFixed})
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, 5, aggregations.Count)
+	assert.Equal(t, map[globals.MessageStatus]int{globals.StatusFixed: 3, globals.StatusUnresponded: 2}, aggregations.Statuses)
+	assert.Equal(t, 4, len(aggregations.Distributions), "function shall return every distribution")
+
+	resolution := aggregations.Distributions["resolution_time"]
+	assert.Equal(t, 3, resolution.Count)
+	assert.Equal(t, time.Duration(604), resolution.Mean)
+	assert.Equal(t, time.Duration(12), resolution.P50)
+	assert.Equal(t, time.Duration(300), resolution.P90)
+	assert.Equal(t, time.Duration(1500), resolution.P99)
+	assert.Equal(t, len(globals.DurationBuckets)+1, len(resolution.Histogram))
+	assert.Equal(t, globals.Bucket{From: 0, To: 15, Count: 2}, resolution.Histogram[0])
+	assert.Equal(t, globals.Bucket{From: 1440, Count: 1}, resolution.Histogram[5])
+}
+
+func TestAggregateAnswers(t *testing.T) {
+	response := `{
+		"hits": {"total": 4, "hits": []},
+		"aggregations": {
+			"answers": {"buckets": [
+				{"key": "a2", "doc_count": 1, "feedback": {"buckets": [{"key": "asked_feedback", "doc_count": 1}]}},
+				{"key": "a1", "doc_count": 3, "feedback": {"buckets": [
+					{"key": "feedback_useful", "doc_count": 2},
+					{"key": "feedback_useless", "doc_count": 1}
+				]}}
+			]}
+		}
+	}`
+
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/messages/_search", req.RequestURI, "Wrong path")
+		body, err := ioutil.ReadAll(req.Body)
+		assert.Equal(t, nil, err, "Reading body shall not return errors")
+		assert.Contains(t, string(body), `"size":0`, "Messages shall not be loaded")
+		assert.Contains(t, string(body), `"terms":{"field":"answers.keyword","size":1000}`, "Messages shall be counted by answer")
+		res.WriteHeader(200)
+		_, _ = res.Write([]byte(response))
+	}))
+	defer mockESServer.Close()
+
+	statistics, err := MockClient(t, mockESServer).AggregateAnswers("CLK7MCUS3")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, []globals.AnswerStatistics{
+		{AnswerID: "a1", Sent: 3, Useful: 2, Useless: 1},
+		{AnswerID: "a2", Sent: 1},
+	}, statistics)
+}
+
+func TestQueryTimeSeries(t *testing.T) {
+	latency := `"response_time": {"doc_count": 1, "percentiles": {"values": {"50.0": 5, "90.0": 9, "99.0": 9.9}}},
+		"resolution_time": {"doc_count": 0, "percentiles": {"values": {"50.0": null, "90.0": null, "99.0": null}}}`
+	response := `{
+		"hits": {"total": 3, "hits": []},
+		"aggregations": {"dates": {"buckets": [
+			{"key_as_string": "2020-06-15", "key": 1592172000000, "doc_count": 3, ` + latency + `,
+				"groups": {"buckets": [
+					{"key": "vault", "doc_count": 2, ` + latency + `},
+					{"key": "rights", "doc_count": 1, ` + latency + `}
+				]}},
+			{"key_as_string": "2020-06-16", "key": 1592258400000, "doc_count": 0, ` + latency + `, "groups": {"buckets": []}}
+		]}}
+	}`
+
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/messages/_search", req.RequestURI, "Wrong path")
+		body, err := ioutil.ReadAll(req.Body)
+		assert.Equal(t, nil, err, "Reading body shall not return errors")
+		assert.Contains(t, string(body), `interval":"day"`, "Buckets shall have the requested interval")
+		assert.Contains(t, string(body), `"time_zone":"Europe/Paris"`, "Buckets shall be in the requested timezone")
+		assert.Contains(t, string(body), `"field":"labels.keyword"`, "Buckets shall be grouped by label")
+		res.WriteHeader(200)
+		_, _ = res.Write([]byte(response))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	series, err := e.QueryTimeSeries(globals.TimeSeriesQuery{
+		Channel:  "CLK7MCUS3",
+		Start:    "1592172000",
+		End:      "1592776800",
+		Interval: "day",
+		GroupBy:  "label",
+		TimeZone: "Europe/Paris",
+		Closing:  []globals.MessageStatus{globals.StatusFixed},
+	})
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, 2, len(series.Buckets), "function shall return every bucket")
+	assert.Equal(t, "2020-06-15", series.Buckets[0].Date)
+	assert.Equal(t, 3, series.Buckets[0].Count)
+	assert.Equal(t, globals.Percentiles{P50: 5, P90: 9, P99: 9}, series.Buckets[0].ResponseTime)
+	assert.Equal(t, globals.Percentiles{}, series.Buckets[0].ResolutionTime)
+	assert.Equal(t, []globals.TimeGroup{
+		{Key: "vault", Count: 2, ResponseTime: globals.Percentiles{P50: 5, P90: 9, P99: 9}},
+		{Key: "rights", Count: 1, ResponseTime: globals.Percentiles{P50: 5, P90: 9, P99: 9}},
+	}, series.Buckets[0].Groups)
+	assert.Equal(t, 0, len(series.Buckets[1].Groups))
+
+	_, err = e.QueryTimeSeries(globals.TimeSeriesQuery{Interval: "day", GroupBy: "mood"})
+	assert.NotEqual(t, nil, err, "function shall refuse unknown groups")
+}
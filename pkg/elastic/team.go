@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/olivere/elastic"
+	"github.com/olivere/elastic/v7"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/leboncoin/subot/pkg/globals"
@@ -19,7 +19,6 @@ func (es ES) AddTeamMember(user globals.TeamMember) error {
 
 	_, err = es.Client.Index().
 		Index("team").
-		Type("_doc").
 		BodyString(string(b)).
 		Refresh("true").
 		Do(es.Context)
@@ -38,7 +37,6 @@ func (es ES) DeleteTeamMember(documentID string) error {
 
 	_, err := es.Client.Delete().
 		Index("team").
-		Type("_doc").
 		Id(documentID).
 		Refresh("true").
 		Do(es.Context)
@@ -63,7 +61,6 @@ func (es ES) EditTeamMember(documentID string, teamMember globals.TeamMember) er
 
 	_, err = es.Client.Index().
 		Index("team").
-		Type("_doc").
 		BodyString(string(b)).
 		Refresh("true").
 		Id(documentID).
@@ -76,46 +73,36 @@ func (es ES) EditTeamMember(documentID string, teamMember globals.TeamMember) er
 	return nil
 }
 
-//GetTeamMembers Retrieve all of the document at the index "team"
-func (es ES) GetTeamMembers() ([]globals.TeamMember, error) {
-	query := elastic.NewMatchAllQuery()
-	searchResult, err := es.Client.Search().
-		Index("team").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
-
+//GetTeamMembers Retrieve all of the document at the index "team" for the given channel
+func (es ES) GetTeamMembers(channel string) ([]globals.TeamMember, error) {
+	query := filterChannel(elastic.NewBoolQuery().Must(elastic.NewMatchAllQuery()), channel)
 	var users []globals.TeamMember
-	if searchResult.Hits.TotalHits > 0 {
-		fmt.Printf("Found a total of %d team members\n", searchResult.Hits.TotalHits)
-
-		for _, hit := range searchResult.Hits.Hits {
-			var u globals.TeamMember
-			err := json.Unmarshal(*hit.Source, &u)
-			u.ID = hit.Id
-			if err != nil {
-				log.Errorf("Unable to deserialize source into message : %s", err)
-			}
-			users = append(users, u)
+	total, err := es.searchAll("team", query, func(hit *elastic.SearchHit) error {
+		var u globals.TeamMember
+		err := json.Unmarshal(hit.Source, &u)
+		u.ID = hit.Id
+		if err != nil {
+			log.Errorf("Unable to deserialize source into message : %s", err)
 		}
+		users = append(users, u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d team members\n", total)
 
 	return users, nil
 }
 
-// IsTeamMember looks for the userId in the team index
-func (es ES) IsTeamMember(userID string) (bool, error) {
-	query := elastic.NewTermQuery("slack_id", userID)
+// IsTeamMember looks for the userId in the team index of the channel
+func (es ES) IsTeamMember(channel string, userID string) (bool, error) {
+	query := filterChannel(elastic.NewBoolQuery().Filter(elastic.NewTermQuery("slack_id.keyword", userID)), channel)
 
 	searchResult, err := es.Client.Search().
 		Index("team").
 		Query(query).
-		From(0).Size(1000).
+		Size(0).
 		Pretty(true).
 		Do(es.Context)
 
@@ -123,5 +110,5 @@ func (es ES) IsTeamMember(userID string) (bool, error) {
 		return false, err
 	}
 
-	return searchResult.Hits.TotalHits > 0, nil
+	return searchResult.TotalHits() > 0, nil
 }
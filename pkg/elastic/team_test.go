@@ -2,6 +2,7 @@ package elastic_test
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"github.com/leboncoin/subot/pkg/elastic"
@@ -80,7 +81,7 @@ func TestIsTeamMember(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	teamMember, err := e.IsTeamMember(userID)
+	teamMember, err := e.IsTeamMember("", userID)
 	assert.Equal(t, nil, err, "function shall not return errors")
 	assert.Equal(t, true, teamMember, "function shall return expected response")
 }
@@ -110,7 +111,43 @@ func TestIsNotTeamMember(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	teamMember, err := e.IsTeamMember(userID)
+	teamMember, err := e.IsTeamMember("", userID)
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, false, teamMember, "function shall return expected response")
+}
+
+func TestIsTeamMemberOfChannel(t *testing.T) {
+	userID := "mock"
+	channel := "CLK7MCUS3"
+	expectedPath := "/team/_search?pretty=true"
+	expectedResponse := elastic.Match{
+		Took:     6,
+		TimedOut: false,
+		Hits: elastic.HitList{
+			Total:    0,
+			MaxScore: 0,
+			Hits:     []elastic.Hit{},
+		},
+	}
+	expectedJSONResponse, err := json.Marshal(expectedResponse)
+	assert.Equal(t, nil, err, "Parsing json shall not return errors")
+
+	var mockESServer *httptest.Server
+	mockESServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		body, err := ioutil.ReadAll(req.Body)
+		assert.Equal(t, nil, err, "Reading body shall not return errors")
+		assert.Contains(t, string(body), `{"term":{"channel.keyword":"CLK7MCUS3"}}`, "query shall be scoped to the channel")
+		assert.Contains(t, string(body), `{"exists":{"field":"channel"}}`, "query shall include members shared by every channel")
+		assert.Contains(t, string(body), `{"term":{"slack_id.keyword":"mock"}}`, "query shall match the slack ID of the member")
+		res.WriteHeader(200)
+
+		_, err = res.Write(expectedJSONResponse)
+		assert.Equal(t, nil, err, "Parsing json shall not return errors")
+	}))
+
+	e := MockClient(t, mockESServer)
+	teamMember, err := e.IsTeamMember(channel, userID)
 	assert.Equal(t, nil, err, "function shall not return errors")
 	assert.Equal(t, false, teamMember, "function shall return expected response")
 }
@@ -230,7 +267,7 @@ func TestDeleteTeamMember(t *testing.T) {
 }
 
 func TestGetTeamMembers(t *testing.T) {
-	expectedPath := "/team/_search?pretty=true"
+	expectedPath := "/team/_search"
 
 	message1 := globals.TeamMember{
 		ID:       "chuz&fzofzo23R92I",
@@ -278,7 +315,7 @@ func TestGetTeamMembers(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	hits, err := e.GetTeamMembers()
+	hits, err := e.GetTeamMembers("")
 	assert.Equal(t, nil, err, "function shall not return errors")
 	assert.Equal(t, 2, len(hits), "function shall not return no hits")
 	assert.Equal(t, message1, hits[0], "function shall return expected response")
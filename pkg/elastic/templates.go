@@ -0,0 +1,150 @@
+package elastic
+
+import (
+	"fmt"
+)
+
+// Template holds the mappings of an index owned by the service.
+// They are applied by an index template to every versioned index behind the alias named after the index
+type Template struct {
+	Index    string
+	Mappings string
+}
+
+// keyword is the mapping of the string fields, searchable as text and exactly with their keyword subfield
+const keyword = `{"type": "text", "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}}`
+
+// Templates are the indices of the service and their mappings
+var Templates = []Template{
+	{Index: "answers", Mappings: `{
+		"id": ` + keyword + `,
+		"channel": ` + keyword + `,
+		"tool": ` + keyword + `,
+		"label": ` + keyword + `,
+		"answer": {"type": "text"},
+		"feedback": {"type": "boolean"}
+	}`},
+	{Index: "escalations", Mappings: `{
+		"channel": ` + keyword + `,
+		"label": ` + keyword + `,
+		"tool": ` + keyword + `,
+		"steps": {"properties": {
+			"after": ` + keyword + `,
+			"target": ` + keyword + `,
+			"slack_id": ` + keyword + `
+		}}
+	}`},
+	{Index: "firemen", Mappings: `{
+		"channel": ` + keyword + `,
+		"user": ` + keyword + `,
+		"ts": {"type": "date", "format": "epoch_second||strict_date_optional_time", "fields": {"keyword": {"type": "keyword"}}}
+	}`},
+	{Index: "labels", Mappings: percoMappings},
+	{Index: "message_templates", Mappings: `{
+		"locale": ` + keyword + `,
+		"key": ` + keyword + `,
+		"text": {"type": "text", "index": false}
+	}`},
+	{Index: "messages", Mappings: `{
+		"type": ` + keyword + `,
+		"channel": ` + keyword + `,
+		"status": ` + keyword + `,
+		"labels": ` + keyword + `,
+		"tools": ` + keyword + `,
+		"text": {"type": "text"},
+		"user": ` + keyword + `,
+		"user_name": ` + keyword + `,
+		"ts": {"type": "date", "format": "epoch_second||strict_date_optional_time", "fields": {"keyword": {"type": "keyword"}}},
+		"remind_at": ` + keyword + `,
+		"replies": {"properties": {
+			"user": ` + keyword + `,
+			"text": {"type": "text"},
+			"ts": ` + keyword + `,
+			"thread_ts": ` + keyword + `
+		}},
+		"response_time": {"type": "long"},
+		"resolution_time": {"type": "long"},
+		"business_response_time": {"type": "long"},
+		"business_resolution_time": {"type": "long"},
+		"feedback_status": ` + keyword + `,
+		"resolution_note": {"type": "text"},
+		"similar_threads": ` + keyword + `,
+		"similar_feedback": ` + keyword + `,
+		"answers": ` + keyword + `
+	}`},
+	{Index: "rotations", Mappings: `{
+		"channel": ` + keyword + `,
+		"roster": ` + keyword + `,
+		"start": ` + keyword + `,
+		"shift_days": {"type": "integer"},
+		"topic": {"type": "text"},
+		"overrides": {"properties": {
+			"slack_id": ` + keyword + `,
+			"replacement": ` + keyword + `,
+			"start": ` + keyword + `,
+			"end": ` + keyword + `
+		}}
+	}`},
+	{Index: "team", Mappings: `{
+		"id": ` + keyword + `,
+		"slack_id": ` + keyword + `,
+		"name": ` + keyword + `,
+		"channel": ` + keyword + `
+	}`},
+	{Index: "tools", Mappings: percoMappings},
+	{Index: "welcome", Mappings: `{
+		"channel": ` + keyword + `,
+		"text": {"type": "text", "index": false},
+		"blocks": {"type": "object", "enabled": false},
+		"direct": {"type": "boolean"},
+		"acknowledge": {"type": "boolean"}
+	}`},
+	{Index: "welcome_acknowledgements", Mappings: `{
+		"channel": ` + keyword + `,
+		"user_id": ` + keyword + `,
+		"date": {"type": "date", "format": "epoch_second||strict_date_optional_time", "fields": {"keyword": {"type": "keyword"}}}
+	}`},
+	{Index: "workflow", Mappings: `{
+		"channel": ` + keyword + `,
+		"emoji": ` + keyword + `,
+		"status": ` + keyword + `,
+		"closes": {"type": "boolean"}
+	}`},
+}
+
+// percoMappings are the mappings of the labels and tools, matched against the input text of the messages
+const percoMappings = `{
+	"id": ` + keyword + `,
+	"name": ` + keyword + `,
+	"channel": ` + keyword + `,
+	"query": {"type": "percolator"},
+	"input": {"type": "text"}
+}`
+
+// templateName is the name of the index template of the index
+func templateName(index string) string {
+	return fmt.Sprintf("subot-%s", index)
+}
+
+// templateBody is the index template applying the mappings to the versioned indices of the template
+func (es ES) templateBody(t Template) string {
+	mappings := fmt.Sprintf(`{"properties": %s}`, t.Mappings)
+	if es.Distribution.Typed() {
+		mappings = fmt.Sprintf(`{"_doc": %s}`, mappings)
+	}
+	return fmt.Sprintf(`{"index_patterns": ["%s-*"], "version": %d, "mappings": %s}`,
+		t.Index, SchemaVersion(), mappings)
+}
+
+// putTemplates creates or updates the index templates of every index of the service
+func (es ES) putTemplates() error {
+	for _, t := range Templates {
+		_, err := es.Client.IndexPutTemplate(templateName(t.Index)).
+			BodyString(es.templateBody(t)).
+			Do(es.Context)
+		if err != nil {
+			return fmt.Errorf("unable to put the template of %s : %s", t.Index, err)
+		}
+	}
+	return nil
+}
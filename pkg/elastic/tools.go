@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/olivere/elastic"
+	"github.com/olivere/elastic/v7"
 	log "github.com/sirupsen/logrus"
 	"github.com/leboncoin/subot/pkg/globals"
 )
@@ -24,7 +24,6 @@ func (es ES) AddTool(tool globals.Perco) error {
 		Index("tools").
 		Id(tool.ID).
 		Refresh("true").
-		Type("_doc").
 		BodyString(string(b)).
 		Do(es.Context)
 
@@ -57,7 +56,6 @@ func (es ES) DeleteTool(documentID string) error {
 
 	_, err = es.Client.Delete().
 		Index("tools").
-		Type("_doc").
 		Id(documentID).
 		Refresh("true").
 		Do(es.Context)
@@ -82,7 +80,6 @@ func (es ES) EditTool(documentID string, tool globals.Perco) error {
 
 	_, err = es.Client.Index().
 		Index("tools").
-		Type("_doc").
 		Refresh("true").
 		Id(documentID).
 		BodyString(string(b)).
@@ -94,68 +91,49 @@ func (es ES) EditTool(documentID string, tool globals.Perco) error {
 	return nil
 }
 
-//GetTools List all of the tools store in elasticsearch
-func (es ES) GetTools() ([]globals.Perco, error) {
-	query := elastic.NewMatchAllQuery()
-	searchResult, err := es.Client.Search().
-		Index("tools").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
-
+//GetTools List all of the tools of the channel store in elasticsearch
+func (es ES) GetTools(channel string) ([]globals.Perco, error) {
+	query := filterChannel(elastic.NewBoolQuery().Must(elastic.NewMatchAllQuery()), channel)
 	tools := make([]globals.Perco, 0)
-
-	if searchResult.Hits.TotalHits > 0 {
-		fmt.Printf("Found a total of %d tools\n", searchResult.Hits.TotalHits)
-
-		for _, hit := range searchResult.Hits.Hits {
-			var p globals.Perco
-			err := json.Unmarshal(*hit.Source, &p)
-			p.ID = hit.Id
-			if err != nil {
-				log.Errorf("Unable to deserialize source into perco : %s", err)
-			}
-			tools = append(tools, p)
+	total, err := es.searchAll("tools", query, func(hit *elastic.SearchHit) error {
+		var p globals.Perco
+		err := json.Unmarshal(hit.Source, &p)
+		p.ID = hit.Id
+		if err != nil {
+			log.Errorf("Unable to deserialize source into perco : %s", err)
 		}
+		tools = append(tools, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d tools\n", total)
 	log.Debug("Tools : ?", tools)
 	return tools, nil
 }
 
-//QueryTools Query elasticsearch "tools" percolator to match tools to the given text
-func (es ES) QueryTools(text string) ([]string, error) {
+//QueryTools Query elasticsearch "tools" percolator to match tools of the channel to the given text
+func (es ES) QueryTools(channel string, text string) ([]string, error) {
 	pq := elastic.NewPercolatorQuery().
 		Field("query").
 		Document(map[string]interface{}{"input": text})
-
-	searchResult, err := es.Client.Search().
-		Index("tools").
-		Query(pq).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
+	query := filterChannel(elastic.NewBoolQuery().Must(pq), channel)
 
 	var tools []string
-	if searchResult.Hits.TotalHits > 0 {
-		fmt.Printf("Found a total of %d tools\n", searchResult.Hits.TotalHits)
-		for _, hit := range searchResult.Hits.Hits {
-			var tool globals.Perco
-			err := json.Unmarshal(*hit.Source, &tool)
-			if err != nil {
-				return nil, err
-			}
-			tools = append(tools, tool.Name)
+	total, err := es.searchAll("tools", query, func(hit *elastic.SearchHit) error {
+		var tool globals.Perco
+		err := json.Unmarshal(hit.Source, &tool)
+		if err != nil {
+			return err
 		}
+		tools = append(tools, tool.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d tools\n", total)
 
 	return tools, nil
 }
@@ -174,7 +152,7 @@ func (es ES) QueryToolByID(id string) (globals.Perco, error) {
 
 	if searchResult.Found {
 		log.Debug("Found a tool matching this ID")
-		err := json.Unmarshal(*searchResult.Source, &tool)
+		err := json.Unmarshal(searchResult.Source, &tool)
 		tool.ID = searchResult.Id
 		if err != nil {
 			return tool, err
@@ -188,30 +166,21 @@ func (es ES) QueryToolByID(id string) (globals.Perco, error) {
 func (es ES) QueryToolByName(name string) ([]globals.Perco, error) {
 	query := elastic.NewTermQuery("name.keyword", name)
 
-	searchResult, err := es.Client.Search().
-		Index("tools").
-		Query(query).
-		From(0).Size(1000).
-		Pretty(true).
-		Do(es.Context)
-
-	if err != nil {
-		return nil, err
-	}
-
 	var tools []globals.Perco
-	if searchResult.Hits.TotalHits > 0 {
-		fmt.Printf("Found a total of %d tools\n", searchResult.Hits.TotalHits)
-		for _, hit := range searchResult.Hits.Hits {
-			var tool globals.Perco
-			err := json.Unmarshal(*hit.Source, &tool)
-			tool.ID = hit.Id
-			if err != nil {
-				log.Errorf("Unable to deserialize source into answer : %s", err)
-			}
-			tools = append(tools, tool)
+	total, err := es.searchAll("tools", query, func(hit *elastic.SearchHit) error {
+		var tool globals.Perco
+		err := json.Unmarshal(hit.Source, &tool)
+		tool.ID = hit.Id
+		if err != nil {
+			log.Errorf("Unable to deserialize source into answer : %s", err)
 		}
+		tools = append(tools, tool)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("Found a total of %d tools\n", total)
 
 	return tools, nil
 }
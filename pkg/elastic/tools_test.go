@@ -2,7 +2,7 @@ package elastic_test
 
 import (
 	"encoding/json"
-	olivere "github.com/olivere/elastic"
+	olivere "github.com/olivere/elastic/v7"
 	"net/http"
 	"net/http/httptest"
 	"github.com/leboncoin/subot/pkg/elastic"
@@ -14,16 +14,16 @@ import (
 
 func TestQueryTools(t *testing.T) {
 	tool := "vault"
-	expectedPath := "/tools/_search?pretty=true"
+	expectedPath := "/tools/_search"
 
 	h := json.RawMessage(`{"name": "mock"}`)
 
 	expectedTools := olivere.SearchResult{
 		Hits: &olivere.SearchHits{
-			TotalHits: 1,
+			TotalHits: &olivere.TotalHits{Value: 1},
 			Hits: []*olivere.SearchHit{{
 				Id:     "Dont care",
-				Source: &h,
+				Source: h,
 			}},
 		},
 	}
@@ -41,14 +41,14 @@ func TestQueryTools(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	hits, err := e.QueryTools(tool)
+	hits, err := e.QueryTools("", tool)
 	assert.Equal(t, nil, err, "function shall not return errors")
 	assert.Equal(t, 1, len(hits), "function shall not return no hits")
 	assert.Equal(t, "mock", hits[0], "function shall return expected response")
 }
 
 func TestGetTools(t *testing.T) {
-	expectedPath := "/tools/_search?pretty=true"
+	expectedPath := "/tools/_search"
 	expectedResponse := elastic.Match{
 		Took:     6,
 		TimedOut: false,
@@ -101,7 +101,7 @@ func TestGetTools(t *testing.T) {
 	}))
 
 	e := MockClient(t, mockESServer)
-	hits, err := e.GetTools()
+	hits, err := e.GetTools("")
 	assert.Equal(t, err, nil, "function shall not return errors")
 	assert.Equal(t, len(hits), 3, "function shall not return no hits")
 	assert.Equal(t, globals.Perco{
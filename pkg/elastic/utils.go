@@ -1,5 +1,7 @@
 package elastic
 
+import "github.com/olivere/elastic/v7"
+
 func stringToInterface(s []string) (result []interface{}) {
 	result = make([]interface{}, len(s))
 	for i, v := range s {
@@ -7,3 +9,14 @@ func stringToInterface(s []string) (result []interface{}) {
 	}
 	return
 }
+
+// filterChannel restricts the query to the documents of the given channel and to the ones shared by every channel.
+// An empty channel does not filter anything
+func filterChannel(query *elastic.BoolQuery, channel string) *elastic.BoolQuery {
+	if channel == "" {
+		return query
+	}
+	return query.Filter(elastic.NewBoolQuery().
+		Should(elastic.NewTermQuery("channel.keyword", channel)).
+		Should(elastic.NewBoolQuery().MustNot(elastic.NewExistsQuery("channel"))))
+}
@@ -0,0 +1,126 @@
+package elastic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/olivere/elastic/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+// SetWelcomeMessage stores the welcome message in the elastic search index,
+// replacing the one of the same channel
+func (es ES) SetWelcomeMessage(welcome globals.WelcomeMessage) error {
+	if welcome.Text == "" && len(welcome.Blocks) == 0 {
+		return errors.New("cannot store welcome message without text or blocks")
+	}
+
+	b, err := json.Marshal(welcome)
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index("welcome").
+		Id(welcome.DocumentID()).
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+
+	if err != nil {
+		return fmt.Errorf("error creating document : %s", err.Error())
+	}
+	return nil
+}
+
+// DeleteWelcomeMessage removes the welcome message of the channel from the elastic search index,
+// the one shared by every channel when empty
+func (es ES) DeleteWelcomeMessage(channel string) error {
+	_, err := es.Client.Delete().
+		Index("welcome").
+		Refresh("true").
+		Id(globals.WelcomeMessage{Channel: channel}.DocumentID()).
+		Do(es.Context)
+
+	if elastic.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// GetWelcomeMessages returns the welcome message of the channel and the one shared by every channel
+func (es ES) GetWelcomeMessages(channel string) ([]globals.WelcomeMessage, error) {
+	query := filterChannel(elastic.NewBoolQuery().Must(elastic.NewMatchAllQuery()), channel)
+	var messages []globals.WelcomeMessage
+	_, err := es.searchAll("welcome", query, func(hit *elastic.SearchHit) error {
+		var welcome globals.WelcomeMessage
+		if err := json.Unmarshal(hit.Source, &welcome); err != nil {
+			log.Errorf("Unable to deserialize source into welcome message : %s", err)
+			return nil
+		}
+		messages = append(messages, welcome)
+		return nil
+	})
+	if elastic.IsNotFound(err) {
+		log.Debug("No welcome index, no welcome message defined")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// AddWelcomeAcknowledgement stores that the user read the rules of the channel in the elastic search index
+func (es ES) AddWelcomeAcknowledgement(acknowledgement globals.WelcomeAcknowledgement) error {
+	if acknowledgement.Channel == "" || acknowledgement.UserID == "" {
+		return errors.New("cannot store welcome acknowledgement without channel or user")
+	}
+
+	b, err := json.Marshal(acknowledgement)
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index("welcome_acknowledgements").
+		Id(acknowledgement.DocumentID()).
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+
+	if err != nil {
+		return fmt.Errorf("error creating document : %s", err.Error())
+	}
+	return nil
+}
+
+// GetWelcomeAcknowledgements returns the users who read the rules of the channel, of every channel when empty
+func (es ES) GetWelcomeAcknowledgements(channel string) ([]globals.WelcomeAcknowledgement, error) {
+	query := elastic.NewBoolQuery().Must(elastic.NewMatchAllQuery())
+	if channel != "" {
+		query = query.Filter(elastic.NewTermQuery("channel.keyword", channel))
+	}
+	var acknowledgements []globals.WelcomeAcknowledgement
+	_, err := es.searchAll("welcome_acknowledgements", query, func(hit *elastic.SearchHit) error {
+		var acknowledgement globals.WelcomeAcknowledgement
+		if err := json.Unmarshal(hit.Source, &acknowledgement); err != nil {
+			log.Errorf("Unable to deserialize source into welcome acknowledgement : %s", err)
+			return nil
+		}
+		acknowledgements = append(acknowledgements, acknowledgement)
+		return nil
+	})
+	if elastic.IsNotFound(err) {
+		log.Debug("No welcome_acknowledgements index, no rules acknowledged")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return acknowledgements, nil
+}
@@ -0,0 +1,102 @@
+package elastic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	olivere "github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWelcomeMessages(t *testing.T) {
+	expectedPath := "/welcome/_search"
+	welcome := json.RawMessage(`{"channel": "CLK7MCUS3", "text": "Bienvenue", "blocks": [{"type": "divider"}], "direct": true}`)
+	expectedResponse := olivere.SearchResult{
+		Hits: &olivere.SearchHits{
+			TotalHits: &olivere.TotalHits{Value: 1},
+			Hits:      []*olivere.SearchHit{{Id: "CLK7MCUS3", Source: welcome}},
+		},
+	}
+	expectedJSONResponse, err := json.Marshal(expectedResponse)
+	assert.Equal(t, nil, err, "Parsing json shall not return errors")
+
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		res.WriteHeader(200)
+		_, err := res.Write(expectedJSONResponse)
+		assert.Equal(t, nil, err, "Parsing json shall not return errors")
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	messages, err := e.GetWelcomeMessages("CLK7MCUS3")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, 1, len(messages), "function shall return the welcome message")
+	assert.Equal(t, "CLK7MCUS3", messages[0].Channel, "function shall return the channel")
+	assert.Equal(t, true, messages[0].Direct, "function shall return the settings")
+	assert.JSONEq(t, `[{"type": "divider"}]`, string(messages[0].Blocks), "function shall return the blocks")
+}
+
+func TestGetWelcomeMessagesWithoutIndex(t *testing.T) {
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(404)
+		_, _ = res.Write([]byte(`{"error": {"type": "index_not_found_exception"}, "status": 404}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	messages, err := e.GetWelcomeMessages("CLK7MCUS3")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, 0, len(messages), "function shall not return any welcome message")
+}
+
+func TestSetWelcomeMessage(t *testing.T) {
+	expectedPath := "/welcome/_doc/default?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		var welcome globals.WelcomeMessage
+		assert.Equal(t, nil, json.NewDecoder(req.Body).Decode(&welcome), "body shall be a welcome message")
+		assert.Equal(t, "Welcome", welcome.Text, "body shall contain the text")
+		res.WriteHeader(201)
+		_, _ = res.Write([]byte(`{"_index": "welcome", "_type": "_doc", "_id": "default", "result": "created"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	assert.Equal(t, nil, e.SetWelcomeMessage(globals.WelcomeMessage{Text: "Welcome"}), "function shall not return errors")
+	assert.NotEqual(t, nil, e.SetWelcomeMessage(globals.WelcomeMessage{}), "function shall refuse an empty message")
+}
+
+func TestDeleteWelcomeMessage(t *testing.T) {
+	expectedPath := "/welcome/_doc/CLK7MCUS3?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		assert.Equal(t, "DELETE", req.Method, "Wrong method")
+		res.WriteHeader(404)
+		_, _ = res.Write([]byte(`{"_index": "welcome", "_type": "_doc", "_id": "CLK7MCUS3", "result": "not_found"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	assert.Equal(t, nil, e.DeleteWelcomeMessage("CLK7MCUS3"), "function shall ignore a missing welcome message")
+}
+
+func TestAddWelcomeAcknowledgement(t *testing.T) {
+	expectedPath := "/welcome_acknowledgements/_doc/CLK7MCUS3.UALICE?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		res.WriteHeader(201)
+		_, _ = res.Write([]byte(`{"_index": "welcome_acknowledgements", "_type": "_doc", "_id": "CLK7MCUS3.UALICE", "result": "created"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	err := e.AddWelcomeAcknowledgement(globals.WelcomeAcknowledgement{Channel: "CLK7MCUS3", UserID: "UALICE", Date: "1600000000"})
+	assert.Equal(t, nil, err, "function shall not return errors")
+
+	err = e.AddWelcomeAcknowledgement(globals.WelcomeAcknowledgement{Channel: "CLK7MCUS3"})
+	assert.NotEqual(t, nil, err, "function shall refuse an acknowledgement without user")
+}
@@ -0,0 +1,99 @@
+package elastic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/olivere/elastic/v7"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddStatusRule stores the status rule in the elastic search index
+func (es ES) AddStatusRule(rule globals.StatusRule) error {
+	if rule.Emoji == "" || rule.Status == "" {
+		return errors.New("cannot create status rule without emoji or status")
+	}
+
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index("workflow").
+		Id(rule.ID).
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+
+	if err != nil {
+		return fmt.Errorf("error creating document : %s", err.Error())
+	}
+	return nil
+}
+
+// EditStatusRule Modifies the status rule matching the given documentID
+func (es ES) EditStatusRule(documentID string, rule globals.StatusRule) error {
+	if documentID == "" {
+		return errors.New("cannot edit status rule without documentID")
+	}
+	if rule.Emoji == "" || rule.Status == "" {
+		return errors.New("cannot edit status rule without emoji or status")
+	}
+
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+
+	_, err = es.Client.Index().
+		Index("workflow").
+		Id(documentID).
+		Refresh("true").
+		BodyString(string(b)).
+		Do(es.Context)
+
+	return err
+}
+
+// DeleteStatusRule removes the status rule from the elastic search index
+func (es ES) DeleteStatusRule(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+
+	_, err := es.Client.Delete().
+		Index("workflow").
+		Refresh("true").
+		Id(documentID).
+		Do(es.Context)
+
+	return err
+}
+
+// GetWorkflow returns the status rules of the channel and the ones shared by every channel.
+// The default rules whose emoji is not used by them are added
+func (es ES) GetWorkflow(channel string) (globals.Workflow, error) {
+	query := filterChannel(elastic.NewBoolQuery().Must(elastic.NewMatchAllQuery()), channel)
+	var workflow globals.Workflow
+	_, err := es.searchAll("workflow", query, func(hit *elastic.SearchHit) error {
+		var rule globals.StatusRule
+		if err := json.Unmarshal(hit.Source, &rule); err != nil {
+			log.Errorf("Unable to deserialize source into status rule : %s", err)
+			return nil
+		}
+		rule.ID = hit.Id
+		workflow = append(workflow, rule)
+		return nil
+	})
+	if elastic.IsNotFound(err) {
+		log.Debug("No workflow index, use the default workflow")
+		return globals.DefaultWorkflow, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return workflow.WithDefaults(), nil
+}
@@ -0,0 +1,101 @@
+package elastic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	olivere "github.com/olivere/elastic/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWorkflow(t *testing.T) {
+	expectedPath := "/workflow/_search"
+	eyes := json.RawMessage(`{"emoji": "eyes", "status": "in_progress"}`)
+	x := json.RawMessage(`{"emoji": "x", "status": "wont_fix", "closes": true, "channel": "CLK7MCUS3"}`)
+	expectedResponse := olivere.SearchResult{
+		Hits: &olivere.SearchHits{
+			TotalHits: &olivere.TotalHits{Value: 2},
+			Hits: []*olivere.SearchHit{
+				{Id: "rule0", Source: eyes},
+				{Id: "rule1", Source: x},
+			},
+		},
+	}
+	expectedJSONResponse, err := json.Marshal(expectedResponse)
+	assert.Equal(t, nil, err, "Parsing json shall not return errors")
+
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		res.WriteHeader(200)
+		_, err := res.Write(expectedJSONResponse)
+		assert.Equal(t, nil, err, "Parsing json shall not return errors")
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	workflow, err := e.GetWorkflow("CLK7MCUS3")
+	assert.Equal(t, nil, err, "function shall not return errors")
+	assert.Equal(t, globals.Workflow{
+		{ID: "rule0", Emoji: "eyes", Status: globals.StatusInProgress},
+		{ID: "rule1", Channel: "CLK7MCUS3", Emoji: "x", Status: globals.StatusWontFix, Closes: true},
+		{Emoji: "heavy_check_mark", Status: globals.StatusFixed, Closes: true},
+		{Emoji: "hourglass_flowing_sand", Status: globals.StatusWaitingUser},
+	}, workflow, "function shall return the stored rules followed by the default ones")
+}
+
+func TestGetDefaultWorkflow(t *testing.T) {
+	for name, status := range map[string]int{"missing index": 404, "no rule": 200} {
+		mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(status)
+			if status == 404 {
+				_, _ = res.Write([]byte(`{"error": {"type": "index_not_found_exception"}, "status": 404}`))
+				return
+			}
+			_, _ = res.Write([]byte(`{"hits": {"total": 0, "hits": []}}`))
+		}))
+
+		e := MockClient(t, mockESServer)
+		workflow, err := e.GetWorkflow("")
+		assert.Equal(t, nil, err, name+": function shall not return errors")
+		assert.Equal(t, globals.DefaultWorkflow, workflow, name+": function shall return the default workflow")
+		mockESServer.Close()
+	}
+}
+
+func TestAddStatusRule(t *testing.T) {
+	expectedPath := "/workflow/_doc/I-LEfXQBBlaSKk1R5bDF?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		var rule globals.StatusRule
+		assert.Equal(t, nil, json.NewDecoder(req.Body).Decode(&rule), "body shall be a status rule")
+		assert.Equal(t, globals.StatusInProgress, rule.Status, "body shall contain the status")
+		res.WriteHeader(201)
+		_, _ = res.Write([]byte(`{"_index": "workflow", "_type": "_doc", "_id": "I-LEfXQBBlaSKk1R5bDF", "result": "created"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	err := e.AddStatusRule(globals.StatusRule{ID: "I-LEfXQBBlaSKk1R5bDF", Emoji: "eyes", Status: globals.StatusInProgress})
+	assert.Equal(t, nil, err, "function shall not return errors")
+
+	err = e.AddStatusRule(globals.StatusRule{Emoji: "eyes"})
+	assert.NotEqual(t, nil, err, "function shall refuse a rule without status")
+}
+
+func TestDeleteStatusRule(t *testing.T) {
+	expectedPath := "/workflow/_doc/I-LEfXQBBlaSKk1R5bDF?refresh=true"
+	mockESServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, expectedPath, req.RequestURI, "Wrong path")
+		assert.Equal(t, "DELETE", req.Method, "Wrong method")
+		res.WriteHeader(200)
+		_, _ = res.Write([]byte(`{"_index": "workflow", "_type": "_doc", "_id": "I-LEfXQBBlaSKk1R5bDF", "result": "deleted"}`))
+	}))
+	defer mockESServer.Close()
+
+	e := MockClient(t, mockESServer)
+	assert.Equal(t, nil, e.DeleteStatusRule("I-LEfXQBBlaSKk1R5bDF"), "function shall not return errors")
+	assert.NotEqual(t, nil, e.DeleteStatusRule(""), "function shall refuse an empty id")
+}
@@ -0,0 +1,134 @@
+package globals
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// EscalationTarget is who is notified at a step of an escalation policy
+type EscalationTarget string
+
+const (
+	// EscalateFireman mentions the fireman of the channel in the thread
+	EscalateFireman EscalationTarget = "fireman"
+	// EscalateUser mentions a team member in the thread, the backup of the fireman
+	EscalateUser EscalationTarget = "user"
+	// EscalateGroup mentions a slack user group in the thread, the whole team
+	EscalateGroup EscalationTarget = "group"
+	// EscalateDirect sends a direct message to a team member, the team lead
+	EscalateDirect EscalationTarget = "dm"
+)
+
+// EscalationStep notifies the target when a thread had no activity since the delay
+type EscalationStep struct {
+	After   string           `json:"after"`
+	Target  EscalationTarget `json:"target"`
+	SlackID string           `json:"slack_id,omitempty"`
+}
+
+// EscalationPolicy is the list of the steps followed when a thread stays without answer.
+// A policy applies to the threads of its label and of its tool, every thread when both are empty
+type EscalationPolicy struct {
+	ID      string           `json:"id,omitempty"`
+	Channel string           `json:"channel,omitempty"`
+	Label   string           `json:"label,omitempty"`
+	Tool    string           `json:"tool,omitempty"`
+	Steps   []EscalationStep `json:"steps"`
+}
+
+// Delay returns the delay after which the step is reached, 0 when invalid
+func (s EscalationStep) Delay() time.Duration {
+	delay, err := time.ParseDuration(s.After)
+	if err != nil {
+		return 0
+	}
+	return delay
+}
+
+// Validate checks that the steps of the policy follow each other and that their target is known
+func (p EscalationPolicy) Validate() error {
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("steps are required")
+	}
+	var previous time.Duration
+	for i, step := range p.Steps {
+		delay, err := time.ParseDuration(step.After)
+		if err != nil || delay <= previous {
+			return fmt.Errorf("after of step %d shall be a duration longer than the one of the previous step", i+1)
+		}
+		previous = delay
+		switch step.Target {
+		case EscalateFireman:
+		case EscalateUser, EscalateGroup, EscalateDirect:
+			if step.SlackID == "" {
+				return fmt.Errorf("slack_id of step %d is required to notify a %s", i+1, step.Target)
+			}
+		default:
+			return fmt.Errorf("target of step %d shall be one of fireman, user, group or dm", i+1)
+		}
+	}
+	return nil
+}
+
+// NextReminder returns the timestamp at which the step of the thread is reached, from its last activity.
+// It is empty once every step is reached
+func (p EscalationPolicy) NextReminder(activity time.Time, step int) string {
+	if step < 0 || step >= len(p.Steps) {
+		return ""
+	}
+	return strconv.FormatInt(activity.Add(p.Steps[step].Delay()).Unix(), 10)
+}
+
+// matches counts the criteria of the policy matched by the message, -1 when one of them is not
+func (p EscalationPolicy) matches(message Message) int {
+	criteria := 0
+	for _, c := range []struct {
+		value  string
+		values []string
+	}{{p.Label, message.Labels}, {p.Tool, message.Tools}} {
+		if c.value == "" {
+			continue
+		}
+		if !containsString(c.values, c.value) {
+			return -1
+		}
+		criteria++
+	}
+	return criteria
+}
+
+// EscalationPolicyFor returns the policy of the message, the one matching most of its label and tool.
+// The policies of the channel take precedence over the ones shared by every channel
+func EscalationPolicyFor(policies []EscalationPolicy, message Message) (EscalationPolicy, bool) {
+	best, bestScore := -1, -1
+	for i, policy := range policies {
+		if policy.Channel != "" && policy.Channel != message.Channel {
+			continue
+		}
+		criteria := policy.matches(message)
+		if criteria < 0 {
+			continue
+		}
+		score := 2 * criteria
+		if policy.Channel != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return EscalationPolicy{}, false
+	}
+	return policies[best], true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
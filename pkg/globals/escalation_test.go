@@ -0,0 +1,68 @@
+package globals_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+var escalation = globals.EscalationPolicy{
+	Steps: []globals.EscalationStep{
+		{After: "1h", Target: globals.EscalateFireman},
+		{After: "4h", Target: globals.EscalateGroup, SlackID: "STEAM"},
+		{After: "24h", Target: globals.EscalateDirect, SlackID: "ULEAD"},
+	},
+}
+
+func TestEscalationValidate(t *testing.T) {
+	if err := escalation.Validate(); err != nil {
+		t.Errorf("Policy shall be valid, got %s", err)
+	}
+	invalid := map[string]globals.EscalationPolicy{
+		"no steps":        {},
+		"invalid delay":   {Steps: []globals.EscalationStep{{After: "tomorrow", Target: globals.EscalateFireman}}},
+		"unordered steps": {Steps: []globals.EscalationStep{{After: "2h", Target: globals.EscalateFireman}, {After: "1h", Target: globals.EscalateFireman}}},
+		"unknown target":  {Steps: []globals.EscalationStep{{After: "1h", Target: "channel"}}},
+		"missing user":    {Steps: []globals.EscalationStep{{After: "1h", Target: globals.EscalateUser}}},
+	}
+	for name, policy := range invalid {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("Policy with %s shall be invalid", name)
+		}
+	}
+}
+
+func TestEscalationNextReminder(t *testing.T) {
+	activity := time.Unix(1600000000, 0)
+	if remindAt := escalation.NextReminder(activity, 1); remindAt != "1600014400" {
+		t.Errorf("Second step shall be reached 4 hours after the activity, got %s", remindAt)
+	}
+	if remindAt := escalation.NextReminder(activity, 3); remindAt != "" {
+		t.Errorf("No step shall follow the last one, got %s", remindAt)
+	}
+}
+
+func TestEscalationPolicyFor(t *testing.T) {
+	policies := []globals.EscalationPolicy{
+		{ID: "shared"},
+		{ID: "channel", Channel: "CLK7MCUS3"},
+		{ID: "vault", Tool: "vault"},
+		{ID: "vault rights", Label: "rights", Tool: "vault"},
+		{ID: "other", Channel: "COTHER", Tool: "vault"},
+	}
+	cases := map[string]globals.Message{
+		"channel":      {Channel: "CLK7MCUS3"},
+		"shared":       {Channel: "CUNKNOWN"},
+		"vault":        {Channel: "CLK7MCUS3", Tools: []string{"vault"}},
+		"vault rights": {Channel: "CLK7MCUS3", Labels: []string{"rights"}, Tools: []string{"vault"}},
+	}
+	for expected, message := range cases {
+		if policy, ok := globals.EscalationPolicyFor(policies, message); !ok || policy.ID != expected {
+			t.Errorf("Policy of %v shall be %s, got %s", message, expected, policy.ID)
+		}
+	}
+	if _, ok := globals.EscalationPolicyFor(policies[2:], globals.Message{Channel: "CLK7MCUS3"}); ok {
+		t.Errorf("No policy shall match a message without tool")
+	}
+}
@@ -12,6 +12,7 @@ type User struct {
 	Avatar     string      `json:"avatar"`
 	TeamMember bool        `json:"team_member"`
 	Name       string      `json:"name"`
+	Locale     string      `json:"locale,omitempty"`
 	Profile    UserProfile `json:"profile"`
 }
 
@@ -20,6 +21,7 @@ type TeamMember struct {
 	ID         string      `json:"id"`
 	SlackID    string         `json:"slack_id"`
 	Name       string      `json:"name"`
+	Channel    string      `json:"channel,omitempty"`
 }
 
 // UserProfile contains profile information about the user
@@ -37,48 +39,174 @@ type UserProfile struct {
 
 // Statistics is the object containing all information about support in a period
 type Statistics struct {
-	ID             int           `json:"id"`
-	Messages       []Message     `json:"messages"`
-	ResponseTime   time.Duration `json:"response_time"`
-	ResolutionTime time.Duration `json:"resolution_time"`
-	ResolutionRate int           `json:"resolution_rate"`
-	Firemen        []User        `json:"firemen"`
-	Start          string        `json:"start"`
-	End            string        `json:"end"`
+	ID                     int                     `json:"id"`
+	Count                  int                     `json:"count"`
+	ResponseTime           time.Duration           `json:"response_time"`
+	ResolutionTime         time.Duration           `json:"resolution_time"`
+	BusinessResponseTime   time.Duration           `json:"business_response_time"`
+	BusinessResolutionTime time.Duration           `json:"business_resolution_time"`
+	ResolutionRate         int                     `json:"resolution_rate"`
+	Statuses               map[MessageStatus]int   `json:"statuses"`
+	Distributions          map[string]Distribution `json:"distributions"`
+	Firemen                []User                  `json:"firemen"`
+	Channel                string                  `json:"channel"`
+	Start                  string                  `json:"start"`
+	End                    string                  `json:"end"`
+}
+
+// DurationBuckets are the upper bounds, in minutes, of the histograms of the response and resolution times
+var DurationBuckets = []time.Duration{15, 60, 240, 480, 1440}
+
+// Aggregations are the statistics computed by the storage on the messages of a period
+type Aggregations struct {
+	Count         int                     `json:"count"`
+	Statuses      map[MessageStatus]int   `json:"statuses"`
+	Distributions map[string]Distribution `json:"distributions"`
+}
+
+// Distribution describes how a duration, in minutes, is spread among the messages
+type Distribution struct {
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"mean"`
+	Percentiles
+	Histogram []Bucket `json:"histogram"`
+}
+
+// Percentiles of a duration, in minutes
+type Percentiles struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+}
+
+// Bucket is the number of messages whose duration is in [From, To). The last bucket has no upper bound
+type Bucket struct {
+	From  time.Duration `json:"from"`
+	To    time.Duration `json:"to,omitempty"`
+	Count int           `json:"count"`
+}
+
+// TimeSeriesIntervals are the supported sizes of the buckets of a time series
+var TimeSeriesIntervals = []string{"day", "week", "month"}
+
+// TimeSeriesGroups are the supported ways of splitting the buckets of a time series
+var TimeSeriesGroups = []string{"label", "tool", "status", "user"}
+
+// TimeSeriesQuery selects the user messages to bucket in a time series
+type TimeSeriesQuery struct {
+	Channel  string
+	Start    string
+	End      string
+	Interval string
+	GroupBy  string
+	TimeZone string
+	Closing  []MessageStatus
+}
+
+// MessageSorts are the supported orders of a page of messages
+var MessageSorts = []string{"ts", "response_time", "resolution_time", "relevance"}
+
+// MessageQuery selects a page of user messages, the cursor being the one returned by the previous page.
+// Empty filters do not restrict anything
+type MessageQuery struct {
+	Channel     string
+	Start       string
+	End         string
+	Status      MessageStatus
+	Label       string
+	Tool        string
+	User        string
+	Fireman     string
+	HasFeedback *bool
+	Search      string
+	Sort        string
+	Ascending   bool
+	Cursor      string
+	Size        int
+}
+
+// MessagePage is a page of user messages, the next cursor being empty on the last page
+type MessagePage struct {
+	Messages   []Message `json:"messages"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// TimeSeries is the number of messages and their times over a period, by bucket
+type TimeSeries struct {
+	Interval string       `json:"interval"`
+	GroupBy  string       `json:"group_by,omitempty"`
+	Buckets  []TimeBucket `json:"buckets"`
+}
+
+// TimeBucket holds the messages of an interval of a time series
+type TimeBucket struct {
+	Date           string      `json:"date"`
+	Count          int         `json:"count"`
+	ResponseTime   Percentiles `json:"response_time"`
+	ResolutionTime Percentiles `json:"resolution_time"`
+	Groups         []TimeGroup `json:"groups,omitempty"`
+}
+
+// TimeGroup holds the messages of a bucket sharing the same label, tool, status or user
+type TimeGroup struct {
+	Key            string      `json:"key"`
+	Count          int         `json:"count"`
+	ResponseTime   Percentiles `json:"response_time"`
+	ResolutionTime Percentiles `json:"resolution_time"`
 }
 
 // Message is the main structure representing a message
 type Message struct {
-	ID             string         `json:"id"`
-	Type           MessageType    `json:"type"`
-	Status         string         `json:"status"`
-	Labels         []string       `json:"labels"`
-	Tools          []string       `json:"tools"`
-	AILabels       []pb.Category  `json:"ai_labels"`
-	AITools        []pb.Category  `json:"ai_tools"`
-	Text           string         `json:"text"`
-	UserID         string         `json:"user"`
-	UserName       string         `json:"user_name"`
-	UserInfo       User           `json:"user_info"`
-	Timestamp      string         `json:"ts"`
-	Reactions      []Reaction     `json:"reactions"`
-	Replies        []Reply        `json:"replies"`
-	EditedTs       string         `json:"edited_ts"`
-	DeletedTs      string         `json:"deleted_ts"`
-	RemindAt       string         `json:"remind_at"`
-	ResponseTime   time.Duration  `json:"response_time"`
-	ResolutionTime time.Duration  `json:"resolution_time"`
-	FeedbackStatus FeedbackStatus `json:"feedback_status"`
-	FeedbackTs     string         `json:"feedback_ts"`
+	ID                     string         `json:"id"`
+	Type                   MessageType    `json:"type"`
+	Channel                string         `json:"channel"`
+	Status                 MessageStatus  `json:"status"`
+	Labels                 []string       `json:"labels"`
+	Tools                  []string       `json:"tools"`
+	AILabels               []pb.Category  `json:"ai_labels"`
+	AITools                []pb.Category  `json:"ai_tools"`
+	Text                   string         `json:"text"`
+	UserID                 string         `json:"user"`
+	UserName               string         `json:"user_name"`
+	UserInfo               User           `json:"user_info"`
+	Timestamp              string         `json:"ts"`
+	Reactions              []Reaction     `json:"reactions"`
+	Replies                []Reply        `json:"replies"`
+	EditedTs               string         `json:"edited_ts"`
+	DeletedTs              string         `json:"deleted_ts"`
+	RemindAt               string         `json:"remind_at"`
+	EscalationStep         int            `json:"escalation_step"`
+	WaitingSince           string         `json:"waiting_since,omitempty"`
+	WaitingTime            time.Duration  `json:"waiting_time"`
+	BusinessWaitingTime    time.Duration  `json:"business_waiting_time"`
+	ResponseTime           time.Duration  `json:"response_time"`
+	ResolutionTime         time.Duration  `json:"resolution_time"`
+	BusinessResponseTime   time.Duration  `json:"business_response_time"`
+	BusinessResolutionTime time.Duration  `json:"business_resolution_time"`
+	FeedbackStatus         FeedbackStatus `json:"feedback_status"`
+	FeedbackTs             string         `json:"feedback_ts"`
+	ResolutionNote         string         `json:"resolution_note,omitempty"`
+	ResolutionNoteUser     string         `json:"resolution_note_user,omitempty"`
+	SimilarThreads         []string       `json:"similar_threads,omitempty"`
+	SimilarFeedback        FeedbackStatus `json:"similar_feedback,omitempty"`
+	Answers                []string       `json:"answers,omitempty"`
+}
+
+// SimilarMessage is a closed thread similar to a text, with the relevance score given by the storage
+type SimilarMessage struct {
+	Message
+	Score float64 `json:"score"`
 }
 
 // Reaction is an icon placed on a message. All info comes from slack api except ts
 type Reaction struct {
 	Name      string   `json:"name"`
 	Users     []string `json:"users"`
+	Channel   string   `json:"channel,omitempty"`
 	MessageTs string   `json:"message_ts,omitempty"`
 	Timestamp string   `json:"ts,omitempty"`
 	Count     int      `json:"count"`
+	Removed   bool     `json:"removed,omitempty"`
 }
 
 // Reply represents a message sent in a thread associated with a message
@@ -88,6 +216,7 @@ type Reply struct {
 	UserInfo  User   `json:"user_info"`
 	Timestamp string `json:"ts"`
 	ThreadTs  string `json:"thread_ts"`
+	Channel   string `json:"channel,omitempty"`
 	Text      string `json:"text"`
 	FromBot   bool   `json:"from_bot"`
 }
@@ -95,17 +224,43 @@ type Reply struct {
 // Answer represents a known answer matching a tool and a label
 type Answer struct {
 	ID       string `json:"id,omitempty"`
+	Channel  string `json:"channel,omitempty"`
 	Tool     string `json:"tool,omitempty"`
 	Label    string `json:"label,omitempty"`
 	Answer   string `json:"answer"`
 	Feedback bool   `json:"feedback"`
 }
 
+// AnswerStatistics counts the messages an answer was sent to, and the feedback of their requesters
+type AnswerStatistics struct {
+	AnswerID string `json:"answer_id"`
+	Sent     int    `json:"sent"`
+	Useful   int    `json:"useful"`
+	Useless  int    `json:"useless"`
+}
+
+// Usefulness is the share of the feedback finding the answer useful. It is smoothed so that the answers
+// without any feedback yet rank between the useful and the useless ones
+func (s AnswerStatistics) Usefulness() float64 {
+	return float64(s.Useful+1) / float64(s.Useful+s.Useless+2)
+}
+
+// AnswerReport is the effectiveness of an answer, flagged when it was sent often without ever being useful
+type AnswerReport struct {
+	Answer      Answer  `json:"answer"`
+	Sent        int     `json:"sent"`
+	Useful      int     `json:"useful"`
+	Useless     int     `json:"useless"`
+	Usefulness  float64 `json:"usefulness"`
+	NeverUseful bool    `json:"never_useful"`
+}
+
 // Perco represents a percolate query to match a label
 type Perco struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Query Query  `json:"query"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Channel string `json:"channel,omitempty"`
+	Query   Query  `json:"query"`
 }
 
 // Query represents a percolator query
@@ -136,6 +291,10 @@ const (
 	DeleteMessage ResponseAction = "delete"
 	// UpdateBlockKit Update a block kit in a message
 	UpdateBlockKit ResponseAction = "update_block_kit"
+	// SetTopic Set the topic of the channel (e.g. the fireman of the rotation)
+	SetTopic ResponseAction = "topic"
+	// OpenModal Open a modal to the user who clicked a button (e.g. the resolution note of a thread)
+	OpenModal ResponseAction = "modal"
 )
 
 // SlackResponse describes the data returned from analytics API
@@ -147,6 +306,8 @@ type SlackResponse struct {
 	ChanID      string         `json:"chan_id"`
 	UserID      string         `json:"user_id"`
 	ResponseURL string         `json:"response_url"`
+	TriggerID   string         `json:"trigger_id,omitempty"`
+	View        interface{}    `json:"view,omitempty"`
 }
 
 // MessageType represents the type of event we received
@@ -193,12 +354,31 @@ const (
 	UselessFeedback FeedbackStatus = "feedback_useless"
 )
 
+const (
+	// WaitingUserAction is the action of the buttons and message shortcuts waiting for an answer of the requester
+	WaitingUserAction = "waiting_user"
+	// SnoozeAction is the action of the buttons and message shortcuts postponing the reminders of a thread
+	SnoozeAction = "snooze"
+	// ResolutionNoteAction is the action of the buttons and the callback of the modals of the resolution notes
+	ResolutionNoteAction = "resolution_note"
+	// SimilarFeedbackAction is the action of the buttons telling whether the similar threads suggested are useful
+	SimilarFeedbackAction = "similar_feedback"
+)
+
+// IsThreadAction checks if the action of a button or a message shortcut changes the state of a thread
+func IsThreadAction(action string) bool {
+	return action == WaitingUserAction || action == SnoozeAction
+}
+
 // Interaction represents an interaction with a slack button from a user
 type Interaction struct {
-	MessageTs    string `json:"message_ts"`
-	ThreadTs     string `json:"thread_ts"`
-	ActionTs     string `json:"action_ts"`
-	ActionUserID string `json:"action_user_id"`
-	ActionValue  string `json:"action_value"`
-	ResponseURL  string `json:"response_url"`
+	MessageTs    string            `json:"message_ts"`
+	ThreadTs     string            `json:"thread_ts"`
+	Channel      string            `json:"channel"`
+	ActionTs     string            `json:"action_ts"`
+	ActionUserID string            `json:"action_user_id"`
+	ActionValue  string            `json:"action_value"`
+	ResponseURL  string            `json:"response_url"`
+	TriggerID    string            `json:"trigger_id,omitempty"`
+	Values       map[string]string `json:"values,omitempty"`
 }
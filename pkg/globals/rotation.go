@@ -0,0 +1,149 @@
+package globals
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultShiftDays is the length of the shifts of a rotation when not given, a week
+const DefaultShiftDays = 7
+
+// FiremanPlaceholder is replaced by the mention of the fireman in the topic of a rotation
+const FiremanPlaceholder = "{fireman}"
+
+// Rotation is the ordered roster of the team members taking turns as fireman of a channel.
+// The first shift starts at the start date, each member of the roster taking the next one
+type Rotation struct {
+	ID        string             `json:"id,omitempty"`
+	Channel   string             `json:"channel,omitempty"`
+	Roster    []string           `json:"roster"`
+	Start     string             `json:"start"`
+	ShiftDays int                `json:"shift_days,omitempty"`
+	Topic     string             `json:"topic,omitempty"`
+	Overrides []RotationOverride `json:"overrides,omitempty"`
+}
+
+// RotationOverride replaces a member of the roster from the start to the end date, both included.
+// Without replacement the member is skipped, during vacations, and the next available member of the roster
+// takes the shift. A swap is two overrides, each member replacing the other one during the other's shift
+type RotationOverride struct {
+	SlackID     string `json:"slack_id"`
+	Replacement string `json:"replacement,omitempty"`
+	Start       string `json:"start"`
+	End         string `json:"end"`
+}
+
+// RotationShift is a period during which the same member is the fireman
+type RotationShift struct {
+	Start   string `json:"start"`
+	End     string `json:"end"`
+	Fireman string `json:"fireman"`
+}
+
+// ShiftLength returns the number of days of a shift
+func (r Rotation) ShiftLength() int {
+	if r.ShiftDays <= 0 {
+		return DefaultShiftDays
+	}
+	return r.ShiftDays
+}
+
+// shift returns the number of the shift including the date, -1 before the start of the rotation
+func (r Rotation) shift(date string) int {
+	start, err := time.Parse(DateLayout, r.Start)
+	if err != nil {
+		return -1
+	}
+	day, err := time.Parse(DateLayout, date)
+	if err != nil || day.Before(start) {
+		return -1
+	}
+	return int(day.Sub(start).Hours()/24) / r.ShiftLength()
+}
+
+// Scheduled returns the member of the roster whose shift includes the date, regardless of the overrides.
+// It is empty before the start of the rotation
+func (r Rotation) Scheduled(date string) string {
+	shift := r.shift(date)
+	if shift < 0 || len(r.Roster) == 0 {
+		return ""
+	}
+	return r.Roster[shift%len(r.Roster)]
+}
+
+// FiremanOn returns the fireman of the date, with the overrides applied.
+// It is empty before the start of the rotation or when every member of the roster is skipped
+func (r Rotation) FiremanOn(date string) string {
+	shift := r.shift(date)
+	if shift < 0 {
+		return ""
+	}
+	for i := range r.Roster {
+		member := r.Roster[(shift+i)%len(r.Roster)]
+		override, ok := r.overrideOf(member, date)
+		if !ok {
+			return member
+		}
+		if override.Replacement != "" {
+			return override.Replacement
+		}
+	}
+	return ""
+}
+
+// overrideOf returns the first override of the member covering the date
+func (r Rotation) overrideOf(member string, date string) (RotationOverride, bool) {
+	for _, override := range r.Overrides {
+		if override.SlackID == member && override.Start <= date && date <= override.End {
+			return override, true
+		}
+	}
+	return RotationOverride{}, false
+}
+
+// Schedule returns the shifts from the start to the end date, both included.
+// The days following each other with the same fireman are merged in a single shift
+func (r Rotation) Schedule(start string, end string) ([]RotationShift, error) {
+	from, err := time.Parse(DateLayout, start)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.Parse(DateLayout, end)
+	if err != nil {
+		return nil, err
+	}
+
+	shifts := []RotationShift{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(DateLayout)
+		fireman := r.FiremanOn(date)
+		if last := len(shifts) - 1; last >= 0 && shifts[last].Fireman == fireman {
+			shifts[last].End = date
+			continue
+		}
+		shifts = append(shifts, RotationShift{Start: date, End: date, Fireman: fireman})
+	}
+	return shifts, nil
+}
+
+// TopicFor returns the topic of the channel during the shift of the fireman, empty when the topic is not managed
+func (r Rotation) TopicFor(fireman string) string {
+	return strings.Replace(r.Topic, FiremanPlaceholder, "<@"+fireman+">", -1)
+}
+
+// RotationFor returns the rotation of the channel, which takes precedence over a rotation shared by every channel
+func RotationFor(rotations []Rotation, channel string) (Rotation, bool) {
+	var shared *Rotation
+	for i, rotation := range rotations {
+		if rotation.Channel == channel && channel != "" {
+			return rotation, true
+		}
+		if rotation.Channel == "" && shared == nil {
+			shared = &rotations[i]
+		}
+	}
+	if shared != nil {
+		return *shared, true
+	}
+	return Rotation{}, false
+}
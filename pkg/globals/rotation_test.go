@@ -0,0 +1,100 @@
+package globals_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+var rotation = globals.Rotation{
+	Roster: []string{"UALICE", "UBOB", "UCAROL"},
+	Start:  "2020-06-01",
+	Topic:  "Pompier : {fireman}",
+	Overrides: []globals.RotationOverride{
+		{SlackID: "UBOB", Start: "2020-06-08", End: "2020-06-10"},
+		{SlackID: "UCAROL", Replacement: "UALICE", Start: "2020-06-15", End: "2020-06-21"},
+		{SlackID: "UALICE", Start: "2020-06-22", End: "2020-06-28"},
+		{SlackID: "UBOB", Start: "2020-06-22", End: "2020-06-28"},
+		{SlackID: "UCAROL", Start: "2020-06-22", End: "2020-06-28"},
+	},
+}
+
+func TestRotationScheduled(t *testing.T) {
+	cases := map[string]string{
+		"2020-05-31": "",
+		"2020-06-01": "UALICE",
+		"2020-06-07": "UALICE",
+		"2020-06-08": "UBOB",
+		"2020-06-15": "UCAROL",
+		"2020-06-22": "UALICE",
+	}
+	for date, expected := range cases {
+		if fireman := rotation.Scheduled(date); fireman != expected {
+			t.Errorf("Scheduled fireman of %s shall be %s, got %s", date, expected, fireman)
+		}
+	}
+}
+
+func TestRotationFiremanOn(t *testing.T) {
+	cases := map[string]string{
+		"2020-05-31": "",
+		"2020-06-01": "UALICE",
+		"2020-06-08": "UCAROL",
+		"2020-06-10": "UCAROL",
+		"2020-06-11": "UBOB",
+		"2020-06-15": "UALICE",
+		"2020-06-22": "",
+		"2020-06-29": "UBOB",
+	}
+	for date, expected := range cases {
+		if fireman := rotation.FiremanOn(date); fireman != expected {
+			t.Errorf("Fireman of %s shall be %s, got %s", date, expected, fireman)
+		}
+	}
+
+	custom := globals.Rotation{Roster: []string{"UALICE", "UBOB"}, Start: "2020-06-01", ShiftDays: 1}
+	if fireman := custom.FiremanOn("2020-06-04"); fireman != "UBOB" {
+		t.Errorf("Daily shifts shall alternate, got %s", fireman)
+	}
+}
+
+func TestRotationSchedule(t *testing.T) {
+	shifts, err := rotation.Schedule("2020-06-06", "2020-06-12")
+	if err != nil {
+		t.Fatalf("Schedule shall not fail: %s", err)
+	}
+	expected := []globals.RotationShift{
+		{Start: "2020-06-06", End: "2020-06-07", Fireman: "UALICE"},
+		{Start: "2020-06-08", End: "2020-06-10", Fireman: "UCAROL"},
+		{Start: "2020-06-11", End: "2020-06-12", Fireman: "UBOB"},
+	}
+	if !reflect.DeepEqual(shifts, expected) {
+		t.Errorf("Schedule shall be %v, got %v", expected, shifts)
+	}
+	if _, err := rotation.Schedule("2020-06-06", "tomorrow"); err == nil {
+		t.Errorf("Schedule shall fail on invalid dates")
+	}
+}
+
+func TestRotationTopic(t *testing.T) {
+	if topic := rotation.TopicFor("UBOB"); topic != "Pompier : <@UBOB>" {
+		t.Errorf("Topic shall mention the fireman, got %s", topic)
+	}
+	if topic := (globals.Rotation{}).TopicFor("UBOB"); topic != "" {
+		t.Errorf("Topic shall be empty when not managed, got %s", topic)
+	}
+}
+
+func TestRotationFor(t *testing.T) {
+	rotations := []globals.Rotation{{ID: "shared"}, {ID: "channel", Channel: "CLK7MCUS3"}}
+	if r, ok := globals.RotationFor(rotations, "CLK7MCUS3"); !ok || r.ID != "channel" {
+		t.Errorf("Rotation of the channel shall take precedence, got %v", r)
+	}
+	if r, ok := globals.RotationFor(rotations, "COTHER"); !ok || r.ID != "shared" {
+		t.Errorf("Shared rotation shall be used by the other channels, got %v", r)
+	}
+	if _, ok := globals.RotationFor(nil, "CLK7MCUS3"); ok {
+		t.Errorf("No rotation shall be found")
+	}
+}
@@ -0,0 +1,26 @@
+package globals
+
+// StopWords are the french and english words too common to tell whether two messages are similar
+var StopWords = []string{
+	// french
+	"a", "afin", "ai", "au", "aux", "avec", "avez", "avoir", "bien", "ce", "ces", "cet", "cette", "ci", "comme",
+	"dans", "de", "des", "donc", "du", "elle", "en", "est", "et", "été", "être", "fait", "il", "ils", "je", "j",
+	"l", "la", "le", "les", "leur", "lui", "ma", "mais", "me", "mes", "moi", "mon", "ne", "nos", "notre", "nous",
+	"on", "ou", "où", "par", "pas", "peut", "plus", "pour", "qu", "que", "qui", "sa", "se", "ses", "si", "son",
+	"sont", "sur", "ta", "te", "tes", "toi", "ton", "tu", "un", "une", "vos", "votre", "vous", "y",
+	// english
+	"an", "and", "any", "are", "as", "at", "be", "been", "but", "by", "can", "do", "does", "for", "from", "has",
+	"have", "how", "i", "if", "in", "into", "is", "it", "its", "my", "no", "not", "of", "or", "our",
+	"so", "some", "that", "the", "their", "them", "then", "there", "these", "they", "this", "to", "was", "we",
+	"were", "what", "when", "which", "who", "will", "with", "you", "your",
+}
+
+// IsStopWord tells whether the lowercase word is one of the StopWords
+func IsStopWord(word string) bool {
+	for _, stopWord := range StopWords {
+		if word == stopWord {
+			return true
+		}
+	}
+	return false
+}
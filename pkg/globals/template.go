@@ -0,0 +1,13 @@
+package globals
+
+// MessageTemplate replaces the text of a message of the bot in a locale, as edited through the admin api
+type MessageTemplate struct {
+	Locale string `json:"locale"`
+	Key    string `json:"key"`
+	Text   string `json:"text"`
+}
+
+// DocumentID is the ID of the template in the storage, a single template being stored per locale and key
+func (t MessageTemplate) DocumentID() string {
+	return t.Locale + "." + t.Key
+}
@@ -40,6 +40,12 @@ func ParseDate(date string) (string, error) {
 	return timestamp, nil
 }
 
+//ParseTimestamp converts a slack timestamp into a time
+func ParseTimestamp(ts string) time.Time {
+	f := ParseDuration(ts)
+	return time.Unix(0, int64(f*float64(time.Second)))
+}
+
 //ParseDuration parses string duration into float
 func ParseDuration(t string) float64 {
 	f, err := strconv.ParseFloat(t, 10)
@@ -48,3 +54,15 @@ func ParseDuration(t string) float64 {
 	}
 	return f
 }
+
+//MessageID returns the document ID of a message, unique for a channel and a timestamp
+//so that storing the same message twice never creates duplicates
+func MessageID(channel string, ts string) string {
+	if ts == "" {
+		return ""
+	}
+	if channel == "" {
+		return ts
+	}
+	return channel + "-" + ts
+}
@@ -36,4 +36,15 @@ func TestCompareTimeStamps(t *testing.T) {
 	if diff != 100000. {
 		t.Errorf("Incorrect difference: %f is not equal to %f", diff, 100000.)
 	}
-}
\ No newline at end of file
+}
+func TestMessageID(t *testing.T) {
+	if id := globals.MessageID("CLK7MCUS3", "1592208201.000100"); id != "CLK7MCUS3-1592208201.000100" {
+		t.Errorf("wrong message id %s", id)
+	}
+	if id := globals.MessageID("", "1592208201.000100"); id != "1592208201.000100" {
+		t.Errorf("wrong message id without channel %s", id)
+	}
+	if id := globals.MessageID("CLK7MCUS3", ""); id != "" {
+		t.Errorf("message id shall be empty without timestamp, got %s", id)
+	}
+}
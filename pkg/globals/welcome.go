@@ -0,0 +1,36 @@
+package globals
+
+import "encoding/json"
+
+// WelcomeAckAction is the action of the button acknowledging the rules of a welcome message
+const WelcomeAckAction = "welcome_ack"
+
+// WelcomeMessage is sent to the newcomers of its channel, every channel without one of their own when empty.
+// Its text and its Block Kit blocks are templates given the User and the Channel
+type WelcomeMessage struct {
+	Channel     string          `json:"channel,omitempty"`
+	Text        string          `json:"text"`
+	Blocks      json.RawMessage `json:"blocks,omitempty"`
+	Direct      bool            `json:"direct"`
+	Acknowledge bool            `json:"acknowledge"`
+}
+
+// DocumentID is the ID of the welcome message in the storage, a single message being stored per channel
+func (w WelcomeMessage) DocumentID() string {
+	if w.Channel == "" {
+		return "default"
+	}
+	return w.Channel
+}
+
+// WelcomeAcknowledgement records that a user read the rules of a channel
+type WelcomeAcknowledgement struct {
+	Channel string `json:"channel"`
+	UserID  string `json:"user_id"`
+	Date    string `json:"date"`
+}
+
+// DocumentID is the ID of the acknowledgement in the storage, a single one being stored per channel and user
+func (a WelcomeAcknowledgement) DocumentID() string {
+	return a.Channel + "." + a.UserID
+}
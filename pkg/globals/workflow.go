@@ -0,0 +1,145 @@
+package globals
+
+// MessageStatus is the progress of the support request of a message
+type MessageStatus string
+
+const (
+	// StatusUnresponded nobody of the team replied to the message yet
+	StatusUnresponded MessageStatus = "unresponded"
+	// StatusResponded a team member replied to the message
+	StatusResponded MessageStatus = "responded"
+	// StatusInProgress a team member is working on the request
+	StatusInProgress MessageStatus = "in_progress"
+	// StatusWaitingUser the team is waiting for an answer of the requester
+	StatusWaitingUser MessageStatus = "waiting_user"
+	// StatusFixed the request is solved
+	StatusFixed MessageStatus = "fixed"
+	// StatusWontFix the request is closed without being solved
+	StatusWontFix MessageStatus = "wont_fix"
+	// StatusDeleted the message was deleted by its author
+	StatusDeleted MessageStatus = "deleted"
+)
+
+// StatusRule sets the status of a message when the emoji is added to it.
+// Closing statuses stop the reminders and count as resolved in the analytics
+type StatusRule struct {
+	ID      string        `json:"id,omitempty"`
+	Channel string        `json:"channel,omitempty"`
+	Emoji   string        `json:"emoji"`
+	Status  MessageStatus `json:"status"`
+	Closes  bool          `json:"closes"`
+}
+
+// Workflow is the list of the status rules of a channel
+type Workflow []StatusRule
+
+// DefaultWorkflow holds the rules applied in every channel, unless a rule of the channel uses their emoji
+var DefaultWorkflow = Workflow{
+	{Emoji: "heavy_check_mark", Status: StatusFixed, Closes: true},
+	{Emoji: "hourglass_flowing_sand", Status: StatusWaitingUser},
+}
+
+// Rule returns the rule matching the emoji.
+// The rules of the channel take precedence over the rules shared by every channel
+func (w Workflow) Rule(emoji string) (StatusRule, bool) {
+	var shared *StatusRule
+	for i, rule := range w {
+		if rule.Emoji != emoji {
+			continue
+		}
+		if rule.Channel != "" {
+			return rule, true
+		}
+		if shared == nil {
+			shared = &w[i]
+		}
+	}
+	if shared != nil {
+		return *shared, true
+	}
+	return StatusRule{}, false
+}
+
+// ForChannel returns the workflow of the channel out of the rules of every channel:
+// its own rules and the ones shared by every channel, along with the default rules
+func (w Workflow) ForChannel(channel string) Workflow {
+	var workflow Workflow
+	for _, rule := range w {
+		if rule.Channel == "" || rule.Channel == channel {
+			workflow = append(workflow, rule)
+		}
+	}
+	return workflow.WithDefaults()
+}
+
+// WithDefaults returns the rules followed by the ones of the DefaultWorkflow whose emoji they do not use,
+// so that configuring a rule does not drop the default closing one
+func (w Workflow) WithDefaults() Workflow {
+	workflow := append(Workflow{}, w...)
+	for _, rule := range DefaultWorkflow {
+		if _, ok := w.Rule(rule.Emoji); !ok {
+			workflow = append(workflow, rule)
+		}
+	}
+	return workflow
+}
+
+// IsClosing checks if the status closes the support request
+func (w Workflow) IsClosing(status MessageStatus) bool {
+	for _, rule := range w {
+		if rule.Status == status && rule.Closes {
+			return true
+		}
+	}
+	return false
+}
+
+// ClosingStatuses returns the statuses closing the support requests
+func (w Workflow) ClosingStatuses() []MessageStatus {
+	var statuses []MessageStatus
+	for _, rule := range w {
+		if rule.Closes && !containsStatus(statuses, rule.Status) {
+			statuses = append(statuses, rule.Status)
+		}
+	}
+	return statuses
+}
+
+// EmojiFor returns the emoji of the first rule setting the status
+func (w Workflow) EmojiFor(status MessageStatus) string {
+	for _, rule := range w {
+		if rule.Status == status {
+			return rule.Emoji
+		}
+	}
+	return ""
+}
+
+// StatusFromReactions returns the status set by the reactions of a message.
+// Closing statuses take precedence, it is empty when no reaction matches a rule
+func (w Workflow) StatusFromReactions(reactions []Reaction) (StatusRule, bool) {
+	var found StatusRule
+	var ok bool
+	for _, reaction := range reactions {
+		rule, match := w.Rule(reaction.Name)
+		if !match {
+			continue
+		}
+		if rule.Closes {
+			return rule, true
+		}
+		if !ok {
+			found, ok = rule, true
+		}
+	}
+	return found, ok
+}
+
+func containsStatus(statuses []MessageStatus, status MessageStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package globals_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+var workflow = globals.Workflow{
+	{Emoji: "eyes", Status: globals.StatusInProgress},
+	{Emoji: "x", Status: globals.StatusWontFix, Closes: true},
+	{Emoji: "x", Channel: "CLK7MCUS3", Status: globals.StatusFixed, Closes: true},
+	{Emoji: "heavy_check_mark", Status: globals.StatusFixed, Closes: true},
+}
+
+func TestWorkflowRule(t *testing.T) {
+	rule, ok := workflow.Rule("eyes")
+	if !ok || rule.Status != globals.StatusInProgress {
+		t.Errorf("Rule of eyes shall be in progress, got %v", rule)
+	}
+	rule, ok = workflow.Rule("x")
+	if !ok || rule.Channel != "CLK7MCUS3" {
+		t.Errorf("Rule of the channel shall take precedence, got %v", rule)
+	}
+	if _, ok := workflow.Rule("tada"); ok {
+		t.Errorf("tada shall not match any rule")
+	}
+}
+
+func TestWorkflowClosingStatuses(t *testing.T) {
+	expected := []globals.MessageStatus{globals.StatusWontFix, globals.StatusFixed}
+	if statuses := workflow.ClosingStatuses(); !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("Closing statuses shall be %v, got %v", expected, statuses)
+	}
+	if workflow.IsClosing(globals.StatusInProgress) {
+		t.Errorf("In progress shall not close the messages")
+	}
+}
+
+func TestWorkflowForChannel(t *testing.T) {
+	if !workflow.ForChannel("CLK7MCUS3").IsClosing(globals.StatusFixed) || len(workflow.ForChannel("CLK7MCUS3")) != 5 {
+		t.Errorf("Workflow of the channel shall hold its rules, the shared ones and the missing default ones")
+	}
+	if len(workflow.ForChannel("COTHER")) != 4 {
+		t.Errorf("Workflow of another channel shall only hold the shared rules and the missing default ones")
+	}
+	channels := globals.Workflow{{Emoji: "x", Channel: "CLK7MCUS3", Status: globals.StatusWontFix, Closes: true}}
+	if other := channels.ForChannel("COTHER"); !reflect.DeepEqual(other, globals.DefaultWorkflow) {
+		t.Errorf("Workflow of a channel without rules shall be the default one, got %v", other)
+	}
+}
+
+func TestWorkflowWithDefaults(t *testing.T) {
+	custom := globals.Workflow{{Emoji: "eyes", Status: globals.StatusInProgress}}.WithDefaults()
+	if len(custom) != 3 || !custom.IsClosing(globals.StatusFixed) || custom.EmojiFor(globals.StatusWaitingUser) != "hourglass_flowing_sand" {
+		t.Errorf("Default rules shall be kept along with a custom rule, got %v", custom)
+	}
+	replaced := globals.Workflow{{Emoji: "heavy_check_mark", Status: globals.StatusWontFix, Closes: true}}.WithDefaults()
+	if rule, _ := replaced.Rule("heavy_check_mark"); len(replaced) != 2 || rule.Status != globals.StatusWontFix {
+		t.Errorf("Custom rule shall replace the default rule of its emoji, got %v", replaced)
+	}
+	if empty := (globals.Workflow{}).WithDefaults(); !reflect.DeepEqual(empty, globals.DefaultWorkflow) {
+		t.Errorf("Workflow without rules shall be the default one, got %v", empty)
+	}
+}
+
+func TestWorkflowStatusFromReactions(t *testing.T) {
+	reactions := []globals.Reaction{{Name: "tada"}, {Name: "eyes"}, {Name: "heavy_check_mark"}}
+	rule, ok := workflow.StatusFromReactions(reactions)
+	if !ok || rule.Status != globals.StatusFixed {
+		t.Errorf("Closing reaction shall take precedence, got %v", rule)
+	}
+	if _, ok := workflow.StatusFromReactions([]globals.Reaction{{Name: "tada"}}); ok {
+		t.Errorf("tada shall not set any status")
+	}
+}
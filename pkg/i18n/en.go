@@ -0,0 +1,84 @@
+package i18n
+
+var en = Messages{
+	"message.thanks":  "Thanks for your message.",
+	"message.threads": "Please keep the discussion in threads.",
+	"welcome": `:wave: _Welcome to {{channel .Channel}}_
+
+	:warning: *Read before posting* :warning:
+
+		:fireman: Every week a member of the team is dedicated to the support
+		:redcard: Please do not use @here or @channel
+		:point_right: Please explain your question in your first message
+		:threadplz: Please keep the discussion in threads`,
+
+	"welcome.acknowledge":  "I've read the rules",
+	"welcome.acknowledged": ":white_check_mark: Thanks {{mention .User}}, enjoy {{channel .Channel}}!",
+
+	"similar.intro":  "These resolved threads look like your request:",
+	"similar.thread": "• {{link .Link .Excerpt}}{{if .Note}}: _{{.Note}}_{{end}}",
+
+	"similar.question": "Did these threads help you?",
+	"similar.useful":   "Yes :+1:",
+	"similar.useless":  "No :-1:",
+	"similar.thanks":   "Thanks for your feedback on the suggested threads",
+
+	"feedback.question": "Did this answer solve your issue?",
+	"feedback.useful":   "Yes, thanks Subot! :slightly_smiling_face:",
+	"feedback.useless":  "No, call the fireman :fire:",
+	"feedback.fixed":    "Glad I could help",
+	"feedback.fireman":  "Handing over to the fireman. Help {{mention .Fireman}}",
+
+	"reminder.fireman": "Any news {{mention .Fireman}}?",
+	"reminder.user":    "Any news {{mention .User}}? This thread is still waiting for an answer.",
+	"reminder.group":   "Any news {{group .Group}}? This thread is still waiting for an answer.",
+	"reminder.direct":  "The thread {{link .Link .Excerpt}} of {{channel .Channel}} had no news for {{.Age}}.",
+
+	"thread.no_text":         "(no text)",
+	"thread.waiting_button":  "Waiting for the requester :hourglass_flowing_sand:",
+	"thread.snooze_button":   "Snooze the reminders :zzz:",
+	"thread.not_support":     "This message is not a support thread.",
+	"thread.not_team":        "Only the members of the team can change the state of a thread.",
+	"thread.closed":          "This thread is already closed.",
+	"thread.already_waiting": "This thread is already waiting for the requester.",
+	"thread.no_reminder":     "No reminder is planned on this thread.",
+	"thread.snoozed":         "The reminders of this thread are postponed by {{.Delay}}.",
+
+	"waiting.started":  "Waiting for an answer of {{mention .User}}. Without news, this thread will be closed in {{.Delay}}.",
+	"waiting.reminder": "Any news {{mention .User}}? Without news from you, this thread will be closed in {{.Delay}}.",
+	"waiting.closed":   "No news from {{mention .User}}, I am closing this thread. Feel free to post a new message if needed.",
+
+	"resolution.ask":    "This thread is resolved :tada: A one-line resolution note will help find the solution next time.",
+	"resolution.button": "Add a note",
+	"resolution.title":  "Resolution note",
+	"resolution.label":  "How was this thread resolved?",
+	"resolution.submit": "Save",
+	"resolution.cancel": "Cancel",
+	"resolution.saved":  ":memo: Resolution note by {{mention .User}}: {{.Note}}",
+
+	"rotation.announce": "{{mention .Fireman}} is the fireman now.",
+
+	"handover.text":            "Handover of {{channel .Channel}} to {{mention .Fireman}}",
+	"handover.intro":           "{{mention .Fireman}} takes over {{channel .Channel}}: {{.Count}} open thread(s) in the last {{.Days}} days.",
+	"handover.status":          "*{{.Status}}* ({{.Count}})",
+	"handover.opened":          "open for {{.Age}}",
+	"handover.feedback":        "*Waiting for a user feedback* ({{.Count}})",
+	"handover.feedback_detail": "from {{mention .User}}",
+	"handover.reminders":       "*Planned reminders* ({{.Count}})",
+	"handover.reminder_detail": "<!date^{{.Date}}^{date_short_pretty} at {time}|{{.Date}}>",
+	"handover.more":            "… and {{.Count}} more",
+
+	"report.text":            "Report",
+	"report.intro":           "Here are the statistics of our performance on the support for the past week",
+	"report.messages":        "*Messages:*\n{{.Count}} messages this week",
+	"report.resolution_rate": "*Resolution rate*\n{{.Rate}}% fixed",
+	"report.response_time":   "*Average response time*\n{{.Minutes}} min ({{.BusinessMinutes}} min in business hours)",
+	"report.evolution":       "*Messages evolution*\n{{.Count}} messages {{if .Less}}less{{else}}more{{end}} compared to last week",
+	"report.resolution_diff": "*Resolution rate compared to last week*\n{{printf \"%+d\" .Diff}} %",
+	"report.firemen":         "*Firemen*\n{{.Firemen}}",
+	"report.dashboard":       "For more statistics see our *<{{.URL}}|analytics dashboard>*",
+
+	"duration.minutes": "{{.Count}} min",
+	"duration.hours":   "{{.Count}} h",
+	"duration.days":    "{{.Count}} d",
+}
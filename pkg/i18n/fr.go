@@ -0,0 +1,84 @@
+package i18n
+
+var fr = Messages{
+	"message.thanks":  "Merci pour ton message.",
+	"message.threads": "Merci de respecter les threads.",
+	"welcome": `:wave: _Bienvenue sur {{channel .Channel}}_
+
+	:warning: *A lire avant de poster* :warning:
+
+		:fireman: Une personne de l'équipe est dédiée chaque semaine à la gestion du support
+		:redcard: Merci de ne pas utiliser de @here ou @channel
+		:point_right: Merci d'exposer ta question dans ton premier message
+		:threadplz: Merci de continuer la discussion en thread`,
+
+	"welcome.acknowledge":  "J'ai lu les règles",
+	"welcome.acknowledged": ":white_check_mark: Merci {{mention .User}}, bonne journée sur {{channel .Channel}} !",
+
+	"similar.intro":  "Ces fils résolus ressemblent à ta demande :",
+	"similar.thread": "• {{link .Link .Excerpt}}{{if .Note}} : _{{.Note}}_{{end}}",
+
+	"similar.question": "Ces fils t'ont-ils aidé ?",
+	"similar.useful":   "Oui :+1:",
+	"similar.useless":  "Non :-1:",
+	"similar.thanks":   "Merci pour ton retour sur les fils suggérés",
+
+	"feedback.question": "Cette réponse a t'elle permis de résoudre ton souci ?",
+	"feedback.useful":   "Oui, merci Subot ! :slightly_smiling_face:",
+	"feedback.useless":  "Non, contacter le pompier :fire:",
+	"feedback.fixed":    "Ravi d'avoir pu aider",
+	"feedback.fireman":  "Je rends la main au pompier. Au secours {{mention .Fireman}}",
+
+	"reminder.fireman": "Du nouveau {{mention .Fireman}} ?",
+	"reminder.user":    "Du nouveau {{mention .User}} ? Ce fil attend toujours une réponse.",
+	"reminder.group":   "Du nouveau {{group .Group}} ? Ce fil attend toujours une réponse.",
+	"reminder.direct":  "Le fil {{link .Link .Excerpt}} de {{channel .Channel}} est sans nouvelles depuis {{.Age}}.",
+
+	"thread.no_text":         "(sans texte)",
+	"thread.waiting_button":  "En attente du demandeur :hourglass_flowing_sand:",
+	"thread.snooze_button":   "Reporter les relances :zzz:",
+	"thread.not_support":     "Ce message n'est pas un fil de support.",
+	"thread.not_team":        "Seuls les membres de l'équipe peuvent changer l'état d'un fil.",
+	"thread.closed":          "Ce fil est déjà fermé.",
+	"thread.already_waiting": "Ce fil attend déjà un retour du demandeur.",
+	"thread.no_reminder":     "Aucune relance n'est prévue sur ce fil.",
+	"thread.snoozed":         "Les relances de ce fil sont reportées de {{.Delay}}.",
+
+	"waiting.started":  "En attente d'un retour de {{mention .User}}. Sans nouvelles, ce fil sera fermé dans {{.Delay}}.",
+	"waiting.reminder": "Du nouveau {{mention .User}} ? Sans nouvelles de ta part, ce fil sera fermé dans {{.Delay}}.",
+	"waiting.closed":   "Sans nouvelles de {{mention .User}}, je ferme ce fil. N'hésite pas à poster un nouveau message si besoin.",
+
+	"resolution.ask":    "Ce fil est résolu :tada: Une note de résolution en une ligne aidera à retrouver la solution la prochaine fois.",
+	"resolution.button": "Ajouter une note",
+	"resolution.title":  "Note de résolution",
+	"resolution.label":  "Comment ce fil a-t-il été résolu ?",
+	"resolution.submit": "Enregistrer",
+	"resolution.cancel": "Annuler",
+	"resolution.saved":  ":memo: Note de résolution de {{mention .User}} : {{.Note}}",
+
+	"rotation.announce": "C'est au tour de {{mention .Fireman}} d'être pompier.",
+
+	"handover.text":            "Passation de {{channel .Channel}} à {{mention .Fireman}}",
+	"handover.intro":           "{{mention .Fireman}} prend la relève sur {{channel .Channel}} : {{.Count}} fil(s) ouvert(s) ces {{.Days}} derniers jours.",
+	"handover.status":          "*{{.Status}}* ({{.Count}})",
+	"handover.opened":          "ouvert depuis {{.Age}}",
+	"handover.feedback":        "*En attente d'un retour utilisateur* ({{.Count}})",
+	"handover.feedback_detail": "de {{mention .User}}",
+	"handover.reminders":       "*Relances prévues* ({{.Count}})",
+	"handover.reminder_detail": "<!date^{{.Date}}^{date_short_pretty} à {time}|{{.Date}}>",
+	"handover.more":            "… et {{.Count}} autre(s)",
+
+	"report.text":            "Rapport",
+	"report.intro":           "Voici les statistiques du support de la semaine passée",
+	"report.messages":        "*Messages*\n{{.Count}} messages cette semaine",
+	"report.resolution_rate": "*Taux de résolution*\n{{.Rate}} % résolus",
+	"report.response_time":   "*Temps de réponse moyen*\n{{.Minutes}} min ({{.BusinessMinutes}} min en heures ouvrées)",
+	"report.evolution":       "*Évolution des messages*\n{{.Count}} messages de {{if .Less}}moins{{else}}plus{{end}} que la semaine dernière",
+	"report.resolution_diff": "*Taux de résolution par rapport à la semaine dernière*\n{{printf \"%+d\" .Diff}} %",
+	"report.firemen":         "*Pompiers*\n{{.Firemen}}",
+	"report.dashboard":       "Plus de statistiques sur le *<{{.URL}}|tableau de bord>*",
+
+	"duration.minutes": "{{.Count}} min",
+	"duration.hours":   "{{.Count}} h",
+	"duration.days":    "{{.Count}} j",
+}
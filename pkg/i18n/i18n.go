@@ -0,0 +1,342 @@
+// Package i18n renders the messages sent by the bot in the locale of the channel or of the user they address.
+// The messages are text/template templates, shipped for every built-in locale, replaced by the locale files
+// of the locales_path directory and by the templates edited through the admin api
+package i18n
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is the locale of the messages when neither the channel nor the user has one,
+// and the one the missing templates of the other locales fall back on
+const DefaultLocale = "fr"
+
+// Messages are the templates of the messages of a locale, by key
+type Messages map[string]string
+
+// Vars are the variables given to a template
+type Vars map[string]interface{}
+
+var builtin = map[string]Messages{
+	"en": en,
+	"fr": fr,
+}
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,4})?$`)
+
+var funcs = template.FuncMap{
+	"mention": func(id string) string { return "<@" + id + ">" },
+	"group":   func(id string) string { return "<!subteam^" + id + ">" },
+	"channel": func(id string) string { return "<#" + id + ">" },
+	"link":    func(url string, text string) string { return "<" + url + "|" + text + ">" },
+}
+
+// Entry is the template of a message in a locale, as listed by the api
+type Entry struct {
+	Locale     string   `json:"locale"`
+	Key        string   `json:"key"`
+	Text       string   `json:"text"`
+	Default    string   `json:"default"`
+	Overridden bool     `json:"overridden"`
+	Fallback   bool     `json:"fallback"`
+	Variables  []string `json:"variables"`
+}
+
+// Catalogue holds the templates of the messages in every locale, safe for concurrent use.
+// A nil catalogue renders the built-in templates
+type Catalogue struct {
+	mutex     sync.RWMutex
+	files     map[string]Messages
+	overrides map[string]Messages
+}
+
+// Configure creates the catalogue with the locale files of the locales_path directory, if any
+func Configure() (*Catalogue, error) {
+	c := New()
+	if path := viper.GetString("locales_path"); path != "" {
+		if err := c.LoadDir(path); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// New returns a catalogue of the built-in templates
+func New() *Catalogue {
+	return &Catalogue{
+		files:     make(map[string]Messages),
+		overrides: make(map[string]Messages),
+	}
+}
+
+// LoadDir reads the locale files of the directory, YAML maps of the keys to their template named after
+// their locale (en.yml, pt-BR.yml). Their templates replace the built-in ones of the locale
+func (c *Catalogue) LoadDir(path string) error {
+	files, err := filepath.Glob(filepath.Join(path, "*.y*ml"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		locale := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if !localePattern.MatchString(locale) {
+			return fmt.Errorf("invalid locale file %s, it shall be named after its locale", file)
+		}
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var messages Messages
+		if err := yaml.Unmarshal(b, &messages); err != nil {
+			return fmt.Errorf("could not parse locale file %s : %s", file, err)
+		}
+		for key, text := range messages {
+			if err := Validate(key, text); err != nil {
+				return fmt.Errorf("invalid locale file %s : %s", file, err)
+			}
+		}
+
+		c.mutex.Lock()
+		c.files[locale] = messages
+		c.mutex.Unlock()
+		log.WithFields(log.Fields{"locale": locale, "messages": len(messages)}).Info("Loaded locale file")
+	}
+	return nil
+}
+
+// Override replaces the template of the message in its locale
+func (c *Catalogue) Override(t globals.MessageTemplate) error {
+	if err := ValidateTemplate(t); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.overrides[t.Locale] == nil {
+		c.overrides[t.Locale] = make(Messages)
+	}
+	c.overrides[t.Locale][t.Key] = t.Text
+	return nil
+}
+
+// Restore removes the template replacing the message in the locale
+func (c *Catalogue) Restore(locale string, key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.overrides[locale], key)
+}
+
+// Reload replaces all the templates overriding the messages, the invalid ones are returned and left out
+func (c *Catalogue) Reload(templates []globals.MessageTemplate) map[globals.MessageTemplate]error {
+	overrides := make(map[string]Messages)
+	invalid := make(map[globals.MessageTemplate]error)
+	for _, t := range templates {
+		if err := ValidateTemplate(t); err != nil {
+			invalid[t] = err
+			continue
+		}
+		if overrides[t.Locale] == nil {
+			overrides[t.Locale] = make(Messages)
+		}
+		overrides[t.Locale][t.Key] = t.Text
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.overrides = overrides
+	return invalid
+}
+
+// Render returns the message of the key in the locale. The template is looked for in the locale,
+// then in its language and in the default locale. The key itself is returned when no template renders
+func (c *Catalogue) Render(locale string, key string, vars Vars) string {
+	for _, candidate := range candidates(locale) {
+		text, ok := c.lookup(candidate, key)
+		if !ok {
+			continue
+		}
+		rendered, err := render(key, text, vars)
+		if err != nil {
+			log.WithFields(log.Fields{"locale": candidate, "key": key, "error": err}).Error("Could not render the message")
+			continue
+		}
+		return rendered
+	}
+	log.WithFields(log.Fields{"locale": locale, "key": key}).Error("No template for the message")
+	return key
+}
+
+// Locales returns the locales having templates, sorted
+func (c *Catalogue) Locales() []string {
+	seen := make(map[string]bool)
+	for locale := range builtin {
+		seen[locale] = true
+	}
+	if c != nil {
+		c.mutex.RLock()
+		for locale := range c.files {
+			seen[locale] = true
+		}
+		for locale, messages := range c.overrides {
+			if len(messages) > 0 {
+				seen[locale] = true
+			}
+		}
+		c.mutex.RUnlock()
+	}
+	var locales []string
+	for locale := range seen {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Entries returns the templates of every message in the locale, with the ones they replace.
+// The messages missing in the locale have the template they fall back on
+func (c *Catalogue) Entries(locale string) []Entry {
+	var entries []Entry
+	for _, key := range Keys() {
+		entry := Entry{Locale: locale, Key: key, Variables: variableNames(key)}
+		for _, candidate := range candidates(locale) {
+			if text, ok := c.lookup(candidate, key); ok && entry.Text == "" {
+				entry.Text = text
+				entry.Fallback = candidate != locale
+			}
+			if text, ok := c.lookupDefault(candidate, key); ok && entry.Default == "" {
+				entry.Default = text
+			}
+		}
+		if c != nil {
+			c.mutex.RLock()
+			_, entry.Overridden = c.overrides[locale][key]
+			c.mutex.RUnlock()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// lookup returns the template of the key in the locale, edited through the api, from a locale file or built-in
+func (c *Catalogue) lookup(locale string, key string) (string, bool) {
+	if c != nil {
+		c.mutex.RLock()
+		text, ok := c.overrides[locale][key]
+		c.mutex.RUnlock()
+		if ok {
+			return text, true
+		}
+	}
+	return c.lookupDefault(locale, key)
+}
+
+// lookupDefault returns the template of the key in the locale, ignoring the ones edited through the api
+func (c *Catalogue) lookupDefault(locale string, key string) (string, bool) {
+	if c != nil {
+		c.mutex.RLock()
+		text, ok := c.files[locale][key]
+		c.mutex.RUnlock()
+		if ok {
+			return text, true
+		}
+	}
+	text, ok := builtin[locale][key]
+	return text, ok
+}
+
+// candidates returns the locales whose templates are used for the locale, in order.
+// Slack locales such as fr-FR fall back on their language
+func candidates(locale string) []string {
+	locale = strings.Replace(locale, "_", "-", -1)
+	var locales []string
+	add := func(l string) {
+		for _, existing := range locales {
+			if existing == l {
+				return
+			}
+		}
+		locales = append(locales, l)
+	}
+	if locale != "" {
+		add(locale)
+		add(strings.ToLower(strings.SplitN(locale, "-", 2)[0]))
+	}
+	add(DefaultLocale)
+	return locales
+}
+
+// render executes the template of the key. A variable missing from the variables of the key is an error
+func render(key string, text string, vars Vars) (string, error) {
+	tmpl, err := template.New(key).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	if vars == nil {
+		vars = Vars{}
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Execute renders a template which is not a message of the catalogue, such as the welcome message of a channel,
+// with the functions of the messages. A variable missing from the variables is an error
+func Execute(name string, text string, vars Vars) (string, error) {
+	return render(name, text, vars)
+}
+
+// Keys returns the keys of every message of the bot, sorted
+func Keys() []string {
+	var keys []string
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate checks that the key is a message of the bot and that its template renders with the variables of the key
+func Validate(key string, text string) error {
+	sample, ok := variables[key]
+	if !ok {
+		return fmt.Errorf("unknown message %q", key)
+	}
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("empty template for the message %q", key)
+	}
+	if _, err := render(key, text, sample); err != nil {
+		return fmt.Errorf("invalid template for the message %q : %s", key, err)
+	}
+	return nil
+}
+
+// ValidateTemplate checks the locale and the template of the message
+func ValidateTemplate(t globals.MessageTemplate) error {
+	if !localePattern.MatchString(t.Locale) {
+		return errors.New("locale shall be a language code, optionally followed by a region (fr, en-US)")
+	}
+	return Validate(t.Key, t.Text)
+}
+
+// variableNames returns the names of the variables of the key, sorted
+func variableNames(key string) []string {
+	names := []string{}
+	for name := range variables[key] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
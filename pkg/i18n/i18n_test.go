@@ -0,0 +1,88 @@
+package i18n_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinLocales(t *testing.T) {
+	c := i18n.New()
+	assert.Equal(t, []string{"en", "fr"}, c.Locales(), "built-in locales shall be available")
+	for _, locale := range c.Locales() {
+		for _, entry := range c.Entries(locale) {
+			assert.False(t, entry.Fallback, "%s shall be translated in %s", entry.Key, locale)
+			assert.Nil(t, i18n.Validate(entry.Key, entry.Text), "%s shall render in %s", entry.Key, locale)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	var c *i18n.Catalogue
+	assert.Equal(t, "Du nouveau <@U1> ?", c.Render("fr", "reminder.fireman", i18n.Vars{"Fireman": "U1"}), "nil catalogue shall render the built-in templates")
+	assert.Equal(t, "Any news <@U1>?", c.Render("en-US", "reminder.fireman", i18n.Vars{"Fireman": "U1"}), "slack locales shall fall back on their language")
+	assert.Equal(t, "Du nouveau <@U1> ?", c.Render("de-DE", "reminder.fireman", i18n.Vars{"Fireman": "U1"}), "unknown locales shall fall back on the default locale")
+	assert.Equal(t, "Du nouveau <@U1> ?", c.Render("", "reminder.fireman", i18n.Vars{"Fireman": "U1"}), "empty locale shall be the default locale")
+	assert.Equal(t, "unknown.key", c.Render("fr", "unknown.key", nil), "unknown messages shall render their key")
+	assert.Equal(t, "*Messages evolution*\n3 messages less compared to last week",
+		c.Render("en", "report.evolution", i18n.Vars{"Count": 3, "Less": true}), "templates shall render conditions")
+}
+
+func TestOverride(t *testing.T) {
+	c := i18n.New()
+	assert.NotNil(t, c.Override(globals.MessageTemplate{Locale: "fr", Key: "unknown.key", Text: "Bonjour"}), "unknown messages shall be refused")
+	assert.NotNil(t, c.Override(globals.MessageTemplate{Locale: "fr", Key: "reminder.fireman", Text: "{{mention .User}} ?"}), "unknown variables shall be refused")
+	assert.NotNil(t, c.Override(globals.MessageTemplate{Locale: "fr", Key: "reminder.fireman", Text: "{{mention .Fireman"}), "invalid templates shall be refused")
+	assert.NotNil(t, c.Override(globals.MessageTemplate{Locale: "french", Key: "reminder.fireman", Text: "Hop"}), "invalid locales shall be refused")
+
+	assert.Nil(t, c.Override(globals.MessageTemplate{Locale: "fr", Key: "reminder.fireman", Text: "Alors {{mention .Fireman}} ?"}))
+	assert.Equal(t, "Alors <@U1> ?", c.Render("fr-FR", "reminder.fireman", i18n.Vars{"Fireman": "U1"}), "override shall replace the template")
+	for _, entry := range c.Entries("fr") {
+		if entry.Key == "reminder.fireman" {
+			assert.True(t, entry.Overridden, "entry shall be overridden")
+			assert.Equal(t, "Du nouveau {{mention .Fireman}} ?", entry.Default, "entry shall keep the replaced template")
+			assert.Equal(t, []string{"Fireman"}, entry.Variables, "entry shall list its variables")
+		}
+	}
+
+	c.Restore("fr", "reminder.fireman")
+	assert.Equal(t, "Du nouveau <@U1> ?", c.Render("fr", "reminder.fireman", i18n.Vars{"Fireman": "U1"}), "restore shall bring the template back")
+}
+
+func TestReload(t *testing.T) {
+	c := i18n.New()
+	assert.Nil(t, c.Override(globals.MessageTemplate{Locale: "fr", Key: "reminder.fireman", Text: "Alors {{mention .Fireman}} ?"}))
+
+	invalid := globals.MessageTemplate{Locale: "fr", Key: "reminder.fireman", Text: "{{mention .User}} ?"}
+	errors := c.Reload([]globals.MessageTemplate{
+		{Locale: "en", Key: "reminder.fireman", Text: "So {{mention .Fireman}}?"},
+		invalid,
+	})
+	assert.Len(t, errors, 1, "invalid templates shall be returned")
+	assert.NotNil(t, errors[invalid])
+	assert.Equal(t, "So <@U1>?", c.Render("en", "reminder.fireman", i18n.Vars{"Fireman": "U1"}), "reload shall apply the templates")
+	assert.Equal(t, "Du nouveau <@U1> ?", c.Render("fr", "reminder.fireman", i18n.Vars{"Fireman": "U1"}), "reload shall drop the templates no longer stored")
+}
+
+func TestLoadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "locales")
+	assert.Equal(t, nil, err, "creating the directory shall not return errors")
+	defer os.RemoveAll(dir)
+
+	content := "reminder.fireman: \"Novità {{mention .Fireman}}?\"\nmessage.thanks: Grazie per il messaggio.\n"
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "it.yml"), []byte(content), 0600))
+
+	c := i18n.New()
+	assert.Equal(t, nil, c.LoadDir(dir), "loading the locale files shall not return errors")
+	assert.Equal(t, []string{"en", "fr", "it"}, c.Locales(), "locale files shall add their locale")
+	assert.Equal(t, "Novità <@U1>?", c.Render("it", "reminder.fireman", i18n.Vars{"Fireman": "U1"}), "locale file shall be rendered")
+	assert.Equal(t, "Merci de respecter les threads.", c.Render("it", "message.threads", nil), "missing messages shall fall back on the default locale")
+
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "de.yml"), []byte("reminder.fireman: \"{{.Unknown}}\"\n"), 0600))
+	assert.NotNil(t, i18n.New().LoadDir(dir), "invalid templates shall be refused")
+}
@@ -0,0 +1,88 @@
+package i18n
+
+// variables lists every message of the bot, with a sample of each variable given to its template.
+// The templates are validated against these samples
+var variables = map[string]Vars{
+	"message.thanks":  {},
+	"message.threads": {},
+	"welcome":         {"User": "U0123", "Channel": "C0123"},
+
+	"welcome.acknowledge":  {},
+	"welcome.acknowledged": {"User": "U0123", "Channel": "C0123"},
+
+	"similar.intro": {},
+	"similar.thread": {
+		"Link":    "https://slack.com/archives/C0123/p1600000000000100",
+		"Excerpt": "Mon token vault a expiré",
+		"Note":    "Le token a été renouvelé",
+	},
+
+	"similar.question": {},
+	"similar.useful":   {},
+	"similar.useless":  {},
+	"similar.thanks":   {},
+
+	"feedback.question": {},
+	"feedback.useful":   {},
+	"feedback.useless":  {},
+	"feedback.fixed":    {},
+	"feedback.fireman":  {"Fireman": "U0123"},
+
+	"reminder.fireman": {"Fireman": "U0123"},
+	"reminder.user":    {"User": "U0123"},
+	"reminder.group":   {"Group": "S0123"},
+	"reminder.direct": {
+		"Link":    "https://slack.com/archives/C0123/p1600000000000100",
+		"Excerpt": "Mon token vault a expiré",
+		"Channel": "C0123",
+		"Age":     "3 h",
+	},
+
+	"thread.no_text":         {},
+	"thread.waiting_button":  {},
+	"thread.snooze_button":   {},
+	"thread.not_support":     {},
+	"thread.not_team":        {},
+	"thread.closed":          {},
+	"thread.already_waiting": {},
+	"thread.no_reminder":     {},
+	"thread.snoozed":         {"Delay": "1 j"},
+
+	"waiting.started":  {"User": "U0123", "Delay": "3 j"},
+	"waiting.reminder": {"User": "U0123", "Delay": "2 j"},
+	"waiting.closed":   {"User": "U0123"},
+
+	"resolution.ask":    {},
+	"resolution.button": {},
+	"resolution.title":  {},
+	"resolution.label":  {},
+	"resolution.submit": {},
+	"resolution.cancel": {},
+	"resolution.saved":  {"User": "U0123", "Note": "Le token a été renouvelé"},
+
+	"rotation.announce": {"Fireman": "U0123"},
+
+	"handover.text":            {"Fireman": "U0123", "Channel": "C0123"},
+	"handover.intro":           {"Fireman": "U0123", "Channel": "C0123", "Count": 4, "Days": 30},
+	"handover.status":          {"Status": "unresponded", "Count": 2},
+	"handover.opened":          {"Age": "3 j"},
+	"handover.feedback":        {"Count": 1},
+	"handover.feedback_detail": {"User": "U0123"},
+	"handover.reminders":       {"Count": 1},
+	"handover.reminder_detail": {"Date": int64(1600000000)},
+	"handover.more":            {"Count": 2},
+
+	"report.text":            {},
+	"report.intro":           {},
+	"report.messages":        {"Count": 42},
+	"report.resolution_rate": {"Rate": 80},
+	"report.response_time":   {"Minutes": 25, "BusinessMinutes": 20},
+	"report.evolution":       {"Count": 3, "Less": false},
+	"report.resolution_diff": {"Diff": -5},
+	"report.firemen":         {"Firemen": "<@U0123> <@U4567>"},
+	"report.dashboard":       {"URL": "https://subot.example.com"},
+
+	"duration.minutes": {"Count": 5},
+	"duration.hours":   {"Count": 3},
+	"duration.days":    {"Count": 2},
+}
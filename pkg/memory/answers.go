@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+)
+
+// AddAnswer Adds an answer matching the given tool and label
+func (m *Memory) AddAnswer(answer globals.Answer) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err := m.checkAnswer(answer); err != nil {
+		return err
+	}
+	m.putAnswer(storage.NewID(), answer)
+	return nil
+}
+
+// checkAnswer verifies if the tool and label specified in the answer really exists and that the answer is not empty
+func (m *Memory) checkAnswer(answer globals.Answer) error {
+	if answer.Answer == "" {
+		return errors.New("no answer provided")
+	}
+
+	named := func(name string) func(globals.Perco) bool {
+		return func(perco globals.Perco) bool { return perco.Name == name }
+	}
+	if answer.Tool != "" && len(m.selectPercos(toolsKind, named(answer.Tool))) != 1 {
+		return errors.New("specified tool does not exist")
+	}
+	if answer.Label != "" && len(m.selectPercos(labelsKind, named(answer.Label))) != 1 {
+		return errors.New("specified label does not exist")
+	}
+	return nil
+}
+
+func (m *Memory) putAnswer(documentID string, answer globals.Answer) {
+	var stored globals.Answer
+	clone(answer, &stored)
+	m.answers[documentID] = stored
+}
+
+// DeleteAnswer removes an answer
+func (m *Memory) DeleteAnswer(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete answer without documentID")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.answers, documentID)
+	return nil
+}
+
+// EditAnswer Modifies an answer matching the given documentID
+func (m *Memory) EditAnswer(documentID string, answer globals.Answer) error {
+	if documentID == "" {
+		return errors.New("cannot edit answer without documentID")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err := m.checkAnswer(answer); err != nil {
+		return err
+	}
+	m.putAnswer(documentID, answer)
+	return nil
+}
+
+// selectAnswers returns a copy of the answers matching the filter
+func (m *Memory) selectAnswers(match func(globals.Answer) bool) []globals.Answer {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var answers []globals.Answer
+	for id, stored := range m.answers {
+		if !match(stored) {
+			continue
+		}
+		var answer globals.Answer
+		clone(stored, &answer)
+		answer.ID = id
+		answers = append(answers, answer)
+	}
+	sort.Slice(answers, func(i, j int) bool { return answers[i].ID < answers[j].ID })
+	return answers
+}
+
+// GetAnswers returns all of the answers of the channel
+func (m *Memory) GetAnswers(channel string) ([]globals.Answer, error) {
+	return m.selectAnswers(func(answer globals.Answer) bool { return inChannel(answer.Channel, channel) }), nil
+}
+
+// QueryAnswers returns all answers of the channel matching at least one tool or one label from given parameters.
+// Answers without tool, or without label, are the ones matching when no tool, or no label, is given
+func (m *Memory) QueryAnswers(channel string, tools []string, labels []string) ([]globals.Answer, error) {
+	matches := func(values []string, value string) bool {
+		if len(values) > 0 {
+			return contains(values, value)
+		}
+		return value == ""
+	}
+	return m.selectAnswers(func(answer globals.Answer) bool {
+		return matches(tools, answer.Tool) && matches(labels, answer.Label) && inChannel(answer.Channel, channel)
+	}), nil
+}
+
+// QueryAnswersByLabel returns all answers matching the given label
+func (m *Memory) QueryAnswersByLabel(label string) ([]globals.Answer, error) {
+	return m.selectAnswers(func(answer globals.Answer) bool { return answer.Label == label }), nil
+}
+
+// QueryAnswersByTool returns all answers matching at the given tool
+func (m *Memory) QueryAnswersByTool(tool string) ([]globals.Answer, error) {
+	return m.selectAnswers(func(answer globals.Answer) bool { return answer.Tool == tool }), nil
+}
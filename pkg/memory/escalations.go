@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+)
+
+// AddEscalationPolicy stores the escalation policy
+func (m *Memory) AddEscalationPolicy(policy globals.EscalationPolicy) error {
+	if len(policy.Steps) == 0 {
+		return errors.New("cannot create escalation policy without steps")
+	}
+	id := policy.ID
+	if id == "" {
+		id = storage.NewID()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putEscalationPolicy(id, policy)
+	return nil
+}
+
+// EditEscalationPolicy Modifies the escalation policy matching the given documentID
+func (m *Memory) EditEscalationPolicy(documentID string, policy globals.EscalationPolicy) error {
+	if documentID == "" {
+		return errors.New("cannot edit escalation policy without documentID")
+	}
+	if len(policy.Steps) == 0 {
+		return errors.New("cannot edit escalation policy without steps")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putEscalationPolicy(documentID, policy)
+	return nil
+}
+
+func (m *Memory) putEscalationPolicy(documentID string, policy globals.EscalationPolicy) {
+	var stored globals.EscalationPolicy
+	clone(policy, &stored)
+	m.escalations[documentID] = stored
+}
+
+// DeleteEscalationPolicy removes the escalation policy
+func (m *Memory) DeleteEscalationPolicy(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.escalations, documentID)
+	return nil
+}
+
+// GetEscalationPolicies returns the escalation policies of the channel and the ones shared by every channel
+func (m *Memory) GetEscalationPolicies(channel string) ([]globals.EscalationPolicy, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var policies []globals.EscalationPolicy
+	for id, stored := range m.escalations {
+		if !inChannel(stored.Channel, channel) {
+			continue
+		}
+		var policy globals.EscalationPolicy
+		clone(stored, &policy)
+		policy.ID = id
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].ID < policies[j].ID })
+	return policies, nil
+}
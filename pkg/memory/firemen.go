@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// AddFireman stores the firemen info and period
+func (m *Memory) AddFireman(message globals.Message) error {
+	var stored globals.Message
+	clone(message, &stored)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.firemen = append(m.firemen, stored)
+	return nil
+}
+
+// QueryRangeFireman returns the fireman of the chan for the range
+func (m *Memory) QueryRangeFireman(channel string, start string, end string) ([]globals.Message, error) {
+	startTs, err := time.Parse(globals.DateLayout, start)
+	if err != nil {
+		return nil, err
+	}
+	endTs, err := time.Parse(globals.DateLayout, end)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var messages []globals.Message
+	for _, stored := range m.firemen {
+		ts := globals.ParseDuration(stored.Timestamp)
+		if ts < float64(startTs.Unix()) || ts > float64(endTs.Unix()) || (channel != "" && stored.Channel != channel) {
+			continue
+		}
+		var message globals.Message
+		clone(stored, &message)
+		messages = append(messages, message)
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return globals.ParseDuration(messages[i].Timestamp) < globals.ParseDuration(messages[j].Timestamp)
+	})
+	return messages, nil
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// AddLabel stores the label
+func (m *Memory) AddLabel(label globals.Perco) error {
+	return m.addPerco(labelsKind, label)
+}
+
+// DeleteLabel removes the label, unless an answer uses it
+func (m *Memory) DeleteLabel(documentID string) error {
+	return m.deletePerco(labelsKind, documentID)
+}
+
+// EditLabel Modifies a label matching the given documentID
+func (m *Memory) EditLabel(documentID string, label globals.Perco) error {
+	return m.editPerco(labelsKind, documentID, label)
+}
+
+// GetLabels returns the list of all the labels of the channel
+func (m *Memory) GetLabels(channel string) ([]globals.Perco, error) {
+	return m.getPercos(labelsKind, channel), nil
+}
+
+// QueryLabels returns all the labels of the channel whose regexp matches a word of the text
+func (m *Memory) QueryLabels(channel string, text string) ([]string, error) {
+	return m.queryPercos(labelsKind, channel, text), nil
+}
+
+// QueryLabelByID returns the label that matches the id
+func (m *Memory) QueryLabelByID(id string) (globals.Perco, error) {
+	return m.percoByID(labelsKind, id)
+}
+
+// QueryLabelByName returns the label that match the given Name
+func (m *Memory) QueryLabelByName(name string) ([]globals.Perco, error) {
+	return m.percosByName(labelsKind, name), nil
+}
@@ -0,0 +1,73 @@
+// Package memory keeps the documents of the service in memory, for the tests and the local runs without elasticsearch.
+// The documents are lost when the service stops
+package memory
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	log "github.com/sirupsen/logrus"
+)
+
+// Memory is a storage holding the documents in maps, safe for concurrent use
+type Memory struct {
+	mutex       sync.RWMutex
+	messages    map[string]globals.Message
+	firemen     []globals.Message
+	labels      map[string]globals.Perco
+	tools       map[string]globals.Perco
+	answers     map[string]globals.Answer
+	team        map[string]globals.TeamMember
+	workflow    map[string]globals.StatusRule
+	rotations   map[string]globals.Rotation
+	escalations map[string]globals.EscalationPolicy
+	templates   map[string]globals.MessageTemplate
+	welcome     map[string]globals.WelcomeMessage
+	acks        map[string]globals.WelcomeAcknowledgement
+}
+
+// New returns an empty storage
+func New() *Memory {
+	return &Memory{
+		messages:    make(map[string]globals.Message),
+		labels:      make(map[string]globals.Perco),
+		tools:       make(map[string]globals.Perco),
+		answers:     make(map[string]globals.Answer),
+		team:        make(map[string]globals.TeamMember),
+		workflow:    make(map[string]globals.StatusRule),
+		rotations:   make(map[string]globals.Rotation),
+		escalations: make(map[string]globals.EscalationPolicy),
+		templates:   make(map[string]globals.MessageTemplate),
+		welcome:     make(map[string]globals.WelcomeMessage),
+		acks:        make(map[string]globals.WelcomeAcknowledgement),
+	}
+}
+
+// clone deep copies a document through its JSON form, as the storages serializing it do,
+// so that the callers never share the slices of the stored documents
+func clone(source interface{}, destination interface{}) {
+	b, err := json.Marshal(source)
+	if err != nil {
+		log.Errorf("unable to serialize document : %s", err)
+		return
+	}
+	if err := json.Unmarshal(b, destination); err != nil {
+		log.Errorf("unable to deserialize document : %s", err)
+	}
+}
+
+// inChannel tells whether a document of the channel is shared with the given channel.
+// The documents without channel are shared by every channel, an empty channel does not filter anything
+func inChannel(documentChannel string, channel string) bool {
+	return channel == "" || documentChannel == "" || documentChannel == channel
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package memory_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/pkg/storage/storagetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContract runs the storage contract against an empty storage for each case
+func TestContract(t *testing.T) {
+	storagetest.Suite{
+		Open: func(t *testing.T) elastic.Interface {
+			return memory.New()
+		},
+	}.Run(t)
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	m := memory.New()
+	require.NoError(t, m.AddLabel(globals.Perco{Name: "rights", Query: globals.Query{Regexp: globals.Regexp{Input: "rights?"}}}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ts := fmt.Sprintf("1600000%03d.000100", i)
+			assert.NoError(t, m.AddMessage(globals.Message{Type: globals.NewMessage, Channel: "C1", Timestamp: ts}))
+			_, err := m.QueryLabels("C1", "please give me the rights")
+			assert.NoError(t, err)
+			_, err = m.QueryRangeMessages("C1", "", "")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	messages, err := m.QueryRangeMessages("C1", "", "")
+	require.NoError(t, err)
+	assert.Len(t, messages, 50)
+}
+
+func TestDocumentsAreCopied(t *testing.T) {
+	m := memory.New()
+	message := globals.Message{Type: globals.NewMessage, Channel: "C1", Timestamp: "1600000000.000100", Labels: []string{"rights"}}
+	require.NoError(t, m.AddMessage(message))
+	message.Labels[0] = "changed"
+
+	stored, err := m.GetMessage("C1", "1600000000.000100")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rights"}, stored.Labels)
+	stored.Labels[0] = "changed"
+
+	stored, err = m.GetMessage("C1", "1600000000.000100")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rights"}, stored.Labels)
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// SetMessageTemplate stores the template of the message, replacing the one of the same locale and key
+func (m *Memory) SetMessageTemplate(template globals.MessageTemplate) error {
+	if template.Locale == "" || template.Key == "" {
+		return errors.New("cannot store message template without locale and key")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.templates[template.DocumentID()] = template
+	return nil
+}
+
+// DeleteMessageTemplate removes the template of the message in the locale
+func (m *Memory) DeleteMessageTemplate(locale string, key string) error {
+	if locale == "" || key == "" {
+		return errors.New("cannot delete message template without locale and key")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.templates, globals.MessageTemplate{Locale: locale, Key: key}.DocumentID())
+	return nil
+}
+
+// GetMessageTemplates returns the templates of the messages edited through the api, in every locale
+func (m *Memory) GetMessageTemplates() ([]globals.MessageTemplate, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var templates []globals.MessageTemplate
+	for _, template := range m.templates {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].DocumentID() < templates[j].DocumentID() })
+	return templates, nil
+}
@@ -0,0 +1,322 @@
+package memory
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+)
+
+// AddMessage stores a message.
+// The document ID is computed from the channel and the timestamp when not given
+func (m *Memory) AddMessage(message globals.Message, id ...string) error {
+	var documentID string
+	if len(id) > 0 {
+		documentID = id[0]
+	}
+	if documentID == "" {
+		documentID = globals.MessageID(message.Channel, message.Timestamp)
+	}
+	if documentID == "" {
+		documentID = storage.NewID()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putMessage(documentID, message)
+	return nil
+}
+
+// EditMessage replaces the message posted at the timestamp in the channel
+func (m *Memory) EditMessage(channel string, messageTs string, message globals.Message) error {
+	documentID := globals.MessageID(channel, messageTs)
+	if documentID == "" {
+		return errors.New("cannot edit message without timestamp")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putMessage(documentID, message)
+	return nil
+}
+
+func (m *Memory) putMessage(documentID string, message globals.Message) {
+	var stored globals.Message
+	clone(message, &stored)
+	m.messages[documentID] = stored
+}
+
+// DeleteMessage removes the message posted at the timestamp in the channel
+func (m *Memory) DeleteMessage(channel string, messageTs string) error {
+	documentID := globals.MessageID(channel, messageTs)
+	if documentID == "" {
+		return errors.New("cannot delete message without timestamp")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.messages, documentID)
+	return nil
+}
+
+// selectMessages returns a copy of the messages matching the filter, oldest first
+func (m *Memory) selectMessages(match func(globals.Message) bool) []globals.Message {
+	var messages []globals.Message
+	for id, stored := range m.messages {
+		if !match(stored) {
+			continue
+		}
+		var message globals.Message
+		clone(stored, &message)
+		message.ID = id
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return position(messages[i], 0).Compare(position(messages[j], 0)) < 0
+	})
+	return messages
+}
+
+// position returns the position of the message in a page sorted by the value
+func position(message globals.Message, value float64) storage.Position {
+	return storage.Position{
+		Sort:    value,
+		Ts:      globals.ParseDuration(message.Timestamp),
+		Channel: message.Channel,
+		ID:      message.ID,
+	}
+}
+
+// QueryLastUserMessages returns the last messages of the given user in the channel in the last 2 minutes
+func (m *Memory) QueryLastUserMessages(channel string, userID string) ([]globals.Message, error) {
+	end := time.Now()
+	start := end.Add(-2 * time.Minute)
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.selectMessages(func(message globals.Message) bool {
+		ts := globals.ParseDuration(message.Timestamp)
+		return message.UserID == userID &&
+			ts >= float64(start.Unix()) && ts <= float64(end.Unix()) &&
+			inChannel(message.Channel, channel)
+	}), nil
+}
+
+// QueryReminderMessages returns the user messages whose reminder is due, open according to the workflow of their channel
+func (m *Memory) QueryReminderMessages() ([]globals.Message, error) {
+	end := time.Now()
+	start := end.Add(-1 * time.Minute)
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	workflow := m.getWorkflow("")
+	return m.selectMessages(func(message globals.Message) bool {
+		remindAt, err := strconv.ParseFloat(message.RemindAt, 64)
+		closing := append(workflow.ForChannel(message.Channel).ClosingStatuses(), globals.StatusDeleted)
+		return err == nil && message.Type == globals.NewMessage && !storage.Closed(message, closing) &&
+			remindAt >= float64(start.Unix()) && remindAt <= float64(end.Unix())
+	}), nil
+}
+
+// userMessages matches the user messages of the channel in a timestamp range.
+// The bounds are epochs in seconds or dates, the empty ones do not restrict anything
+func userMessages(channel string, start string, end string) (func(globals.Message) bool, error) {
+	from, to := math.Inf(-1), math.Inf(1)
+	var err error
+	if start != "" {
+		if from, err = storage.ParseBound(start, false); err != nil {
+			return nil, err
+		}
+	}
+	if end != "" {
+		if to, err = storage.ParseBound(end, true); err != nil {
+			return nil, err
+		}
+	}
+	return func(message globals.Message) bool {
+		ts := globals.ParseDuration(message.Timestamp)
+		return message.Type == globals.NewMessage && ts >= from && ts <= to &&
+			inChannel(message.Channel, channel)
+	}, nil
+}
+
+// QueryRangeMessages returns a list of messages of the channel in a timestamp range
+func (m *Memory) QueryRangeMessages(channel string, start string, end string) ([]globals.Message, error) {
+	match, err := userMessages(channel, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.selectMessages(match), nil
+}
+
+// GetMessage returns the user message of the channel posted at the timestamp, with its replies and reactions
+func (m *Memory) GetMessage(channel string, messageTs string) (globals.Message, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	messages := m.selectMessages(func(message globals.Message) bool {
+		return message.Type == globals.NewMessage && message.Timestamp == messageTs &&
+			inChannel(message.Channel, channel)
+	})
+	if len(messages) == 0 {
+		return globals.Message{}, elastic.ErrMessageNotFound
+	}
+	return messages[0], nil
+}
+
+// messagesSortValues are the values sorting the messages for each of globals.MessageSorts but relevance
+var messagesSortValues = map[string]func(globals.Message) float64{
+	"ts":              func(message globals.Message) float64 { return globals.ParseDuration(message.Timestamp) },
+	"response_time":   func(message globals.Message) float64 { return float64(message.ResponseTime) },
+	"resolution_time": func(message globals.Message) float64 { return float64(message.ResolutionTime) },
+}
+
+// matchPage tells whether the message matches the filters of the query
+func matchPage(message globals.Message, q globals.MessageQuery) bool {
+	var repliers []string
+	for _, reply := range message.Replies {
+		repliers = append(repliers, reply.UserID)
+	}
+	filters := []struct {
+		value string
+		match bool
+	}{
+		{string(q.Status), message.Status == q.Status},
+		{q.Label, contains(message.Labels, q.Label)},
+		{q.Tool, contains(message.Tools, q.Tool)},
+		{q.User, message.UserID == q.User},
+		{q.Fireman, contains(repliers, q.Fireman)},
+	}
+	for _, filter := range filters {
+		if filter.value != "" && !filter.match {
+			return false
+		}
+	}
+	if q.HasFeedback != nil {
+		answered := message.FeedbackStatus == globals.UsefulFeedback || message.FeedbackStatus == globals.UselessFeedback
+		if answered != *q.HasFeedback {
+			return false
+		}
+	}
+	return true
+}
+
+// relevance counts the words of the message and of its replies found in the search, any of them shall be found
+func relevance(message globals.Message, search []string) float64 {
+	terms := storage.Terms(message.Text)
+	for _, reply := range message.Replies {
+		terms = append(terms, storage.Terms(reply.Text)...)
+	}
+	terms = append(terms, storage.Terms(message.ResolutionNote)...)
+	var found float64
+	for _, term := range terms {
+		if contains(search, term) {
+			found++
+		}
+	}
+	return found
+}
+
+// QueryMessagesPage returns a page of the user messages matching the query, most recent first unless sorted otherwise
+func (m *Memory) QueryMessagesPage(q globals.MessageQuery) (globals.MessagePage, error) {
+	page := globals.MessagePage{Messages: []globals.Message{}}
+
+	var after *storage.Position
+	if q.Cursor != "" {
+		after = &storage.Position{}
+		if err := storage.DecodeCursor(q.Cursor, after); err != nil {
+			return page, err
+		}
+	}
+	match, err := userMessages(q.Channel, q.Start, q.End)
+	if err != nil {
+		return page, err
+	}
+	search := storage.Terms(q.Search)
+	value, ok := messagesSortValues[q.Sort]
+	if !ok {
+		value = messagesSortValues["ts"]
+	}
+
+	m.mutex.RLock()
+	messages := m.selectMessages(func(message globals.Message) bool {
+		return match(message) && matchPage(message, q) && (q.Search == "" || relevance(message, search) > 0)
+	})
+	m.mutex.RUnlock()
+
+	positions := make(map[string]storage.Position)
+	for _, message := range messages {
+		if q.Sort == "relevance" {
+			positions[message.ID] = position(message, relevance(message, search))
+		} else {
+			positions[message.ID] = position(message, value(message))
+		}
+	}
+	// ascending order, reversed unless asked for
+	before := func(a storage.Position, b storage.Position) bool {
+		if q.Ascending {
+			return a.Compare(b) < 0
+		}
+		return a.Compare(b) > 0
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return before(positions[messages[i].ID], positions[messages[j].ID])
+	})
+
+	for _, message := range messages {
+		if len(page.Messages) == q.Size {
+			break
+		}
+		if after != nil && !before(*after, positions[message.ID]) {
+			continue
+		}
+		page.Messages = append(page.Messages, message)
+	}
+	if q.Size > 0 && len(page.Messages) == q.Size {
+		page.NextCursor, err = storage.EncodeCursor(positions[page.Messages[q.Size-1].ID])
+	}
+	return page, err
+}
+
+// DefaultSimilarScore is the number of words in common with the similar messages at least, when none is given
+const DefaultSimilarScore = 2
+
+// QuerySimilarMessages returns the closed user messages of the channel most similar to the text, most similar first.
+// The text is compared to the messages, their replies and their resolution notes, the score is the number of words
+// in common, stop words left out, and shall be at least minScore, DefaultSimilarScore when 0
+func (m *Memory) QuerySimilarMessages(channel string, text string, size int, minScore float64) ([]globals.SimilarMessage, error) {
+	search := storage.Keywords(text)
+	if minScore == 0 {
+		minScore = DefaultSimilarScore
+	}
+
+	m.mutex.RLock()
+	closing := m.getWorkflow(channel).ClosingStatuses()
+	matching := m.selectMessages(func(message globals.Message) bool {
+		return message.Type == globals.NewMessage && storage.Closed(message, closing) &&
+			inChannel(message.Channel, channel) && relevance(message, search) >= minScore
+	})
+	m.mutex.RUnlock()
+
+	var messages []globals.SimilarMessage
+	for _, message := range matching {
+		messages = append(messages, globals.SimilarMessage{Message: message, Score: relevance(message, search)})
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].Score != messages[j].Score {
+			return messages[i].Score > messages[j].Score
+		}
+		return globals.ParseDuration(messages[i].Timestamp) > globals.ParseDuration(messages[j].Timestamp)
+	})
+	if len(messages) > size {
+		messages = messages[:size]
+	}
+	return messages, nil
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+)
+
+// percoKind is the kind of the labels or of the tools, the answers reference them by their name
+type percoKind struct {
+	name  string
+	store func(m *Memory) map[string]globals.Perco
+	used  func(answer globals.Answer) string
+}
+
+var (
+	labelsKind = percoKind{
+		name:  "label",
+		store: func(m *Memory) map[string]globals.Perco { return m.labels },
+		used:  func(answer globals.Answer) string { return answer.Label },
+	}
+	toolsKind = percoKind{
+		name:  "tool",
+		store: func(m *Memory) map[string]globals.Perco { return m.tools },
+		used:  func(answer globals.Answer) string { return answer.Tool },
+	}
+)
+
+// addPerco stores the label or the tool, its ID is generated when not given
+func (m *Memory) addPerco(kind percoKind, perco globals.Perco) error {
+	if perco.Name == "" || perco.Query.Regexp.Input == "" {
+		return fmt.Errorf("cannot create empty %s", kind.name)
+	}
+	id := perco.ID
+	if id == "" {
+		id = storage.NewID()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putPerco(kind, id, perco)
+	return nil
+}
+
+// editPerco modifies the label or the tool of the document ID
+func (m *Memory) editPerco(kind percoKind, documentID string, perco globals.Perco) error {
+	if documentID == "" {
+		return fmt.Errorf("cannot edit %s without documentID", kind.name)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putPerco(kind, documentID, perco)
+	return nil
+}
+
+func (m *Memory) putPerco(kind percoKind, documentID string, perco globals.Perco) {
+	var stored globals.Perco
+	clone(perco, &stored)
+	kind.store(m)[documentID] = stored
+}
+
+// deletePerco removes the label or the tool of the document ID, unless an answer uses it
+func (m *Memory) deletePerco(kind percoKind, documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	perco, ok := kind.store(m)[documentID]
+	if !ok {
+		return fmt.Errorf("%s %s not found", kind.name, documentID)
+	}
+	for _, answer := range m.answers {
+		if kind.used(answer) == perco.Name {
+			return fmt.Errorf("cannot delete %s : already in use", kind.name)
+		}
+	}
+	delete(kind.store(m), documentID)
+	return nil
+}
+
+// selectPercos returns a copy of the labels or the tools matching the filter
+func (m *Memory) selectPercos(kind percoKind, match func(globals.Perco) bool) []globals.Perco {
+	var percos []globals.Perco
+	for id, stored := range kind.store(m) {
+		if !match(stored) {
+			continue
+		}
+		var perco globals.Perco
+		clone(stored, &perco)
+		perco.ID = id
+		percos = append(percos, perco)
+	}
+	sort.Slice(percos, func(i, j int) bool { return percos[i].ID < percos[j].ID })
+	return percos
+}
+
+// getPercos returns the labels or the tools of the channel
+func (m *Memory) getPercos(kind percoKind, channel string) []globals.Perco {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.selectPercos(kind, func(perco globals.Perco) bool { return inChannel(perco.Channel, channel) })
+}
+
+// queryPercos returns the names of the labels or the tools of the channel whose regexp matches the text
+func (m *Memory) queryPercos(kind percoKind, channel string, text string) []string {
+	return storage.MatchPercos(m.getPercos(kind, channel), text)
+}
+
+// percosByName returns the labels or the tools of the given name
+func (m *Memory) percosByName(kind percoKind, name string) []globals.Perco {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.selectPercos(kind, func(perco globals.Perco) bool { return perco.Name == name })
+}
+
+// percoByID returns the label or the tool of the document ID
+func (m *Memory) percoByID(kind percoKind, documentID string) (globals.Perco, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	stored, ok := kind.store(m)[documentID]
+	if !ok {
+		return globals.Perco{}, fmt.Errorf("%s %s not found", kind.name, documentID)
+	}
+	var perco globals.Perco
+	clone(stored, &perco)
+	perco.ID = documentID
+	return perco, nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+)
+
+// AddRotation stores the rotation
+func (m *Memory) AddRotation(rotation globals.Rotation) error {
+	if len(rotation.Roster) == 0 {
+		return errors.New("cannot create rotation without roster")
+	}
+	id := rotation.ID
+	if id == "" {
+		id = storage.NewID()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putRotation(id, rotation)
+	return nil
+}
+
+// EditRotation Modifies the rotation matching the given documentID
+func (m *Memory) EditRotation(documentID string, rotation globals.Rotation) error {
+	if documentID == "" {
+		return errors.New("cannot edit rotation without documentID")
+	}
+	if len(rotation.Roster) == 0 {
+		return errors.New("cannot edit rotation without roster")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putRotation(documentID, rotation)
+	return nil
+}
+
+func (m *Memory) putRotation(documentID string, rotation globals.Rotation) {
+	var stored globals.Rotation
+	clone(rotation, &stored)
+	m.rotations[documentID] = stored
+}
+
+// DeleteRotation removes the rotation
+func (m *Memory) DeleteRotation(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.rotations, documentID)
+	return nil
+}
+
+// GetRotations returns the rotations of the channel and the ones shared by every channel
+func (m *Memory) GetRotations(channel string) ([]globals.Rotation, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var rotations []globals.Rotation
+	for id, stored := range m.rotations {
+		if !inChannel(stored.Channel, channel) {
+			continue
+		}
+		var rotation globals.Rotation
+		clone(stored, &rotation)
+		rotation.ID = id
+		rotations = append(rotations, rotation)
+	}
+	sort.Slice(rotations, func(i, j int) bool { return rotations[i].ID < rotations[j].ID })
+	return rotations, nil
+}
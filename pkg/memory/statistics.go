@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+)
+
+// AggregateMessages computes the number of user messages of each status and the distribution
+// of their response and resolution times, for the channel in a timestamp range.
+// Response times are measured on answered messages, resolution times on the ones with a closing status
+func (m *Memory) AggregateMessages(channel string, start string, end string, closing []globals.MessageStatus) (globals.Aggregations, error) {
+	messages, err := m.QueryRangeMessages(channel, start, end)
+	if err != nil {
+		return globals.Aggregations{}, err
+	}
+	return storage.Aggregate(messages, closing), nil
+}
+
+// AggregateAnswers counts the user messages of the channel each answer was sent to, and their useful and useless feedback
+func (m *Memory) AggregateAnswers(channel string) ([]globals.AnswerStatistics, error) {
+	m.mutex.RLock()
+	messages := m.selectMessages(func(message globals.Message) bool {
+		return message.Type == globals.NewMessage && len(message.Answers) > 0 && inChannel(message.Channel, channel)
+	})
+	m.mutex.RUnlock()
+	return storage.AggregateAnswers(messages), nil
+}
+
+// QueryTimeSeries buckets the user messages by date, and optionally by label, tool, status or user,
+// with the percentiles of their response and resolution times
+func (m *Memory) QueryTimeSeries(q globals.TimeSeriesQuery) (globals.TimeSeries, error) {
+	messages, err := m.QueryRangeMessages(q.Channel, q.Start, q.End)
+	if err != nil {
+		return globals.TimeSeries{Interval: q.Interval, GroupBy: q.GroupBy, Buckets: []globals.TimeBucket{}}, err
+	}
+	return storage.TimeSeries(messages, q)
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+)
+
+// AddTeamMember stores a team member
+func (m *Memory) AddTeamMember(user globals.TeamMember) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putTeamMember(storage.NewID(), user)
+	return nil
+}
+
+// DeleteTeamMember removes a team member
+func (m *Memory) DeleteTeamMember(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete without document ID")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.team, documentID)
+	return nil
+}
+
+// EditTeamMember modifies team member info
+func (m *Memory) EditTeamMember(documentID string, teamMember globals.TeamMember) error {
+	if documentID == "" {
+		return errors.New("cannot edit without document ID")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putTeamMember(documentID, teamMember)
+	return nil
+}
+
+func (m *Memory) putTeamMember(documentID string, teamMember globals.TeamMember) {
+	var stored globals.TeamMember
+	clone(teamMember, &stored)
+	m.team[documentID] = stored
+}
+
+// GetTeamMembers returns the team members of the given channel
+func (m *Memory) GetTeamMembers(channel string) ([]globals.TeamMember, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var users []globals.TeamMember
+	for id, stored := range m.team {
+		if !inChannel(stored.Channel, channel) {
+			continue
+		}
+		var user globals.TeamMember
+		clone(stored, &user)
+		user.ID = id
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// IsTeamMember looks for the userId in the team of the channel
+func (m *Memory) IsTeamMember(channel string, userID string) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for _, user := range m.team {
+		if user.SlackID == userID && inChannel(user.Channel, channel) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
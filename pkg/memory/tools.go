@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// AddTool stores the tool
+func (m *Memory) AddTool(tool globals.Perco) error {
+	return m.addPerco(toolsKind, tool)
+}
+
+// DeleteTool removes the tool, unless an answer uses it
+func (m *Memory) DeleteTool(documentID string) error {
+	return m.deletePerco(toolsKind, documentID)
+}
+
+// EditTool Modifies a tool matching the given documentID
+func (m *Memory) EditTool(documentID string, tool globals.Perco) error {
+	return m.editPerco(toolsKind, documentID, tool)
+}
+
+// GetTools returns the list of all the tools of the channel
+func (m *Memory) GetTools(channel string) ([]globals.Perco, error) {
+	return m.getPercos(toolsKind, channel), nil
+}
+
+// QueryTools returns all the tools of the channel whose regexp matches a word of the text
+func (m *Memory) QueryTools(channel string, text string) ([]string, error) {
+	return m.queryPercos(toolsKind, channel, text), nil
+}
+
+// QueryToolByID returns the tool that matches the id
+func (m *Memory) QueryToolByID(id string) (globals.Perco, error) {
+	return m.percoByID(toolsKind, id)
+}
+
+// QueryToolByName returns the tool that match the given Name
+func (m *Memory) QueryToolByName(name string) ([]globals.Perco, error) {
+	return m.percosByName(toolsKind, name), nil
+}
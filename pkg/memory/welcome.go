@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// SetWelcomeMessage stores the welcome message, replacing the one of the same channel
+func (m *Memory) SetWelcomeMessage(welcome globals.WelcomeMessage) error {
+	if welcome.Text == "" && len(welcome.Blocks) == 0 {
+		return errors.New("cannot store welcome message without text or blocks")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var stored globals.WelcomeMessage
+	clone(welcome, &stored)
+	m.welcome[welcome.DocumentID()] = stored
+	return nil
+}
+
+// DeleteWelcomeMessage removes the welcome message of the channel, the one shared by every channel when empty
+func (m *Memory) DeleteWelcomeMessage(channel string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.welcome, globals.WelcomeMessage{Channel: channel}.DocumentID())
+	return nil
+}
+
+// GetWelcomeMessages returns the welcome message of the channel and the one shared by every channel
+func (m *Memory) GetWelcomeMessages(channel string) ([]globals.WelcomeMessage, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var messages []globals.WelcomeMessage
+	for _, stored := range m.welcome {
+		if !inChannel(stored.Channel, channel) {
+			continue
+		}
+		var welcome globals.WelcomeMessage
+		clone(stored, &welcome)
+		messages = append(messages, welcome)
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].DocumentID() < messages[j].DocumentID() })
+	return messages, nil
+}
+
+// AddWelcomeAcknowledgement stores that the user read the rules of the channel
+func (m *Memory) AddWelcomeAcknowledgement(acknowledgement globals.WelcomeAcknowledgement) error {
+	if acknowledgement.Channel == "" || acknowledgement.UserID == "" {
+		return errors.New("cannot store welcome acknowledgement without channel or user")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.acks[acknowledgement.DocumentID()] = acknowledgement
+	return nil
+}
+
+// GetWelcomeAcknowledgements returns the users who read the rules of the channel, of every channel when empty
+func (m *Memory) GetWelcomeAcknowledgements(channel string) ([]globals.WelcomeAcknowledgement, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var acknowledgements []globals.WelcomeAcknowledgement
+	for _, acknowledgement := range m.acks {
+		if channel == "" || acknowledgement.Channel == channel {
+			acknowledgements = append(acknowledgements, acknowledgement)
+		}
+	}
+	sort.Slice(acknowledgements, func(i, j int) bool {
+		return acknowledgements[i].DocumentID() < acknowledgements[j].DocumentID()
+	})
+	return acknowledgements, nil
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+)
+
+// AddStatusRule stores the status rule
+func (m *Memory) AddStatusRule(rule globals.StatusRule) error {
+	if rule.Emoji == "" || rule.Status == "" {
+		return errors.New("cannot create status rule without emoji or status")
+	}
+	id := rule.ID
+	if id == "" {
+		id = storage.NewID()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putStatusRule(id, rule)
+	return nil
+}
+
+// EditStatusRule Modifies the status rule matching the given documentID
+func (m *Memory) EditStatusRule(documentID string, rule globals.StatusRule) error {
+	if documentID == "" {
+		return errors.New("cannot edit status rule without documentID")
+	}
+	if rule.Emoji == "" || rule.Status == "" {
+		return errors.New("cannot edit status rule without emoji or status")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.putStatusRule(documentID, rule)
+	return nil
+}
+
+func (m *Memory) putStatusRule(documentID string, rule globals.StatusRule) {
+	var stored globals.StatusRule
+	clone(rule, &stored)
+	m.workflow[documentID] = stored
+}
+
+// DeleteStatusRule removes the status rule
+func (m *Memory) DeleteStatusRule(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.workflow, documentID)
+	return nil
+}
+
+// GetWorkflow returns the status rules of the channel and the ones shared by every channel.
+// The default rules whose emoji is not used by them are added
+func (m *Memory) GetWorkflow(channel string) (globals.Workflow, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.getWorkflow(channel), nil
+}
+
+func (m *Memory) getWorkflow(channel string) globals.Workflow {
+	var workflow globals.Workflow
+	for id, stored := range m.workflow {
+		if !inChannel(stored.Channel, channel) {
+			continue
+		}
+		var rule globals.StatusRule
+		clone(stored, &rule)
+		rule.ID = id
+		workflow = append(workflow, rule)
+	}
+	sort.Slice(workflow, func(i, j int) bool { return workflow[i].ID < workflow[j].ID })
+	return workflow.WithDefaults()
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddAnswer Adds an answer matching the given tool and label
+func (pg Postgres) AddAnswer(answer globals.Answer) error {
+	if err := pg.checkAnswer(answer); err != nil {
+		return err
+	}
+	if err := pg.putAnswer(storage.NewID(), answer); err != nil {
+		return fmt.Errorf("error creating document : %s", err)
+	}
+	return nil
+}
+
+// checkAnswer verifies if the tool and label specified in the answer really exists and that the answer is not empty
+func (pg Postgres) checkAnswer(answer globals.Answer) error {
+	if answer.Answer == "" {
+		return errors.New("no answer provided")
+	}
+
+	if answer.Tool != "" {
+		tools, err := pg.QueryToolByName(answer.Tool)
+		if err != nil {
+			return err
+		}
+		if len(tools) != 1 {
+			return errors.New("specified tool does not exist")
+		}
+	}
+
+	if answer.Label != "" {
+		labels, err := pg.QueryLabelByName(answer.Label)
+		if err != nil {
+			return err
+		}
+		if len(labels) != 1 {
+			return errors.New("specified label does not exist")
+		}
+	}
+	return nil
+}
+
+func (pg Postgres) putAnswer(documentID string, answer globals.Answer) error {
+	b, err := json.Marshal(answer)
+	if err != nil {
+		return fmt.Errorf("cannot marshall answer document : %s", err)
+	}
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO answers (id, channel, tool, label, doc) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET channel = EXCLUDED.channel, tool = EXCLUDED.tool, label = EXCLUDED.label, doc = EXCLUDED.doc`,
+		documentID, nullable(answer.Channel), nullable(answer.Tool), nullable(answer.Label), string(b))
+	return err
+}
+
+// DeleteAnswer removes an answer from the answers table
+func (pg Postgres) DeleteAnswer(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete answer without documentID")
+	}
+	_, err := pg.DB.ExecContext(pg.Context, `DELETE FROM answers WHERE id = $1`, documentID)
+	return err
+}
+
+// EditAnswer Modifies an answer matching the given documentID
+func (pg Postgres) EditAnswer(documentID string, answer globals.Answer) error {
+	if documentID == "" {
+		return errors.New("cannot edit answer without documentID")
+	}
+
+	if err := pg.checkAnswer(answer); err != nil {
+		return err
+	}
+
+	if err := pg.putAnswer(documentID, answer); err != nil {
+		return fmt.Errorf("error indexing document ID=%s : %s", documentID, err)
+	}
+	return nil
+}
+
+// selectAnswers returns the answers matching the conditions
+func (pg Postgres) selectAnswers(c conditions) ([]globals.Answer, error) {
+	var answers []globals.Answer
+	err := pg.selectDocuments("answers", c, "id", func(id string, doc []byte) error {
+		var a globals.Answer
+		if err := json.Unmarshal(doc, &a); err != nil {
+			log.Errorf("Unable to deserialize document into answer : %s", err)
+		}
+		a.ID = id
+		answers = append(answers, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found a total of %d answers\n", len(answers))
+
+	return answers, nil
+}
+
+// GetAnswers returns all of the answers of the channel
+func (pg Postgres) GetAnswers(channel string) ([]globals.Answer, error) {
+	var c conditions
+	c.channel(channel)
+	return pg.selectAnswers(c)
+}
+
+// QueryAnswers returns all answers of the channel matching at least one tool or one label from given parameters.
+// Answers without tool, or without label, are the ones matching when no tool, or no label, is given
+func (pg Postgres) QueryAnswers(channel string, tools []string, labels []string) ([]globals.Answer, error) {
+	var c conditions
+	if len(tools) > 0 {
+		c.add("tool = ANY(?)", pq.Array(tools))
+	} else {
+		c.add("tool IS NULL")
+	}
+	if len(labels) > 0 {
+		c.add("label = ANY(?)", pq.Array(labels))
+	} else {
+		c.add("label IS NULL")
+	}
+	c.channel(channel)
+	return pg.selectAnswers(c)
+}
+
+// QueryAnswersByLabel returns all answers matching the given label
+func (pg Postgres) QueryAnswersByLabel(label string) ([]globals.Answer, error) {
+	var c conditions
+	c.add("label = ?", label)
+	return pg.selectAnswers(c)
+}
+
+// QueryAnswersByTool returns all answers matching at the given tool
+func (pg Postgres) QueryAnswersByTool(tool string) ([]globals.Answer, error) {
+	var c conditions
+	c.add("tool = ?", tool)
+	return pg.selectAnswers(c)
+}
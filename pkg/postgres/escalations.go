@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddEscalationPolicy stores the escalation policy in the escalations table
+func (pg Postgres) AddEscalationPolicy(policy globals.EscalationPolicy) error {
+	if len(policy.Steps) == 0 {
+		return errors.New("cannot create escalation policy without steps")
+	}
+	id := policy.ID
+	if id == "" {
+		id = storage.NewID()
+	}
+	if err := pg.putEscalationPolicy(id, policy); err != nil {
+		return fmt.Errorf("error creating document : %s", err)
+	}
+	return nil
+}
+
+// EditEscalationPolicy Modifies the escalation policy matching the given documentID
+func (pg Postgres) EditEscalationPolicy(documentID string, policy globals.EscalationPolicy) error {
+	if documentID == "" {
+		return errors.New("cannot edit escalation policy without documentID")
+	}
+	if len(policy.Steps) == 0 {
+		return errors.New("cannot edit escalation policy without steps")
+	}
+	return pg.putEscalationPolicy(documentID, policy)
+}
+
+func (pg Postgres) putEscalationPolicy(documentID string, policy globals.EscalationPolicy) error {
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO escalations (id, channel, doc) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET channel = EXCLUDED.channel, doc = EXCLUDED.doc`,
+		documentID, nullable(policy.Channel), string(b))
+	return err
+}
+
+// DeleteEscalationPolicy removes the escalation policy from the escalations table
+func (pg Postgres) DeleteEscalationPolicy(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+	_, err := pg.DB.ExecContext(pg.Context, `DELETE FROM escalations WHERE id = $1`, documentID)
+	return err
+}
+
+// GetEscalationPolicies returns the escalation policies of the channel and the ones shared by every channel
+func (pg Postgres) GetEscalationPolicies(channel string) ([]globals.EscalationPolicy, error) {
+	var c conditions
+	c.channel(channel)
+
+	var policies []globals.EscalationPolicy
+	err := pg.selectDocuments("escalations", c, "id", func(id string, doc []byte) error {
+		var policy globals.EscalationPolicy
+		if err := json.Unmarshal(doc, &policy); err != nil {
+			log.Errorf("Unable to deserialize document into escalation policy : %s", err)
+			return nil
+		}
+		policy.ID = id
+		policies = append(policies, policy)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
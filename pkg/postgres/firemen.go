@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddFireman stores the firemen info and period in the firemen table
+func (pg Postgres) AddFireman(message globals.Message) error {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO firemen (channel, ts_epoch, doc) VALUES ($1, $2, $3)`,
+		message.Channel, globals.ParseDuration(message.Timestamp), string(b))
+	return err
+}
+
+// QueryRangeFireman returns the fireman of the chan for the range
+func (pg Postgres) QueryRangeFireman(channel string, start string, end string) ([]globals.Message, error) {
+	startTs, err := time.Parse(globals.DateLayout, start)
+	if err != nil {
+		return nil, err
+	}
+	endTs, err := time.Parse(globals.DateLayout, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var c conditions
+	c.add("ts_epoch BETWEEN ? AND ?", startTs.Unix(), endTs.Unix())
+	c.channel(channel)
+
+	var messages []globals.Message
+	err = pg.selectDocuments("firemen", c, "ts_epoch, id", func(id string, doc []byte) error {
+		var m globals.Message
+		if err := json.Unmarshal(doc, &m); err != nil {
+			log.Errorf("unable to deserialize document into message : %s", err)
+		}
+		messages = append(messages, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found a total of %d firemen\n", len(messages))
+
+	return messages, nil
+}
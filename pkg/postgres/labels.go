@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// AddLabel stores the label in the labels table
+func (pg Postgres) AddLabel(label globals.Perco) error {
+	return pg.addPerco(labelsTable, label)
+}
+
+// DeleteLabel removes the label from the labels table, unless an answer uses it
+func (pg Postgres) DeleteLabel(documentID string) error {
+	return pg.deletePerco(labelsTable, documentID)
+}
+
+// EditLabel Modifies a label matching the given documentID
+func (pg Postgres) EditLabel(documentID string, label globals.Perco) error {
+	return pg.editPerco(labelsTable, documentID, label)
+}
+
+// GetLabels returns the list of all the labels of the channel
+func (pg Postgres) GetLabels(channel string) ([]globals.Perco, error) {
+	return pg.getPercos(labelsTable, channel)
+}
+
+// QueryLabels returns all the labels of the channel whose regexp matches a word of the text
+func (pg Postgres) QueryLabels(channel string, text string) ([]string, error) {
+	return pg.queryPercos(labelsTable, channel, text)
+}
+
+// QueryLabelByID returns the label that matches the id
+func (pg Postgres) QueryLabelByID(id string) (globals.Perco, error) {
+	return pg.percoByID(labelsTable, id)
+}
+
+// QueryLabelByName returns the label that match the given Name
+func (pg Postgres) QueryLabelByName(name string) ([]globals.Perco, error) {
+	return pg.percosByName(labelsTable, name)
+}
@@ -0,0 +1,36 @@
+// Package postgres stores the documents of the service in a PostgreSQL database instead of elasticsearch.
+// Each document is kept as JSON next to the columns it is filtered and sorted by
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq" // postgres driver
+	"github.com/spf13/viper"
+)
+
+// Postgres is a struct representing the postgres database
+type Postgres struct {
+	DB      *sql.DB
+	Context context.Context
+}
+
+// Configure connects to the database of postgres_url and migrates its schema
+func Configure() (instance Postgres, err error) {
+	ctx := context.Background()
+	db, err := sql.Open("postgres", viper.GetString("postgres_url"))
+	if err != nil {
+		return instance, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return instance, fmt.Errorf("unable to ping postgres : %s", err)
+	}
+
+	instance = Postgres{DB: db, Context: ctx}
+	if err := instance.Migrate(); err != nil {
+		return instance, fmt.Errorf("unable to migrate the postgres schema : %s", err)
+	}
+	return instance, nil
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	log "github.com/sirupsen/logrus"
+)
+
+// SetMessageTemplate stores the template of the message in the message_templates table,
+// replacing the one of the same locale and key
+func (pg Postgres) SetMessageTemplate(template globals.MessageTemplate) error {
+	if template.Locale == "" || template.Key == "" {
+		return errors.New("cannot store message template without locale and key")
+	}
+	b, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO message_templates (locale, key, doc) VALUES ($1, $2, $3)
+		ON CONFLICT (locale, key) DO UPDATE SET doc = EXCLUDED.doc`,
+		template.Locale, template.Key, string(b))
+	if err != nil {
+		return fmt.Errorf("error creating document : %s", err)
+	}
+	return nil
+}
+
+// DeleteMessageTemplate removes the template of the message in the locale from the message_templates table
+func (pg Postgres) DeleteMessageTemplate(locale string, key string) error {
+	if locale == "" || key == "" {
+		return errors.New("cannot delete message template without locale and key")
+	}
+	_, err := pg.DB.ExecContext(pg.Context, `DELETE FROM message_templates WHERE locale = $1 AND key = $2`, locale, key)
+	return err
+}
+
+// GetMessageTemplates returns the templates of the messages edited through the api, in every locale
+func (pg Postgres) GetMessageTemplates() ([]globals.MessageTemplate, error) {
+	rows, err := pg.DB.QueryContext(pg.Context, `SELECT doc FROM message_templates ORDER BY locale, key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []globals.MessageTemplate
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var template globals.MessageTemplate
+		if err := json.Unmarshal(doc, &template); err != nil {
+			log.Errorf("Unable to deserialize document into message template : %s", err)
+			continue
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
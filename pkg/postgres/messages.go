@@ -0,0 +1,366 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddMessage stores a message in the messages table.
+// The document ID is computed from the channel and the timestamp when not given
+func (pg Postgres) AddMessage(message globals.Message, id ...string) error {
+	var documentID string
+	if len(id) > 0 {
+		documentID = id[0]
+	}
+	if documentID == "" {
+		documentID = globals.MessageID(message.Channel, message.Timestamp)
+	}
+	if documentID == "" {
+		documentID = storage.NewID()
+	}
+	return pg.putMessage(documentID, message)
+}
+
+// EditMessage replaces the message posted at the timestamp in the channel in the messages table
+func (pg Postgres) EditMessage(channel string, messageTs string, message globals.Message) error {
+	documentID := globals.MessageID(channel, messageTs)
+	if documentID == "" {
+		return errors.New("cannot edit message without timestamp")
+	}
+	return pg.putMessage(documentID, message)
+}
+
+// putMessage creates or replaces the message of the document ID, with the columns it is queried by
+func (pg Postgres) putMessage(documentID string, message globals.Message) error {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	var remindAt sql.NullFloat64
+	if message.RemindAt != "" {
+		remindAt.Float64, err = strconv.ParseFloat(message.RemindAt, 64)
+		remindAt.Valid = err == nil
+	}
+	repliers := []string{}
+	texts := []string{message.Text}
+	for _, reply := range message.Replies {
+		repliers = append(repliers, reply.UserID)
+		texts = append(texts, reply.Text)
+	}
+	if message.ResolutionNote != "" {
+		texts = append(texts, message.ResolutionNote)
+	}
+
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO messages (id, type, channel, status, user_id, ts, ts_epoch, remind_at,
+			response_time, resolution_time, feedback_status, labels, tools, repliers, search, doc)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, to_tsvector('simple', $15), $16)
+		ON CONFLICT (id) DO UPDATE SET type = EXCLUDED.type, channel = EXCLUDED.channel, status = EXCLUDED.status,
+			user_id = EXCLUDED.user_id, ts = EXCLUDED.ts, ts_epoch = EXCLUDED.ts_epoch, remind_at = EXCLUDED.remind_at,
+			response_time = EXCLUDED.response_time, resolution_time = EXCLUDED.resolution_time,
+			feedback_status = EXCLUDED.feedback_status, labels = EXCLUDED.labels, tools = EXCLUDED.tools,
+			repliers = EXCLUDED.repliers, search = EXCLUDED.search, doc = EXCLUDED.doc`,
+		documentID, string(message.Type), message.Channel, string(message.Status), message.UserID,
+		message.Timestamp, globals.ParseDuration(message.Timestamp), remindAt,
+		int64(message.ResponseTime), int64(message.ResolutionTime), string(message.FeedbackStatus),
+		pq.Array(append([]string{}, message.Labels...)), pq.Array(append([]string{}, message.Tools...)), pq.Array(repliers),
+		strings.Join(texts, "\n"), string(b))
+	return err
+}
+
+// DeleteMessage removes the message posted at the timestamp in the channel
+func (pg Postgres) DeleteMessage(channel string, messageTs string) error {
+	documentID := globals.MessageID(channel, messageTs)
+	if documentID == "" {
+		return errors.New("cannot delete message without timestamp")
+	}
+	_, err := pg.DB.ExecContext(pg.Context, `DELETE FROM messages WHERE id = $1`, documentID)
+	return err
+}
+
+// selectMessages returns the messages matching the conditions, oldest first
+func (pg Postgres) selectMessages(c conditions) ([]globals.Message, error) {
+	var messages []globals.Message
+	err := pg.selectDocuments("messages", c, "ts_epoch, channel, id", func(id string, doc []byte) error {
+		var m globals.Message
+		if err := json.Unmarshal(doc, &m); err != nil {
+			log.Errorf("unable to deserialize document into message : %s", err)
+		}
+		m.ID = id
+		messages = append(messages, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found a total of %d messages\n", len(messages))
+
+	return messages, nil
+}
+
+// QueryLastUserMessages returns the last messages of the given user in the channel in the last 2 minutes
+func (pg Postgres) QueryLastUserMessages(channel string, userID string) ([]globals.Message, error) {
+	end := time.Now()
+	start := end.Add(-2 * time.Minute)
+
+	var c conditions
+	c.add("user_id = ?", userID)
+	c.add("ts_epoch BETWEEN ? AND ?", start.Unix(), end.Unix())
+	c.channel(channel)
+	return pg.selectMessages(c)
+}
+
+// QueryReminderMessages returns the user messages whose reminder is due, open according to the workflow of their channel
+func (pg Postgres) QueryReminderMessages() ([]globals.Message, error) {
+	end := time.Now()
+	start := end.Add(-1 * time.Minute)
+
+	// the rules of every channel
+	workflow, err := pg.GetWorkflow("")
+	if err != nil {
+		return nil, err
+	}
+
+	var c conditions
+	c.add("type = ?", string(globals.NewMessage))
+	c.add("status <> ?", string(globals.StatusDeleted))
+	c.add("remind_at BETWEEN ? AND ?", start.Unix(), end.Unix())
+	candidates, err := pg.selectMessages(c)
+	if err != nil {
+		return nil, err
+	}
+	var messages []globals.Message
+	for _, message := range candidates {
+		if !workflow.ForChannel(message.Channel).IsClosing(message.Status) {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+// userMessages restricts the query to the user messages of the channel in a timestamp range.
+// The bounds are epochs in seconds or dates, the empty ones do not restrict anything
+func userMessages(channel string, start string, end string) (conditions, error) {
+	var c conditions
+	c.add("type = ?", string(globals.NewMessage))
+	for _, bound := range []struct {
+		value  string
+		clause string
+		upper  bool
+	}{{start, "ts_epoch >= ?", false}, {end, "ts_epoch <= ?", true}} {
+		if bound.value == "" {
+			continue
+		}
+		epoch, err := storage.ParseBound(bound.value, bound.upper)
+		if err != nil {
+			return c, err
+		}
+		c.add(bound.clause, epoch)
+	}
+	c.channel(channel)
+	return c, nil
+}
+
+// QueryRangeMessages returns a list of messages of the channel in a timestamp range
+func (pg Postgres) QueryRangeMessages(channel string, start string, end string) ([]globals.Message, error) {
+	c, err := userMessages(channel, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return pg.selectMessages(c)
+}
+
+// GetMessage returns the user message of the channel posted at the timestamp, with its replies and reactions
+func (pg Postgres) GetMessage(channel string, messageTs string) (globals.Message, error) {
+	var c conditions
+	c.add("type = ?", string(globals.NewMessage))
+	c.add("ts = ?", messageTs)
+	c.channel(channel)
+
+	messages, err := pg.selectMessages(c)
+	if err != nil {
+		return globals.Message{}, err
+	}
+	if len(messages) == 0 {
+		return globals.Message{}, elastic.ErrMessageNotFound
+	}
+	return messages[0], nil
+}
+
+// messagesSortColumns are the expressions sorting the messages for each of globals.MessageSorts but relevance
+var messagesSortColumns = map[string]string{
+	"ts":              "ts_epoch",
+	"response_time":   "response_time::double precision",
+	"resolution_time": "resolution_time::double precision",
+}
+
+// messagesPageConditions restricts the user messages to the filters of the query and searches its text
+// in the messages and their replies. It returns the expression of the relevance of the messages
+func messagesPageConditions(q globals.MessageQuery) (conditions, string, error) {
+	c, err := userMessages(q.Channel, q.Start, q.End)
+	if err != nil {
+		return c, "", err
+	}
+	filters := []struct{ clause, value string }{
+		{"status = ?", string(q.Status)},
+		{"? = ANY(labels)", q.Label},
+		{"? = ANY(tools)", q.Tool},
+		{"user_id = ?", q.User},
+		{"? = ANY(repliers)", q.Fireman},
+	}
+	for _, filter := range filters {
+		if filter.value != "" {
+			c.add(filter.clause, filter.value)
+		}
+	}
+	if q.HasFeedback != nil {
+		answered := pq.Array([]string{string(globals.UsefulFeedback), string(globals.UselessFeedback)})
+		if *q.HasFeedback {
+			c.add("feedback_status = ANY(?)", answered)
+		} else {
+			c.add("NOT (feedback_status = ANY(?))", answered)
+		}
+	}
+
+	relevance := "0::double precision"
+	if q.Search != "" {
+		// any of the words shall match, like the match query of elasticsearch
+		terms := storage.Terms(q.Search)
+		if len(terms) == 0 {
+			c.add("false")
+			return c, relevance, nil
+		}
+		search := c.arg(strings.Join(terms, " | "))
+		c.add(fmt.Sprintf("search @@ to_tsquery('simple', %s)", search))
+		relevance = fmt.Sprintf("round(ts_rank(search, to_tsquery('simple', %s))::numeric, 6)::double precision", search)
+	}
+	return c, relevance, nil
+}
+
+// QueryMessagesPage returns a page of the user messages matching the query, most recent first unless sorted otherwise
+func (pg Postgres) QueryMessagesPage(q globals.MessageQuery) (globals.MessagePage, error) {
+	page := globals.MessagePage{Messages: []globals.Message{}}
+
+	c, relevance, err := messagesPageConditions(q)
+	if err != nil {
+		return page, err
+	}
+	sort, ok := messagesSortColumns[q.Sort]
+	if q.Sort == "relevance" {
+		sort = relevance
+	} else if !ok {
+		sort = messagesSortColumns["ts"]
+	}
+	order, after := "DESC", "<"
+	if q.Ascending {
+		order, after = "ASC", ">"
+	}
+
+	if q.Cursor != "" {
+		var position storage.Position
+		if err := storage.DecodeCursor(q.Cursor, &position); err != nil {
+			return page, err
+		}
+		c.add(fmt.Sprintf("(%s, ts_epoch, channel, id) %s (?, ?, ?, ?)", sort, after),
+			position.Sort, position.Ts, position.Channel, position.ID)
+	}
+	query := fmt.Sprintf("SELECT id, doc, %s, ts_epoch, channel FROM messages%s ORDER BY %s %s, ts_epoch %s, channel %s, id %s LIMIT %s",
+		sort, c.where(), sort, order, order, order, order, c.arg(q.Size))
+
+	rows, err := pg.DB.QueryContext(pg.Context, query, c.args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	var last storage.Position
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&last.ID, &doc, &last.Sort, &last.Ts, &last.Channel); err != nil {
+			return page, err
+		}
+		var m globals.Message
+		if err := json.Unmarshal(doc, &m); err != nil {
+			log.Errorf("unable to deserialize document into message : %s", err)
+		}
+		m.ID = last.ID
+		page.Messages = append(page.Messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if q.Size > 0 && len(page.Messages) == q.Size {
+		page.NextCursor, err = storage.EncodeCursor(last)
+	}
+	return page, err
+}
+
+// DefaultSimilarScore is the ts_rank of the similar messages at least, when none is given. A word found once
+// ranks 0.06 divided by the number of words searched, about half of them shall be found
+const DefaultSimilarScore = 0.03
+
+// QuerySimilarMessages returns the closed user messages of the channel most similar to the text, most similar first.
+// The text is compared to the messages, their replies and their resolution notes, the score is their ts_rank
+// and shall be at least minScore, DefaultSimilarScore when 0
+func (pg Postgres) QuerySimilarMessages(channel string, text string, size int, minScore float64) ([]globals.SimilarMessage, error) {
+	workflow, err := pg.GetWorkflow(channel)
+	if err != nil {
+		return nil, err
+	}
+	closing := []string{}
+	for _, status := range workflow.ClosingStatuses() {
+		closing = append(closing, string(status))
+	}
+	terms := storage.Keywords(text)
+	if len(closing) == 0 || len(terms) == 0 {
+		return nil, nil
+	}
+	if minScore == 0 {
+		minScore = DefaultSimilarScore
+	}
+
+	var c conditions
+	c.add("type = ?", string(globals.NewMessage))
+	c.add("status = ANY(?)", pq.Array(closing))
+	c.channel(channel)
+	// any of the words shall match, like the more_like_this query of elasticsearch
+	search := c.arg(strings.Join(terms, " | "))
+	c.add(fmt.Sprintf("search @@ to_tsquery('simple', %s)", search))
+	c.add(fmt.Sprintf("ts_rank(search, to_tsquery('simple', %s)) >= ?", search), minScore)
+	query := fmt.Sprintf("SELECT id, doc, ts_rank(search, to_tsquery('simple', %s)) AS score FROM messages%s ORDER BY score DESC, ts_epoch DESC LIMIT %s",
+		search, c.where(), c.arg(size))
+
+	rows, err := pg.DB.QueryContext(pg.Context, query, c.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []globals.SimilarMessage
+	for rows.Next() {
+		var id string
+		var doc []byte
+		var m globals.SimilarMessage
+		if err := rows.Scan(&id, &doc, &m.Score); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(doc, &m.Message); err != nil {
+			log.Errorf("unable to deserialize document into message : %s", err)
+		}
+		m.ID = id
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Migration changes the schema of the database. Migrations are applied in order, each in its own transaction,
+// and recorded in the schema_migrations table so that they never run twice
+type Migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+// Migrations are the changes of the schema, never edit an applied one
+var Migrations = []Migration{
+	{Version: 1, Description: "create the tables of the documents", Statements: []string{
+		`CREATE TABLE messages (
+			id text PRIMARY KEY,
+			type text NOT NULL,
+			channel text NOT NULL,
+			status text NOT NULL,
+			user_id text NOT NULL,
+			ts text NOT NULL,
+			ts_epoch double precision NOT NULL,
+			remind_at double precision,
+			response_time bigint NOT NULL,
+			resolution_time bigint NOT NULL,
+			feedback_status text NOT NULL,
+			labels text[] NOT NULL,
+			tools text[] NOT NULL,
+			repliers text[] NOT NULL,
+			search tsvector NOT NULL,
+			doc jsonb NOT NULL
+		)`,
+		`CREATE INDEX messages_channel_ts ON messages (channel, ts_epoch)`,
+		`CREATE INDEX messages_ts ON messages (ts)`,
+		`CREATE INDEX messages_remind_at ON messages (remind_at)`,
+		`CREATE INDEX messages_search ON messages USING gin (search)`,
+		`CREATE TABLE firemen (
+			id bigserial PRIMARY KEY,
+			channel text NOT NULL,
+			ts_epoch double precision NOT NULL,
+			doc jsonb NOT NULL
+		)`,
+		`CREATE TABLE labels (id text PRIMARY KEY, name text NOT NULL, channel text, doc jsonb NOT NULL)`,
+		`CREATE INDEX labels_name ON labels (name)`,
+		`CREATE TABLE tools (id text PRIMARY KEY, name text NOT NULL, channel text, doc jsonb NOT NULL)`,
+		`CREATE INDEX tools_name ON tools (name)`,
+		`CREATE TABLE answers (id text PRIMARY KEY, channel text, tool text, label text, doc jsonb NOT NULL)`,
+		`CREATE TABLE team (id text PRIMARY KEY, slack_id text NOT NULL, channel text, doc jsonb NOT NULL)`,
+		`CREATE TABLE workflow (id text PRIMARY KEY, channel text, doc jsonb NOT NULL)`,
+	}},
+	{Version: 2, Description: "create the table of the fireman rotations", Statements: []string{
+		`CREATE TABLE rotations (id text PRIMARY KEY, channel text, doc jsonb NOT NULL)`,
+	}},
+	{Version: 3, Description: "create the table of the escalation policies", Statements: []string{
+		`CREATE TABLE escalations (id text PRIMARY KEY, channel text, doc jsonb NOT NULL)`,
+	}},
+	{Version: 4, Description: "create the table of the message templates", Statements: []string{
+		`CREATE TABLE message_templates (locale text NOT NULL, key text NOT NULL, doc jsonb NOT NULL, PRIMARY KEY (locale, key))`,
+	}},
+	{Version: 5, Description: "create the tables of the welcome messages and of their acknowledgements", Statements: []string{
+		`CREATE TABLE welcome (id text PRIMARY KEY, channel text, doc jsonb NOT NULL)`,
+		`CREATE TABLE welcome_acknowledgements (channel text NOT NULL, user_id text NOT NULL, doc jsonb NOT NULL, PRIMARY KEY (channel, user_id))`,
+	}},
+}
+
+// migrationsLock is the advisory lock taken while migrating, so that instances starting together migrate once
+const migrationsLock = 7216
+
+// SchemaVersion is the version of the schema once every migration is applied
+func SchemaVersion() int {
+	return Migrations[len(Migrations)-1].Version
+}
+
+// Migrate applies the pending migrations
+func (pg Postgres) Migrate() error {
+	_, err := pg.DB.ExecContext(pg.Context, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version integer PRIMARY KEY,
+		description text NOT NULL,
+		migrated_at timestamptz NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+	for _, migration := range Migrations {
+		if err := pg.migrate(migration); err != nil {
+			return fmt.Errorf("migration %d failed : %s", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// migrate applies the migration unless it already was
+func (pg Postgres) migrate(migration Migration) error {
+	tx, err := pg.DB.BeginTx(pg.Context, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(pg.Context, `SELECT pg_advisory_xact_lock($1)`, migrationsLock); err != nil {
+		return err
+	}
+	var applied bool
+	err = tx.QueryRowContext(pg.Context, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, migration.Version).
+		Scan(&applied)
+	if err != nil || applied {
+		return err
+	}
+
+	log.WithFields(log.Fields{"version": migration.Version}).Infof("Migrating : %s", migration.Description)
+	for _, statement := range migration.Statements {
+		if _, err := tx.ExecContext(pg.Context, statement); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(pg.Context, `INSERT INTO schema_migrations (version, description) VALUES ($1, $2)`,
+		migration.Version, migration.Description)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+// percoTable is the table of the labels or of the tools. The kind is the column of the answers referencing them
+type percoTable struct {
+	name string
+	kind string
+}
+
+var (
+	labelsTable = percoTable{name: "labels", kind: "label"}
+	toolsTable  = percoTable{name: "tools", kind: "tool"}
+)
+
+// addPerco stores the label or the tool, its ID is generated when not given
+func (pg Postgres) addPerco(table percoTable, perco globals.Perco) error {
+	if perco.Name == "" || perco.Query.Regexp.Input == "" {
+		return fmt.Errorf("cannot create empty %s", table.kind)
+	}
+	id := perco.ID
+	if id == "" {
+		id = storage.NewID()
+	}
+	if err := pg.putPerco(table, id, perco); err != nil {
+		return fmt.Errorf("error creating document : %s", err)
+	}
+	return nil
+}
+
+// editPerco modifies the label or the tool of the document ID
+func (pg Postgres) editPerco(table percoTable, documentID string, perco globals.Perco) error {
+	if documentID == "" {
+		return fmt.Errorf("cannot edit %s without documentID", table.kind)
+	}
+	return pg.putPerco(table, documentID, perco)
+}
+
+func (pg Postgres) putPerco(table percoTable, documentID string, perco globals.Perco) error {
+	b, err := json.Marshal(perco)
+	if err != nil {
+		return err
+	}
+	_, err = pg.DB.ExecContext(pg.Context, fmt.Sprintf(`INSERT INTO %s (id, name, channel, doc) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, channel = EXCLUDED.channel, doc = EXCLUDED.doc`, table.name),
+		documentID, perco.Name, nullable(perco.Channel), string(b))
+	return err
+}
+
+// deletePerco removes the label or the tool of the document ID, unless an answer uses it
+func (pg Postgres) deletePerco(table percoTable, documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+
+	perco, err := pg.percoByID(table, documentID)
+	if err != nil {
+		return err
+	}
+	var inUse bool
+	err = pg.DB.QueryRowContext(pg.Context, fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM answers WHERE %s = $1)`, table.kind), perco.Name).
+		Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return fmt.Errorf("cannot delete %s : already in use", table.kind)
+	}
+
+	_, err = pg.DB.ExecContext(pg.Context, fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, table.name), documentID)
+	return err
+}
+
+// selectPercos returns the labels or the tools matching the conditions
+func (pg Postgres) selectPercos(table percoTable, c conditions) ([]globals.Perco, error) {
+	var percos []globals.Perco
+	err := pg.selectDocuments(table.name, c, "id", func(id string, doc []byte) error {
+		var p globals.Perco
+		if err := json.Unmarshal(doc, &p); err != nil {
+			log.Errorf("Unable to deserialize document into perco : %s", err)
+		}
+		p.ID = id
+		percos = append(percos, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found a total of %d %s\n", len(percos), table.name)
+
+	return percos, nil
+}
+
+// getPercos returns the labels or the tools of the channel
+func (pg Postgres) getPercos(table percoTable, channel string) ([]globals.Perco, error) {
+	var c conditions
+	c.channel(channel)
+	return pg.selectPercos(table, c)
+}
+
+// queryPercos returns the names of the labels or the tools of the channel whose regexp matches the text
+func (pg Postgres) queryPercos(table percoTable, channel string, text string) ([]string, error) {
+	percos, err := pg.getPercos(table, channel)
+	if err != nil {
+		return nil, err
+	}
+	return storage.MatchPercos(percos, text), nil
+}
+
+// percosByName returns the labels or the tools of the given name
+func (pg Postgres) percosByName(table percoTable, name string) ([]globals.Perco, error) {
+	var c conditions
+	c.add("name = ?", name)
+	return pg.selectPercos(table, c)
+}
+
+// percoByID returns the label or the tool of the document ID
+func (pg Postgres) percoByID(table percoTable, documentID string) (globals.Perco, error) {
+	var c conditions
+	c.add("id = ?", documentID)
+	percos, err := pg.selectPercos(table, c)
+	if err != nil {
+		return globals.Perco{}, err
+	}
+	if len(percos) == 0 {
+		return globals.Perco{}, fmt.Errorf("%s %s not found", table.kind, documentID)
+	}
+	return percos[0], nil
+}
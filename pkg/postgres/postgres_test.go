@@ -0,0 +1,31 @@
+package postgres_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/postgres"
+	"github.com/leboncoin/subot/pkg/storage/storagetest"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContract runs the storage contract against the database of POSTGRES_URL, whose tables are emptied. See make test-storage
+func TestContract(t *testing.T) {
+	if os.Getenv("POSTGRES_URL") == "" {
+		t.Skip("POSTGRES_URL is not set")
+	}
+	viper.Set("postgres_url", os.Getenv("POSTGRES_URL"))
+	pg, err := postgres.Configure()
+	require.NoError(t, err)
+	require.NoError(t, pg.Migrate(), "migrating twice shall not fail")
+
+	storagetest.Suite{
+		Open: func(t *testing.T) elastic.Interface {
+			_, err := pg.DB.Exec("TRUNCATE messages, firemen, labels, tools, answers, team, workflow, rotations, escalations, message_templates, welcome, welcome_acknowledgements")
+			require.NoError(t, err)
+			return pg
+		},
+	}.Run(t)
+}
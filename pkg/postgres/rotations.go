@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddRotation stores the rotation in the rotations table
+func (pg Postgres) AddRotation(rotation globals.Rotation) error {
+	if len(rotation.Roster) == 0 {
+		return errors.New("cannot create rotation without roster")
+	}
+	id := rotation.ID
+	if id == "" {
+		id = storage.NewID()
+	}
+	if err := pg.putRotation(id, rotation); err != nil {
+		return fmt.Errorf("error creating document : %s", err)
+	}
+	return nil
+}
+
+// EditRotation Modifies the rotation matching the given documentID
+func (pg Postgres) EditRotation(documentID string, rotation globals.Rotation) error {
+	if documentID == "" {
+		return errors.New("cannot edit rotation without documentID")
+	}
+	if len(rotation.Roster) == 0 {
+		return errors.New("cannot edit rotation without roster")
+	}
+	return pg.putRotation(documentID, rotation)
+}
+
+func (pg Postgres) putRotation(documentID string, rotation globals.Rotation) error {
+	b, err := json.Marshal(rotation)
+	if err != nil {
+		return err
+	}
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO rotations (id, channel, doc) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET channel = EXCLUDED.channel, doc = EXCLUDED.doc`,
+		documentID, nullable(rotation.Channel), string(b))
+	return err
+}
+
+// DeleteRotation removes the rotation from the rotations table
+func (pg Postgres) DeleteRotation(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+	_, err := pg.DB.ExecContext(pg.Context, `DELETE FROM rotations WHERE id = $1`, documentID)
+	return err
+}
+
+// GetRotations returns the rotations of the channel and the ones shared by every channel
+func (pg Postgres) GetRotations(channel string) ([]globals.Rotation, error) {
+	var c conditions
+	c.channel(channel)
+
+	var rotations []globals.Rotation
+	err := pg.selectDocuments("rotations", c, "id", func(id string, doc []byte) error {
+		var rotation globals.Rotation
+		if err := json.Unmarshal(doc, &rotation); err != nil {
+			log.Errorf("Unable to deserialize document into rotation : %s", err)
+			return nil
+		}
+		rotation.ID = id
+		rotations = append(rotations, rotation)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rotations, nil
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddTeamMember stores a team member in the team table
+func (pg Postgres) AddTeamMember(user globals.TeamMember) error {
+	if err := pg.putTeamMember(storage.NewID(), user); err != nil {
+		return fmt.Errorf("error creating document : %s", err)
+	}
+	return nil
+}
+
+// DeleteTeamMember removes a team member from the team table
+func (pg Postgres) DeleteTeamMember(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete without document ID")
+	}
+	_, err := pg.DB.ExecContext(pg.Context, `DELETE FROM team WHERE id = $1`, documentID)
+	return err
+}
+
+// EditTeamMember modifies team member info in the team table
+func (pg Postgres) EditTeamMember(documentID string, teamMember globals.TeamMember) error {
+	if documentID == "" {
+		return errors.New("cannot edit without document ID")
+	}
+	return pg.putTeamMember(documentID, teamMember)
+}
+
+func (pg Postgres) putTeamMember(documentID string, teamMember globals.TeamMember) error {
+	b, err := json.Marshal(teamMember)
+	if err != nil {
+		return err
+	}
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO team (id, slack_id, channel, doc) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET slack_id = EXCLUDED.slack_id, channel = EXCLUDED.channel, doc = EXCLUDED.doc`,
+		documentID, teamMember.SlackID, nullable(teamMember.Channel), string(b))
+	return err
+}
+
+// GetTeamMembers returns the team members of the given channel
+func (pg Postgres) GetTeamMembers(channel string) ([]globals.TeamMember, error) {
+	var c conditions
+	c.channel(channel)
+
+	var users []globals.TeamMember
+	err := pg.selectDocuments("team", c, "id", func(id string, doc []byte) error {
+		var u globals.TeamMember
+		if err := json.Unmarshal(doc, &u); err != nil {
+			log.Errorf("Unable to deserialize document into team member : %s", err)
+		}
+		u.ID = id
+		users = append(users, u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Found a total of %d team members\n", len(users))
+
+	return users, nil
+}
+
+// IsTeamMember looks for the userId in the team of the channel
+func (pg Postgres) IsTeamMember(channel string, userID string) (bool, error) {
+	var c conditions
+	c.add("slack_id = ?", userID)
+	c.channel(channel)
+
+	var isMember bool
+	err := pg.DB.QueryRowContext(pg.Context, "SELECT EXISTS (SELECT 1 FROM team"+c.where()+")", c.args...).Scan(&isMember)
+	return isMember, err
+}
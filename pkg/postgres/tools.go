@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// AddTool stores the tool in the tools table
+func (pg Postgres) AddTool(tool globals.Perco) error {
+	return pg.addPerco(toolsTable, tool)
+}
+
+// DeleteTool removes the tool from the tools table, unless an answer uses it
+func (pg Postgres) DeleteTool(documentID string) error {
+	return pg.deletePerco(toolsTable, documentID)
+}
+
+// EditTool Modifies a tool matching the given documentID
+func (pg Postgres) EditTool(documentID string, tool globals.Perco) error {
+	return pg.editPerco(toolsTable, documentID, tool)
+}
+
+// GetTools returns the list of all the tools of the channel
+func (pg Postgres) GetTools(channel string) ([]globals.Perco, error) {
+	return pg.getPercos(toolsTable, channel)
+}
+
+// QueryTools returns all the tools of the channel whose regexp matches a word of the text
+func (pg Postgres) QueryTools(channel string, text string) ([]string, error) {
+	return pg.queryPercos(toolsTable, channel, text)
+}
+
+// QueryToolByID returns the tool that matches the id
+func (pg Postgres) QueryToolByID(id string) (globals.Perco, error) {
+	return pg.percoByID(toolsTable, id)
+}
+
+// QueryToolByName returns the tool that match the given Name
+func (pg Postgres) QueryToolByName(name string) ([]globals.Perco, error) {
+	return pg.percosByName(toolsTable, name)
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// conditions accumulates the WHERE clause of a query and its arguments.
+// The ? of the clauses are replaced by the numbered placeholders of their arguments
+type conditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+// arg adds an argument to the query and returns its placeholder
+func (c *conditions) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	return "$" + strconv.Itoa(len(c.args))
+}
+
+// add restricts the query to the rows matching the clause
+func (c *conditions) add(clause string, args ...interface{}) {
+	for _, arg := range args {
+		clause = strings.Replace(clause, "?", c.arg(arg), 1)
+	}
+	c.clauses = append(c.clauses, clause)
+}
+
+// channel restricts the query to the rows of the given channel and to the ones shared by every channel.
+// An empty channel does not filter anything
+func (c *conditions) channel(channel string) {
+	if channel != "" {
+		c.add("(channel = ? OR channel IS NULL)", channel)
+	}
+}
+
+func (c conditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// selectDocuments calls handle with the id and the document of every row of the table matching the conditions,
+// in the given order
+func (pg Postgres) selectDocuments(table string, c conditions, orderBy string, handle func(id string, doc []byte) error) error {
+	query := fmt.Sprintf("SELECT id, doc FROM %s%s ORDER BY %s", table, c.where(), orderBy)
+	rows, err := pg.DB.QueryContext(pg.Context, query, c.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var doc []byte
+		if err := rows.Scan(&id, &doc); err != nil {
+			return err
+		}
+		if err := handle(id, doc); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// nullable stores the empty strings as NULL, the documents without channel being shared by every channel
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	log "github.com/sirupsen/logrus"
+)
+
+// SetWelcomeMessage stores the welcome message in the welcome table, replacing the one of the same channel
+func (pg Postgres) SetWelcomeMessage(welcome globals.WelcomeMessage) error {
+	if welcome.Text == "" && len(welcome.Blocks) == 0 {
+		return errors.New("cannot store welcome message without text or blocks")
+	}
+	b, err := json.Marshal(welcome)
+	if err != nil {
+		return err
+	}
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO welcome (id, channel, doc) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET channel = EXCLUDED.channel, doc = EXCLUDED.doc`,
+		welcome.DocumentID(), nullable(welcome.Channel), string(b))
+	if err != nil {
+		return fmt.Errorf("error creating document : %s", err)
+	}
+	return nil
+}
+
+// DeleteWelcomeMessage removes the welcome message of the channel from the welcome table,
+// the one shared by every channel when empty
+func (pg Postgres) DeleteWelcomeMessage(channel string) error {
+	_, err := pg.DB.ExecContext(pg.Context, `DELETE FROM welcome WHERE id = $1`,
+		globals.WelcomeMessage{Channel: channel}.DocumentID())
+	return err
+}
+
+// GetWelcomeMessages returns the welcome message of the channel and the one shared by every channel
+func (pg Postgres) GetWelcomeMessages(channel string) ([]globals.WelcomeMessage, error) {
+	var c conditions
+	c.channel(channel)
+
+	var messages []globals.WelcomeMessage
+	err := pg.selectDocuments("welcome", c, "id", func(id string, doc []byte) error {
+		var welcome globals.WelcomeMessage
+		if err := json.Unmarshal(doc, &welcome); err != nil {
+			log.Errorf("Unable to deserialize document into welcome message : %s", err)
+			return nil
+		}
+		messages = append(messages, welcome)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// AddWelcomeAcknowledgement stores that the user read the rules of the channel in the welcome_acknowledgements table
+func (pg Postgres) AddWelcomeAcknowledgement(acknowledgement globals.WelcomeAcknowledgement) error {
+	if acknowledgement.Channel == "" || acknowledgement.UserID == "" {
+		return errors.New("cannot store welcome acknowledgement without channel or user")
+	}
+	b, err := json.Marshal(acknowledgement)
+	if err != nil {
+		return err
+	}
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO welcome_acknowledgements (channel, user_id, doc) VALUES ($1, $2, $3)
+		ON CONFLICT (channel, user_id) DO UPDATE SET doc = EXCLUDED.doc`,
+		acknowledgement.Channel, acknowledgement.UserID, string(b))
+	if err != nil {
+		return fmt.Errorf("error creating document : %s", err)
+	}
+	return nil
+}
+
+// GetWelcomeAcknowledgements returns the users who read the rules of the channel, of every channel when empty
+func (pg Postgres) GetWelcomeAcknowledgements(channel string) ([]globals.WelcomeAcknowledgement, error) {
+	query := `SELECT doc FROM welcome_acknowledgements`
+	var args []interface{}
+	if channel != "" {
+		query += ` WHERE channel = $1`
+		args = append(args, channel)
+	}
+	rows, err := pg.DB.QueryContext(pg.Context, query+` ORDER BY channel, user_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var acknowledgements []globals.WelcomeAcknowledgement
+	for rows.Next() {
+		var doc []byte
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var acknowledgement globals.WelcomeAcknowledgement
+		if err := json.Unmarshal(doc, &acknowledgement); err != nil {
+			log.Errorf("Unable to deserialize document into welcome acknowledgement : %s", err)
+			continue
+		}
+		acknowledgements = append(acknowledgements, acknowledgement)
+	}
+	return acknowledgements, rows.Err()
+}
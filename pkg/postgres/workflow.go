@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddStatusRule stores the status rule in the workflow table
+func (pg Postgres) AddStatusRule(rule globals.StatusRule) error {
+	if rule.Emoji == "" || rule.Status == "" {
+		return errors.New("cannot create status rule without emoji or status")
+	}
+	id := rule.ID
+	if id == "" {
+		id = storage.NewID()
+	}
+	if err := pg.putStatusRule(id, rule); err != nil {
+		return fmt.Errorf("error creating document : %s", err)
+	}
+	return nil
+}
+
+// EditStatusRule Modifies the status rule matching the given documentID
+func (pg Postgres) EditStatusRule(documentID string, rule globals.StatusRule) error {
+	if documentID == "" {
+		return errors.New("cannot edit status rule without documentID")
+	}
+	if rule.Emoji == "" || rule.Status == "" {
+		return errors.New("cannot edit status rule without emoji or status")
+	}
+	return pg.putStatusRule(documentID, rule)
+}
+
+func (pg Postgres) putStatusRule(documentID string, rule globals.StatusRule) error {
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	_, err = pg.DB.ExecContext(pg.Context, `INSERT INTO workflow (id, channel, doc) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET channel = EXCLUDED.channel, doc = EXCLUDED.doc`,
+		documentID, nullable(rule.Channel), string(b))
+	return err
+}
+
+// DeleteStatusRule removes the status rule from the workflow table
+func (pg Postgres) DeleteStatusRule(documentID string) error {
+	if documentID == "" {
+		return errors.New("cannot delete empty documentID")
+	}
+	_, err := pg.DB.ExecContext(pg.Context, `DELETE FROM workflow WHERE id = $1`, documentID)
+	return err
+}
+
+// GetWorkflow returns the status rules of the channel and the ones shared by every channel.
+// The default rules whose emoji is not used by them are added
+func (pg Postgres) GetWorkflow(channel string) (globals.Workflow, error) {
+	var c conditions
+	c.channel(channel)
+
+	var workflow globals.Workflow
+	err := pg.selectDocuments("workflow", c, "id", func(id string, doc []byte) error {
+		var rule globals.StatusRule
+		if err := json.Unmarshal(doc, &rule); err != nil {
+			log.Errorf("Unable to deserialize document into status rule : %s", err)
+			return nil
+		}
+		rule.ID = id
+		workflow = append(workflow, rule)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workflow.WithDefaults(), nil
+}
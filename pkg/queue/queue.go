@@ -0,0 +1,340 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	pendingDir = "pending"
+	deadDir    = "dead"
+)
+
+// ErrNotFound is returned when an item is not in the queue
+var ErrNotFound = errors.New("item not found")
+
+var invalidIDChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// permanentError is the error of an item which would fail on every attempt
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks the error of an item which would fail on every attempt, like an invalid payload.
+// The item is moved to the dead-letter directory right away instead of being retried
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Item is a payload waiting to be processed
+type Item struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	CreatedAt time.Time       `json:"created_at"`
+	NextRetry time.Time       `json:"next_retry"`
+	LastError string          `json:"last_error,omitempty"`
+}
+
+// Queue is a durable queue storing each item as a json file.
+// Items failing more than MaxAttempts times are moved to the dead-letter directory
+type Queue struct {
+	Path        string
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+
+	mu      sync.Mutex
+	counter uint64
+	wake    chan struct{}
+}
+
+// New creates the queue directories under the given path
+func New(path string, maxAttempts int, baseDelay time.Duration, maxDelay time.Duration) (*Queue, error) {
+	for _, dir := range []string{pendingDir, deadDir} {
+		if err := os.MkdirAll(filepath.Join(path, dir), 0750); err != nil {
+			return nil, err
+		}
+	}
+	return &Queue{
+		Path:        path,
+		MaxAttempts: maxAttempts,
+		BaseDelay:   baseDelay,
+		MaxDelay:    maxDelay,
+		wake:        make(chan struct{}, 1),
+	}, nil
+}
+
+// Push stores a new item in the queue. A unique ID is generated when none is given
+func (q *Queue) Push(kind string, id string, payload interface{}) (Item, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Item{}, err
+	}
+	now := time.Now()
+	if id == "" {
+		id = strconv.FormatInt(now.UnixNano(), 10) + "-" + strconv.FormatUint(atomic.AddUint64(&q.counter, 1), 10)
+	}
+	item := Item{
+		ID:        invalidIDChars.ReplaceAllString(id, "_"),
+		Kind:      kind,
+		Payload:   data,
+		CreatedAt: now,
+		NextRetry: now,
+	}
+
+	q.mu.Lock()
+	err = q.write(pendingDir, item)
+	q.mu.Unlock()
+	if err != nil {
+		return Item{}, err
+	}
+
+	// wake the worker up without waiting for the next tick
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return item, nil
+}
+
+// Due returns the pending items ready to be processed, oldest first
+func (q *Queue) Due(now time.Time) ([]Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items, err := q.list(pendingDir)
+	if err != nil {
+		return nil, err
+	}
+	var due []Item
+	for _, item := range items {
+		if !item.NextRetry.After(now) {
+			due = append(due, item)
+		}
+	}
+	return due, nil
+}
+
+// Pending returns every item waiting to be processed
+func (q *Queue) Pending() ([]Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.list(pendingDir)
+}
+
+// Dead returns the items which could not be processed
+func (q *Queue) Dead() ([]Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.list(deadDir)
+}
+
+// Has checks if an item with the given ID is pending or dead
+func (q *Queue) Has(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, dir := range []string{pendingDir, deadDir} {
+		if _, err := os.Stat(q.file(dir, id)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Done removes a processed item from the queue
+func (q *Queue) Done(item Item) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return os.Remove(q.file(pendingDir, item.ID))
+}
+
+// Fail schedules the item for a new attempt with an exponential backoff,
+// or moves it to the dead-letter directory when it ran out of attempts or when the error is Permanent.
+// It returns true when the item is dead
+func (q *Queue) Fail(item Item, cause error) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item.Attempts++
+	if cause != nil {
+		item.LastError = cause.Error()
+	}
+
+	var permanent permanentError
+	if item.Attempts >= q.MaxAttempts || errors.As(cause, &permanent) {
+		if err := q.write(deadDir, item); err != nil {
+			return false, err
+		}
+		return true, os.Remove(q.file(pendingDir, item.ID))
+	}
+
+	item.NextRetry = time.Now().Add(q.backoff(item.Attempts))
+	return false, q.write(pendingDir, item)
+}
+
+// Replay moves a dead item back to the pending items with its attempts reset
+func (q *Queue) Replay(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, err := q.read(q.file(deadDir, id))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	item.Attempts = 0
+	item.NextRetry = time.Now()
+	if err := q.write(pendingDir, item); err != nil {
+		return err
+	}
+	return os.Remove(q.file(deadDir, id))
+}
+
+// Process handles every due item and returns the number of processed items
+func (q *Queue) Process(handler func(Item) error) int {
+	items, err := q.Due(time.Now())
+	if err != nil {
+		log.Errorf("Could not read queue: %s", err)
+		return 0
+	}
+
+	for _, item := range items {
+		if err := handler(item); err != nil {
+			dead, ferr := q.Fail(item, err)
+			if ferr != nil {
+				log.WithFields(log.Fields{"id": item.ID}).Errorf("Could not reschedule queue item: %s", ferr)
+				continue
+			}
+			if dead {
+				log.WithFields(log.Fields{"id": item.ID, "kind": item.Kind, "attempts": item.Attempts + 1}).Errorf("Queue item moved to dead letters: %s", err)
+			} else {
+				log.WithFields(log.Fields{"id": item.ID, "kind": item.Kind, "attempts": item.Attempts + 1}).Warnf("Queue item failed, will retry: %s", err)
+			}
+			continue
+		}
+		if err := q.Done(item); err != nil {
+			log.WithFields(log.Fields{"id": item.ID}).Errorf("Could not remove queue item: %s", err)
+		}
+	}
+	return len(items)
+}
+
+// Run processes the due items every interval, or as soon as an item is pushed,
+// until the context is cancelled
+func (q *Queue) Run(ctx context.Context, interval time.Duration, handler func(Item) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		q.Process(handler)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+// backoff returns the delay before the given attempt
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if q.MaxDelay > 0 && delay >= q.MaxDelay {
+			return q.MaxDelay
+		}
+	}
+	return delay
+}
+
+func (q *Queue) file(dir string, id string) string {
+	return filepath.Join(q.Path, dir, invalidIDChars.ReplaceAllString(id, "_")+".json")
+}
+
+// write stores the item atomically so that a crash never leaves a partial file
+func (q *Queue) write(dir string, item Item) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Join(q.Path, dir), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), q.file(dir, item.ID))
+}
+
+func (q *Queue) read(path string) (Item, error) {
+	var item Item
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return item, err
+	}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return item, fmt.Errorf("invalid queue item %s: %s", path, err)
+	}
+	return item, nil
+}
+
+func (q *Queue) list(dir string) ([]Item, error) {
+	files, err := ioutil.ReadDir(filepath.Join(q.Path, dir))
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		item, err := q.read(filepath.Join(q.Path, dir, file.Name()))
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
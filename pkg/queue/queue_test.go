@@ -0,0 +1,129 @@
+package queue_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func newQueue(t *testing.T, maxAttempts int) (*queue.Queue, func()) {
+	dir, err := ioutil.TempDir("", "queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := queue.New(dir, maxAttempts, time.Minute, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestPushAndProcess(t *testing.T) {
+	q, clean := newQueue(t, 3)
+	defer clean()
+
+	_, err := q.Push("event", "", map[string]string{"ts": "1"})
+	assert.Nil(t, err, "item shall be pushed")
+	_, err = q.Push("event", "", map[string]string{"ts": "2"})
+	assert.Nil(t, err, "item shall be pushed")
+
+	var processed []string
+	count := q.Process(func(item queue.Item) error {
+		processed = append(processed, string(item.Payload))
+		return nil
+	})
+	assert.Equal(t, 2, count, "every due item shall be processed")
+	assert.Equal(t, []string{`{"ts":"1"}`, `{"ts":"2"}`}, processed, "items shall be processed in order")
+
+	pending, err := q.Pending()
+	assert.Nil(t, err, "pending items shall be read")
+	assert.Empty(t, pending, "processed items shall be removed")
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	q, clean := newQueue(t, 3)
+	defer clean()
+
+	item, err := q.Push("event", "Ev01", "payload")
+	assert.Nil(t, err, "item shall be pushed")
+
+	q.Process(func(queue.Item) error { return errors.New("analytics is down") })
+
+	pending, _ := q.Pending()
+	assert.Equal(t, 1, len(pending), "failed item shall be kept")
+	assert.Equal(t, 1, pending[0].Attempts, "attempt shall be counted")
+	assert.Equal(t, "analytics is down", pending[0].LastError, "error shall be kept")
+	assert.True(t, pending[0].NextRetry.After(item.CreatedAt.Add(59*time.Second)), "retry shall be delayed")
+
+	due, _ := q.Due(time.Now())
+	assert.Empty(t, due, "item shall not be due before its next retry")
+
+	due, _ = q.Due(time.Now().Add(time.Minute))
+	assert.Equal(t, 1, len(due), "item shall be due after its next retry")
+
+	dead, err := q.Fail(due[0], errors.New("analytics is still down"))
+	assert.Nil(t, err, "item shall be rescheduled")
+	assert.False(t, dead, "item shall not be dead before its last attempt")
+
+	pending, _ = q.Pending()
+	assert.True(t, pending[0].NextRetry.After(time.Now().Add(119*time.Second)), "delay shall double")
+}
+
+func TestDeadLetterAndReplay(t *testing.T) {
+	q, clean := newQueue(t, 2)
+	defer clean()
+
+	item, _ := q.Push("event", "Ev01", "payload")
+	dead, _ := q.Fail(item, errors.New("first"))
+	assert.False(t, dead, "item shall be retried")
+	pending, _ := q.Pending()
+	dead, _ = q.Fail(pending[0], errors.New("second"))
+	assert.True(t, dead, "item shall be dead after the last attempt")
+
+	pending, _ = q.Pending()
+	assert.Empty(t, pending, "dead item shall not be pending")
+	failed, _ := q.Dead()
+	assert.Equal(t, 1, len(failed), "dead item shall be listed")
+	assert.Equal(t, "second", failed[0].LastError, "last error shall be kept")
+
+	assert.Equal(t, queue.ErrNotFound, q.Replay("unknown"), "unknown item shall not be replayed")
+	assert.Nil(t, q.Replay("Ev01"), "dead item shall be replayed")
+
+	failed, _ = q.Dead()
+	assert.Empty(t, failed, "replayed item shall not be dead")
+	due, _ := q.Due(time.Now())
+	assert.Equal(t, 1, len(due), "replayed item shall be due")
+	assert.Equal(t, 0, due[0].Attempts, "attempts shall be reset")
+}
+
+func TestPermanentError(t *testing.T) {
+	q, clean := newQueue(t, 10)
+	defer clean()
+
+	_, _ = q.Push("event", "Ev01", "payload")
+	q.Process(func(queue.Item) error { return queue.Permanent(errors.New("invalid payload")) })
+
+	pending, _ := q.Pending()
+	assert.Empty(t, pending, "item shall not be retried")
+	failed, _ := q.Dead()
+	assert.Equal(t, 1, len(failed), "item shall be dead after its first attempt")
+	assert.Equal(t, "invalid payload", failed[0].LastError, "error shall be kept")
+}
+
+func TestQueueIsDurable(t *testing.T) {
+	q, clean := newQueue(t, 2)
+	defer clean()
+
+	_, err := q.Push("event", "", "payload")
+	assert.Nil(t, err, "item shall be pushed")
+
+	reopened, err := queue.New(q.Path, 2, time.Second, time.Minute)
+	assert.Nil(t, err, "queue shall be reopened")
+	pending, _ := reopened.Pending()
+	assert.Equal(t, 1, len(pending), "items shall survive a restart")
+}
@@ -5,14 +5,19 @@ import (
 	"github.com/leboncoin/subot/pkg/globals"
 )
 
-// IsValidToken checks if given token is the same as the config token
-func (s Slack) IsValidToken(request EventRequest) bool {
-	return request.Token == s.Token
+// IsWatchedChannel checks if given channelId is one of the channels watched in the config
+func (s Slack) IsWatchedChannel(event Event) bool {
+	for _, channel := range s.Channels {
+		if event.Channel == channel.ID || event.Item.Channel == channel.ID {
+			return true
+		}
+	}
+	return false
 }
 
-// IsWatchedChannel checks if given channelId is the same as the one watched in the config
-func (s Slack) IsWatchedChannel(event Event) bool {
-	return event.Channel == s.Channel.ID || event.Item.Channel == s.Channel.ID
+// GetChannels returns the list of channels watched in the config
+func (s Slack) GetChannels() []Chan {
+	return s.Channels
 }
 
 // IsThreadMessage checks if event is from a thread reply
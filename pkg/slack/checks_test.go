@@ -76,3 +76,19 @@ func TestGetMessageType(t *testing.T) {
 		t.Errorf("did not detect message message: %s is not equal to %s", messageResult, "message")
 	}
 }
+
+func TestIsWatchedChannel(t *testing.T) {
+	s := slack.Slack{
+		Channels: []slack.Chan{{ID: "C1"}, {ID: "C2"}},
+	}
+
+	if !s.IsWatchedChannel(slack.Event{Channel: "C2"}) {
+		t.Errorf("did not watch second channel")
+	}
+	if !s.IsWatchedChannel(slack.Event{Item: slack.EventItem{Channel: "C1"}}) {
+		t.Errorf("did not watch reaction on first channel")
+	}
+	if s.IsWatchedChannel(slack.Event{Channel: "C3"}) {
+		t.Errorf("watched a channel that is not configured")
+	}
+}
@@ -18,11 +18,12 @@ type CommandRequest struct {
 
 // Slack structure of the slack object
 type Slack struct {
-	Host     string `json:"host"`
-	Channel  Chan   `json:"channel"`
-	Token    string `json:"token"`
-	BotToken string `json:"bot_token"`
-	BotID    string `json:"bot_id"`
+	Host          string `json:"host"`
+	Channels      []Chan `json:"channels"`
+	Token         string `json:"token"`
+	BotToken      string `json:"bot_token"`
+	BotID         string `json:"bot_id"`
+	SigningSecret string `json:"signing_secret"`
 }
 
 // Event wrapper around any kind of event received by slack
@@ -46,6 +47,7 @@ type Event struct {
 	Item            EventItem          `form:"item" json:"item"`
 	Name            string             `json:"name"`
 	LinkNames       bool               `json:"link_names"`
+	Topic           string             `json:"topic,omitempty"`
 }
 
 // EventItem an Item of an even (used in some cases)
@@ -61,6 +63,8 @@ type EventRequest struct {
 	Token     string `form:"token" json:"token"`
 	Event     Event  `form:"event" json:"event"`
 	Challenge string `form:"challenge" json:"challenge"`
+	EventID   string `form:"event_id" json:"event_id"`
+	EventTime int64  `form:"event_time" json:"event_time"`
 }
 
 // InteractivityChannel is the channel as represented in InteractivityRequest payload
@@ -78,6 +82,36 @@ type InteractivityAction struct {
 	ActionTs string `json:"action_ts"`
 }
 
+// InteractivityView is the modal submitted in an InteractivityRequest payload
+type InteractivityView struct {
+	ID              string             `json:"id"`
+	CallbackID      string             `json:"callback_id"`
+	PrivateMetadata string             `json:"private_metadata"`
+	State           InteractivityState `json:"state"`
+}
+
+// InteractivityState holds the values of the inputs of a modal, by block and action ID
+type InteractivityState struct {
+	Values map[string]map[string]InteractivityValue `json:"values"`
+}
+
+// InteractivityValue is the value of an input of a modal
+type InteractivityValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Inputs returns the values of the inputs of the modal by action ID
+func (s InteractivityState) Inputs() map[string]string {
+	inputs := make(map[string]string)
+	for _, actions := range s.Values {
+		for actionID, input := range actions {
+			inputs[actionID] = input.Value
+		}
+	}
+	return inputs
+}
+
 // InteractivityRequest the request which wraps the payload from a slack interaction
 type InteractivityRequest struct {
 	Type        string                `json:"type"`
@@ -89,6 +123,9 @@ type InteractivityRequest struct {
 	Message     globals.Reply         `json:"message"`
 	ResponseURL string                `json:"response_url"`
 	Actions     []InteractivityAction `json:"actions"`
+	CallbackID  string                `json:"callback_id"`
+	ActionTs    string                `json:"action_ts"`
+	View        InteractivityView     `json:"view"`
 }
 
 // ResponseMetadata Metadata containing the cursor when fetching lots of data from the slack api
@@ -106,6 +143,7 @@ type ApiResponse struct {
 	HasMore  bool             `json:"has_more"`
 	Messages []Event          `json:"messages"`
 	Metadata ResponseMetadata `json:"response_metadata"`
+	URL      string           `json:"url"`
 }
 
 // Chan definition of a channel
@@ -123,19 +161,23 @@ type Interface interface {
 	GetUpdatedMessage(e Event) globals.Message
 	GetEvent(e Event) globals.Event
 	ReadUser(string) ApiResponse
-	ReadMessages(string, string, string, ...int) (ApiResponse, error)
-	SendMessage(string, []interface{}) error
-	ReplyToMessage(string, string, []interface{}) error
-	SendEphemeralMessage(string, string) error
-	DeleteResponseToMessage(string) error
-	IsValidToken(request EventRequest) bool
+	ReadMessages(string, string, string, string, ...int) (ApiResponse, error)
+	SendMessage(string, string, []interface{}) error
+	ReplyToMessage(string, string, string, []interface{}) error
+	SendEphemeralMessage(string, string, string, []interface{}) error
+	DeleteResponseToMessage(string, string) error
+	VerifySignature(timestamp string, signature string, body []byte) error
 	IsWatchedChannel(event Event) bool
-	PostResponseURLPayload(responseURL string, text string) error
-	AddReaction(timestamp string, name string) error
+	GetChannels() []Chan
+	PostResponseURLPayload(responseURL string, text string, blocks []interface{}) error
+	AddReaction(channel string, timestamp string, name string) error
+	SetTopic(channel string, topic string) error
+	OpenView(triggerID string, view interface{}) error
 }
 
 // UpdateBlockKit represents the payload sent to a response url
 type UpdateBlockKit struct {
-	ReplaceOriginal bool   `json:"replace_original"`
-	Text            string `json:"text"`
+	ReplaceOriginal bool          `json:"replace_original"`
+	Text            string        `json:"text"`
+	Blocks          []interface{} `json:"blocks,omitempty"`
 }
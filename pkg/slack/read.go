@@ -36,13 +36,13 @@ func (s Slack) curlAPI(urlPath string, query url.Values) ([]byte, error) {
 	return bodyBytes, err
 }
 
-//ReadMessages Call slack api to retrieve all the messages in a period
-func (s Slack) ReadMessages(start string, end string, cursor string, limit ...int) (res ApiResponse, err error) {
+//ReadMessages Call slack api to retrieve all the messages of the channel in a period
+func (s Slack) ReadMessages(channel string, start string, end string, cursor string, limit ...int) (res ApiResponse, err error) {
 	urlPath := "api/conversations.history"
 
 	query := url.Values{}
 	query.Set("token", s.BotToken)
-	query.Set("channel", s.Channel.ID)
+	query.Set("channel", channel)
 	query.Set("oldest", start)
 	query.Set("latest", end)
 	query.Set("cursor", cursor)
@@ -68,13 +68,13 @@ func (s Slack) ReadMessages(start string, end string, cursor string, limit ...in
 	return
 }
 
-//ReadReplies Call the slack api to retrieve all the replies related to the message sent a the given timestamp
-func (s Slack) ReadReplies(ts string) (res ApiResponse, err error) {
+//ReadReplies Call the slack api to retrieve all the replies related to the message sent in the channel a the given timestamp
+func (s Slack) ReadReplies(channel string, ts string) (res ApiResponse, err error) {
 	urlPath := "api/conversations.replies"
 
 	query := url.Values{}
 	query.Set("token", s.BotToken)
-	query.Set("channel", s.Channel.ID)
+	query.Set("channel", channel)
 	query.Set("ts", ts)
 	query.Set("limit", strconv.Itoa(100))
 
@@ -103,6 +103,7 @@ func (s Slack) ReadUser(id string) ApiResponse {
 	query := url.Values{}
 	query.Set("token", s.BotToken)
 	query.Set("user", id)
+	query.Set("include_locale", "true")
 
 	bodyBytes, err := s.curlAPI(urlPath, query)
 	if err != nil {
@@ -64,9 +64,9 @@ func postRequest(queryURL url.URL, payload string, channelToken string) error {
 }
 
 // SendMessage calls Slack API on given channel URL with given body
-func (s *Slack) SendMessage(text string, blocks []interface{}) error {
+func (s *Slack) SendMessage(channel string, text string, blocks []interface{}) error {
 	payloadJSON := Event{
-		Channel: s.Channel.ID,
+		Channel: channel,
 		Text:    text,
 		Blocks:  blocks,
 	}
@@ -86,10 +86,10 @@ func (s *Slack) SendMessage(text string, blocks []interface{}) error {
 }
 
 // ReplyToMessage calls Slack API on given channel URL with given body
-func (s *Slack) ReplyToMessage(timestamp string, text string, blocks []interface{}) error {
+func (s *Slack) ReplyToMessage(channel string, timestamp string, text string, blocks []interface{}) error {
 	payloadJSON := Event{
 		Blocks:   blocks,
-		Channel:  s.Channel.ID,
+		Channel:  channel,
 		Text:     text,
 		ThreadTs: timestamp,
 		LinkNames: true,
@@ -111,9 +111,9 @@ func (s *Slack) ReplyToMessage(timestamp string, text string, blocks []interface
 }
 
 //DeleteResponseToMessage Calls the slack api to delete a message
-func (s *Slack) DeleteResponseToMessage(timestamp string) error {
+func (s *Slack) DeleteResponseToMessage(channel string, timestamp string) error {
 	payloadJSON := Event{
-		Channel: s.Channel.ID,
+		Channel: channel,
 		Ts:      timestamp,
 	}
 	payloadMarshalled, err := json.Marshal(payloadJSON)
@@ -131,9 +131,10 @@ func (s *Slack) DeleteResponseToMessage(timestamp string) error {
 }
 
 // SendEphemeralMessage sends an ephemeral message to the given user on the given channel
-func (s *Slack) SendEphemeralMessage(userID string, text string) error {
+func (s *Slack) SendEphemeralMessage(channel string, userID string, text string, blocks []interface{}) error {
 	payloadJSON := Event{
-		Channel: s.Channel.ID,
+		Blocks:  blocks,
+		Channel: channel,
 		User:    userID,
 		Text:    text,
 	}
@@ -153,11 +154,12 @@ func (s *Slack) SendEphemeralMessage(userID string, text string) error {
 }
 
 // PostResponseURLPayload posts a request to Slack from a response URL
-func (s *Slack) PostResponseURLPayload(responseURL string, text string) error {
+func (s *Slack) PostResponseURLPayload(responseURL string, text string, blocks []interface{}) error {
 
 	payload := UpdateBlockKit{
 		ReplaceOriginal: true,
 		Text:            text,
+		Blocks:          blocks,
 	}
 	payloadMarshalled, err := json.Marshal(payload)
 	if err != nil {
@@ -174,9 +176,9 @@ func (s *Slack) PostResponseURLPayload(responseURL string, text string) error {
 }
 
 // AddReaction places the requested emoji onto the message
-func (s *Slack) AddReaction(timestamp string, name string) error {
+func (s *Slack) AddReaction(channel string, timestamp string, name string) error {
 	payloadJSON := Event{
-		Channel:   s.Channel.ID,
+		Channel:   channel,
 		Name:      name,
 		Timestamp: timestamp,
 	}
@@ -194,3 +196,44 @@ func (s *Slack) AddReaction(timestamp string, name string) error {
 	}
 	return nil
 }
+
+// SetTopic replaces the topic of the channel
+func (s *Slack) SetTopic(channel string, topic string) error {
+	payloadJSON := Event{
+		Channel: channel,
+		Topic:   topic,
+	}
+	payloadMarshalled, err := json.Marshal(payloadJSON)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error while marshalling json")
+		return err
+	}
+	payloadString := string(payloadMarshalled)
+
+	err = postAPIPayload(s.Host, "conversations.setTopic", payloadString, s.BotToken)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error while posting api payload")
+		return err
+	}
+	return nil
+}
+
+// OpenView opens the modal to the user who triggered the interaction
+func (s *Slack) OpenView(triggerID string, view interface{}) error {
+	payloadMarshalled, err := json.Marshal(map[string]interface{}{
+		"trigger_id": triggerID,
+		"view":       view,
+	})
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error while marshalling json")
+		return err
+	}
+	payloadString := string(payloadMarshalled)
+
+	err = postAPIPayload(s.Host, "views.open", payloadString, s.BotToken)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error while posting api payload")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader is the header holding the signature of the requests sent by slack
+	SignatureHeader = "X-Slack-Signature"
+	// TimestampHeader is the header holding the timestamp used to sign the requests sent by slack
+	TimestampHeader = "X-Slack-Request-Timestamp"
+	// SignatureMaxAge is the maximum age of a signed request before it is considered as a replay
+	SignatureMaxAge = 5 * time.Minute
+
+	signatureVersion = "v0"
+)
+
+// ErrMissingSignature is returned when the signature headers are not present in the request
+var ErrMissingSignature = errors.New("missing slack signature")
+
+// ErrStaleSignature is returned when the request timestamp is outside of the replay window
+var ErrStaleSignature = errors.New("slack request timestamp is too old")
+
+// ErrInvalidSignature is returned when the signature does not match the request body
+var ErrInvalidSignature = errors.New("invalid slack signature")
+
+// ComputeSignature returns the signature of the body as computed by slack for the given timestamp
+func ComputeSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%s:%s:", signatureVersion, timestamp)))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets the slack signature headers on the request the same way slack does
+func SignRequest(req *http.Request, secret string, body []byte, at time.Time) {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, ComputeSignature(secret, timestamp, body))
+}
+
+// VerifySignature checks that the request body was signed by slack with the signing secret
+// and that it was sent within the replay window
+func (s Slack) VerifySignature(timestamp string, signature string, body []byte) error {
+	if s.SigningSecret == "" {
+		return errors.New("slack signing secret is not configured")
+	}
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid slack request timestamp %s: %s", timestamp, err)
+	}
+	if math.Abs(time.Since(time.Unix(sentAt, 0)).Seconds()) > SignatureMaxAge.Seconds() {
+		return ErrStaleSignature
+	}
+
+	expected := ComputeSignature(s.SigningSecret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package slack_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/slack"
+)
+
+func TestComputeSignature(t *testing.T) {
+	// example taken from the slack documentation
+	body := []byte("token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c")
+	signature := slack.ComputeSignature("8f742231b10e8888abcd99yyyzzz85a5", "1531420618", body)
+	expected := "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+	if signature != expected {
+		t.Errorf("wrong signature: %s is not equal to %s", signature, expected)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	s := slack.Slack{SigningSecret: "secret"}
+	body := []byte(`{"type": "event_callback"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	if err := s.VerifySignature(now, slack.ComputeSignature("secret", now, body), body); err != nil {
+		t.Errorf("valid signature was rejected: %s", err)
+	}
+
+	if err := s.VerifySignature(now, slack.ComputeSignature("other", now, body), body); err != slack.ErrInvalidSignature {
+		t.Errorf("signature with wrong secret was accepted: %v", err)
+	}
+
+	if err := s.VerifySignature(now, slack.ComputeSignature("secret", now, body), []byte("{}")); err != slack.ErrInvalidSignature {
+		t.Errorf("signature of another body was accepted: %v", err)
+	}
+
+	if err := s.VerifySignature("", "", body); err != slack.ErrMissingSignature {
+		t.Errorf("unsigned request was accepted: %v", err)
+	}
+
+	old := strconv.FormatInt(time.Now().Add(-6*time.Minute).Unix(), 10)
+	if err := s.VerifySignature(old, slack.ComputeSignature("secret", old, body), body); err != slack.ErrStaleSignature {
+		t.Errorf("stale request was accepted: %v", err)
+	}
+
+	unconfigured := slack.Slack{}
+	if err := unconfigured.VerifySignature(now, slack.ComputeSignature("", now, body), body); err == nil {
+		t.Errorf("request was accepted without signing secret")
+	}
+}
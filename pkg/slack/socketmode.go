@@ -0,0 +1,201 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultSocketModeURL is the base url of the slack api used to open socket mode connections
+const DefaultSocketModeURL = "https://slack.com/api/"
+
+// SocketEnvelope wraps every message received from slack over a socket mode connection
+type SocketEnvelope struct {
+	EnvelopeID             string          `json:"envelope_id"`
+	Type                   string          `json:"type"`
+	Reason                 string          `json:"reason"`
+	Payload                json.RawMessage `json:"payload"`
+	AcceptsResponsePayload bool            `json:"accepts_response_payload"`
+}
+
+// socketAck is sent back to slack to acknowledge an envelope
+type socketAck struct {
+	EnvelopeID string `json:"envelope_id"`
+}
+
+// SocketMode receives the events and interactions from slack over a websocket
+// instead of the public webhook endpoints
+type SocketMode struct {
+	// APIURL is the base url of the slack api, DefaultSocketModeURL when empty
+	APIURL string
+	// AppToken is the app-level token (xapp-) allowed to open connections
+	AppToken string
+	// RetryDelay is the delay before reconnecting after a connection error
+	RetryDelay time.Duration
+
+	OnEvent       func(EventRequest)
+	OnInteraction func(InteractivityRequest)
+	OnCommand     func(CommandRequest)
+}
+
+// OpenConnection asks slack for the url of a new websocket connection
+func (s SocketMode) OpenConnection() (string, error) {
+	apiURL := s.APIURL
+	if apiURL == "" {
+		apiURL = DefaultSocketModeURL
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(apiURL, "/")+"/apps.connections.open", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Authorization", "Bearer "+s.AppToken)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Errorf("Error while closing body %s", err)
+		}
+	}()
+
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("could not open socket mode connection: (%d) %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var r ApiResponse
+	if err = json.Unmarshal(bodyBytes, &r); err != nil {
+		return "", err
+	}
+	if !r.Ok {
+		return "", fmt.Errorf("could not open socket mode connection: %s", r.Error)
+	}
+	return r.URL, nil
+}
+
+// Run connects to slack and dispatches the envelopes until the context is cancelled.
+// The connection is opened again every time slack closes it
+func (s SocketMode) Run(ctx context.Context) {
+	retryDelay := s.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = 5 * time.Second
+	}
+
+	for {
+		err := s.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Errorf("Socket mode connection lost: %s", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+		}
+		log.Debug("Reconnecting to slack socket mode")
+	}
+}
+
+// listen reads the envelopes of a single connection until it is closed
+func (s SocketMode) listen(ctx context.Context) error {
+	wsURL, err := s.OpenConnection()
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Debugf("Error while closing socket mode connection %s", err)
+		}
+	}()
+
+	// unblock the read loop when the context is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	log.Info("Connected to slack socket mode")
+	for {
+		var envelope SocketEnvelope
+		if err := conn.ReadJSON(&envelope); err != nil {
+			return err
+		}
+
+		if envelope.EnvelopeID != "" {
+			if err := conn.WriteJSON(socketAck{EnvelopeID: envelope.EnvelopeID}); err != nil {
+				return err
+			}
+		}
+
+		if envelope.Type == "disconnect" {
+			log.WithFields(log.Fields{"reason": envelope.Reason}).Debug("Socket mode connection closed by slack")
+			return nil
+		}
+
+		if err := s.dispatch(envelope); err != nil {
+			log.WithFields(log.Fields{"envelope": envelope.EnvelopeID, "type": envelope.Type}).Errorf("Could not dispatch socket mode envelope: %s", err)
+		}
+	}
+}
+
+// dispatch passes the payload of the envelope to the matching callback
+func (s SocketMode) dispatch(envelope SocketEnvelope) error {
+	switch envelope.Type {
+	case "hello":
+		return nil
+	case "events_api":
+		var request EventRequest
+		if err := json.Unmarshal(envelope.Payload, &request); err != nil {
+			return err
+		}
+		if s.OnEvent != nil {
+			go s.OnEvent(request)
+		}
+	case "interactive":
+		var request InteractivityRequest
+		if err := json.Unmarshal(envelope.Payload, &request); err != nil {
+			return err
+		}
+		if s.OnInteraction != nil {
+			go s.OnInteraction(request)
+		}
+	case "slash_commands":
+		var request CommandRequest
+		if err := json.Unmarshal(envelope.Payload, &request); err != nil {
+			return err
+		}
+		if s.OnCommand != nil {
+			go s.OnCommand(request)
+		}
+	default:
+		return errors.New("unknown envelope type")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package slack_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/leboncoin/subot/pkg/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSocketServer mimics the slack socket mode api.
+// Each connection sends the envelopes of the matching session then closes
+type fakeSocketServer struct {
+	server   *httptest.Server
+	sessions [][]string
+	mu       sync.Mutex
+	opened   int
+	acks     []string
+}
+
+func newFakeSocketServer(t *testing.T, sessions ...[]string) *fakeSocketServer {
+	f := &fakeSocketServer{sessions: sessions}
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/apps.connections.open", func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer xapp-token" {
+			_, _ = res.Write([]byte(`{"ok": false, "error": "invalid_auth"}`))
+			return
+		}
+		wsURL := "ws" + strings.TrimPrefix(f.server.URL, "http") + "/link"
+		_, _ = res.Write([]byte(`{"ok": true, "url": "` + wsURL + `"}`))
+	})
+	mux.HandleFunc("/link", func(res http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(res, req, nil)
+		if err != nil {
+			t.Errorf("could not upgrade connection: %s", err)
+			return
+		}
+		defer conn.Close()
+
+		f.mu.Lock()
+		session := f.opened
+		f.opened++
+		f.mu.Unlock()
+		if session >= len(f.sessions) {
+			// keep the last connection open until the client leaves
+			_, _, _ = conn.ReadMessage()
+			return
+		}
+
+		for _, envelope := range f.sessions[session] {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(envelope)); err != nil {
+				return
+			}
+			var ack map[string]string
+			if strings.Contains(envelope, "envelope_id") {
+				if err := conn.ReadJSON(&ack); err != nil {
+					return
+				}
+				f.mu.Lock()
+				f.acks = append(f.acks, ack["envelope_id"])
+				f.mu.Unlock()
+			}
+		}
+	})
+	f.server = httptest.NewServer(mux)
+	return f
+}
+
+func (f *fakeSocketServer) getAcks() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.acks...)
+}
+
+func TestOpenConnection(t *testing.T) {
+	fake := newFakeSocketServer(t)
+	defer fake.server.Close()
+
+	s := slack.SocketMode{APIURL: fake.server.URL, AppToken: "xapp-token"}
+	wsURL, err := s.OpenConnection()
+	assert.Nil(t, err, "connection shall be opened")
+	assert.True(t, strings.HasPrefix(wsURL, "ws://"), "websocket url shall be returned")
+
+	s.AppToken = "wrong"
+	_, err = s.OpenConnection()
+	assert.NotNil(t, err, "invalid token shall be rejected")
+}
+
+func TestSocketModeDispatch(t *testing.T) {
+	event, _ := json.Marshal(slack.EventRequest{Type: "event_callback", Event: slack.Event{Type: "message", Channel: "CLK7MCUS3", Ts: "1592397637.000100"}})
+	interaction, _ := json.Marshal(slack.InteractivityRequest{Type: "block_actions", Channel: slack.InteractivityChannel{ID: "CLK7MCUS3"}})
+	fake := newFakeSocketServer(t,
+		[]string{
+			`{"type": "hello"}`,
+			`{"envelope_id": "1", "type": "events_api", "payload": ` + string(event) + `}`,
+			`{"type": "disconnect", "reason": "refresh_requested"}`,
+		},
+		[]string{
+			`{"envelope_id": "2", "type": "interactive", "payload": ` + string(interaction) + `}`,
+			`{"envelope_id": "3", "type": "slash_commands", "payload": {"command": "/incident", "channel_id": "CLK7MCUS3"}}`,
+		},
+	)
+	defer fake.server.Close()
+
+	events := make(chan slack.EventRequest, 1)
+	interactions := make(chan slack.InteractivityRequest, 1)
+	commands := make(chan slack.CommandRequest, 1)
+	s := slack.SocketMode{
+		APIURL:        fake.server.URL,
+		AppToken:      "xapp-token",
+		RetryDelay:    10 * time.Millisecond,
+		OnEvent:       func(r slack.EventRequest) { events <- r },
+		OnInteraction: func(r slack.InteractivityRequest) { interactions <- r },
+		OnCommand:     func(r slack.CommandRequest) { commands <- r },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case e := <-events:
+		assert.Equal(t, "1592397637.000100", e.Event.Ts, "event shall be dispatched")
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was not dispatched")
+	}
+
+	select {
+	case i := <-interactions:
+		assert.Equal(t, "CLK7MCUS3", i.Channel.ID, "interaction shall be dispatched after reconnection")
+	case <-time.After(2 * time.Second):
+		t.Fatal("interaction was not dispatched")
+	}
+
+	select {
+	case c := <-commands:
+		assert.Equal(t, "/incident", c.Command, "command shall be dispatched")
+	case <-time.After(2 * time.Second):
+		t.Fatal("command was not dispatched")
+	}
+
+	// the last ack may still be in flight when the command is dispatched
+	deadline := time.Now().Add(2 * time.Second)
+	for len(fake.getAcks()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, fake.getAcks(), "every envelope shall be acknowledged")
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("socket mode did not stop with its context")
+	}
+}
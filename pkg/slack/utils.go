@@ -33,6 +33,7 @@ func (s Slack) GetMessage(e Event) globals.Message {
 	}
 	return globals.Message{
 		Type:         s.GetMessageType(e),
+		Channel:      e.Channel,
 		Text:         e.Text,
 		UserID:       e.User,
 		UserName:     apiResponse.User.Name,
@@ -51,6 +52,7 @@ func (s Slack) GetUpdatedMessage(e Event) globals.Message {
 	apiResponse := s.ReadUser(e.User)
 	return globals.Message{
 		Type:         s.GetMessageType(e),
+		Channel:      e.Channel,
 		Text:         e.Message.Text,
 		UserID:       e.Message.UserID,
 		UserName:     apiResponse.User.Name,
@@ -68,10 +70,12 @@ func (s Slack) GetUpdatedMessage(e Event) globals.Message {
 func (s Slack) GetReaction(e Event) globals.Reaction {
 	return globals.Reaction{
 		Name:      e.Reaction,
+		Channel:   e.Item.Channel,
 		MessageTs: e.Item.Ts,
 		Users:     []string{e.User},
 		Timestamp: strconv.FormatInt(time.Now().Unix(), 10),
 		Count:     0,
+		Removed:   e.Type == "reaction_removed",
 	}
 }
 
@@ -80,6 +84,7 @@ func (s Slack) GetReply(e Event) globals.Reply {
 	apiResponse := s.ReadUser(e.User)
 	return globals.Reply{
 		ThreadTs:  e.ThreadTs,
+		Channel:   e.Channel,
 		UserID:    e.User,
 		UserName:  apiResponse.User.Name,
 		UserInfo:  apiResponse.User,
@@ -95,7 +100,7 @@ func (s Slack) GetMessageReplies(e Event) (replies []globals.Reply) {
 		return e.Message.Replies
 	}
 
-	apiResponse, err := s.ReadReplies(e.Ts)
+	apiResponse, err := s.ReadReplies(e.Channel, e.Ts)
 
 	if err != nil {
 		return []globals.Reply{}
@@ -107,6 +112,7 @@ func (s Slack) GetMessageReplies(e Event) (replies []globals.Reply) {
 		}
 		replies = append(replies, globals.Reply{
 			ThreadTs:  message.ThreadTs,
+			Channel:   e.Channel,
 			UserID:    message.User,
 			UserName:  apiResponse.User.Name,
 			UserInfo:  apiResponse.User,
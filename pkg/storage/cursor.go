@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+)
+
+// Position is the position of a message in a page sorted by a value, then by timestamp, channel and document ID.
+// The position of the last message of a page is encoded in the cursor of the next one
+type Position struct {
+	Sort    float64 `json:"sort"`
+	Ts      float64 `json:"ts"`
+	Channel string  `json:"channel"`
+	ID      string  `json:"id"`
+}
+
+// Compare returns -1, 0 or 1 when the position comes before, at or after the other one, in ascending order
+func (p Position) Compare(other Position) int {
+	switch {
+	case p.Sort != other.Sort:
+		return compare(p.Sort < other.Sort)
+	case p.Ts != other.Ts:
+		return compare(p.Ts < other.Ts)
+	case p.Channel != other.Channel:
+		return compare(p.Channel < other.Channel)
+	case p.ID != other.ID:
+		return compare(p.ID < other.ID)
+	}
+	return 0
+}
+
+func compare(before bool) int {
+	if before {
+		return -1
+	}
+	return 1
+}
+
+// EncodeCursor turns the position of the last message of a page into an opaque cursor
+func EncodeCursor(position interface{}) (string, error) {
+	b, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor reads the position encoded in the cursor, elastic.ErrInvalidCursor is returned
+// when the cursor was not created by EncodeCursor
+func DecodeCursor(cursor string, position interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return elastic.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, position); err != nil {
+		return elastic.ErrInvalidCursor
+	}
+	return nil
+}
+
+// NewID returns a random document ID, like the ones generated by elasticsearch
+func NewID() string {
+	b := make([]byte, 15)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
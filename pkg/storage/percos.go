@@ -0,0 +1,52 @@
+// Package storage computes in the service what elasticsearch computes for the storages without a search engine:
+// the matching of the labels and tools, the statistics of the messages and the cursors of their pages
+package storage
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	log "github.com/sirupsen/logrus"
+)
+
+// Terms splits a text into the lowercase words elasticsearch indexes with its standard analyzer
+func Terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// Keywords returns the Terms of a text which are not stop words, the ones telling whether two messages are similar
+func Keywords(text string) []string {
+	var keywords []string
+	for _, term := range Terms(text) {
+		if !globals.IsStopWord(term) {
+			keywords = append(keywords, term)
+		}
+	}
+	return keywords
+}
+
+// MatchPercos returns the names of the labels or tools whose regexp matches a word of the text,
+// like the percolator matching their regexp query against the analyzed input.
+// The regexp shall match the whole word, invalid ones never match
+func MatchPercos(percos []globals.Perco, text string) []string {
+	terms := Terms(text)
+	var names []string
+	for _, perco := range percos {
+		re, err := regexp.Compile("^(?:" + perco.Query.Regexp.Input + ")$")
+		if err != nil {
+			log.WithFields(log.Fields{"name": perco.Name, "error": err}).Warn("Invalid regexp")
+			continue
+		}
+		for _, term := range terms {
+			if re.MatchString(term) {
+				names = append(names, perco.Name)
+				break
+			}
+		}
+	}
+	return names
+}
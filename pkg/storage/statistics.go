@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// ParseBound converts the bound of a range of messages, an epoch in seconds or a date, into an epoch in seconds.
+// The end of a range being inclusive, an upper date bound is the last instant of the day
+func ParseBound(bound string, upper bool) (float64, error) {
+	if epoch, err := strconv.ParseFloat(bound, 64); err == nil {
+		return epoch, nil
+	}
+	date, err := time.Parse(globals.DateLayout, bound)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, bound)
+		if err != nil {
+			return 0, fmt.Errorf("invalid range bound %s, shall be an epoch or a date", bound)
+		}
+		return float64(date.UnixNano()) / float64(time.Second), nil
+	}
+	if upper {
+		return float64(date.AddDate(0, 0, 1).Unix()) - 1e-6, nil
+	}
+	return float64(date.Unix()), nil
+}
+
+// Answered tells whether a team member replied to the message
+func Answered(message globals.Message) bool {
+	return message.Status != globals.StatusUnresponded
+}
+
+// Closed tells whether the message has one of the closing statuses
+func Closed(message globals.Message, closing []globals.MessageStatus) bool {
+	for _, status := range closing {
+		if message.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AggregateAnswers counts the messages each answer was sent to and their useful and useless feedback, by answer ID
+func AggregateAnswers(messages []globals.Message) []globals.AnswerStatistics {
+	counts := make(map[string]*globals.AnswerStatistics)
+	for _, message := range messages {
+		for _, id := range message.Answers {
+			if counts[id] == nil {
+				counts[id] = &globals.AnswerStatistics{AnswerID: id}
+			}
+			counts[id].Sent++
+			switch message.FeedbackStatus {
+			case globals.UsefulFeedback:
+				counts[id].Useful++
+			case globals.UselessFeedback:
+				counts[id].Useless++
+			}
+		}
+	}
+	statistics := []globals.AnswerStatistics{}
+	for _, count := range counts {
+		statistics = append(statistics, *count)
+	}
+	sort.Slice(statistics, func(i, j int) bool { return statistics[i].AnswerID < statistics[j].AnswerID })
+	return statistics
+}
+
+// Aggregate computes the number of messages, of each status and the distribution of their response and resolution times.
+// Response times are measured on answered messages, resolution times on the ones with a closing status
+func Aggregate(messages []globals.Message, closing []globals.MessageStatus) globals.Aggregations {
+	aggregations := globals.Aggregations{
+		Count:         len(messages),
+		Statuses:      make(map[globals.MessageStatus]int),
+		Distributions: make(map[string]globals.Distribution),
+	}
+	durations := map[string][]float64{
+		"response_time":            {},
+		"business_response_time":   {},
+		"resolution_time":          {},
+		"business_resolution_time": {},
+	}
+	for _, m := range messages {
+		aggregations.Statuses[m.Status]++
+		if Answered(m) {
+			durations["response_time"] = append(durations["response_time"], float64(m.ResponseTime))
+			durations["business_response_time"] = append(durations["business_response_time"], float64(m.BusinessResponseTime))
+		}
+		if Closed(m, closing) {
+			durations["resolution_time"] = append(durations["resolution_time"], float64(m.ResolutionTime))
+			durations["business_resolution_time"] = append(durations["business_resolution_time"], float64(m.BusinessResolutionTime))
+		}
+	}
+	for field, values := range durations {
+		aggregations.Distributions[field] = globals.Distribution{
+			Count:       len(values),
+			Mean:        mean(values),
+			Percentiles: Percentiles(values),
+			Histogram:   histogram(values),
+		}
+	}
+	return aggregations
+}
+
+// mean returns the average of the durations, zero when there is none
+func mean(values []float64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.
+	for _, v := range values {
+		sum += v
+	}
+	return time.Duration(sum / float64(len(values)))
+}
+
+// histogram counts the durations in each of the globals.DurationBuckets
+func histogram(values []float64) []globals.Bucket {
+	var buckets []globals.Bucket
+	var from time.Duration
+	for _, to := range globals.DurationBuckets {
+		buckets = append(buckets, globals.Bucket{From: from, To: to})
+		from = to
+	}
+	buckets = append(buckets, globals.Bucket{From: from})
+	for _, v := range values {
+		for i := range buckets {
+			b := &buckets[i]
+			if v >= float64(b.From) && (b.To == 0 || v < float64(b.To)) {
+				b.Count++
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// Percentiles returns the 50th, 90th and 99th percentiles of the durations, interpolated between the closest ones
+func Percentiles(values []float64) globals.Percentiles {
+	if len(values) == 0 {
+		return globals.Percentiles{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	percentile := func(p float64) time.Duration {
+		rank := p / 100 * float64(len(sorted)-1)
+		low := int(math.Floor(rank))
+		high := int(math.Ceil(rank))
+		return time.Duration(sorted[low] + (sorted[high]-sorted[low])*(rank-float64(low)))
+	}
+	return globals.Percentiles{P50: percentile(50), P90: percentile(90), P99: percentile(99)}
+}
+
+// groupKeys returns the values of the field of the message the time series are grouped by
+var groupKeys = map[string]func(globals.Message) []string{
+	"label":  func(m globals.Message) []string { return m.Labels },
+	"tool":   func(m globals.Message) []string { return m.Tools },
+	"status": func(m globals.Message) []string { return []string{string(m.Status)} },
+	"user":   func(m globals.Message) []string { return []string{m.UserID} },
+}
+
+// bucketStart returns the first day of the interval of the date: the day itself, the monday of its week or the first of its month
+func bucketStart(date time.Time, interval string) (time.Time, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	switch interval {
+	case "day":
+		return day, nil
+	case "week":
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7), nil
+	case "month":
+		return day.AddDate(0, 0, 1-day.Day()), nil
+	}
+	return day, fmt.Errorf("cannot bucket time series by %s", interval)
+}
+
+// nextBucket returns the first day of the interval following the one starting at the date
+func nextBucket(date time.Time, interval string) time.Time {
+	switch interval {
+	case "week":
+		return date.AddDate(0, 0, 7)
+	case "month":
+		return date.AddDate(0, 1, 0)
+	}
+	return date.AddDate(0, 0, 1)
+}
+
+// TimeSeries buckets the messages by date, and optionally by label, tool, status or user,
+// with the percentiles of their response and resolution times.
+// The buckets go from the one of the first message to the one of the last, empty ones included
+func TimeSeries(messages []globals.Message, q globals.TimeSeriesQuery) (globals.TimeSeries, error) {
+	series := globals.TimeSeries{Interval: q.Interval, GroupBy: q.GroupBy, Buckets: []globals.TimeBucket{}}
+
+	keys, grouped := groupKeys[q.GroupBy]
+	if q.GroupBy != "" && !grouped {
+		return series, fmt.Errorf("cannot group time series by %s", q.GroupBy)
+	}
+	location, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		return series, fmt.Errorf("invalid time zone %s : %s", q.TimeZone, err)
+	}
+
+	buckets := make(map[string][]globals.Message)
+	var first, last time.Time
+	for _, m := range messages {
+		start, err := bucketStart(globals.ParseTimestamp(m.Timestamp).In(location), q.Interval)
+		if err != nil {
+			return series, err
+		}
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if last.IsZero() || start.After(last) {
+			last = start
+		}
+		key := start.Format(globals.DateLayout)
+		buckets[key] = append(buckets[key], m)
+	}
+	if len(buckets) == 0 {
+		return series, nil
+	}
+
+	for date := first; !date.After(last); date = nextBucket(date, q.Interval) {
+		key := date.Format(globals.DateLayout)
+		bucketMessages := buckets[key]
+		b := globals.TimeBucket{Date: key, Count: len(bucketMessages)}
+		b.ResponseTime, b.ResolutionTime = latencies(bucketMessages, q.Closing)
+		if grouped {
+			b.Groups = groups(bucketMessages, keys, q.Closing)
+		}
+		series.Buckets = append(series.Buckets, b)
+	}
+	return series, nil
+}
+
+// latencies returns the percentiles of the response times of the answered messages and of the resolution times of the closed ones
+func latencies(messages []globals.Message, closing []globals.MessageStatus) (response globals.Percentiles, resolution globals.Percentiles) {
+	var responseTimes, resolutionTimes []float64
+	for _, m := range messages {
+		if Answered(m) {
+			responseTimes = append(responseTimes, float64(m.ResponseTime))
+		}
+		if Closed(m, closing) {
+			resolutionTimes = append(resolutionTimes, float64(m.ResolutionTime))
+		}
+	}
+	return Percentiles(responseTimes), Percentiles(resolutionTimes)
+}
+
+// groups splits the messages of a bucket by the values of their field, the largest groups first
+func groups(messages []globals.Message, keys func(globals.Message) []string, closing []globals.MessageStatus) []globals.TimeGroup {
+	byKey := make(map[string][]globals.Message)
+	for _, m := range messages {
+		for _, key := range keys(m) {
+			byKey[key] = append(byKey[key], m)
+		}
+	}
+	var result []globals.TimeGroup
+	for key, groupMessages := range byKey {
+		g := globals.TimeGroup{Key: key, Count: len(groupMessages)}
+		g.ResponseTime, g.ResolutionTime = latencies(groupMessages, closing)
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
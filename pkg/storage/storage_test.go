@@ -0,0 +1,123 @@
+package storage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPercos(t *testing.T) {
+	percos := []globals.Perco{
+		{Name: "vault", Query: globals.Query{Regexp: globals.Regexp{Input: "vault"}}},
+		{Name: "rights", Query: globals.Query{Regexp: globals.Regexp{Input: "rights?|access"}}},
+		{Name: "kafka", Query: globals.Query{Regexp: globals.Regexp{Input: "kafka"}}},
+		{Name: "invalid", Query: globals.Query{Regexp: globals.Regexp{Input: "("}}},
+	}
+
+	names := storage.MatchPercos(percos, "Hi, I need the RIGHTS on Vault-prod")
+	assert.Equal(t, []string{"vault", "rights"}, names)
+	assert.Empty(t, storage.MatchPercos(percos, "kafkaesque"), "regexps shall match whole words")
+}
+
+func TestKeywords(t *testing.T) {
+	assert.Equal(t, []string{"hi", "need", "rights", "vault", "prod"}, storage.Keywords("Hi, I need the RIGHTS on Vault-prod"))
+	assert.Equal(t, []string{"token", "vault", "expiré"}, storage.Keywords("Mon token vault a expiré"))
+	assert.Empty(t, storage.Keywords("it is on the"), "stop words shall be left out")
+}
+
+func TestParseBound(t *testing.T) {
+	start, err := storage.ParseBound("2020-06-15", false)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1592179200), start)
+
+	end, err := storage.ParseBound("2020-06-15", true)
+	assert.NoError(t, err)
+	assert.True(t, end > 1592265599 && end < 1592265600, "the end of a range shall include its whole day")
+
+	epoch, err := storage.ParseBound("1592208201.000100", true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1592208201.0001, epoch)
+
+	_, err = storage.ParseBound("yesterday", false)
+	assert.Error(t, err)
+}
+
+func TestAggregate(t *testing.T) {
+	messages := []globals.Message{
+		{Status: globals.StatusFixed, ResponseTime: 10, ResolutionTime: 100},
+		{Status: globals.StatusFixed, ResponseTime: 30, ResolutionTime: 2000},
+		{Status: globals.StatusResponded, ResponseTime: 50},
+		{Status: globals.StatusUnresponded},
+	}
+
+	aggregations := storage.Aggregate(messages, []globals.MessageStatus{globals.StatusFixed})
+	assert.Equal(t, 4, aggregations.Count)
+	assert.Equal(t, map[globals.MessageStatus]int{
+		globals.StatusFixed:       2,
+		globals.StatusResponded:   1,
+		globals.StatusUnresponded: 1,
+	}, aggregations.Statuses)
+
+	response := aggregations.Distributions["response_time"]
+	assert.Equal(t, 3, response.Count)
+	assert.Equal(t, time.Duration(30), response.Mean)
+	assert.Equal(t, time.Duration(30), response.P50)
+	assert.Equal(t, []globals.Bucket{
+		{From: 0, To: 15, Count: 1},
+		{From: 15, To: 60, Count: 2},
+		{From: 60, To: 240},
+		{From: 240, To: 480},
+		{From: 480, To: 1440},
+		{From: 1440},
+	}, response.Histogram)
+
+	resolution := aggregations.Distributions["resolution_time"]
+	assert.Equal(t, 2, resolution.Count)
+	assert.Equal(t, time.Duration(1050), resolution.Mean)
+	assert.Equal(t, time.Duration(1050), resolution.P50)
+	assert.Equal(t, 1, resolution.Histogram[5].Count)
+}
+
+func TestTimeSeries(t *testing.T) {
+	messages := []globals.Message{
+		// wednesday 2020-06-17 and thursday 2020-06-18
+		{Timestamp: "1592388000.000100", Status: globals.StatusFixed, Labels: []string{"rights", "vault"}, ResponseTime: 10},
+		{Timestamp: "1592474400.000100", Status: globals.StatusUnresponded, Labels: []string{"rights"}},
+		// monday 2020-06-29
+		{Timestamp: "1593424800.000100", Status: globals.StatusFixed, ResponseTime: 20},
+	}
+
+	series, err := storage.TimeSeries(messages, globals.TimeSeriesQuery{Interval: "week", GroupBy: "label", TimeZone: "Europe/Paris"})
+	assert.NoError(t, err)
+	assert.Len(t, series.Buckets, 3, "empty weeks between the first and the last one shall be returned")
+	assert.Equal(t, "2020-06-15", series.Buckets[0].Date)
+	assert.Equal(t, 2, series.Buckets[0].Count)
+	assert.Equal(t, time.Duration(10), series.Buckets[0].ResponseTime.P50)
+	assert.Equal(t, []string{"rights", "vault"}, []string{series.Buckets[0].Groups[0].Key, series.Buckets[0].Groups[1].Key})
+	assert.Equal(t, 2, series.Buckets[0].Groups[0].Count)
+	assert.Equal(t, "2020-06-22", series.Buckets[1].Date)
+	assert.Equal(t, 0, series.Buckets[1].Count)
+	assert.Equal(t, "2020-06-29", series.Buckets[2].Date)
+
+	series, err = storage.TimeSeries(messages, globals.TimeSeriesQuery{Interval: "month"})
+	assert.NoError(t, err)
+	assert.Len(t, series.Buckets, 1)
+	assert.Equal(t, "2020-06-01", series.Buckets[0].Date)
+
+	_, err = storage.TimeSeries(messages, globals.TimeSeriesQuery{Interval: "day", GroupBy: "channel"})
+	assert.Error(t, err)
+}
+
+func TestCursor(t *testing.T) {
+	cursor, err := storage.EncodeCursor([]string{"a", "b"})
+	assert.NoError(t, err)
+
+	var position []string
+	assert.NoError(t, storage.DecodeCursor(cursor, &position))
+	assert.Equal(t, []string{"a", "b"}, position)
+	assert.Equal(t, elastic.ErrInvalidCursor, storage.DecodeCursor("not a cursor", &position))
+}
@@ -0,0 +1,762 @@
+// Package storagetest is the contract every storage of the service fulfils, whatever holds the documents.
+// Each storage runs the suite from its own tests against an empty database
+package storagetest
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Suite is the contract run against a storage
+type Suite struct {
+	// Open returns the storage under test, without any document
+	Open func(t *testing.T) elastic.Interface
+	// Refresh makes the last writes visible to the searches, for the storages indexing them asynchronously
+	Refresh func(t *testing.T)
+}
+
+// Run runs every test of the contract
+func (s Suite) Run(t *testing.T) {
+	t.Run("Messages", s.testMessages)
+	t.Run("MessagesPage", s.testMessagesPage)
+	t.Run("SimilarMessages", s.testSimilarMessages)
+	t.Run("LastUserMessages", s.testLastUserMessages)
+	t.Run("ReminderMessages", s.testReminderMessages)
+	t.Run("Labels", s.testLabels)
+	t.Run("Tools", s.testTools)
+	t.Run("Answers", s.testAnswers)
+	t.Run("AnswerStatistics", s.testAnswerStatistics)
+	t.Run("Team", s.testTeam)
+	t.Run("Workflow", s.testWorkflow)
+	t.Run("Rotations", s.testRotations)
+	t.Run("EscalationPolicies", s.testEscalationPolicies)
+	t.Run("MessageTemplates", s.testMessageTemplates)
+	t.Run("Welcome", s.testWelcome)
+	t.Run("Firemen", s.testFiremen)
+	t.Run("Statistics", s.testStatistics)
+}
+
+func (s Suite) refresh(t *testing.T) {
+	if s.Refresh != nil {
+		s.Refresh(t)
+	}
+}
+
+// since is the time the messages of the tests are posted after
+var since = time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+
+// timestamp returns the slack timestamp of a message posted the given number of minutes after since
+func timestamp(minutes int) string {
+	return strconv.FormatInt(since.Add(time.Duration(minutes)*time.Minute).Unix(), 10) + ".000100"
+}
+
+// period returns the dates of the range holding every message of the tests
+func period() (start string, end string) {
+	return since.Add(-24 * time.Hour).Format(globals.DateLayout), time.Now().Add(24 * time.Hour).Format(globals.DateLayout)
+}
+
+// message returns a user message of the channel, replied by U2
+func message(channel string, minutes int, status globals.MessageStatus) globals.Message {
+	ts := timestamp(minutes)
+	return globals.Message{
+		Type:      globals.NewMessage,
+		Channel:   channel,
+		Status:    status,
+		Labels:    []string{"rights"},
+		Tools:     []string{"vault"},
+		Text:      "please give me the rights on vault",
+		UserID:    "U1",
+		Timestamp: ts,
+		Replies:   []globals.Reply{{UserID: "U2", Text: "done", Timestamp: ts, ThreadTs: ts, Channel: channel}},
+	}
+}
+
+func (s Suite) testMessages(t *testing.T) {
+	store := s.Open(t)
+	first := message("C1", 0, globals.StatusUnresponded)
+	second := message("C1", 1, globals.StatusFixed)
+	other := message("C2", 2, globals.StatusFixed)
+	thread := message("C1", 3, "")
+	thread.Type = globals.Thread
+	for _, m := range []globals.Message{first, second, other, thread} {
+		require.NoError(t, store.AddMessage(m))
+	}
+	s.refresh(t)
+
+	start, end := period()
+	messages, err := store.QueryRangeMessages("C1", start, end)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2, "only the user messages of the channel shall be returned")
+
+	messages, err = store.QueryRangeMessages("C1", first.Timestamp, first.Timestamp)
+	assert.NoError(t, err)
+	require.Len(t, messages, 1, "a message shall be found by its timestamp")
+	assert.Equal(t, globals.MessageID("C1", first.Timestamp), messages[0].ID)
+
+	stored, err := store.GetMessage("C1", first.Timestamp)
+	assert.NoError(t, err)
+	assert.Equal(t, messages[0].ID, stored.ID)
+	assert.Equal(t, "done", stored.Replies[0].Text)
+	assert.Equal(t, []string{"rights"}, stored.Labels)
+
+	_, err = store.GetMessage("C2", first.Timestamp)
+	assert.Equal(t, elastic.ErrMessageNotFound, err)
+
+	stored.Status = globals.StatusInProgress
+	assert.NoError(t, store.EditMessage("C1", first.Timestamp, stored))
+	assert.Error(t, store.EditMessage("C1", "", stored))
+	stored, err = store.GetMessage("C1", first.Timestamp)
+	assert.NoError(t, err)
+	assert.Equal(t, globals.StatusInProgress, stored.Status)
+	s.refresh(t)
+	messages, err = store.QueryRangeMessages("C1", first.Timestamp, first.Timestamp)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1, "editing a message shall not duplicate it")
+
+	require.NoError(t, store.AddMessage(second))
+	s.refresh(t)
+	messages, err = store.QueryRangeMessages("C1", start, end)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2, "storing a message twice shall not duplicate it")
+
+	twin := message("C2", 1, globals.StatusFixed)
+	require.NoError(t, store.AddMessage(twin))
+	assert.NoError(t, store.DeleteMessage("C1", second.Timestamp))
+	assert.Error(t, store.DeleteMessage("C1", ""))
+	s.refresh(t)
+	_, err = store.GetMessage("C1", second.Timestamp)
+	assert.Equal(t, elastic.ErrMessageNotFound, err)
+	_, err = store.GetMessage("C2", twin.Timestamp)
+	assert.NoError(t, err, "the message of another channel posted at the same time shall be kept")
+}
+
+func (s Suite) testMessagesPage(t *testing.T) {
+	store := s.Open(t)
+	oldest := message("C1", 0, globals.StatusFixed)
+	oldest.ResponseTime = 30
+	kafka := message("C1", 1, globals.StatusUnresponded)
+	kafka.Text, kafka.Labels, kafka.Tools, kafka.Replies = "a new topic on kafka", []string{"topic"}, []string{"kafka"}, nil
+	kafka.ResolutionNote = "the consumers were rebalanced"
+	latest := message("C1", 2, globals.StatusFixed)
+	latest.Text, latest.ResponseTime, latest.FeedbackStatus = "my secret is gone", 10, globals.UsefulFeedback
+	latest.Replies = []globals.Reply{{UserID: "U3", Text: "vault is sealed", Timestamp: latest.Timestamp}}
+	for _, m := range []globals.Message{oldest, kafka, latest, message("C2", 3, globals.StatusFixed)} {
+		require.NoError(t, store.AddMessage(m))
+	}
+	s.refresh(t)
+
+	start, end := period()
+	query := globals.MessageQuery{Channel: "C1", Start: start, End: end, Size: 2}
+	page, err := store.QueryMessagesPage(query)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{latest.Timestamp, kafka.Timestamp}, timestamps(page), "most recent messages shall come first")
+	require.NotEmpty(t, page.NextCursor)
+
+	query.Cursor = page.NextCursor
+	page, err = store.QueryMessagesPage(query)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{oldest.Timestamp}, timestamps(page))
+	assert.Empty(t, page.NextCursor, "the last page shall not have a next cursor")
+
+	query.Cursor = "not a cursor"
+	_, err = store.QueryMessagesPage(query)
+	assert.Equal(t, elastic.ErrInvalidCursor, err)
+
+	page, err = store.QueryMessagesPage(globals.MessageQuery{Channel: "C1", Start: start, End: end, Size: 10, Sort: "response_time", Ascending: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{kafka.Timestamp, latest.Timestamp, oldest.Timestamp}, timestamps(page))
+
+	yes, no := true, false
+	filters := []struct {
+		name     string
+		query    globals.MessageQuery
+		expected []string
+	}{
+		{"status", globals.MessageQuery{Status: globals.StatusFixed}, []string{latest.Timestamp, oldest.Timestamp}},
+		{"label", globals.MessageQuery{Label: "topic"}, []string{kafka.Timestamp}},
+		{"tool", globals.MessageQuery{Tool: "vault"}, []string{latest.Timestamp, oldest.Timestamp}},
+		{"user", globals.MessageQuery{User: "U1"}, []string{latest.Timestamp, kafka.Timestamp, oldest.Timestamp}},
+		{"fireman", globals.MessageQuery{Fireman: "U3"}, []string{latest.Timestamp}},
+		{"with feedback", globals.MessageQuery{HasFeedback: &yes}, []string{latest.Timestamp}},
+		{"without feedback", globals.MessageQuery{HasFeedback: &no}, []string{kafka.Timestamp, oldest.Timestamp}},
+		{"search", globals.MessageQuery{Search: "vault"}, []string{latest.Timestamp, oldest.Timestamp}},
+		{"resolution note", globals.MessageQuery{Search: "rebalanced"}, []string{kafka.Timestamp}},
+		{"range", globals.MessageQuery{Start: kafka.Timestamp, End: kafka.Timestamp}, []string{kafka.Timestamp}},
+	}
+	for _, filter := range filters {
+		q := filter.query
+		q.Channel, q.Size = "C1", 10
+		if q.Start == "" {
+			q.Start, q.End = start, end
+		}
+		page, err := store.QueryMessagesPage(q)
+		assert.NoError(t, err, filter.name)
+		assert.Equal(t, filter.expected, timestamps(page), filter.name)
+	}
+}
+
+func (s Suite) testSimilarMessages(t *testing.T) {
+	store := s.Open(t)
+	rights := message("C1", 0, globals.StatusFixed)
+	lag := message("C1", 1, globals.StatusFixed)
+	lag.Text, lag.ResolutionNote, lag.Replies = "the kafka consumer lags", "restarted the consumer group", nil
+	topic := message("C1", 2, globals.StatusFixed)
+	topic.Text, topic.Replies = "a new topic on kafka", nil
+	open := message("C1", 3, globals.StatusUnresponded)
+	for _, m := range []globals.Message{rights, lag, topic, open, message("C2", 4, globals.StatusFixed)} {
+		require.NoError(t, store.AddMessage(m))
+	}
+	s.refresh(t)
+
+	// the scores of the storages are on different scales, the tiny one keeps every match
+	const anyScore = 1e-6
+	similar := func(text string, size int, minScore float64) []string {
+		messages, err := store.QuerySimilarMessages("C1", text, size, minScore)
+		assert.NoError(t, err)
+		result := []string{}
+		for _, m := range messages {
+			assert.True(t, m.Score > 0, "the similar messages shall have a score")
+			result = append(result, m.Timestamp)
+		}
+		return result
+	}
+	assert.Equal(t, []string{rights.Timestamp}, similar("need rights for vault", 5, anyScore), "only the closed messages of the channel shall be similar")
+	assert.Equal(t, []string{lag.Timestamp}, similar("consumer group stuck", 5, anyScore), "the resolution notes shall be compared")
+	assert.Equal(t, []string{lag.Timestamp}, similar("kafka consumer lags again", 1, anyScore), "the most similar message shall come first")
+	assert.Empty(t, similar("nothing in common", 5, anyScore))
+	assert.Empty(t, similar("", 5, anyScore))
+	assert.Empty(t, similar("it is on the", 5, anyScore), "the stop words shall not be compared")
+	assert.Empty(t, similar("the kafka is down on my side", 5, 0), "a single word in common shall be below the default score")
+}
+
+func timestamps(page globals.MessagePage) []string {
+	result := []string{}
+	for _, m := range page.Messages {
+		result = append(result, m.Timestamp)
+	}
+	return result
+}
+
+func (s Suite) testLastUserMessages(t *testing.T) {
+	store := s.Open(t)
+	recent := message("C1", 0, globals.StatusUnresponded)
+	recent.Timestamp = strconv.FormatInt(time.Now().Add(-30*time.Second).Unix(), 10) + ".000100"
+	for _, m := range []globals.Message{recent, message("C1", 1, globals.StatusUnresponded)} {
+		require.NoError(t, store.AddMessage(m))
+	}
+	s.refresh(t)
+
+	messages, err := store.QueryLastUserMessages("C1", "U1")
+	assert.NoError(t, err)
+	require.Len(t, messages, 1, "only the messages of the last minutes shall be returned")
+	assert.Equal(t, recent.Timestamp, messages[0].Timestamp)
+
+	messages, err = store.QueryLastUserMessages("C1", "U2")
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func (s Suite) testReminderMessages(t *testing.T) {
+	store := s.Open(t)
+	now := time.Now().Unix()
+	due := message("C1", 0, globals.StatusUnresponded)
+	due.RemindAt = strconv.FormatInt(now-10, 10)
+	closed := message("C1", 1, globals.StatusFixed)
+	closed.RemindAt = strconv.FormatInt(now-10, 10)
+	later := message("C1", 2, globals.StatusInProgress)
+	later.RemindAt = strconv.FormatInt(now+3600, 10)
+	// wont_fix only closes the messages of C2
+	open := message("C1", 3, globals.StatusWontFix)
+	open.RemindAt = strconv.FormatInt(now-10, 10)
+	closedInChannel := message("C2", 4, globals.StatusWontFix)
+	closedInChannel.RemindAt = strconv.FormatInt(now-10, 10)
+	for _, m := range []globals.Message{due, closed, later, open, closedInChannel} {
+		require.NoError(t, store.AddMessage(m))
+	}
+	require.NoError(t, store.AddStatusRule(globals.StatusRule{ID: "c2-x", Channel: "C2", Emoji: "x", Status: globals.StatusWontFix, Closes: true}))
+	s.refresh(t)
+
+	messages, err := store.QueryReminderMessages()
+	assert.NoError(t, err)
+	require.Len(t, messages, 2, "only the open messages due for a reminder shall be returned")
+	timestamps := []string{messages[0].Timestamp, messages[1].Timestamp}
+	assert.ElementsMatch(t, []string{due.Timestamp, open.Timestamp}, timestamps, "statuses closing another channel shall be reminded")
+}
+
+// percos are the methods managing the labels or the tools
+type percos struct {
+	add    func(globals.Perco) error
+	edit   func(string, globals.Perco) error
+	delete func(string) error
+	get    func(string) ([]globals.Perco, error)
+	query  func(string, string) ([]string, error)
+	byName func(string) ([]globals.Perco, error)
+	// answer returns an answer using the label or the tool
+	answer func(string) globals.Answer
+}
+
+func (s Suite) testLabels(t *testing.T) {
+	store := s.Open(t)
+	s.testPercos(t, percos{
+		add: store.AddLabel, edit: store.EditLabel, delete: store.DeleteLabel,
+		get: store.GetLabels, query: store.QueryLabels, byName: store.QueryLabelByName,
+		answer: func(name string) globals.Answer { return globals.Answer{Label: name, Answer: "read the doc"} },
+	}, store)
+}
+
+func (s Suite) testTools(t *testing.T) {
+	store := s.Open(t)
+	s.testPercos(t, percos{
+		add: store.AddTool, edit: store.EditTool, delete: store.DeleteTool,
+		get: store.GetTools, query: store.QueryTools, byName: store.QueryToolByName,
+		answer: func(name string) globals.Answer { return globals.Answer{Tool: name, Answer: "read the doc"} },
+	}, store)
+}
+
+func (s Suite) testPercos(t *testing.T, p percos, store elastic.Interface) {
+	assert.Error(t, p.add(globals.Perco{Name: "empty"}), "a regexp shall be required")
+	vault := globals.Perco{ID: "vault", Name: "vault", Query: globals.Query{Regexp: globals.Regexp{Input: "vault"}}}
+	kafka := globals.Perco{ID: "kafka", Name: "kafka", Channel: "C2", Query: globals.Query{Regexp: globals.Regexp{Input: "kafka|topics?"}}}
+	require.NoError(t, p.add(vault))
+	require.NoError(t, p.add(kafka))
+	s.refresh(t)
+
+	names, err := p.query("C1", "Access to Vault please")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vault"}, names)
+	names, err = p.query("C1", "I need a new topic")
+	assert.NoError(t, err)
+	assert.Empty(t, names, "the percos of another channel shall not match")
+	names, err = p.query("C2", "I need a new topic")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kafka"}, names)
+
+	all, err := p.get("C1")
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+	all, err = p.get("")
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	found, err := p.byName("kafka")
+	assert.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "kafka", found[0].ID)
+
+	kafka.Channel = ""
+	require.NoError(t, p.edit("kafka", kafka))
+	assert.Error(t, p.edit("", kafka))
+	s.refresh(t)
+	names, err = p.query("C1", "I need a new topic")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kafka"}, names)
+
+	require.NoError(t, store.AddAnswer(p.answer("vault")))
+	s.refresh(t)
+	assert.Error(t, p.delete("vault"), "a perco used by an answer shall not be deleted")
+	assert.NoError(t, p.delete("kafka"))
+	s.refresh(t)
+	all, err = p.get("")
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+}
+
+func (s Suite) testAnswers(t *testing.T) {
+	store := s.Open(t)
+	require.NoError(t, store.AddLabel(globals.Perco{ID: "rights", Name: "rights", Query: globals.Query{Regexp: globals.Regexp{Input: "rights?"}}}))
+	require.NoError(t, store.AddTool(globals.Perco{ID: "vault", Name: "vault", Query: globals.Query{Regexp: globals.Regexp{Input: "vault"}}}))
+	s.refresh(t)
+
+	assert.Error(t, store.AddAnswer(globals.Answer{Label: "rights"}), "an answer shall not be empty")
+	assert.Error(t, store.AddAnswer(globals.Answer{Tool: "kafka", Answer: "ask the kafka team"}), "the tool shall exist")
+	for _, answer := range []globals.Answer{
+		{Channel: "C1", Tool: "vault", Label: "rights", Answer: "ask in #vault"},
+		{Label: "rights", Answer: "read the doc"},
+		{Channel: "C2", Label: "rights", Answer: "ask in #c2"},
+	} {
+		require.NoError(t, store.AddAnswer(answer))
+	}
+	s.refresh(t)
+
+	answers, err := store.QueryAnswers("C1", []string{"vault"}, []string{"rights"})
+	assert.NoError(t, err)
+	require.Len(t, answers, 1)
+	assert.Equal(t, "ask in #vault", answers[0].Answer)
+	assert.NotEmpty(t, answers[0].ID, "the matching answers shall have their ID")
+
+	answers, err = store.QueryAnswers("C1", nil, []string{"rights"})
+	assert.NoError(t, err)
+	require.Len(t, answers, 1, "answers without tool shall be returned when no tool is given")
+	assert.Equal(t, "read the doc", answers[0].Answer)
+
+	answers, err = store.GetAnswers("C1")
+	assert.NoError(t, err)
+	assert.Len(t, answers, 2)
+	answers, err = store.GetAnswers("")
+	assert.NoError(t, err)
+	require.Len(t, answers, 3)
+
+	var shared globals.Answer
+	for _, answer := range answers {
+		if answer.Answer == "read the doc" {
+			shared = answer
+		}
+	}
+	require.NotEmpty(t, shared.ID)
+	shared.Answer = "read the docs"
+	assert.NoError(t, store.EditAnswer(shared.ID, shared))
+	assert.Error(t, store.EditAnswer("", shared))
+	s.refresh(t)
+	answers, err = store.QueryAnswers("C1", nil, []string{"rights"})
+	assert.NoError(t, err)
+	require.Len(t, answers, 1)
+	assert.Equal(t, "read the docs", answers[0].Answer)
+
+	assert.NoError(t, store.DeleteAnswer(shared.ID))
+	s.refresh(t)
+	answers, err = store.GetAnswers("")
+	assert.NoError(t, err)
+	assert.Len(t, answers, 2)
+}
+
+func (s Suite) testAnswerStatistics(t *testing.T) {
+	store := s.Open(t)
+	useful := message("C1", 0, globals.StatusFixed)
+	useful.Answers, useful.FeedbackStatus = []string{"a1"}, globals.UsefulFeedback
+	useless := message("C1", 1, globals.StatusFixed)
+	useless.Answers, useless.FeedbackStatus = []string{"a1", "a2"}, globals.UselessFeedback
+	asked := message("C1", 2, globals.StatusUnresponded)
+	asked.Answers, asked.FeedbackStatus = []string{"a2"}, globals.AskedFeedback
+	other := message("C2", 3, globals.StatusFixed)
+	other.Answers, other.FeedbackStatus = []string{"a1"}, globals.UsefulFeedback
+	for _, m := range []globals.Message{useful, useless, asked, other, message("C1", 4, globals.StatusFixed)} {
+		require.NoError(t, store.AddMessage(m))
+	}
+	s.refresh(t)
+
+	statistics, err := store.AggregateAnswers("C1")
+	assert.NoError(t, err)
+	assert.Equal(t, []globals.AnswerStatistics{
+		{AnswerID: "a1", Sent: 2, Useful: 1, Useless: 1},
+		{AnswerID: "a2", Sent: 2, Useful: 0, Useless: 1},
+	}, statistics)
+
+	statistics, err = store.AggregateAnswers("")
+	assert.NoError(t, err)
+	require.Len(t, statistics, 2)
+	assert.Equal(t, globals.AnswerStatistics{AnswerID: "a1", Sent: 3, Useful: 2, Useless: 1}, statistics[0], "every channel shall be counted")
+}
+
+func (s Suite) testTeam(t *testing.T) {
+	store := s.Open(t)
+	require.NoError(t, store.AddTeamMember(globals.TeamMember{SlackID: "UB210NGRK", Name: "alice", Channel: "C1"}))
+	require.NoError(t, store.AddTeamMember(globals.TeamMember{SlackID: "UB210NGRL", Name: "bob"}))
+	s.refresh(t)
+
+	members := []struct {
+		channel, user string
+		expected      bool
+	}{
+		{"C1", "UB210NGRK", true},
+		{"C2", "UB210NGRK", false},
+		{"C2", "UB210NGRL", true},
+		{"C1", "UB210NGRM", false},
+	}
+	for _, member := range members {
+		isMember, err := store.IsTeamMember(member.channel, member.user)
+		assert.NoError(t, err)
+		assert.Equal(t, member.expected, isMember, "%s in %s", member.user, member.channel)
+	}
+
+	team, err := store.GetTeamMembers("C2")
+	assert.NoError(t, err)
+	require.Len(t, team, 1)
+	bob := team[0]
+	assert.Equal(t, "bob", bob.Name)
+	require.NotEmpty(t, bob.ID)
+
+	bob.Name = "robert"
+	assert.NoError(t, store.EditTeamMember(bob.ID, bob))
+	assert.Error(t, store.EditTeamMember("", bob))
+	s.refresh(t)
+	team, err = store.GetTeamMembers("C2")
+	assert.NoError(t, err)
+	require.Len(t, team, 1)
+	assert.Equal(t, "robert", team[0].Name)
+
+	assert.NoError(t, store.DeleteTeamMember(bob.ID))
+	assert.Error(t, store.DeleteTeamMember(""))
+	s.refresh(t)
+	team, err = store.GetTeamMembers("")
+	assert.NoError(t, err)
+	assert.Len(t, team, 1)
+}
+
+func (s Suite) testWorkflow(t *testing.T) {
+	store := s.Open(t)
+	workflow, err := store.GetWorkflow("C1")
+	assert.NoError(t, err)
+	assert.Equal(t, globals.DefaultWorkflow, workflow, "the default workflow shall be used without rules")
+
+	assert.Error(t, store.AddStatusRule(globals.StatusRule{Emoji: "eyes"}))
+	require.NoError(t, store.AddStatusRule(globals.StatusRule{ID: "wip", Emoji: "eyes", Status: globals.StatusInProgress}))
+	require.NoError(t, store.AddStatusRule(globals.StatusRule{ID: "c1-fixed", Channel: "C1", Emoji: "white_check_mark", Status: globals.StatusFixed, Closes: true}))
+	s.refresh(t)
+
+	workflow, err = store.GetWorkflow("C1")
+	assert.NoError(t, err)
+	assert.Len(t, workflow, 4, "the default rules shall be kept along with the rules of the channel")
+	assert.Equal(t, []globals.MessageStatus{globals.StatusFixed}, workflow.ClosingStatuses())
+	workflow, err = store.GetWorkflow("C2")
+	assert.NoError(t, err)
+	require.Len(t, workflow, 3)
+	assert.Equal(t, "wip", workflow[0].ID)
+	assert.True(t, workflow.IsClosing(globals.StatusFixed), "a rule shall not drop the default closing rule")
+
+	assert.Error(t, store.EditStatusRule("wip", globals.StatusRule{Emoji: "eyes"}))
+	require.NoError(t, store.EditStatusRule("wip", globals.StatusRule{Emoji: "hourglass", Status: globals.StatusInProgress}))
+	s.refresh(t)
+	workflow, err = store.GetWorkflow("C2")
+	assert.NoError(t, err)
+	require.Len(t, workflow, 3)
+	assert.Equal(t, "hourglass", workflow[0].Emoji)
+
+	assert.NoError(t, store.DeleteStatusRule("wip"))
+	s.refresh(t)
+	workflow, err = store.GetWorkflow("C2")
+	assert.NoError(t, err)
+	assert.Equal(t, globals.DefaultWorkflow, workflow)
+}
+
+func (s Suite) testRotations(t *testing.T) {
+	store := s.Open(t)
+	rotations, err := store.GetRotations("C1")
+	assert.NoError(t, err)
+	assert.Empty(t, rotations, "no rotation shall be defined")
+
+	assert.Error(t, store.AddRotation(globals.Rotation{Start: "2020-06-01"}))
+	require.NoError(t, store.AddRotation(globals.Rotation{ID: "shared", Roster: []string{"U1"}, Start: "2020-06-01"}))
+	require.NoError(t, store.AddRotation(globals.Rotation{
+		ID:        "c1",
+		Channel:   "C1",
+		Roster:    []string{"U1", "U2"},
+		Start:     "2020-06-01",
+		ShiftDays: 14,
+		Topic:     "Pompier : {fireman}",
+		Overrides: []globals.RotationOverride{{SlackID: "U2", Replacement: "U1", Start: "2020-06-15", End: "2020-06-16"}},
+	}))
+	s.refresh(t)
+
+	rotations, err = store.GetRotations("C1")
+	assert.NoError(t, err)
+	assert.Len(t, rotations, 2)
+	rotation, ok := globals.RotationFor(rotations, "C1")
+	require.True(t, ok)
+	assert.Equal(t, "c1", rotation.ID)
+	assert.Equal(t, []string{"U1", "U2"}, rotation.Roster)
+	assert.Equal(t, 14, rotation.ShiftDays)
+	assert.Equal(t, []globals.RotationOverride{{SlackID: "U2", Replacement: "U1", Start: "2020-06-15", End: "2020-06-16"}}, rotation.Overrides)
+	rotations, err = store.GetRotations("C2")
+	assert.NoError(t, err)
+	require.Len(t, rotations, 1)
+	assert.Equal(t, "shared", rotations[0].ID)
+
+	assert.Error(t, store.EditRotation("shared", globals.Rotation{Start: "2020-06-01"}))
+	require.NoError(t, store.EditRotation("shared", globals.Rotation{Roster: []string{"U1", "U3"}, Start: "2020-06-01"}))
+	s.refresh(t)
+	rotations, err = store.GetRotations("C2")
+	assert.NoError(t, err)
+	require.Len(t, rotations, 1)
+	assert.Equal(t, []string{"U1", "U3"}, rotations[0].Roster)
+
+	assert.NoError(t, store.DeleteRotation("shared"))
+	s.refresh(t)
+	rotations, err = store.GetRotations("C2")
+	assert.NoError(t, err)
+	assert.Empty(t, rotations)
+}
+
+func (s Suite) testEscalationPolicies(t *testing.T) {
+	store := s.Open(t)
+	policies, err := store.GetEscalationPolicies("C1")
+	assert.NoError(t, err)
+	assert.Empty(t, policies, "no escalation policy shall be defined")
+
+	fireman := []globals.EscalationStep{{After: "1h", Target: globals.EscalateFireman}}
+	assert.Error(t, store.AddEscalationPolicy(globals.EscalationPolicy{Tool: "vault"}))
+	require.NoError(t, store.AddEscalationPolicy(globals.EscalationPolicy{ID: "shared", Steps: fireman}))
+	require.NoError(t, store.AddEscalationPolicy(globals.EscalationPolicy{
+		ID:      "vault",
+		Channel: "C1",
+		Label:   "rights",
+		Tool:    "vault",
+		Steps: []globals.EscalationStep{
+			{After: "30m", Target: globals.EscalateFireman},
+			{After: "2h", Target: globals.EscalateDirect, SlackID: "U3"},
+		},
+	}))
+	s.refresh(t)
+
+	policies, err = store.GetEscalationPolicies("C1")
+	assert.NoError(t, err)
+	assert.Len(t, policies, 2)
+	policy, ok := globals.EscalationPolicyFor(policies, message("C1", 0, globals.StatusUnresponded))
+	require.True(t, ok)
+	assert.Equal(t, "vault", policy.ID)
+	assert.Equal(t, []globals.EscalationStep{
+		{After: "30m", Target: globals.EscalateFireman},
+		{After: "2h", Target: globals.EscalateDirect, SlackID: "U3"},
+	}, policy.Steps)
+	policies, err = store.GetEscalationPolicies("C2")
+	assert.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "shared", policies[0].ID)
+
+	assert.Error(t, store.EditEscalationPolicy("shared", globals.EscalationPolicy{}))
+	require.NoError(t, store.EditEscalationPolicy("shared", globals.EscalationPolicy{Tool: "vault", Steps: fireman}))
+	s.refresh(t)
+	policies, err = store.GetEscalationPolicies("C2")
+	assert.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "vault", policies[0].Tool)
+
+	assert.NoError(t, store.DeleteEscalationPolicy("shared"))
+	s.refresh(t)
+	policies, err = store.GetEscalationPolicies("C2")
+	assert.NoError(t, err)
+	assert.Empty(t, policies)
+}
+
+func (s Suite) testMessageTemplates(t *testing.T) {
+	store := s.Open(t)
+	templates, err := store.GetMessageTemplates()
+	assert.NoError(t, err)
+	assert.Empty(t, templates, "no message template shall be edited")
+
+	assert.Error(t, store.SetMessageTemplate(globals.MessageTemplate{Key: "reminder.fireman", Text: "Alors ?"}))
+	require.NoError(t, store.SetMessageTemplate(globals.MessageTemplate{Locale: "fr", Key: "reminder.fireman", Text: "Alors ?"}))
+	require.NoError(t, store.SetMessageTemplate(globals.MessageTemplate{Locale: "en", Key: "reminder.fireman", Text: "So?"}))
+	require.NoError(t, store.SetMessageTemplate(globals.MessageTemplate{Locale: "fr", Key: "reminder.fireman", Text: "Du neuf ?"}))
+	s.refresh(t)
+
+	templates, err = store.GetMessageTemplates()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []globals.MessageTemplate{
+		{Locale: "en", Key: "reminder.fireman", Text: "So?"},
+		{Locale: "fr", Key: "reminder.fireman", Text: "Du neuf ?"},
+	}, templates)
+
+	assert.Error(t, store.DeleteMessageTemplate("fr", ""))
+	assert.NoError(t, store.DeleteMessageTemplate("fr", "reminder.fireman"))
+	s.refresh(t)
+	templates, err = store.GetMessageTemplates()
+	assert.NoError(t, err)
+	assert.Equal(t, []globals.MessageTemplate{{Locale: "en", Key: "reminder.fireman", Text: "So?"}}, templates)
+}
+
+func (s Suite) testWelcome(t *testing.T) {
+	store := s.Open(t)
+	messages, err := store.GetWelcomeMessages("C1")
+	assert.NoError(t, err)
+	assert.Empty(t, messages, "no welcome message shall be defined")
+
+	assert.Error(t, store.SetWelcomeMessage(globals.WelcomeMessage{Channel: "C1"}))
+	require.NoError(t, store.SetWelcomeMessage(globals.WelcomeMessage{Text: "Bienvenue"}))
+	require.NoError(t, store.SetWelcomeMessage(globals.WelcomeMessage{Channel: "C1", Text: "Bienvenue sur C1"}))
+	require.NoError(t, store.SetWelcomeMessage(globals.WelcomeMessage{
+		Channel: "C1",
+		Blocks:  json.RawMessage(`[{"type": "divider"}]`),
+		Direct:  true,
+	}))
+	require.NoError(t, store.SetWelcomeMessage(globals.WelcomeMessage{Channel: "C2", Text: "Bienvenue sur C2"}))
+	s.refresh(t)
+
+	messages, err = store.GetWelcomeMessages("C1")
+	assert.NoError(t, err)
+	require.Len(t, messages, 2, "the welcome message of the channel and the shared one shall be returned")
+	byChannel := make(map[string]globals.WelcomeMessage)
+	for _, welcome := range messages {
+		byChannel[welcome.Channel] = welcome
+	}
+	assert.Equal(t, "Bienvenue", byChannel[""].Text)
+	assert.Empty(t, byChannel["C1"].Text, "the welcome message of the channel shall be replaced")
+	assert.True(t, byChannel["C1"].Direct)
+	assert.JSONEq(t, `[{"type": "divider"}]`, string(byChannel["C1"].Blocks))
+
+	require.NoError(t, store.DeleteWelcomeMessage("C1"))
+	require.NoError(t, store.DeleteWelcomeMessage(""))
+	s.refresh(t)
+	messages, err = store.GetWelcomeMessages("C1")
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+
+	assert.Error(t, store.AddWelcomeAcknowledgement(globals.WelcomeAcknowledgement{Channel: "C1"}))
+	require.NoError(t, store.AddWelcomeAcknowledgement(globals.WelcomeAcknowledgement{Channel: "C1", UserID: "U1", Date: "1600000000"}))
+	require.NoError(t, store.AddWelcomeAcknowledgement(globals.WelcomeAcknowledgement{Channel: "C1", UserID: "U1", Date: "1600000100"}))
+	require.NoError(t, store.AddWelcomeAcknowledgement(globals.WelcomeAcknowledgement{Channel: "C2", UserID: "U1", Date: "1600000200"}))
+	s.refresh(t)
+
+	acknowledgements, err := store.GetWelcomeAcknowledgements("C1")
+	assert.NoError(t, err)
+	assert.Equal(t, []globals.WelcomeAcknowledgement{{Channel: "C1", UserID: "U1", Date: "1600000100"}}, acknowledgements,
+		"a single acknowledgement shall be kept per user")
+	acknowledgements, err = store.GetWelcomeAcknowledgements("")
+	assert.NoError(t, err)
+	assert.Len(t, acknowledgements, 2)
+}
+
+func (s Suite) testFiremen(t *testing.T) {
+	store := s.Open(t)
+	now := strconv.FormatInt(time.Now().Unix(), 10) + ".000100"
+	require.NoError(t, store.AddFireman(globals.Message{Channel: "C1", UserID: "U1", Timestamp: now}))
+	require.NoError(t, store.AddFireman(globals.Message{Channel: "C2", UserID: "U2", Timestamp: now}))
+	s.refresh(t)
+
+	start, end := period()
+	firemen, err := store.QueryRangeFireman("C1", start, end)
+	assert.NoError(t, err)
+	require.Len(t, firemen, 1)
+	assert.Equal(t, "U1", firemen[0].UserID)
+}
+
+func (s Suite) testStatistics(t *testing.T) {
+	store := s.Open(t)
+	fixed := message("C1", 0, globals.StatusFixed)
+	fixed.ResponseTime, fixed.ResolutionTime = 10, 100
+	responded := message("C1", 1, globals.StatusResponded)
+	responded.ResponseTime = 20
+	for _, m := range []globals.Message{fixed, responded, message("C1", 2, globals.StatusUnresponded), message("C2", 3, globals.StatusFixed)} {
+		require.NoError(t, store.AddMessage(m))
+	}
+	s.refresh(t)
+
+	start, end := period()
+	closing := []globals.MessageStatus{globals.StatusFixed}
+	aggregations, err := store.AggregateMessages("C1", start, end, closing)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, aggregations.Count)
+	assert.Equal(t, 1, aggregations.Statuses[globals.StatusFixed])
+	assert.Equal(t, 1, aggregations.Statuses[globals.StatusUnresponded])
+	assert.Equal(t, 2, aggregations.Distributions["response_time"].Count)
+	assert.Equal(t, time.Duration(15), aggregations.Distributions["response_time"].Mean)
+	assert.Equal(t, 1, aggregations.Distributions["resolution_time"].Count)
+	assert.Equal(t, 1, aggregations.Distributions["resolution_time"].Histogram[2].Count, "100 minutes shall be in the bucket from 60 to 240")
+
+	series, err := store.QueryTimeSeries(globals.TimeSeriesQuery{Channel: "C1", Start: start, End: end, Interval: "day", GroupBy: "status", Closing: closing})
+	assert.NoError(t, err)
+	require.NotEmpty(t, series.Buckets)
+	count, groups := 0, 0
+	for _, bucket := range series.Buckets {
+		count += bucket.Count
+		groups += len(bucket.Groups)
+	}
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 3, groups)
+}
@@ -1,81 +1,78 @@
 package analytics
 
 import (
-	"fmt"
 	"math"
-	"time"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/leboncoin/subot/pkg/globals"
 )
 
 // Analyse godoc
 // @Summary Retrieve analytics for the period
 // @Description performs the analysis of the support performances
-// @Description for the given period
+// @Description for the given period, on one channel or on all of them.
+// @Description Response and resolution times are given in wall-clock
+// @Description and in business minutes, along with their p50, p90 and p99
+// @Description and their histogram, and the number of messages of each status.
+// @Description The statistics are computed by the storage, the messages themselves are listed by /messages
 // @Tags Analytics
 // @ID analyse-messages
 // @Accept  json
 // @Produce  json
+// @Param channel query string false "ID of the channel to analyse, all channels when empty"
 // @Param start query string true "Start date of the period to analyse (format 2020-12-31)"
 // @Param end query string true "End date of the period to analyse (format 2020-12-31)"
 // @Success 200 {object} map[string]string
 // @Success 500 {string} Error
 // @Router /analytics [get]
-func (a Analyser) Analyse(start string, end string) (globals.Statistics, error) {
-	messages, err := a.retrieveMessages(start, end)
+func (a Analyser) Analyse(channel string, start string, end string) (globals.Statistics, error) {
+	firemen, err := a.retrieveFiremen(channel, start, end)
 	if err != nil {
 		return globals.Statistics{}, err
 	}
-	firemen, err := a.retrieveFiremen(start, end)
+	workflow, err := a.ESClient.GetWorkflow(channel)
 	if err != nil {
 		return globals.Statistics{}, err
 	}
-	responseTime, err := a.calculateResponseTime(messages)
-	if err != nil {
-		return globals.Statistics{}, err
-	}
-	resolutionRate, err := a.calculateResolutionRate(messages)
+	aggregations, err := a.retrieveAggregations(channel, start, end, workflow)
 	if err != nil {
 		return globals.Statistics{}, err
 	}
 
 	stats := globals.Statistics{
+		Count:          aggregations.Count,
 		Firemen:        firemen,
-		Messages:       messages,
-		ResponseTime:   responseTime,
-		ResolutionRate: resolutionRate,
+		ResponseTime:   aggregations.Distributions["response_time"].Mean,
+		ResolutionTime: aggregations.Distributions["resolution_time"].Mean,
+		ResolutionRate: resolutionRate(aggregations),
+		Statuses:       aggregations.Statuses,
+		Distributions:  aggregations.Distributions,
+		Channel:        channel,
 		Start:          start,
 		End:            end,
+
+		BusinessResponseTime:   aggregations.Distributions["business_response_time"].Mean,
+		BusinessResolutionTime: aggregations.Distributions["business_resolution_time"].Mean,
 	}
 	return stats, nil
 }
 
-func (a Analyser) retrieveMessages(start string, end string) ([]globals.Message, error) {
+// retrieveAggregations lets the storage count the statuses and compute the distributions of the times,
+// which covers every message of the period and not only the loaded ones
+func (a Analyser) retrieveAggregations(channel string, start string, end string, workflow globals.Workflow) (globals.Aggregations, error) {
 	startTs, err := globals.ParseDate(start)
 	if err != nil {
-		return nil, err
+		return globals.Aggregations{}, err
 	}
 	endTs, err := globals.ParseDate(end)
 	if err != nil {
-		return nil, err
+		return globals.Aggregations{}, err
 	}
-	log.WithFields(log.Fields{
-		"start": start,
-		"end":   end,
-	}).Debug("Starting fetch of messages to analyse")
-
-	messages, err := a.ESClient.QueryRangeMessages(startTs, endTs)
-	if err != nil {
-		return nil, err
-	}
-
-	return messages, nil
+	return a.ESClient.AggregateMessages(channel, startTs, endTs, workflow.ClosingStatuses())
 }
 
-func (a Analyser) retrieveFiremen(start string, end string) ([]globals.User, error) {
+func (a Analyser) retrieveFiremen(channel string, start string, end string) ([]globals.User, error) {
 	var firemen []globals.User
-	esFiremen, err := a.ESClient.QueryRangeFireman(start, end)
+	esFiremen, err := a.ESClient.QueryRangeFireman(channel, start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -87,81 +84,11 @@ func (a Analyser) retrieveFiremen(start string, end string) ([]globals.User, err
 	return firemen, nil
 }
 
-func (a Analyser) calculateResponseTime(messages []globals.Message) (time.Duration, error) {
-	log.Debug("Starting calculating response time")
-	if len(messages) == 0 {
-		return 0, nil
-	}
-	sum := 0.
-	var responseTime []time.Duration
-	for _, msg := range messages {
-		if len(msg.Replies) == 0 {
-			continue
-		}
-		responseTime = append(responseTime, msg.ResponseTime)
-		sum = sum + float64(msg.ResponseTime)
-	}
-	log.Debug("Finished summing all individual response times")
-
-	if sum == 0 {
-		return 0, nil
-	}
-
-	log.Debug("Performing mean calculation")
-
-	mean, err := time.ParseDuration(fmt.Sprintf("%f", sum/float64(len(responseTime))) + "ns")
-	if err != nil {
-		log.Errorf("got an error while calculating mean : %s", err)
-		return 0, err
-	}
-	return mean, nil
-}
-
-func (a Analyser) calculateResolutionTime(messages []globals.Message) (time.Duration, error) {
-	log.Debug("Starting calculating resolution time")
-	if len(messages) == 0 {
-		return 0, nil
-	}
-	sum := 0.
-	var resolutionTime []time.Duration
-	for _, msg := range messages {
-		if len(msg.Reactions) == 0 {
-			continue
-		}
-		resolutionTime = append(resolutionTime, msg.ResolutionTime)
-		sum = sum + float64(msg.ResolutionTime)
-	}
-	log.Debug("Finished summing all individual response times")
-
-	if sum == 0 {
-		return 0, nil
-	}
-
-	log.Debug("Performing mean calculation")
-
-	mean, err := time.ParseDuration(fmt.Sprintf("%f", sum/float64(len(resolutionTime))) + "ns")
-	if err != nil {
-		log.Errorf("got an error while calculating mean : %s", err)
-		return 0, err
-	}
-	return mean, nil
-}
-
-func (a Analyser) calculateResolutionRate(messages []globals.Message) (int, error) {
-	log.Debug("Starting calculating resolution rate")
-	if len(messages) == 0 {
-		return 0, nil
-	}
-	var fixedMessages []globals.Message
-	log.Debug("Find proportion of fixed out of user message")
-	for _, message := range messages {
-		if message.Status == "fixed" {
-			fixedMessages = append(fixedMessages, message)
-		}
-	}
-	log.Debug("Finished categorising messages")
-	if len(fixedMessages) < 1 {
-		return 0, nil
+// resolutionRate is the percentage of the messages of the period with a closing status
+func resolutionRate(aggregations globals.Aggregations) int {
+	if aggregations.Count == 0 {
+		return 0
 	}
-	return int(math.Min(100, float64(len(fixedMessages)*100/len(messages)))), nil
+	closed := aggregations.Distributions["resolution_time"].Count
+	return int(math.Min(100, float64(closed*100/aggregations.Count)))
 }
@@ -0,0 +1,45 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	log "github.com/sirupsen/logrus"
+)
+
+// rankAnswers orders the answers matching a message of the channel by their historical usefulness,
+// drops the answers repeating a better one and keeps the best answers of the channel
+func (a Analyser) rankAnswers(channel string, answers []globals.Answer) []globals.Answer {
+	if len(answers) > 1 {
+		statistics, err := a.ESClient.AggregateAnswers(channel)
+		if err != nil {
+			log.WithFields(log.Fields{"channel": channel, "error": err}).Error("Could not aggregate the answers, keep their order")
+		}
+		usefulness := make(map[string]float64)
+		for _, answer := range answers {
+			usefulness[answer.ID] = globals.AnswerStatistics{}.Usefulness()
+		}
+		for _, stats := range statistics {
+			usefulness[stats.AnswerID] = stats.Usefulness()
+		}
+		sort.SliceStable(answers, func(i, j int) bool {
+			return usefulness[answers[i].ID] > usefulness[answers[j].ID]
+		})
+	}
+
+	ranked := make([]globals.Answer, 0, len(answers))
+	seen := make(map[string]bool)
+	for _, answer := range answers {
+		text := strings.ToLower(strings.Join(strings.Fields(answer.Answer), " "))
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+		ranked = append(ranked, answer)
+	}
+	if max := channelSettings(channel).MaxAnswers; max > 0 && len(ranked) > max {
+		ranked = ranked[:max]
+	}
+	return ranked
+}
@@ -1,10 +1,16 @@
 package analytics
 
 import (
+	"sort"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/leboncoin/subot/pkg/globals"
 )
 
+// defaultMinSent is the number of times an answer shall be sent before it can be flagged as never useful
+const defaultMinSent = 5
+
 // GetAnswers godoc
 // @Summary Get all configured answers
 // @Description Returns the list of existing answers.
@@ -12,9 +18,10 @@ import (
 // @Tags Answers
 // @ID get-answers
 // @Produce  json
+// @Param channel query string false "ID of the channel, all answers when empty"
 // @Router /answers [get]
 func (a Analyser) GetAnswers(ctx *gin.Context) {
-	answers, err := a.ESClient.GetAnswers()
+	answers, err := a.ESClient.GetAnswers(ctx.Query("channel"))
 	if err != nil {
 		ctx.JSON(500, gin.H{
 			"error": err.Error(),
@@ -32,6 +39,7 @@ func (a Analyser) GetAnswers(ctx *gin.Context) {
 // @Tags Answers
 // @ID add-answer
 // @Produce  json
+// @Param channel body string false "ID of the channel of this answer, shared by every channel when empty"
 // @Param tool body string false "Tool to match for this answer"
 // @Param label body string false "Label to match for this answer"
 // @Param answer body string true "The answer to reply when matched"
@@ -63,6 +71,7 @@ func (a Analyser) AddAnswer(c *gin.Context) {
 // @ID edit-answer
 // @Produce  json
 // @Param documentID query string true "Answer id to update"
+// @Param channel body string false "ID of the channel of this answer, shared by every channel when empty"
 // @Param tool body string false "Tool to match for this answer"
 // @Param label body string false "Label to match for this answer"
 // @Param answer body string true "The answer to reply when matched"
@@ -105,3 +114,64 @@ func (a Analyser) DeleteAnswer(c *gin.Context) {
 	}
 	c.JSON(204, gin.H{})
 }
+
+// GetAnswersReport godoc
+// @Summary Get the effectiveness of the answers
+// @Description Returns the answers with the number of times they were sent
+// @Description and the useful and useless feedbacks they got, most useful first.
+// @Description The answers sent at least min_sent times without any useful feedback are flagged.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Answers
+// @ID get-answers-report
+// @Produce  json
+// @Param channel query string false "ID of the channel, all answers when empty"
+// @Param min_sent query int false "Number of times an answer shall be sent to be flagged as never useful, 5 by default"
+// @Router /admin/answers/report [get]
+func (a Analyser) GetAnswersReport(c *gin.Context) {
+	minSent, err := strconv.Atoi(c.DefaultQuery("min_sent", strconv.Itoa(defaultMinSent)))
+	if err != nil || minSent <= 0 {
+		c.JSON(400, gin.H{
+			"error": "min_sent shall be a positive number",
+		})
+		return
+	}
+	channel := c.Query("channel")
+	answers, err := a.ESClient.GetAnswers(channel)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	statistics, err := a.ESClient.AggregateAnswers(channel)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	byAnswer := make(map[string]globals.AnswerStatistics)
+	for _, stats := range statistics {
+		byAnswer[stats.AnswerID] = stats
+	}
+
+	report := make([]globals.AnswerReport, 0, len(answers))
+	for _, answer := range answers {
+		stats := byAnswer[answer.ID]
+		report = append(report, globals.AnswerReport{
+			Answer:      answer,
+			Sent:        stats.Sent,
+			Useful:      stats.Useful,
+			Useless:     stats.Useless,
+			Usefulness:  stats.Usefulness(),
+			NeverUseful: stats.Sent >= minSent && stats.Useful == 0,
+		})
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].Usefulness != report[j].Usefulness {
+			return report[i].Usefulness > report[j].Usefulness
+		}
+		return report[i].Sent > report[j].Sent
+	})
+	c.JSON(200, report)
+}
@@ -64,9 +64,10 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 	api := r.Group("/v1")
 	{
 		api.GET("/analytics", func(c *gin.Context) {
+			channel := c.Query("channel")
 			start := c.DefaultQuery("start", "2019-01-01")
 			end := c.DefaultQuery("end", time.Now().Format(globals.DateLayout))
-			data, err := instance.Analyse(start, end)
+			data, err := instance.Analyse(channel, start, end)
 			if err != nil {
 				c.JSON(500, gin.H{
 					"error": err.Error(),
@@ -74,6 +75,7 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 				return
 			}
 			c.JSON(200, gin.H{
+				"channel":   channel,
 				"start":     start,
 				"end":       end,
 				"analytics": data,
@@ -99,16 +101,17 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 				c.JSON(201, replies)
 			})
 			analyticsAPI.GET("/report", func(c *gin.Context) {
+				channel := c.Query("channel")
 				start := c.Query("start")
 				end := c.Query("end")
-				log.WithFields(log.Fields{"start": start, "end": end}).Debug("Received report request")
+				log.WithFields(log.Fields{"channel": channel, "start": start, "end": end}).Debug("Received report request")
 				if start > end {
 					c.JSON(400, gin.H{
 						"error": "end is inferior to start time for report request",
 					})
 					return
 				}
-				report, err := instance.HandleReportRequest(start, end)
+				report, err := instance.HandleReportRequest(channel, start, end)
 				if err != nil {
 					c.JSON(500, gin.H{
 						"error": err,
@@ -117,6 +120,27 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 				}
 				c.JSON(200, report)
 			})
+			analyticsAPI.GET("/timeseries", func(c *gin.Context) {
+				channel := c.Query("channel")
+				start := c.DefaultQuery("start", "2019-01-01")
+				end := c.DefaultQuery("end", time.Now().Format(globals.DateLayout))
+				interval := c.DefaultQuery("interval", "day")
+				groupBy := c.Query("group_by")
+				if !isValidTimeSeries(interval, groupBy) {
+					c.JSON(400, gin.H{
+						"error": "interval shall be day, week or month and group_by label, tool, status or user",
+					})
+					return
+				}
+				series, err := instance.TimeSeries(channel, start, end, interval, groupBy)
+				if err != nil {
+					c.JSON(500, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				c.JSON(200, series)
+			})
 			analyticsAPI.GET("/reminders", func(c *gin.Context) {
 				reminders, err := instance.HandleRemindersRequest()
 				if err != nil {
@@ -127,6 +151,16 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 				}
 				c.JSON(200, reminders)
 			})
+			analyticsAPI.GET("/rotation", func(c *gin.Context) {
+				handovers, err := instance.HandleRotationRequest()
+				if err != nil {
+					c.JSON(500, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				c.JSON(200, handovers)
+			})
 			analyticsAPI.POST(fmt.Sprintf("/%s", globals.NewMessage), func(c *gin.Context) {
 				var message globals.Message
 				log.WithFields(log.Fields{"request": message}).Debug("Got new request")
@@ -255,7 +289,92 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 					})
 					return
 				}
-				replies, err := instance.handleFeedback(interaction)
+				replies, err := instance.HandleFeedback(interaction)
+				if err != nil {
+					c.JSON(500, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				c.JSON(201, replies)
+			})
+			analyticsAPI.POST("/similar_feedback", func(c *gin.Context) {
+				var interaction globals.Interaction
+				if err := c.BindJSON(&interaction); err != nil {
+					c.JSON(400, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				replies, err := instance.HandleSimilarFeedback(interaction)
+				if err != nil {
+					c.JSON(500, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				c.JSON(201, replies)
+			})
+			analyticsAPI.POST("/thread_action", func(c *gin.Context) {
+				var interaction globals.Interaction
+				if err := c.BindJSON(&interaction); err != nil {
+					c.JSON(400, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				replies, err := instance.HandleThreadAction(interaction)
+				if err != nil {
+					c.JSON(500, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				c.JSON(201, replies)
+			})
+			analyticsAPI.POST("/welcome_ack", func(c *gin.Context) {
+				var interaction globals.Interaction
+				if err := c.BindJSON(&interaction); err != nil {
+					c.JSON(400, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				replies, err := instance.HandleWelcomeAcknowledgement(interaction)
+				if err != nil {
+					c.JSON(500, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				c.JSON(201, replies)
+			})
+			analyticsAPI.POST("/resolution_note", func(c *gin.Context) {
+				var interaction globals.Interaction
+				if err := c.BindJSON(&interaction); err != nil {
+					c.JSON(400, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				replies, err := instance.HandleResolutionNoteAction(interaction)
+				if err != nil {
+					c.JSON(500, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				c.JSON(201, replies)
+			})
+			analyticsAPI.POST("/view_submission", func(c *gin.Context) {
+				var interaction globals.Interaction
+				if err := c.BindJSON(&interaction); err != nil {
+					c.JSON(400, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				replies, err := instance.HandleViewSubmission(interaction)
 				if err != nil {
 					c.JSON(500, gin.H{
 						"error": err.Error(),
@@ -269,6 +388,11 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 		{
 			answersAPI.GET("", instance.GetAnswers)
 		}
+		messagesAPI := api.Group("/messages")
+		{
+			messagesAPI.GET("", instance.GetMessages)
+			messagesAPI.GET("/:message_ts", instance.GetMessage)
+		}
 		labelsAPI := api.Group("/labels")
 		{
 			labelsAPI.GET("", instance.GetLabels)
@@ -281,6 +405,23 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 		{
 			toolsAPI.GET("", instance.GetTools)
 		}
+		workflowAPI := api.Group("/workflow")
+		{
+			workflowAPI.GET("", instance.GetWorkflow)
+		}
+		rotationsAPI := api.Group("/rotations")
+		{
+			rotationsAPI.GET("", instance.GetRotations)
+			rotationsAPI.GET("/:rotation/schedule", instance.GetRotationSchedule)
+		}
+		escalationsAPI := api.Group("/escalations")
+		{
+			escalationsAPI.GET("", instance.GetEscalationPolicies)
+		}
+		templatesAPI := api.Group("/templates")
+		{
+			templatesAPI.GET("", instance.GetMessageTemplates)
+		}
 		adminAPI := api.Group("/admin")
 		adminAPI.Use(authServer.AuthenticationRequired(true))
 		{
@@ -295,6 +436,38 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 			toolsAdminAPI.PUT("/:tool", instance.EditTool)
 			toolsAdminAPI.DELETE("/:tool", instance.DeleteTool)
 		}
+		workflowAdminAPI := adminAPI.Group("/workflow")
+		{
+			workflowAdminAPI.POST("/new", instance.AddStatusRule)
+			workflowAdminAPI.PUT("/:rule", instance.EditStatusRule)
+			workflowAdminAPI.DELETE("/:rule", instance.DeleteStatusRule)
+		}
+		rotationsAdminAPI := adminAPI.Group("/rotations")
+		{
+			rotationsAdminAPI.POST("/new", instance.AddRotation)
+			rotationsAdminAPI.PUT("/:rotation", instance.EditRotation)
+			rotationsAdminAPI.DELETE("/:rotation", instance.DeleteRotation)
+			rotationsAdminAPI.POST("/:rotation/overrides", instance.AddRotationOverride)
+		}
+		escalationsAdminAPI := adminAPI.Group("/escalations")
+		{
+			escalationsAdminAPI.POST("/new", instance.AddEscalationPolicy)
+			escalationsAdminAPI.PUT("/:policy", instance.EditEscalationPolicy)
+			escalationsAdminAPI.DELETE("/:policy", instance.DeleteEscalationPolicy)
+		}
+		templatesAdminAPI := adminAPI.Group("/templates")
+		{
+			templatesAdminAPI.PUT("/:locale/:key", instance.EditMessageTemplate)
+			templatesAdminAPI.DELETE("/:locale/:key", instance.DeleteMessageTemplate)
+		}
+		welcomeAdminAPI := adminAPI.Group("/welcome")
+		{
+			welcomeAdminAPI.GET("", instance.GetWelcomeMessages)
+			welcomeAdminAPI.PUT("", instance.SetWelcomeMessage)
+			welcomeAdminAPI.DELETE("", instance.DeleteWelcomeMessage)
+			welcomeAdminAPI.POST("/preview", instance.PreviewWelcomeMessage)
+			welcomeAdminAPI.GET("/acknowledgements", instance.GetWelcomeAcknowledgements)
+		}
 		teamAdminAPI := adminAPI.Group("/team")
 		{
 			teamAdminAPI.POST("/new", instance.AddTeamMember)
@@ -303,6 +476,7 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 		}
 		answersAdminAPI := adminAPI.Group("/answers")
 		{
+			answersAdminAPI.GET("/report", instance.GetAnswersReport)
 			answersAdminAPI.POST("/new", instance.AddAnswer)
 			answersAdminAPI.PUT("/:documentID", instance.EditAnswer)
 			answersAdminAPI.DELETE("/:documentID", instance.DeleteAnswer)
@@ -312,6 +486,7 @@ func runAPI(instance *Analyser, authHandler *http.Handler, authServer auth.AuthS
 		{
 			messagesAdminAPI.PUT("/:message_ts", instance.EditMessage)
 			messagesAdminAPI.DELETE("/:message_ts", instance.DeleteMessage)
+			messagesAdminAPI.POST("/:message_ts/answers", instance.PromoteResolutionNote)
 		}
 	}
 	err := r.Run() // listen and serve on 0.0.0.0:8080
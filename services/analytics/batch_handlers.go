@@ -19,11 +19,10 @@ import (
 // @Router /analytics/batch [post]
 func (a Analyser) HandleBatchMessage(messages []globals.Message) (replies []globals.SlackResponse, err error) {
 	var reply globals.SlackResponse
-	var documentID string
 	reply.Action = globals.Nothing
 	for _, message := range messages {
 		log.Debug("Check if message already exists")
-		storedMessages, err := a.ESClient.QueryRangeMessages(message.Timestamp, message.Timestamp)
+		storedMessages, err := a.ESClient.QueryRangeMessages(message.Channel, message.Timestamp, message.Timestamp)
 		if err != nil {
 			continue
 		}
@@ -35,7 +34,7 @@ func (a Analyser) HandleBatchMessage(messages []globals.Message) (replies []glob
 		}
 
 		log.Debug("Check if message comes from team member")
-		isTeamMessage, err := a.ESClient.IsTeamMember(message.UserID)
+		isTeamMessage, err := a.ESClient.IsTeamMember(message.Channel, message.UserID)
 		if err != nil {
 			continue
 		}
@@ -56,7 +55,7 @@ func (a Analyser) HandleBatchMessage(messages []globals.Message) (replies []glob
 
 		log.Debug("Get tools for event")
 		//incidents, err := a.ESClient.QueryIncidents(tools)
-		tools, err := a.ESClient.QueryTools(message.Text)
+		tools, err := a.ESClient.QueryTools(message.Channel, message.Text)
 		if err != nil {
 			log.Error("Got an error while querying tools", err)
 			continue
@@ -68,7 +67,7 @@ func (a Analyser) HandleBatchMessage(messages []globals.Message) (replies []glob
 			//reply.Text = reply.Text + " La documentation de " + t + " est disponible ici : link to doc"
 		}
 		// Error while querying labels
-		labels, err := a.ESClient.QueryLabels(message.Text)
+		labels, err := a.ESClient.QueryLabels(message.Channel, message.Text)
 		if err != nil {
 			log.Error("Got an error while querying labels", err)
 			continue
@@ -78,33 +77,34 @@ func (a Analyser) HandleBatchMessage(messages []globals.Message) (replies []glob
 
 		message.Tools = tools
 		message.Labels = labels
-		message.Status = "unresponded"
+		message.Status = globals.StatusUnresponded
 		if len(message.Replies) > 0 {
 			for _, reply := range message.Replies {
-				isTeamReply, err := a.ESClient.IsTeamMember(reply.UserID)
+				isTeamReply, err := a.ESClient.IsTeamMember(message.Channel, reply.UserID)
 				if err != nil {
 					continue
 				}
 				if isTeamReply {
-					message.Status = "responded"
+					message.Status = globals.StatusResponded
 					responseTime := (globals.ParseDuration(reply.Timestamp) - globals.ParseDuration(message.Timestamp)) / 60
 					log.Debug("Response time is : ", responseTime)
 					message.ResponseTime = time.Duration(responseTime)
+					message.BusinessResponseTime = a.businessTime(message.Timestamp, reply.Timestamp)
 					break
 				}
 			}
 		}
-		if len(message.Reactions) > 0 {
-			for _, reaction := range message.Reactions {
-				if reaction.Name == "heavy_check_mark" {
-					message.Status = "fixed"
-					break
-				}
-			}
+		workflow, err := a.ESClient.GetWorkflow(message.Channel)
+		if err != nil {
+			log.Error("Got an error while querying workflow", err)
+			continue
+		}
+		if rule, ok := workflow.StatusFromReactions(message.Reactions); ok {
+			message.Status = rule.Status
 		}
 		message.RemindAt = ""
 
-		err = a.ESClient.AddMessage(message, documentID)
+		err = a.ESClient.AddMessage(message)
 		if err != nil {
 			log.Error("Got an error while saving message", err)
 			return []globals.SlackResponse{reply}, nil
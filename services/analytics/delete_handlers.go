@@ -21,6 +21,7 @@ import (
 func (a Analyser) HandleDeletedMessage(message globals.Message) (replies []globals.SlackResponse, err error) {
 	var reply globals.SlackResponse
 	reply.Action = globals.Nothing
+	reply.ChanID = channelID(message.Channel)
 	log.WithFields(log.Fields{"message": message}).Debug("Handle deleted message")
 
 	log.Debug("Check if message has responses")
@@ -29,10 +30,11 @@ func (a Analyser) HandleDeletedMessage(message globals.Message) (replies []globa
 		log.Debug("original ts is not deleted ts")
 		originalTs = message.EditedTs
 	}
-	storedMessages, err := a.ESClient.QueryRangeMessages(originalTs, originalTs)
+	storedMessages, err := a.ESClient.QueryRangeMessages(message.Channel, originalTs, originalTs)
 	log.WithFields(log.Fields{"messages": storedMessages}).Debug("Found stored message for this timestamp")
 	if err != nil {
 		log.Error("Failed to fetch last messages", err)
+		return
 	}
 	if len(storedMessages) == 0 {
 		log.Error("Found no messages")
@@ -0,0 +1,142 @@
+package analytics
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// GetEscalationPolicies godoc
+// @Summary Get the escalation policies
+// @Description Returns the escalation policies, with the steps followed when a thread stays without answer.
+// @Description No authentication required
+// @Tags Escalations
+// @ID get-escalation-policies
+// @Produce  json
+// @Param channel query string false "ID of the channel, all policies when empty"
+// @Router /escalations [get]
+func (a Analyser) GetEscalationPolicies(c *gin.Context) {
+	policies, err := a.ESClient.GetEscalationPolicies(c.Query("channel"))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if policies == nil {
+		policies = []globals.EscalationPolicy{}
+	}
+	c.JSON(200, policies)
+}
+
+// AddEscalationPolicy godoc
+// @Summary Create new escalation policy
+// @Description Stores a new escalation policy into the database with the given information.
+// @Description A channel has a single policy per label and tool.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Escalations
+// @ID add-escalation-policy
+// @Produce  json
+// @Param steps body array true "Steps of the escalation, each one with its delay after the last activity of the thread (after), its target (fireman, user, group or dm) and the slack ID of the user or user group (slack_id)"
+// @Param label body string false "Label of the threads of this policy"
+// @Param tool body string false "Tool of the threads of this policy"
+// @Param channel body string false "ID of the channel of this policy, shared by every channel when empty"
+// @Router /escalations/new [post]
+func (a Analyser) AddEscalationPolicy(c *gin.Context) {
+	var policy globals.EscalationPolicy
+	if err := c.BindJSON(&policy); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if status, err := a.checkEscalationPolicy("", policy); err != nil {
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.ESClient.AddEscalationPolicy(policy); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(201, gin.H{})
+}
+
+// EditEscalationPolicy godoc
+// @Summary Edit specified escalation policy
+// @Description Modify the data stored in the database
+// @Description for the document having the given policy ID.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Escalations
+// @ID edit-escalation-policy
+// @Produce  json
+// @Param policy query string true "Escalation policy id to update"
+// @Param steps body array true "Steps of the escalation, each one with its delay after the last activity of the thread (after), its target (fireman, user, group or dm) and the slack ID of the user or user group (slack_id)"
+// @Param label body string false "Label of the threads of this policy"
+// @Param tool body string false "Tool of the threads of this policy"
+// @Param channel body string false "ID of the channel of this policy, shared by every channel when empty"
+// @Router /escalations/:policy [put]
+func (a Analyser) EditEscalationPolicy(c *gin.Context) {
+	id := c.Param("policy")
+	var policy globals.EscalationPolicy
+	if err := c.BindJSON(&policy); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if status, err := a.checkEscalationPolicy(id, policy); err != nil {
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.ESClient.EditEscalationPolicy(id, policy); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{})
+}
+
+// DeleteEscalationPolicy godoc
+// @Summary Delete specified escalation policy
+// @Description Deletes the entry matching the ID.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Escalations
+// @ID delete-escalation-policy
+// @Produce  json
+// @Param policy query string true "Escalation policy id to delete"
+// @Router /escalations/:policy [delete]
+func (a Analyser) DeleteEscalationPolicy(c *gin.Context) {
+	if err := a.ESClient.DeleteEscalationPolicy(c.Param("policy")); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(204, gin.H{})
+}
+
+// checkEscalationPolicy validates the policy and makes sure that the channel has no other policy
+// for the same label and tool. It returns the http status to answer with when the policy is invalid
+func (a Analyser) checkEscalationPolicy(id string, policy globals.EscalationPolicy) (int, error) {
+	if err := policy.Validate(); err != nil {
+		return 400, err
+	}
+	policies, err := a.ESClient.GetEscalationPolicies(policy.Channel)
+	if err != nil {
+		return 500, err
+	}
+	for _, p := range policies {
+		if p.ID != id && p.Channel == policy.Channel && p.Label == policy.Label && p.Tool == policy.Tool {
+			return 409, fmt.Errorf("label %q and tool %q already have the escalation policy %s", policy.Label, policy.Tool, p.ID)
+		}
+	}
+	return 200, nil
+}
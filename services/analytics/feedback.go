@@ -2,10 +2,10 @@ package analytics
 
 import (
 	"errors"
-	"fmt"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
 )
 
 type feedbackTextSection struct {
@@ -31,13 +31,14 @@ type feedbackActionsSection struct {
 	Elements []feedbackElementSection `json:"elements"`
 }
 
-func createFeedbackTemplate() []interface{} {
+// createFeedbackTemplate asks the user whether the answer was helpful, in their locale
+func (a Analyser) createFeedbackTemplate(locale string) []interface{} {
 	template := []interface{}{
 		feedbackTextSection{
 			Type: "section",
 			Text: map[string]string{
 				"type": "mrkdwn",
-				"text": "Cette réponse a t'elle permis de résoudre ton souci ?",
+				"text": a.Messages.Render(locale, "feedback.question", nil),
 			},
 		},
 		feedbackActionsSection{
@@ -49,7 +50,7 @@ func createFeedbackTemplate() []interface{} {
 					Value: "feedback_useful",
 					Text: feedbackElementTextSection{
 						Type:  "plain_text",
-						Text:  "Oui, merci Subot ! :slightly_smiling_face:",
+						Text:  a.Messages.Render(locale, "feedback.useful", nil),
 						Emoji: true,
 					},
 				},
@@ -59,7 +60,7 @@ func createFeedbackTemplate() []interface{} {
 					Value: "feedback_useless",
 					Text: feedbackElementTextSection{
 						Type:  "plain_text",
-						Text:  "Non, contacter le pompier :fire:",
+						Text:  a.Messages.Render(locale, "feedback.useless", nil),
 						Emoji: true,
 					},
 				},
@@ -69,22 +70,24 @@ func createFeedbackTemplate() []interface{} {
 	return template
 }
 
-func getFeedbackResponse(ts string) *globals.SlackResponse {
+func (a Analyser) getFeedbackResponse(locale string, channel string, ts string) *globals.SlackResponse {
 	slackResponse := &globals.SlackResponse{
 		Action: globals.ReplyMessage,
-		Blocks: createFeedbackTemplate(),
+		Blocks: a.createFeedbackTemplate(locale),
 		Ts:     ts,
+		ChanID: channel,
 	}
 	return slackResponse
 }
 
 
-// handleFeedback godoc
+// HandleFeedback godoc
 // @Summary returns a reply when the interaction was completed
 // @Description the reply returned is an update of the original feedback message
 // @Description asking the user if the bot response was helpful.
 // @Description Depending on the answer, it shall either call
-// @Description the fireman for help or mark the original message as fixed.
+// @Description the fireman for help or mark the original message as fixed
+// @Description and ask the user for a resolution note.
 // @Description Only the user that sent the original message
 // @Description is allowed to complete the interaction
 // @Tags Analytics
@@ -93,9 +96,12 @@ func getFeedbackResponse(ts string) *globals.SlackResponse {
 // @Produce  json
 // @Param interaction body object true "Interaction object sent by slack"
 // @Router /analytics/feedback [post]
-func (a Analyser) handleFeedback(interaction globals.Interaction) (replies []globals.SlackResponse, err error) {
+func (a Analyser) HandleFeedback(interaction globals.Interaction) (replies []globals.SlackResponse, err error) {
 	log.Debug("Get original message")
-	originalMessages, err := a.ESClient.QueryRangeMessages(interaction.ThreadTs, interaction.ThreadTs)
+	originalMessages, err := a.ESClient.QueryRangeMessages(interaction.Channel, interaction.ThreadTs, interaction.ThreadTs)
+	if err != nil {
+		return replies, err
+	}
 	if len(originalMessages) == 0 {
 		log.Debug("Original message not found, return")
 		return
@@ -106,21 +112,30 @@ func (a Analyser) handleFeedback(interaction globals.Interaction) (replies []glo
 	}
 	originalMessages[0].FeedbackStatus = globals.FeedbackStatus(interaction.ActionValue)
 	originalMessages[0].FeedbackTs = interaction.ActionTs
-	if err != nil {
-		return replies, err
-	}
 
-	finalMessage := fmt.Sprintf("Je rends la main au pompier. Au secours <@%s>", a.getFiremanID())
+	userLocale := locale(interaction.Channel, originalMessages[0].UserInfo)
+	finalMessage := a.Messages.Render(userLocale, "feedback.fireman", i18n.Vars{"Fireman": a.getFiremanID(interaction.Channel)})
 	if globals.FeedbackStatus(interaction.ActionValue) == globals.UsefulFeedback {
-		originalMessages[0].Status = "fixed"
-		finalMessage = "Ravi d'avoir pu aider"
-		// Set the heavy_check_mark
+		workflow, err := a.ESClient.GetWorkflow(interaction.Channel)
+		if err != nil {
+			return replies, err
+		}
+		// Close the message with the first closing status of the channel, and set its emoji
+		status := globals.StatusFixed
+		if closing := workflow.ClosingStatuses(); len(closing) > 0 {
+			status = closing[0]
+		}
+		originalMessages[0].Status = status
+		finalMessage = a.Messages.Render(userLocale, "feedback.fixed", nil)
+		emoji := workflow.EmojiFor(status)
 		replies = append(replies, globals.SlackResponse{
 			Action: globals.React,
-			Text:   "heavy_check_mark",
+			Text:   emoji,
 			Blocks: nil,
 			Ts:     interaction.ThreadTs,
+			ChanID: channelID(interaction.Channel),
 		})
+		replies = append(replies, a.askResolutionNote(originalMessages[0], interaction.ActionUserID, userLocale)...)
 	}
 	// Update message content using response url
 	replies = append(replies, globals.SlackResponse{
@@ -132,7 +147,7 @@ func (a Analyser) handleFeedback(interaction globals.Interaction) (replies []glo
 	err = a.ESClient.AddMessage(originalMessages[0], originalMessages[0].ID)
 	if err != nil {
 		log.Error("Got an error while saving message", err)
-		return replies, nil
+		return replies, err
 	}
 
 	return replies, nil
@@ -0,0 +1,206 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// handoverDays is the number of days searched for the threads still open at the handover
+const handoverDays = 30
+
+// handoverMaxThreads is the number of threads listed in each section of the handover digest, the oldest first
+const handoverMaxThreads = 10
+
+// defaultWorkspaceURL redirects the links of the threads to the workspace of the reader
+const defaultWorkspaceURL = "https://slack.com"
+
+// handoverStatuses is the order of the groups of open threads in the handover digest, the other statuses follow
+var handoverStatuses = []globals.MessageStatus{globals.StatusUnresponded, globals.StatusResponded, globals.StatusInProgress}
+
+// handoverDigest returns the handover digest of the channel for the new fireman,
+// sent to the fireman or posted in the channel as configured. It is empty when the digest is disabled
+func (a Analyser) handoverDigest(channel config.Channel, fireman string) []globals.SlackResponse {
+	if channel.HandoverDigest == config.HandoverDisabled || fireman == "" {
+		return nil
+	}
+	blocks, err := a.buildHandoverDigest(channel.ID, channel.Locale, fireman, time.Now())
+	if err != nil {
+		log.WithFields(log.Fields{"channel": channel.ID, "error": err}).Error("Could not build the handover digest")
+		return nil
+	}
+
+	response := globals.SlackResponse{
+		Action: globals.ChannelMessage,
+		Text:   a.Messages.Render(channel.Locale, "handover.text", i18n.Vars{"Channel": channel.ID, "Fireman": fireman}),
+		Blocks: blocks,
+		ChanID: fireman,
+	}
+	if channel.HandoverDigest == config.HandoverChannel {
+		response.ChanID = channel.ID
+	}
+	return []globals.SlackResponse{response}
+}
+
+// buildHandoverDigest lists the threads of the channel still open, by status, the ones awaiting the feedback
+// of their author and the ones with a pending reminder
+func (a Analyser) buildHandoverDigest(channel string, locale string, fireman string, now time.Time) ([]interface{}, error) {
+	workflow, err := a.ESClient.GetWorkflow(channel)
+	if err != nil {
+		return nil, err
+	}
+	start := strconv.FormatInt(now.AddDate(0, 0, -handoverDays).Unix(), 10)
+	end := strconv.FormatInt(now.Unix(), 10)
+	messages, err := a.ESClient.QueryRangeMessages(channel, start, end)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return globals.ParseDuration(messages[i].Timestamp) < globals.ParseDuration(messages[j].Timestamp)
+	})
+
+	byStatus := make(map[globals.MessageStatus][]globals.Message)
+	var open, awaitingFeedback, reminded []globals.Message
+	for _, message := range messages {
+		status := message.Status
+		if status == "" {
+			status = globals.StatusUnresponded
+		}
+		if status == globals.StatusDeleted || workflow.IsClosing(status) {
+			continue
+		}
+		open = append(open, message)
+		byStatus[status] = append(byStatus[status], message)
+		if message.FeedbackStatus == globals.AskedFeedback {
+			awaitingFeedback = append(awaitingFeedback, message)
+		}
+		if message.RemindAt != "" {
+			reminded = append(reminded, message)
+		}
+	}
+
+	blocks := []interface{}{
+		reportTextSection{
+			Type: "section",
+			Text: map[string]string{
+				"type": "mrkdwn",
+				"text": a.Messages.Render(locale, "handover.intro", i18n.Vars{
+					"Fireman": fireman,
+					"Channel": channel,
+					"Count":   len(open),
+					"Days":    handoverDays,
+				}),
+			},
+		},
+	}
+	for _, status := range sortedStatuses(byStatus) {
+		threads := byStatus[status]
+		title := a.Messages.Render(locale, "handover.status", i18n.Vars{"Status": status, "Count": len(threads)})
+		blocks = append(blocks, a.handoverSection(channel, locale, title, threads, func(message globals.Message) string {
+			return a.Messages.Render(locale, "handover.opened", i18n.Vars{"Age": a.age(locale, now, message.Timestamp)})
+		}))
+	}
+	if len(awaitingFeedback) > 0 {
+		title := a.Messages.Render(locale, "handover.feedback", i18n.Vars{"Count": len(awaitingFeedback)})
+		blocks = append(blocks, a.handoverSection(channel, locale, title, awaitingFeedback, func(message globals.Message) string {
+			return a.Messages.Render(locale, "handover.feedback_detail", i18n.Vars{"User": message.UserID})
+		}))
+	}
+	if len(reminded) > 0 {
+		title := a.Messages.Render(locale, "handover.reminders", i18n.Vars{"Count": len(reminded)})
+		blocks = append(blocks, a.handoverSection(channel, locale, title, reminded, func(message globals.Message) string {
+			return a.Messages.Render(locale, "handover.reminder_detail", i18n.Vars{"Date": int64(globals.ParseDuration(message.RemindAt))})
+		}))
+	}
+	return blocks, nil
+}
+
+// sortedStatuses returns the statuses of the open threads, the usual ones first in the order of the workflow
+func sortedStatuses(byStatus map[globals.MessageStatus][]globals.Message) []globals.MessageStatus {
+	var statuses, others []globals.MessageStatus
+	for _, status := range handoverStatuses {
+		if _, ok := byStatus[status]; ok {
+			statuses = append(statuses, status)
+		}
+	}
+	for status := range byStatus {
+		if !containsStatus(handoverStatuses, status) {
+			others = append(others, status)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+	return append(statuses, others...)
+}
+
+func containsStatus(statuses []globals.MessageStatus, status globals.MessageStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// handoverSection lists the threads of the channel under the title, with a link to each of them followed by the detail.
+// The threads stored without their channel are linked to the channel as well
+func (a Analyser) handoverSection(channel string, locale string, title string, threads []globals.Message, detail func(globals.Message) string) reportTextSection {
+	lines := []string{title}
+	for i, message := range threads {
+		if i == handoverMaxThreads {
+			lines = append(lines, a.Messages.Render(locale, "handover.more", i18n.Vars{"Count": len(threads) - handoverMaxThreads}))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("• <%s|%s> %s", threadLink(channel, message.Timestamp), a.excerpt(locale, message.Text), detail(message)))
+	}
+	return reportTextSection{
+		Type: "section",
+		Text: map[string]string{
+			"type": "mrkdwn",
+			"text": strings.Join(lines, "\n"),
+		},
+	}
+}
+
+// threadLink returns the link to the thread of the message in the slack workspace
+func threadLink(channel string, ts string) string {
+	workspace := strings.TrimSuffix(viper.GetString("slack_workspace_url"), "/")
+	if workspace == "" {
+		workspace = defaultWorkspaceURL
+	}
+	return fmt.Sprintf("%s/archives/%s/p%s", workspace, channel, strings.Replace(ts, ".", "", -1))
+}
+
+// excerpt returns the beginning of the text of the message, safe to be used as the label of a link
+func (a Analyser) excerpt(locale string, text string) string {
+	const maxLength = 60
+	text = strings.Join(strings.Fields(text), " ")
+	text = strings.NewReplacer("<", "", ">", "", "|", "").Replace(text)
+	if runes := []rune(text); len(runes) > maxLength {
+		text = string(runes[:maxLength]) + "…"
+	}
+	if text == "" {
+		text = a.Messages.Render(locale, "thread.no_text", nil)
+	}
+	return text
+}
+
+// age tells for how long the message was posted, in whole days, hours or minutes
+func (a Analyser) age(locale string, now time.Time, ts string) string {
+	age := now.Sub(time.Unix(int64(globals.ParseDuration(ts)), 0))
+	switch {
+	case age >= 24*time.Hour:
+		return a.Messages.Render(locale, "duration.days", i18n.Vars{"Count": int(age.Hours() / 24)})
+	case age >= time.Hour:
+		return a.Messages.Render(locale, "duration.hours", i18n.Vars{"Count": int(age.Hours())})
+	default:
+		return a.Messages.Render(locale, "duration.minutes", i18n.Vars{"Count": int(age.Minutes())})
+	}
+}
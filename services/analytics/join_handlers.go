@@ -1,13 +1,18 @@
 package analytics
 
 import (
+	"github.com/leboncoin/subot/pkg/config"
 	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+	log "github.com/sirupsen/logrus"
 )
 
 // HandleJoinMessage godoc
 // @Summary Handles people joining the channel
-// @Description For newcomers, it returns an ephemeral message
-// @Description containing the rules of the chan
+// @Description For newcomers, it returns the rules of the chan: the welcome message edited through the admin api,
+// @Description an ephemeral or a direct message, or else the welcome text configured for this channel,
+// @Description or else the welcome message of the locale of the channel or of the newcomer.
+// @Description Nobody is welcomed in the channels which are not configured
 // @Tags Analytics
 // @ID handle-join-message
 // @Accept  json
@@ -15,17 +20,35 @@ import (
 // @Param message body object true "Message content"
 // @Router /analytics/join [post]
 func (a Analyser) HandleJoinMessage(message globals.Message) (replies []globals.SlackResponse, err error) {
+	settings, ok := config.GetChannel(message.Channel)
+	if !ok {
+		log.WithFields(log.Fields{"channel": message.Channel}).Debug("Channel is not configured, do not welcome the newcomer")
+		return nil, nil
+	}
+	userLocale := locale(message.Channel, message.UserInfo)
+
+	welcome, found, err := a.welcomeMessage(settings.ID)
+	if err != nil {
+		log.WithFields(log.Fields{"channel": settings.ID, "error": err}).Error("Could not get the welcome message")
+	} else if found {
+		reply, err := a.welcomeReply(welcome, userLocale, message.UserID, settings.ID)
+		if err == nil {
+			return []globals.SlackResponse{reply}, nil
+		}
+		log.WithFields(log.Fields{"channel": settings.ID, "error": err}).Error("Invalid welcome message")
+	}
+
 	var reply globals.SlackResponse
 	reply.Action = globals.Ephemeral
 	reply.UserID = message.UserID
-	reply.Text = `:wave: _Bienvenue sur le chan de support engprod_
-
-	:warning: *A lire avant de poster* :warning:
-
-		:fireman: Une personne de l'équipe est dédiée chaque semaine à la gestion du support
-		:redcard: Merci de ne pas utiliser de @here ou @channel
-		:point_right: Merci d'exposer ta question dans ton premier message
-		:threadplz: Merci de continuer la discussion en thread`
+	reply.ChanID = settings.ID
+	reply.Text = settings.Welcome
+	if reply.Text == "" {
+		reply.Text = a.Messages.Render(userLocale, "welcome", i18n.Vars{
+			"User":    message.UserID,
+			"Channel": settings.ID,
+		})
+	}
 
 	return []globals.SlackResponse{reply}, nil
 }
@@ -12,9 +12,10 @@ import (
 // @Tags Labels
 // @ID get-labels
 // @Produce  json
+// @Param channel query string false "ID of the channel, all labels when empty"
 // @Router /labels [get]
 func (a Analyser) GetLabels(c *gin.Context) {
-	hits, err := a.ESClient.GetLabels()
+	hits, err := a.ESClient.GetLabels(c.Query("channel"))
 	if err != nil {
 		c.JSON(500, gin.H{
 			"error": err.Error(),
@@ -32,6 +33,7 @@ func (a Analyser) GetLabels(c *gin.Context) {
 // @ID add-label
 // @Produce  json
 // @Param name body string true "Name of the label to add"
+// @Param channel body string false "ID of the channel of this label, shared by every channel when empty"
 // @Param query body object true "The percolator query it shall match"
 // @Router /labels/new [post]
 func (a Analyser) AddLabel(c *gin.Context) {
@@ -61,6 +63,7 @@ func (a Analyser) AddLabel(c *gin.Context) {
 // @Produce  json
 // @Param label query string true "Label id to update"
 // @Param name body string true "Name of the label to add"
+// @Param channel body string false "ID of the channel of this label, shared by every channel when empty"
 // @Param query body object true "The percolator query it shall match"
 // @Router /labels/:label [put]
 func (a Analyser) EditLabel(c *gin.Context) {
@@ -1,15 +1,22 @@
 package analytics
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spf13/viper"
 	_ "github.com/spf13/viper/remote" // blank import for remote
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"github.com/leboncoin/subot/pkg/auth"
+	"github.com/leboncoin/subot/pkg/calendar"
 	"github.com/leboncoin/subot/pkg/config"
 	"github.com/leboncoin/subot/pkg/elastic"
 	engine "github.com/leboncoin/subot/pkg/engine_grpc_client"
+	"github.com/leboncoin/subot/pkg/i18n"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/pkg/postgres"
 	"github.com/leboncoin/subot/pkg/vault"
 )
 
@@ -17,9 +24,9 @@ import (
 func Run() {
 	config.Initialize()
 
-	es, err := elastic.Configure(false)
+	store, err := configureStorage()
 	if err != nil {
-		log.Fatalf("Could not initialize elasticsearch connection %s", err)
+		log.Fatalf("Could not initialize the storage %s", err)
 	}
 
 	var opts []grpc.DialOption
@@ -38,13 +45,78 @@ func Run() {
 		}
 	}
 
+	businessCalendar, err := calendar.Configure()
+	if err != nil {
+		log.Fatalf("Could not load business calendar : %s", err)
+	}
+
+	messages, err := configureMessages(store)
+	if err != nil {
+		log.Fatalf("Could not load the messages : %s", err)
+	}
+
 	// Init analytics
 	analyser := &Analyser{
 		Engine:   engineClient,
-		ESClient: es,
+		ESClient: store,
+		Calendar: businessCalendar,
+		Messages: messages,
 	}
 
 	authHandler, authServer := auth.NewServer()
 
 	runAPI(analyser, &authHandler, authServer)
 }
+
+// configureMessages loads the catalogue of the messages of the bot, with the templates edited through the api.
+// The edited templates are reloaded every templates_refresh_interval, for the edits made through the other instances
+func configureMessages(store elastic.Interface) (*i18n.Catalogue, error) {
+	catalogue, err := i18n.Configure()
+	if err != nil {
+		return nil, err
+	}
+	if err := reloadMessages(store, catalogue); err != nil {
+		log.Errorf("Could not fetch the message templates, using the default ones : %s", err)
+	}
+
+	viper.SetDefault("templates_refresh_interval", "1m")
+	if interval := viper.GetDuration("templates_refresh_interval"); interval > 0 {
+		go func() {
+			for range time.Tick(interval) {
+				if err := reloadMessages(store, catalogue); err != nil {
+					log.Errorf("Could not refresh the message templates : %s", err)
+				}
+			}
+		}()
+	}
+	return catalogue, nil
+}
+
+// reloadMessages replaces the edited templates of the catalogue by the stored ones, they are kept when these cannot
+// be fetched. The stored templates which do not render anymore are ignored
+func reloadMessages(store elastic.Interface, catalogue *i18n.Catalogue) error {
+	templates, err := store.GetMessageTemplates()
+	if err != nil {
+		return err
+	}
+	for template, err := range catalogue.Reload(templates) {
+		log.WithFields(log.Fields{"locale": template.Locale, "key": template.Key, "error": err}).Warn("Ignoring message template")
+	}
+	return nil
+}
+
+// configureStorage connects to the storage_backend holding the messages, elasticsearch unless configured otherwise
+func configureStorage() (elastic.Interface, error) {
+	viper.SetDefault("storage_backend", "elasticsearch")
+	switch backend := viper.GetString("storage_backend"); backend {
+	case "elasticsearch":
+		return elastic.Configure(false)
+	case "postgres":
+		return postgres.Configure()
+	case "memory":
+		log.Warn("Documents are stored in memory, they will be lost when the service stops")
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %s, shall be one of [elasticsearch, postgres, memory]", backend)
+	}
+}
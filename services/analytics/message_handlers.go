@@ -1,11 +1,14 @@
 package analytics
 
 import (
-	"github.com/gin-gonic/gin"
-	pb "github.com/leboncoin/subot/pkg/engine_grpc_client/engine"
+	"errors"
 	"strconv"
 	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/elastic"
+	pb "github.com/leboncoin/subot/pkg/engine_grpc_client/engine"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/leboncoin/subot/pkg/globals"
 )
@@ -16,11 +19,17 @@ import (
 // @Description It shall not respond to team members, returning a "Nothing" action.
 // @Description If the user already sent a message less than a minute ago
 // @Description it will remind him to respect threads.
+// @Description A message which is already stored (slack retries, replays) is ignored.
 // @Description The message is then analysed, looking for known tools and labels.
 // @Description If a known answer is found for those tools and labels,
 // @Description it shall send this answer and ask a feedback from the user.
+// @Description When several answers match, only the most useful ones according to
+// @Description the previous feedbacks are sent, and their IDs are stored on the message.
+// @Description The links to the resolved threads most similar to the message are sent as well,
+// @Description asking a feedback from the user on them with buttons of their own.
 // @Description At the end of the analyse, the message is stored
 // @Description in the database with all the information extracted.
+// @Description The request fails when the storage does not answer, for the event to be retried.
 // @Tags Analytics
 // @ID handle-message
 // @Accept  json
@@ -29,15 +38,26 @@ import (
 // @Router /analytics/user [post]
 func (a Analyser) HandleMessage(message globals.Message) ([]*globals.SlackResponse, error) {
 	var reply globals.SlackResponse
-	var documentID string
 	replies := make([]*globals.SlackResponse, 0)
 	replies = append(replies, &reply)
 	reply.Action = globals.ReplyMessage
 	reply.Ts = message.Timestamp
+	reply.ChanID = channelID(message.Channel)
 	reply.Text = ""
+	userLocale := locale(message.Channel, message.UserInfo)
 	message.FeedbackStatus = globals.NoFeedback
+	log.Debug("Check if message already exists")
+	storedMessages, err := a.ESClient.QueryRangeMessages(message.Channel, message.Timestamp, message.Timestamp)
+	if err != nil {
+		return replies, err
+	}
+	if len(storedMessages) > 0 {
+		log.Debug("Message already stored, do not reply twice")
+		reply.Action = globals.Nothing
+		return replies, nil
+	}
 	log.Debug("Check if message comes from team member")
-	isTeamMessage, err := a.ESClient.IsTeamMember(message.UserID)
+	isTeamMessage, err := a.ESClient.IsTeamMember(message.Channel, message.UserID)
 	if err != nil {
 		return replies, err
 	}
@@ -47,46 +67,62 @@ func (a Analyser) HandleMessage(message globals.Message) ([]*globals.SlackRespon
 		message.Type = "team"
 	}
 	log.Debug("Check if message not respecting threads")
-	LastUserMessages, err := a.ESClient.QueryLastUserMessages(message.UserID)
+	LastUserMessages, err := a.ESClient.QueryLastUserMessages(message.Channel, message.UserID)
 	log.Debug("Last user message", message.UserID)
 	if err != nil {
 		log.Error("Failed to fetch last user messages", err)
+		return replies, err
 	}
 	if len(LastUserMessages) > 0 {
 		log.Debug("Consecutive message detected")
 		log.Debug("Set reply text to : please respect threads")
-		reply.Text = reply.Text + "Merci de respecter les threads."
+		reply.Text = reply.Text + a.Messages.Render(userLocale, "message.threads", nil)
 	} else {
-		reply.Text = reply.Text + "Merci pour ton message."
+		reply.Text = reply.Text + a.Messages.Render(userLocale, "message.thanks", nil)
 	}
 	log.Debug("Get tools for event")
 	//incidents, err := a.ESClient.QueryIncidents(tools)
-	labels, err := a.ESClient.QueryLabels(message.Text)
+	labels, err := a.ESClient.QueryLabels(message.Channel, message.Text)
 	if err != nil {
 		log.Error("Got an error while querying labels", err)
-		return replies, nil
+		return replies, err
 	}
-	tools, err := a.ESClient.QueryTools(message.Text)
+	tools, err := a.ESClient.QueryTools(message.Channel, message.Text)
 	if err != nil {
 		log.Error("Got an error while querying tools", err)
-		return replies, nil
+		return replies, err
 	}
 
 	log.WithFields(log.Fields{"tools": tools, "labels": labels}).Debug("Got tools and labels")
 
-	answers, err := a.ESClient.QueryAnswers(tools, labels)
+	answers, err := a.ESClient.QueryAnswers(message.Channel, tools, labels)
 	if err != nil {
 		log.Error("Got an error while querying answers ", err)
-		return replies, nil
+		return replies, err
 	}
 
-	for _, a := range answers {
+	feedback := false
+	for _, answer := range a.rankAnswers(message.Channel, answers) {
 		log.Debug("Found predefined answer from elasticsearch")
-		reply.Text = reply.Text + "\n" + a.Answer
-		// Add a feedback request when a predefined answer is added to the message
-		if a.Feedback {
-			replies = append(replies, getFeedbackResponse(message.Timestamp))
-			message.FeedbackStatus = globals.AskedFeedback
+		reply.Text = reply.Text + "\n" + answer.Answer
+		message.Answers = append(message.Answers, answer.ID)
+		feedback = feedback || answer.Feedback
+	}
+	// Add a single feedback request when the predefined answers sent ask for it
+	if feedback {
+		replies = append(replies, a.getFeedbackResponse(userLocale, reply.ChanID, message.Timestamp))
+		message.FeedbackStatus = globals.AskedFeedback
+	}
+
+	if !isTeamMessage {
+		if threads := a.similarThreads(message); len(threads) > 0 {
+			reply.Text = reply.Text + "\n" + a.similarThreadsText(userLocale, threads)
+			for _, thread := range threads {
+				message.SimilarThreads = append(message.SimilarThreads, thread.Timestamp)
+			}
+			// the feedback on the suggestions tells whether they are useful
+			replies = append(replies, a.getSimilarFeedbackResponse(userLocale, reply.ChanID, message.Timestamp))
+			message.SimilarFeedback = globals.AskedFeedback
 		}
 	}
 
@@ -108,22 +144,141 @@ func (a Analyser) HandleMessage(message globals.Message) ([]*globals.SlackRespon
 	message.AILabels = aiLabels
 	message.Tools = tools
 	message.Labels = labels
-	message.Status = "unresponded"
-	message.RemindAt = strconv.FormatInt(time.Now().Add(reminderInterval).Unix(), 10)
+	message.Status = globals.StatusUnresponded
+	a.resetReminder(&message)
 
-	log.Debug("Save or update message with document ID = ", documentID)
-	err = a.ESClient.AddMessage(message, documentID)
+	log.Debug("Save message")
+	err = a.ESClient.AddMessage(message)
 	if err != nil {
 		log.Error("Got an error while saving message", err)
-		return replies, nil
+		return replies, err
 	}
 	return replies, nil
 }
 
 
+// GetMessages godoc
+// @Summary List and search the user messages
+// @Description Returns a page of the user messages posted in the range matching the filters, most recent first.
+// @Description The search looks for the text in the messages and in their replies.
+// @Description The next_cursor of the response shall be given, with the same filters, to fetch the following page.
+// @Description It is omitted on the last page.
+// @Tags Messages
+// @ID get-messages
+// @Produce  json
+// @Param channel query string false "Channel ID"
+// @Param start query string false "Start date (YYYY-MM-DD)"
+// @Param end query string false "End date (YYYY-MM-DD)"
+// @Param status query string false "Message status"
+// @Param label query string false "Label of the messages"
+// @Param tool query string false "Tool of the messages"
+// @Param user query string false "ID of the user who posted the messages"
+// @Param fireman query string false "ID of a team member who replied to the messages"
+// @Param has_feedback query bool false "Whether the user answered the feedback"
+// @Param q query string false "Text to search"
+// @Param sort query string false "One of [ts, response_time, resolution_time, relevance]"
+// @Param order query string false "One of [asc, desc]"
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param size query int false "Number of messages per page (max 1000)"
+// @Success 200 {object} globals.MessagePage
+// @Router /messages [get]
+func (a Analyser) GetMessages(c *gin.Context) {
+	query, err := messageQuery(c)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	page, err := a.ESClient.QueryMessagesPage(query)
+	if err == elastic.ErrInvalidCursor {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, page)
+}
+
+// messageQuery reads the filters, the sort and the page of a messages listing
+func messageQuery(c *gin.Context) (globals.MessageQuery, error) {
+	query := globals.MessageQuery{
+		Channel: c.Query("channel"),
+		Start:   c.DefaultQuery("start", "2019-01-01"),
+		End:     c.DefaultQuery("end", time.Now().Format(globals.DateLayout)),
+		Status:  globals.MessageStatus(c.Query("status")),
+		Label:   c.Query("label"),
+		Tool:    c.Query("tool"),
+		User:    c.Query("user"),
+		Fireman: c.Query("fireman"),
+		Search:  c.Query("q"),
+		Sort:    c.DefaultQuery("sort", "ts"),
+		Cursor:  c.Query("cursor"),
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "50"))
+	if err != nil || size <= 0 || size > elastic.PageSize {
+		return query, errors.New("size shall be between 1 and 1000")
+	}
+	query.Size = size
+
+	if !contains(globals.MessageSorts, query.Sort) {
+		return query, errors.New("sort shall be ts, response_time, resolution_time or relevance")
+	}
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		query.Ascending = true
+	case "desc":
+	default:
+		return query, errors.New("order shall be asc or desc")
+	}
+
+	if hasFeedback := c.Query("has_feedback"); hasFeedback != "" {
+		b, err := strconv.ParseBool(hasFeedback)
+		if err != nil {
+			return query, errors.New("has_feedback shall be true or false")
+		}
+		query.HasFeedback = &b
+	}
+	return query, nil
+}
+
+// GetMessage godoc
+// @Summary Get a thread
+// @Description Returns the user message posted at the timestamp, with its replies and reactions
+// @Tags Messages
+// @ID get-message
+// @Produce  json
+// @Param message_ts path string true "Timestamp of the message"
+// @Param channel query string false "Channel ID"
+// @Success 200 {object} globals.Message
+// @Router /messages/:message_ts [get]
+func (a Analyser) GetMessage(c *gin.Context) {
+	message, err := a.ESClient.GetMessage(c.Query("channel"), c.Param("message_ts"))
+	if err == elastic.ErrMessageNotFound {
+		c.JSON(404, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, message)
+}
+
 // EditMessage godoc
 // @Summary Edit tools, labels or status of a message
-// @Description For the given message timestamp,
+// @Description For the given message timestamp in the channel,
 // @Description store this information give in the payload
 // @Tags Messages
 // @ID edit-message
@@ -143,17 +298,26 @@ func (a Analyser) HandleMessage(message globals.Message) ([]*globals.SlackRespon
 // @Param reactions body object true "List of reactions added to this message"
 // @Param replies body object true "List of replies to this message"
 // @Param edited_ts body object true "Timestamp at which the message was last edited"
+// @Param message_ts path string true "Timestamp of the message"
+// @Param channel query string true "Channel ID"
 // @Router /messages/:message_ts [put]
 func (a Analyser) EditMessage(c *gin.Context) {
 	var eventRequest globals.Message
-	messageTs := c.Param("message_ts")
+	messageTs, channel := c.Param("message_ts"), c.Query("channel")
+	if channel == "" {
+		c.JSON(400, gin.H{
+			"error": "channel is required",
+		})
+		return
+	}
 	if err := c.BindJSON(&eventRequest); err != nil {
 		c.JSON(400, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
-	if err := a.ESClient.EditMessage(messageTs, eventRequest); err != nil {
+	eventRequest.Channel = channel
+	if err := a.ESClient.EditMessage(channel, messageTs, eventRequest); err != nil {
 		c.JSON(500, gin.H{
 			"error": err.Error(),
 		})
@@ -164,14 +328,22 @@ func (a Analyser) EditMessage(c *gin.Context) {
 
 // DeleteMessage godoc
 // @Summary Delete a message
-// @Description Set the message status to deleted in the data storage
+// @Description Removes the message posted at the timestamp in the channel from the data storage
 // @Tags Messages
 // @ID delete-message
 // @Produce  json
+// @Param message_ts path string true "Timestamp of the message"
+// @Param channel query string true "Channel ID"
 // @Router /messages/:message_ts [delete]
 func (a Analyser) DeleteMessage(c *gin.Context) {
-	messageTs := c.Param("message_ts")
-	if err := a.ESClient.DeleteMessage(messageTs); err != nil {
+	messageTs, channel := c.Param("message_ts"), c.Query("channel")
+	if channel == "" {
+		c.JSON(400, gin.H{
+			"error": "channel is required",
+		})
+		return
+	}
+	if err := a.ESClient.DeleteMessage(channel, messageTs); err != nil {
 		c.JSON(500, gin.H{
 			"error": err.Error(),
 		})
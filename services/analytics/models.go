@@ -1,8 +1,10 @@
 package analytics
 
 import (
+	"github.com/leboncoin/subot/pkg/calendar"
 	"github.com/leboncoin/subot/pkg/elastic"
 	"github.com/leboncoin/subot/pkg/engine_grpc_client"
+	"github.com/leboncoin/subot/pkg/i18n"
 )
 
 // APIRequest defines the structure of a call to the analytics api
@@ -24,6 +26,8 @@ type APIRequest struct {
 type Analyser struct {
 	ESClient elastic.Interface          `json:"es_client"`
 	Engine   engine_grpc_client.IEngine `json:"engine"`
+	Calendar *calendar.Calendar         `json:"calendar"`
+	Messages *i18n.Catalogue            `json:"messages"`
 }
 
 type reportTextSection struct {
@@ -1,19 +1,23 @@
 package analytics
 
 import (
-	"time"
-
 	log "github.com/sirupsen/logrus"
 	"github.com/leboncoin/subot/pkg/globals"
 )
 
 
 // HandleReaction godoc
-// @Summary Handles reactions, looking for a heavy check mark.
+// @Summary Handles reactions, applying the status workflow of the channel.
 // @Description Returns an empty reply but stores the new status
-// @Description if the reaction is heavy_check_mark.
-// @Description It also calculates response time
-// @Description based on local time and message timestamp.
+// @Description if the reaction matches a status rule of the workflow.
+// @Description It also calculates resolution time, based on the reaction
+// @Description and message timestamps, when the status closes the message.
+// @Description The resolution time is stored both in wall-clock and in business minutes,
+// @Description and the user who closed the message is asked for a resolution note.
+// @Description When a reaction is removed, the status is computed again
+// @Description from the remaining reactions, a message which is not closed
+// @Description anymore gets back to responded or unresponded and is reminded again.
+// @Description The time spent waiting for the requester is not counted in the resolution time
 // @Tags Analytics
 // @ID handle-reaction
 // @Accept  json
@@ -23,24 +27,136 @@ import (
 func (a Analyser) HandleReaction(reaction globals.Reaction) (replies []globals.SlackResponse, err error) {
 	var reply globals.SlackResponse
 	log.Debug("Get original message")
-	originalMessages, err := a.ESClient.QueryRangeMessages(reaction.MessageTs, reaction.MessageTs)
+	originalMessages, err := a.ESClient.QueryRangeMessages(reaction.Channel, reaction.MessageTs, reaction.MessageTs)
+	if err != nil {
+		return replies, err
+	}
 	if len(originalMessages) == 0 {
 		log.Debug("Original message not found, return")
 		return
 	}
 
+	workflow, err := a.ESClient.GetWorkflow(reaction.Channel)
+	if err != nil {
+		return replies, err
+	}
+
 	originalMessage := originalMessages[0]
-	originalMessage.Reactions = append(originalMessage.Reactions, reaction)
-	log.Debug("Calculate and save resolution time if reaction is :heavy_check_mark:")
+	if reaction.Removed {
+		originalMessage.Reactions = removeReaction(originalMessage.Reactions, reaction)
+	} else {
+		originalMessage.Reactions = addReaction(originalMessage.Reactions, reaction)
+	}
 
-	if reaction.Name == "heavy_check_mark" && originalMessage.Status != "fixed" {
-		resolutionTime := (globals.ParseDuration(reaction.Timestamp) - globals.ParseDuration(reaction.MessageTs)) / 60
-		originalMessage.ResolutionTime = time.Duration(resolutionTime)
-		originalMessage.Status = "fixed"
-		originalMessage.RemindAt = ""
+	wasClosed := workflow.IsClosing(originalMessage.Status)
+	wasWaiting := originalMessage.Status == globals.StatusWaitingUser
+	if rule, ok := workflow.Rule(reaction.Name); ok && !reaction.Removed {
+		// a message in progress can be closed, a closed message only changes for another closing status
+		if !wasClosed || rule.Closes {
+			log.WithFields(log.Fields{"status": rule.Status}).Debug("Apply the status of the reaction")
+			originalMessage.Status = rule.Status
+		}
+		if rule.Closes && !wasClosed {
+			a.resolve(&originalMessage, reaction.Timestamp)
+			if len(reaction.Users) > 0 {
+				replies = a.askResolutionNote(originalMessage, reaction.Users[0], noteLocale(originalMessage, reaction.Users[0]))
+			}
+		}
+	}
+
+	if reaction.Removed && hasStatus(workflow, reaction.Name) {
+		log.Debug("Status reaction removed, compute the status again")
+		if rule, found := workflow.StatusFromReactions(originalMessage.Reactions); found {
+			originalMessage.Status = rule.Status
+		} else {
+			originalMessage.Status, err = a.replyStatus(reaction.Channel, originalMessage.Replies)
+			if err != nil {
+				return replies, err
+			}
+		}
+	}
+
+	if wasClosed && !workflow.IsClosing(originalMessage.Status) {
+		log.Debug("Message is not closed anymore, remind it again")
+		originalMessage.ResolutionTime = 0
+		originalMessage.BusinessResolutionTime = 0
+		a.resetReminder(&originalMessage)
+	}
+	if !wasWaiting && originalMessage.Status == globals.StatusWaitingUser {
+		log.Debug("Wait for the requester")
+		a.startWaiting(&originalMessage)
+	} else if wasWaiting && originalMessage.Status != globals.StatusWaitingUser && !workflow.IsClosing(originalMessage.Status) {
+		log.Debug("Not waiting for the requester anymore, remind the thread again")
+		a.stopWaiting(&originalMessage, reaction.Timestamp)
+		a.resetReminder(&originalMessage)
 	}
 	log.WithFields(log.Fields{"event": reaction}).Debug("Save reaction for message")
-	err = a.ESClient.AddMessage(originalMessage, originalMessages[0].ID)
+	if err := a.ESClient.AddMessage(originalMessage, originalMessages[0].ID); err != nil {
+		return replies, err
+	}
 
-	return []globals.SlackResponse{reply}, nil
+	return append([]globals.SlackResponse{reply}, replies...), nil
+}
+
+// hasStatus checks if the emoji sets a status in the workflow
+func hasStatus(workflow globals.Workflow, emoji string) bool {
+	_, ok := workflow.Rule(emoji)
+	return ok
+}
+
+// replyStatus returns the status of a message without status reaction,
+// depending on whether a team member replied to it
+func (a Analyser) replyStatus(channel string, replies []globals.Reply) (globals.MessageStatus, error) {
+	for _, r := range replies {
+		isTeamReply, err := a.ESClient.IsTeamMember(channel, r.UserID)
+		if err != nil {
+			return "", err
+		}
+		if isTeamReply {
+			return globals.StatusResponded, nil
+		}
+	}
+	return globals.StatusUnresponded, nil
+}
+
+// addReaction adds the users of the reaction to the reactions of the message
+func addReaction(reactions []globals.Reaction, reaction globals.Reaction) []globals.Reaction {
+	for i, r := range reactions {
+		if r.Name != reaction.Name {
+			continue
+		}
+		for _, user := range reaction.Users {
+			if !contains(r.Users, user) {
+				r.Users = append(r.Users, user)
+			}
+		}
+		r.Count = len(r.Users)
+		reactions[i] = r
+		return reactions
+	}
+	reaction.Count = len(reaction.Users)
+	return append(reactions, reaction)
+}
+
+// removeReaction removes the users of the reaction from the reactions of the message,
+// dropping the reaction once nobody uses it anymore
+func removeReaction(reactions []globals.Reaction, reaction globals.Reaction) []globals.Reaction {
+	var result []globals.Reaction
+	for _, r := range reactions {
+		if r.Name == reaction.Name {
+			var users []string
+			for _, user := range r.Users {
+				if !contains(reaction.Users, user) {
+					users = append(users, user)
+				}
+			}
+			if len(users) == 0 {
+				continue
+			}
+			r.Users = users
+			r.Count = len(users)
+		}
+		result = append(result, r)
+	}
+	return result
 }
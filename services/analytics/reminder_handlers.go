@@ -1,15 +1,20 @@
 package analytics
 
 import (
-	"fmt"
-	log "github.com/sirupsen/logrus"
+	"strconv"
+	"time"
+
 	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+	log "github.com/sirupsen/logrus"
 )
 
 // HandleRemindersRequest godoc
 // @Summary Checks for reminders
-// @Description Returns the list of reminders to send
-// @Description when last reply on a message is an hour old
+// @Description Returns the list of reminders to send, for the threads reaching the next step
+// @Description of their escalation policy. The step reached by each thread is stored
+// @Description so that the thread is reminded again at the following step, if any.
+// @Description The requester of a thread waiting for them is reminded instead, then the thread is closed
 // @Tags Analytics
 // @ID handle-reminders-request
 // @Produce  json
@@ -22,12 +27,60 @@ func (a Analyser) HandleRemindersRequest() (replies []globals.SlackResponse, err
 	}
 
 	for _, message := range messages {
-		var reply globals.SlackResponse
-		reply.Action = globals.ReplyMessage
-		reply.Text = fmt.Sprintf("Du nouveau <@%s> ?", a.getFiremanID())
-		reply.Ts = message.Timestamp
-		replies = append(replies, reply)
+		if message.Status == globals.StatusWaitingUser {
+			reminders, err := a.remindRequester(&message)
+			if err != nil {
+				log.WithFields(log.Fields{"ts": message.Timestamp, "error": err}).Error("Could not remind the requester")
+				continue
+			}
+			replies = append(replies, reminders...)
+		} else if policy, step := a.escalationPolicy(message), message.EscalationStep; step < len(policy.Steps) {
+			activity := globals.ParseTimestamp(message.RemindAt).Add(-policy.Steps[step].Delay())
+			replies = append(replies, a.escalate(message, policy.Steps[step], activity))
+			message.EscalationStep = step + 1
+			message.RemindAt = policy.NextReminder(activity, step+1)
+		} else {
+			log.WithFields(log.Fields{"ts": message.Timestamp}).Debug("Every step of the escalation is reached")
+			message.RemindAt = ""
+		}
+
+		if err := a.ESClient.AddMessage(message, message.ID); err != nil {
+			log.WithFields(log.Fields{"ts": message.Timestamp, "error": err}).Error("Could not save the escalation step")
+		}
 	}
 
 	return
 }
+
+// escalate returns the reminder of the step of the thread, whose last activity is given
+func (a Analyser) escalate(message globals.Message, step globals.EscalationStep, activity time.Time) globals.SlackResponse {
+	channel := channelID(message.Channel)
+	teamLocale := channelSettings(message.Channel).Locale
+	reply := globals.SlackResponse{
+		Action: globals.ReplyMessage,
+		Ts:     message.Timestamp,
+		ChanID: channel,
+	}
+	switch step.Target {
+	case globals.EscalateUser:
+		reply.Text = a.Messages.Render(teamLocale, "reminder.user", i18n.Vars{"User": step.SlackID})
+	case globals.EscalateGroup:
+		reply.Text = a.Messages.Render(teamLocale, "reminder.group", i18n.Vars{"Group": step.SlackID})
+	case globals.EscalateDirect:
+		reply.Action = globals.ChannelMessage
+		reply.Ts = ""
+		reply.ChanID = step.SlackID
+		reply.Text = a.Messages.Render(teamLocale, "reminder.direct", i18n.Vars{
+			"Link":    threadLink(channel, message.Timestamp),
+			"Excerpt": a.excerpt(teamLocale, message.Text),
+			"Channel": channel,
+			"Age":     a.age(teamLocale, time.Now(), strconv.FormatInt(activity.Unix(), 10)),
+		})
+	default:
+		reply.Text = a.Messages.Render(teamLocale, "reminder.fireman", i18n.Vars{"Fireman": a.getFiremanID(message.Channel)})
+	}
+	if reply.Action == globals.ReplyMessage {
+		reply.Blocks = a.threadActionsTemplate(teamLocale, reply.Text, message.Timestamp)
+	}
+	return reply
+}
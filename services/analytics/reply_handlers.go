@@ -1,7 +1,6 @@
 package analytics
 
 import (
-	"strconv"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -11,7 +10,8 @@ import (
 // HandleReplies godoc
 // @Summary Handles message replies and calculates response time
 // @Description returns an empty reply but stores the replies to the storage.
-// @Description Calculates response times only for the first team member answer
+// @Description Calculates response times only for the first team member answer.
+// @Description An answer of the requester to a thread waiting for them resumes its reminders
 // @Tags Analytics
 // @ID handle-replies
 // @Accept  json
@@ -21,36 +21,56 @@ import (
 func (a Analyser) HandleReplies(message globals.Reply) (replies []globals.SlackResponse, err error) {
 	var reply globals.SlackResponse
 	log.Debug("Get original message")
-	originalMessages, err := a.ESClient.QueryRangeMessages(message.ThreadTs, message.ThreadTs)
+	originalMessages, err := a.ESClient.QueryRangeMessages(message.Channel, message.ThreadTs, message.ThreadTs)
+	if err != nil {
+		return
+	}
 	if len(originalMessages) == 0 {
 		log.Debug("Original message not found, return")
 		return
 	}
+	workflow, err := a.ESClient.GetWorkflow(message.Channel)
+	if err != nil {
+		return
+	}
 	originalMessage := originalMessages[0]
 	originalMessage.Replies = append(originalMessage.Replies, message)
-	if !message.FromBot && originalMessage.Status != "fixed" {
-		originalMessage.RemindAt = strconv.FormatInt(time.Now().Add(reminderInterval).Unix(), 10)
-	} else {
-		originalMessage.RemindAt = ""
-	}
 
 	log.Debug("Calculate and save response time if reply owner is team member")
 
-	isTeamMessage, err := a.ESClient.IsTeamMember(message.UserID)
+	isTeamMessage, err := a.ESClient.IsTeamMember(message.Channel, message.UserID)
 	if err != nil {
 		return
 	}
-	if isTeamMessage && originalMessage.Status == "unresponded" {
+	// the replies of the bot, its reminders among them, do not restart the escalation of the thread,
+	// nor do the replies of the team to a thread waiting for its requester
+	if workflow.IsClosing(originalMessage.Status) {
+		originalMessage.RemindAt = ""
+	} else if originalMessage.Status == globals.StatusWaitingUser {
+		if !message.FromBot && !isTeamMessage {
+			log.Debug("The requester answered, remind the thread again")
+			a.stopWaiting(&originalMessage, message.Timestamp)
+			originalMessage.Status = globals.StatusResponded
+			a.resetReminder(&originalMessage)
+		}
+	} else if !message.FromBot {
+		a.resetReminder(&originalMessage)
+	}
+
+	if isTeamMessage && originalMessage.Status == globals.StatusUnresponded {
 		log.Debug("Its a team message")
 		log.Debug("Set responded status")
-		originalMessage.Status = "responded"
+		originalMessage.Status = globals.StatusResponded
 		responseTime := (globals.ParseDuration(message.Timestamp) - globals.ParseDuration(message.ThreadTs)) / 60
 		log.Debug("Response time is : ", responseTime)
 		originalMessage.ResponseTime = time.Duration(responseTime)
+		originalMessage.BusinessResponseTime = a.businessTime(message.ThreadTs, message.Timestamp)
 	}
 
 	log.WithFields(log.Fields{"event": message}).Debug("Save reply for message")
-	err = a.ESClient.AddMessage(originalMessage, originalMessages[0].ID)
+	if err = a.ESClient.AddMessage(originalMessage, originalMessages[0].ID); err != nil {
+		return
+	}
 
 	return []globals.SlackResponse{reply}, nil
 }
@@ -7,18 +7,20 @@ import (
 // HandleReportRequest godoc
 // @Summary Generate a report for the given period
 // @Description returns the report containing performance data
-// @Description of the period and compared to last week
+// @Description of the period for the channel and compared to last week
 // @Tags Analytics
 // @ID handle-report-request
 // @Accept  json
 // @Produce  json
+// @Param channel body string true "The ID of the channel to generate report for"
 // @Param start body string true "The start of the period to generate report for"
 // @Param end body string true "The end of the period"
 // @Router /analytics/report [post]
-func (a Analyser) HandleReportRequest(start string, end string) (replies []globals.SlackResponse, err error) {
+func (a Analyser) HandleReportRequest(channel string, start string, end string) (replies []globals.SlackResponse, err error) {
 	var reply globals.SlackResponse
 	reply.Action = globals.ChannelMessage
-	statistics, err := a.Analyse(start, end)
+	reply.ChanID = channelID(channel)
+	statistics, err := a.Analyse(channel, start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -26,12 +28,13 @@ func (a Analyser) HandleReportRequest(start string, end string) (replies []globa
 	pastStart := subSevenDays(start)
 	pastEnd := subSevenDays(end)
 
-	pastStatistics, err := a.Analyse(pastStart, pastEnd)
+	pastStatistics, err := a.Analyse(channel, pastStart, pastEnd)
 	if err != nil {
 		return nil, err
 	}
-	report := buildReport(statistics, pastStatistics)
-	reply.Text = "Report"
+	teamLocale := channelSettings(channel).Locale
+	report := a.buildReport(teamLocale, statistics, pastStatistics)
+	reply.Text = a.Messages.Render(teamLocale, "report.text", nil)
 	reply.Blocks = report.Blocks
 	return []globals.SlackResponse{reply}, err
 }
@@ -7,17 +7,18 @@ import (
 	"strings"
 
 	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
 )
 
-func buildReport(statistics globals.Statistics, pastStatistics globals.Statistics) (reportForm reportResponse) {
+// buildReport returns the report of the statistics of the week compared to the past week, in the locale
+func (a Analyser) buildReport(locale string, statistics globals.Statistics, pastStatistics globals.Statistics) (reportForm reportResponse) {
 	responseTime := statistics.ResponseTime
 	resolutionRate := statistics.ResolutionRate
-	messagesDiff := len(statistics.Messages) - len(pastStatistics.Messages)
+	messagesDiff := statistics.Count - pastStatistics.Count
 	resolutionDiff := statistics.ResolutionRate - pastStatistics.ResolutionRate
-	messagesDiffLabel := "more"
-	if messagesDiff < 0 {
+	fewerMessages := messagesDiff < 0
+	if fewerMessages {
 		messagesDiff = int(math.Abs(float64(messagesDiff)))
-		messagesDiffLabel = "less"
 	}
 
 	var firemen []string
@@ -30,7 +31,7 @@ func buildReport(statistics globals.Statistics, pastStatistics globals.Statistic
 				Type: "section",
 				Text: map[string]string{
 					"type": "plain_text",
-					"text": "Here are the statistics of our performance on the support for the past week",
+					"text": a.Messages.Render(locale, "report.intro", nil),
 				},
 			},
 			reportFieldsSection{
@@ -38,27 +39,30 @@ func buildReport(statistics globals.Statistics, pastStatistics globals.Statistic
 				Fields: []map[string]string{
 					{
 						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Messages:*\n%d messages this week", len(statistics.Messages)),
+						"text": a.Messages.Render(locale, "report.messages", i18n.Vars{"Count": statistics.Count}),
 					},
 					{
 						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Resolution rate*\n%d%% fixed", resolutionRate),
+						"text": a.Messages.Render(locale, "report.resolution_rate", i18n.Vars{"Rate": resolutionRate}),
 					},
 					{
 						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Average response time*\n%d min", responseTime),
+						"text": a.Messages.Render(locale, "report.response_time", i18n.Vars{
+							"Minutes":         int64(responseTime),
+							"BusinessMinutes": int64(statistics.BusinessResponseTime),
+						}),
 					},
 					{
 						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Messages evolution*\n%d messages %s compared to last week", messagesDiff, messagesDiffLabel),
+						"text": a.Messages.Render(locale, "report.evolution", i18n.Vars{"Count": messagesDiff, "Less": fewerMessages}),
 					},
 					{
 						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Resolution rate compared to last week*\n%+d %%", resolutionDiff),
+						"text": a.Messages.Render(locale, "report.resolution_diff", i18n.Vars{"Diff": resolutionDiff}),
 					},
 					{
 						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Firemen*\n%s", strings.Join(firemen, " ")),
+						"text": a.Messages.Render(locale, "report.firemen", i18n.Vars{"Firemen": strings.Join(firemen, " ")}),
 					},
 				},
 			},
@@ -66,7 +70,7 @@ func buildReport(statistics globals.Statistics, pastStatistics globals.Statistic
 				Type: "section",
 				Text: map[string]string{
 					"type": "mrkdwn",
-					"text": fmt.Sprintf("For more statistics see our *<%s|analytics dashboard>*", viper.GetString("front_url")),
+					"text": a.Messages.Render(locale, "report.dashboard", i18n.Vars{"URL": viper.GetString("front_url")}),
 				},
 			},
 		},
@@ -0,0 +1,278 @@
+package analytics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxNoteLength is the length of the resolution notes, a single line
+const maxNoteLength = 300
+
+// noteInput is the action ID of the input of the resolution note in its modal
+const noteInput = "note"
+
+// askResolutionNote asks the user who closed the thread for a resolution note, in an ephemeral message of the channel.
+// Nothing is asked when the thread already has a note
+func (a Analyser) askResolutionNote(message globals.Message, user string, locale string) []globals.SlackResponse {
+	if message.ResolutionNote != "" || user == "" {
+		return nil
+	}
+	text := a.Messages.Render(locale, "resolution.ask", nil)
+	return []globals.SlackResponse{{
+		Action: globals.Ephemeral,
+		Text:   text,
+		Blocks: []interface{}{
+			feedbackTextSection{
+				Type: "section",
+				Text: map[string]string{
+					"type": "mrkdwn",
+					"text": text,
+				},
+			},
+			threadActionsSection{
+				Type: "actions",
+				Elements: []threadActionElement{{
+					Type:     "button",
+					ActionID: globals.ResolutionNoteAction,
+					Value:    message.Timestamp,
+					Text: feedbackElementTextSection{
+						Type:  "plain_text",
+						Text:  a.Messages.Render(locale, "resolution.button", nil),
+						Emoji: true,
+					},
+				}},
+			},
+		},
+		ChanID: channelID(message.Channel),
+		UserID: user,
+	}}
+}
+
+// resolutionNoteView is the modal asking for the resolution note of the thread, filled with its current note
+func (a Analyser) resolutionNoteView(locale string, message globals.Message) (map[string]interface{}, error) {
+	// the private metadata is the interaction the modal submission continues
+	metadata, err := json.Marshal(globals.Interaction{Channel: message.Channel, MessageTs: message.Timestamp})
+	if err != nil {
+		return nil, err
+	}
+	plainText := func(key string) map[string]interface{} {
+		return map[string]interface{}{"type": "plain_text", "text": a.Messages.Render(locale, key, nil)}
+	}
+	input := map[string]interface{}{
+		"type":       "plain_text_input",
+		"action_id":  noteInput,
+		"max_length": maxNoteLength,
+	}
+	if message.ResolutionNote != "" {
+		input["initial_value"] = message.ResolutionNote
+	}
+	return map[string]interface{}{
+		"type":             "modal",
+		"callback_id":      globals.ResolutionNoteAction,
+		"private_metadata": string(metadata),
+		"title":            plainText("resolution.title"),
+		"submit":           plainText("resolution.submit"),
+		"close":            plainText("resolution.cancel"),
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"type":     "input",
+				"block_id": noteInput,
+				"label":    plainText("resolution.label"),
+				"element":  input,
+			},
+		},
+	}, nil
+}
+
+// noteLocale returns the locale of the user adding the resolution note of the thread,
+// the one of the requester when they add it themselves
+func noteLocale(message globals.Message, user string) string {
+	if message.UserID == user {
+		return locale(message.Channel, message.UserInfo)
+	}
+	return channelSettings(message.Channel).Locale
+}
+
+// HandleResolutionNoteAction godoc
+// @Summary Opens the modal of the resolution note of a thread
+// @Description Handles the button asking for the resolution note of a closed thread,
+// @Description the modal is opened to the user who clicked it
+// @Tags Analytics
+// @ID handle-resolution-note-action
+// @Accept  json
+// @Produce  json
+// @Param interaction body object true "Interaction object sent by slack, its action value being the timestamp of the thread"
+// @Router /analytics/resolution_note [post]
+func (a Analyser) HandleResolutionNoteAction(interaction globals.Interaction) ([]globals.SlackResponse, error) {
+	if interaction.TriggerID == "" {
+		return nil, errors.New("the trigger ID of the interaction is required to open the modal")
+	}
+	messages, err := a.ESClient.QueryRangeMessages(interaction.Channel, interaction.ActionValue, interaction.ActionValue)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("thread %s not found", interaction.ActionValue)
+	}
+	view, err := a.resolutionNoteView(noteLocale(messages[0], interaction.ActionUserID), messages[0])
+	if err != nil {
+		return nil, err
+	}
+	return []globals.SlackResponse{{
+		Action:    globals.OpenModal,
+		TriggerID: interaction.TriggerID,
+		View:      view,
+	}}, nil
+}
+
+// HandleViewSubmission godoc
+// @Summary Handles the modals submitted by the users
+// @Description The resolution note of a thread is stored on the thread, where it is searchable,
+// @Description and posted in the thread
+// @Tags Analytics
+// @ID handle-view-submission
+// @Accept  json
+// @Produce  json
+// @Param interaction body object true "Interaction of the modal, its action value being the callback ID of the modal"
+// @Router /analytics/view_submission [post]
+func (a Analyser) HandleViewSubmission(interaction globals.Interaction) ([]globals.SlackResponse, error) {
+	switch interaction.ActionValue {
+	case globals.ResolutionNoteAction:
+		return a.saveResolutionNote(interaction)
+	default:
+		return nil, fmt.Errorf("unknown modal %q", interaction.ActionValue)
+	}
+}
+
+// saveResolutionNote stores the resolution note submitted for the thread and posts it in the thread
+func (a Analyser) saveResolutionNote(interaction globals.Interaction) ([]globals.SlackResponse, error) {
+	note := strings.Join(strings.Fields(interaction.Values[noteInput]), " ")
+	if note == "" {
+		return nil, errors.New("the resolution note is empty")
+	}
+	messages, err := a.ESClient.QueryRangeMessages(interaction.Channel, interaction.MessageTs, interaction.MessageTs)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("thread %s not found", interaction.MessageTs)
+	}
+	message := messages[0]
+	message.ResolutionNote = note
+	message.ResolutionNoteUser = interaction.ActionUserID
+	if err := a.ESClient.AddMessage(message, message.ID); err != nil {
+		return nil, err
+	}
+	log.WithFields(log.Fields{"ts": message.Timestamp, "user": interaction.ActionUserID}).Info("Resolution note saved")
+
+	return []globals.SlackResponse{{
+		Action: globals.ReplyMessage,
+		Text: a.Messages.Render(channelSettings(message.Channel).Locale, "resolution.saved", i18n.Vars{
+			"User": interaction.ActionUserID,
+			"Note": note,
+		}),
+		Ts:     message.Timestamp,
+		ChanID: channelID(message.Channel),
+	}}, nil
+}
+
+// resolutionAnswers returns the answers promoted from the resolution note of the thread,
+// one for every tool and label of the thread
+func resolutionAnswers(message globals.Message, answer globals.Answer) []globals.Answer {
+	tools := message.Tools
+	if len(tools) == 0 {
+		tools = []string{""}
+	}
+	labels := message.Labels
+	if len(labels) == 0 {
+		labels = []string{""}
+	}
+	var answers []globals.Answer
+	for _, tool := range tools {
+		for _, label := range labels {
+			if tool == "" && label == "" {
+				continue
+			}
+			answers = append(answers, globals.Answer{
+				Channel:  message.Channel,
+				Tool:     tool,
+				Label:    label,
+				Answer:   answer.Answer,
+				Feedback: answer.Feedback,
+			})
+		}
+	}
+	return answers
+}
+
+// PromoteResolutionNote godoc
+// @Summary Promote the resolution note of a thread to answers
+// @Description Saves an answer for every tool and label of the thread, replying its resolution note
+// @Description to the next threads matching them. The text of the answers defaults to the note.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Messages
+// @ID promote-resolution-note
+// @Produce  json
+// @Param message_ts path string true "Timestamp of the thread"
+// @Param channel query string false "ID of the channel of the thread"
+// @Param answer body string false "The answer to reply when matched, the resolution note when empty"
+// @Param feedback body bool false "Whether or not the bot shall ask for a user feedback"
+// @Router /messages/:message_ts/answers [post]
+func (a Analyser) PromoteResolutionNote(c *gin.Context) {
+	messageTs := c.Param("message_ts")
+	var answer globals.Answer
+	if err := c.ShouldBindJSON(&answer); err != nil && err != io.EOF {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	messages, err := a.ESClient.QueryRangeMessages(c.Query("channel"), messageTs, messageTs)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if len(messages) == 0 || messages[0].Type != globals.NewMessage {
+		c.JSON(404, gin.H{
+			"error": fmt.Sprintf("thread %s not found", messageTs),
+		})
+		return
+	}
+	message := messages[0]
+	if message.ResolutionNote == "" {
+		c.JSON(400, gin.H{
+			"error": "the thread has no resolution note",
+		})
+		return
+	}
+	if strings.TrimSpace(answer.Answer) == "" {
+		answer.Answer = message.ResolutionNote
+	}
+	answers := resolutionAnswers(message, answer)
+	if len(answers) == 0 {
+		c.JSON(400, gin.H{
+			"error": "the thread has neither tools nor labels to match",
+		})
+		return
+	}
+	for _, promoted := range answers {
+		if err := a.ESClient.AddAnswer(promoted); err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	log.WithFields(log.Fields{"ts": message.Timestamp, "answers": len(answers)}).Info("Resolution note promoted")
+	c.JSON(201, answers)
+}
@@ -0,0 +1,398 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetRotations godoc
+// @Summary Get the fireman rotations
+// @Description Returns the rotations, with their roster and overrides.
+// @Description No authentication required
+// @Tags Rotations
+// @ID get-rotations
+// @Produce  json
+// @Param channel query string false "ID of the channel, all rotations when empty"
+// @Router /rotations [get]
+func (a Analyser) GetRotations(c *gin.Context) {
+	rotations, err := a.ESClient.GetRotations(c.Query("channel"))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if rotations == nil {
+		rotations = []globals.Rotation{}
+	}
+	c.JSON(200, rotations)
+}
+
+// GetRotationSchedule godoc
+// @Summary Get the shifts of a rotation
+// @Description Returns the firemen of the rotation from the start to the end date, overrides applied.
+// @Description The days following each other with the same fireman are merged in a single shift.
+// @Description No authentication required
+// @Tags Rotations
+// @ID get-rotation-schedule
+// @Produce  json
+// @Param rotation query string true "Rotation id"
+// @Param start query string false "Start date (YYYY-MM-DD), today when empty"
+// @Param end query string false "End date (YYYY-MM-DD), four weeks after the start when empty"
+// @Router /rotations/:rotation/schedule [get]
+func (a Analyser) GetRotationSchedule(c *gin.Context) {
+	rotation, status, err := a.rotationByID(c.Param("rotation"))
+	if err != nil {
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	start := c.DefaultQuery("start", a.today())
+	from, err := time.Parse(globals.DateLayout, start)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": "start shall be a date (YYYY-MM-DD)",
+		})
+		return
+	}
+	end := c.DefaultQuery("end", from.AddDate(0, 0, 27).Format(globals.DateLayout))
+	shifts, err := rotation.Schedule(start, end)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": "end shall be a date (YYYY-MM-DD)",
+		})
+		return
+	}
+	c.JSON(200, shifts)
+}
+
+// AddRotation godoc
+// @Summary Create new fireman rotation
+// @Description Stores a new rotation of the team members into the database.
+// @Description The members of the roster take turns as fireman, in order, from the start date.
+// @Description A channel can only have one rotation.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Rotations
+// @ID add-rotation
+// @Produce  json
+// @Param roster body array true "Slack IDs of the team members, in the order of their shifts"
+// @Param start body string true "Date of the first shift (YYYY-MM-DD)"
+// @Param shift_days body integer false "Number of days of a shift, a week when empty"
+// @Param topic body string false "Topic set on handover, {fireman} being replaced by the fireman. The topic is not managed when empty"
+// @Param overrides body array false "Members replaced or skipped during a period"
+// @Param channel body string false "ID of the channel of this rotation, shared by every channel when empty"
+// @Router /rotations/new [post]
+func (a Analyser) AddRotation(c *gin.Context) {
+	var rotation globals.Rotation
+	if err := c.BindJSON(&rotation); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if status, err := a.checkRotation("", rotation); err != nil {
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.ESClient.AddRotation(rotation); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(201, gin.H{})
+}
+
+// EditRotation godoc
+// @Summary Edit specified fireman rotation
+// @Description Modify the data stored in the database
+// @Description for the document having the given rotation ID.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Rotations
+// @ID edit-rotation
+// @Produce  json
+// @Param rotation query string true "Rotation id to update"
+// @Param roster body array true "Slack IDs of the team members, in the order of their shifts"
+// @Param start body string true "Date of the first shift (YYYY-MM-DD)"
+// @Param shift_days body integer false "Number of days of a shift, a week when empty"
+// @Param topic body string false "Topic set on handover, {fireman} being replaced by the fireman. The topic is not managed when empty"
+// @Param overrides body array false "Members replaced or skipped during a period"
+// @Param channel body string false "ID of the channel of this rotation, shared by every channel when empty"
+// @Router /rotations/:rotation [put]
+func (a Analyser) EditRotation(c *gin.Context) {
+	id := c.Param("rotation")
+	var rotation globals.Rotation
+	if err := c.BindJSON(&rotation); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if status, err := a.checkRotation(id, rotation); err != nil {
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.ESClient.EditRotation(id, rotation); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{})
+}
+
+// DeleteRotation godoc
+// @Summary Delete specified fireman rotation
+// @Description Deletes the entry matching the ID.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Rotations
+// @ID delete-rotation
+// @Produce  json
+// @Param rotation query string true "Rotation id to delete"
+// @Router /rotations/:rotation [delete]
+func (a Analyser) DeleteRotation(c *gin.Context) {
+	if err := a.ESClient.DeleteRotation(c.Param("rotation")); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(204, gin.H{})
+}
+
+// AddRotationOverride godoc
+// @Summary Replace or skip a member of a rotation
+// @Description Adds an override to the rotation: the member is replaced from the start to the end date, both included.
+// @Description Without replacement the member is skipped, during vacations, and the next available member takes the shift.
+// @Description A swap is two overrides, each member replacing the other one during the other's shift.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Rotations
+// @ID add-rotation-override
+// @Produce  json
+// @Param rotation query string true "Rotation id"
+// @Param slack_id body string true "Slack ID of the replaced member"
+// @Param replacement body string false "Slack ID of the team member taking the shift, the member is skipped when empty"
+// @Param start body string true "First day of the override (YYYY-MM-DD)"
+// @Param end body string true "Last day of the override (YYYY-MM-DD)"
+// @Router /rotations/:rotation/overrides [post]
+func (a Analyser) AddRotationOverride(c *gin.Context) {
+	var override globals.RotationOverride
+	if err := c.BindJSON(&override); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	rotation, status, err := a.rotationByID(c.Param("rotation"))
+	if err != nil {
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	rotation.Overrides = append(rotation.Overrides, override)
+	if status, err := a.checkRotation(rotation.ID, rotation); err != nil {
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.ESClient.EditRotation(rotation.ID, rotation); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(201, gin.H{})
+}
+
+// HandleRotationRequest godoc
+// @Summary Checks the rotations for a new fireman
+// @Description Returns the announcements of the firemen whose shift started since the last handover
+// @Description of their channel, the new topic of the channel when the rotation manages it and the handover digest.
+// @Description The new fireman is stored like the ones found in the topic changes
+// @Tags Analytics
+// @ID handle-rotation-request
+// @Produce  json
+// @Router /analytics/rotation [get]
+func (a Analyser) HandleRotationRequest() (replies []globals.SlackResponse, err error) {
+	today := a.today()
+	for _, channel := range config.Channels() {
+		rotations, err := a.ESClient.GetRotations(channel.ID)
+		if err != nil {
+			return replies, err
+		}
+		rotation, ok := globals.RotationFor(rotations, channel.ID)
+		if !ok {
+			continue
+		}
+		fireman := rotation.FiremanOn(today)
+		if fireman == "" {
+			log.WithFields(log.Fields{"channel": channel.ID}).Warn("No fireman available in the rotation")
+			continue
+		}
+		if a.lastFireman(channel.ID, rotation.ShiftLength()) == fireman {
+			continue
+		}
+
+		log.WithFields(log.Fields{"channel": channel.ID, "fireman": fireman}).Debug("Hand over to the new fireman")
+		if err := a.ESClient.AddFireman(a.firemanMessage(channel.ID, fireman)); err != nil {
+			return replies, err
+		}
+		replies = append(replies, globals.SlackResponse{
+			Action: globals.ChannelMessage,
+			Text:   a.Messages.Render(channel.Locale, "rotation.announce", i18n.Vars{"Fireman": fireman}),
+			ChanID: channel.ID,
+		})
+		if topic := rotation.TopicFor(fireman); topic != "" {
+			replies = append(replies, globals.SlackResponse{
+				Action: globals.SetTopic,
+				Text:   topic,
+				ChanID: channel.ID,
+			})
+		}
+		replies = append(replies, a.handoverDigest(channel, fireman)...)
+	}
+	return replies, nil
+}
+
+// checkRotation validates the rotation and makes sure that the channel has no other rotation.
+// The members of the roster and the replacements shall be team members of the channel.
+// It returns the http status to answer with when the rotation is invalid
+func (a Analyser) checkRotation(id string, rotation globals.Rotation) (int, error) {
+	if len(rotation.Roster) == 0 {
+		return 400, fmt.Errorf("roster is required")
+	}
+	if _, err := time.Parse(globals.DateLayout, rotation.Start); err != nil {
+		return 400, fmt.Errorf("start shall be a date (YYYY-MM-DD)")
+	}
+	if rotation.ShiftDays < 0 {
+		return 400, fmt.Errorf("shift_days shall be positive")
+	}
+	members := append([]string{}, rotation.Roster...)
+	for _, override := range rotation.Overrides {
+		if !contains(rotation.Roster, override.SlackID) {
+			return 400, fmt.Errorf("%s is not in the roster of the rotation", override.SlackID)
+		}
+		start, err := time.Parse(globals.DateLayout, override.Start)
+		if err != nil {
+			return 400, fmt.Errorf("start of the overrides shall be a date (YYYY-MM-DD)")
+		}
+		end, err := time.Parse(globals.DateLayout, override.End)
+		if err != nil || end.Before(start) {
+			return 400, fmt.Errorf("end of the overrides shall be a date (YYYY-MM-DD) after their start")
+		}
+		if override.Replacement != "" {
+			members = append(members, override.Replacement)
+		}
+	}
+	for _, member := range members {
+		isMember, err := a.ESClient.IsTeamMember(rotation.Channel, member)
+		if err != nil {
+			return 500, err
+		}
+		if !isMember {
+			return 400, fmt.Errorf("%s is not a team member of the channel", member)
+		}
+	}
+
+	rotations, err := a.ESClient.GetRotations(rotation.Channel)
+	if err != nil {
+		return 500, err
+	}
+	for _, r := range rotations {
+		if r.ID != id && r.Channel == rotation.Channel {
+			return 409, fmt.Errorf("channel already has the rotation %s", r.ID)
+		}
+	}
+	return 200, nil
+}
+
+// rotationByID returns the rotation of the document ID, and the http status to answer with when it is not found
+func (a Analyser) rotationByID(id string) (globals.Rotation, int, error) {
+	rotations, err := a.ESClient.GetRotations("")
+	if err != nil {
+		return globals.Rotation{}, 500, err
+	}
+	for _, rotation := range rotations {
+		if rotation.ID == id {
+			return rotation, 200, nil
+		}
+	}
+	return globals.Rotation{}, 404, fmt.Errorf("rotation %s not found", id)
+}
+
+// rotationFireman returns the fireman of the rotation of the channel today, empty when the channel has no rotation
+func (a Analyser) rotationFireman(channel string) string {
+	rotations, err := a.ESClient.GetRotations(channel)
+	if err != nil {
+		log.WithFields(log.Fields{"channel": channel, "error": err}).Error("Could not fetch the rotations")
+		return ""
+	}
+	rotation, ok := globals.RotationFor(rotations, channel)
+	if !ok {
+		return ""
+	}
+	return rotation.FiremanOn(a.today())
+}
+
+// lastFireman returns the last fireman stored for the channel during the last shift, by the rotation or a topic change
+func (a Analyser) lastFireman(channel string, shiftDays int) string {
+	now := time.Now().In(a.location())
+	start := now.AddDate(0, 0, -shiftDays).Format(globals.DateLayout)
+	end := now.AddDate(0, 0, 1).Format(globals.DateLayout)
+	firemen, err := a.ESClient.QueryRangeFireman(channel, start, end)
+	if err != nil || len(firemen) == 0 {
+		return ""
+	}
+	sort.SliceStable(firemen, func(i, j int) bool {
+		return globals.ParseDuration(firemen[i].Timestamp) < globals.ParseDuration(firemen[j].Timestamp)
+	})
+	return firemen[len(firemen)-1].UserInfo.ID
+}
+
+// firemanMessage is the fireman document of the rotation, stored like the topic changes
+func (a Analyser) firemanMessage(channel string, fireman string) globals.Message {
+	user := globals.User{ID: fireman}
+	if members, err := a.ESClient.GetTeamMembers(channel); err == nil {
+		for _, member := range members {
+			if member.SlackID == fireman {
+				user.Name = member.Name
+			}
+		}
+	}
+	return globals.Message{
+		Type:      globals.TopicChange,
+		Channel:   channel,
+		UserID:    fireman,
+		UserName:  user.Name,
+		UserInfo:  user,
+		Timestamp: strconv.FormatInt(time.Now().Unix(), 10) + ".000000",
+	}
+}
+
+// location is the time zone of the dates of the rotations, the one of the business calendar
+func (a Analyser) location() *time.Location {
+	if a.Calendar != nil && a.Calendar.Location != nil {
+		return a.Calendar.Location
+	}
+	return time.Local
+}
+
+// today returns the date of the day in the time zone of the rotations
+func (a Analyser) today() string {
+	return time.Now().In(a.location()).Format(globals.DateLayout)
+}
@@ -0,0 +1,124 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+	log "github.com/sirupsen/logrus"
+)
+
+// similarThreads returns the resolved threads of the channel similar to the message, most similar first.
+// Only the best threads of the channel scoring at least its similarity score, or the default one of the storage, are kept
+func (a Analyser) similarThreads(message globals.Message) []globals.SimilarMessage {
+	settings := channelSettings(message.Channel)
+	if settings.DisableSimilarThreads || strings.TrimSpace(message.Text) == "" {
+		return nil
+	}
+	candidates, err := a.ESClient.QuerySimilarMessages(message.Channel, message.Text, settings.SimilarThreads, settings.SimilarScore)
+	if err != nil {
+		log.WithFields(log.Fields{"ts": message.Timestamp, "error": err}).Error("Could not query the similar threads")
+		return nil
+	}
+	var threads []globals.SimilarMessage
+	for _, candidate := range candidates {
+		if candidate.Timestamp == message.Timestamp {
+			continue
+		}
+		threads = append(threads, candidate)
+	}
+	log.WithFields(log.Fields{"ts": message.Timestamp, "threads": len(threads)}).Debug("Got similar threads")
+	return threads
+}
+
+// similarThreadsText lists the links to the similar threads, with their resolution note
+func (a Analyser) similarThreadsText(locale string, threads []globals.SimilarMessage) string {
+	lines := []string{a.Messages.Render(locale, "similar.intro", nil)}
+	for _, thread := range threads {
+		lines = append(lines, a.Messages.Render(locale, "similar.thread", i18n.Vars{
+			"Link":    threadLink(channelID(thread.Channel), thread.Timestamp),
+			"Excerpt": a.excerpt(locale, thread.Text),
+			"Note":    thread.ResolutionNote,
+		}))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getSimilarFeedbackResponse asks the requester whether the similar threads suggested helped, with buttons of their own
+// so that the feedback is credited to the suggestions and not to the thread or to its automatic answers
+func (a Analyser) getSimilarFeedbackResponse(locale string, channel string, ts string) *globals.SlackResponse {
+	button := func(style string, value globals.FeedbackStatus, label string) threadActionElement {
+		return threadActionElement{
+			Type:     "button",
+			ActionID: globals.SimilarFeedbackAction,
+			Style:    style,
+			Value:    string(value),
+			Text:     feedbackElementTextSection{Type: "plain_text", Text: label, Emoji: true},
+		}
+	}
+	return &globals.SlackResponse{
+		Action: globals.ReplyMessage,
+		Blocks: []interface{}{
+			feedbackTextSection{
+				Type: "section",
+				Text: map[string]string{
+					"type": "mrkdwn",
+					"text": a.Messages.Render(locale, "similar.question", nil),
+				},
+			},
+			threadActionsSection{
+				Type: "actions",
+				Elements: []threadActionElement{
+					button("primary", globals.UsefulFeedback, a.Messages.Render(locale, "similar.useful", nil)),
+					button("danger", globals.UselessFeedback, a.Messages.Render(locale, "similar.useless", nil)),
+				},
+			},
+		},
+		Ts:     ts,
+		ChanID: channel,
+	}
+}
+
+// HandleSimilarFeedback godoc
+// @Summary Store the feedback of the requester on the similar threads suggested
+// @Description The feedback is stored in the similar_feedback of the message, and the message
+// @Description asking for it is updated. It neither changes the status of the message
+// @Description nor the usefulness of its automatic answers.
+// @Description Only the user that sent the original message is allowed to complete the interaction
+// @Tags Analytics
+// @ID handle-similar-feedback
+// @Accept  json
+// @Produce  json
+// @Param interaction body object true "Interaction object sent by slack"
+// @Router /analytics/similar_feedback [post]
+func (a Analyser) HandleSimilarFeedback(interaction globals.Interaction) ([]globals.SlackResponse, error) {
+	feedback := globals.FeedbackStatus(interaction.ActionValue)
+	if feedback != globals.UsefulFeedback && feedback != globals.UselessFeedback {
+		return nil, fmt.Errorf("unknown feedback %q on the similar threads", interaction.ActionValue)
+	}
+	messages, err := a.ESClient.QueryRangeMessages(interaction.Channel, interaction.ThreadTs, interaction.ThreadTs)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		log.WithFields(log.Fields{"ts": interaction.ThreadTs}).Debug("Original message not found, return")
+		return nil, nil
+	}
+	message := messages[0]
+	if message.UserID != interaction.ActionUserID {
+		return nil, errors.New("users don't match. Only the message owner can give a feedback on the similar threads")
+	}
+
+	message.SimilarFeedback = feedback
+	if err := a.ESClient.AddMessage(message, message.ID); err != nil {
+		return nil, err
+	}
+	userLocale := locale(interaction.Channel, message.UserInfo)
+	return []globals.SlackResponse{{
+		Action:      globals.UpdateBlockKit,
+		Text:        a.Messages.Render(userLocale, "similar.thanks", nil),
+		ResponseURL: interaction.ResponseURL,
+	}}, nil
+}
@@ -11,9 +11,10 @@ import (
 // @Tags Team
 // @ID get-team-members
 // @Produce  json
+// @Param channel query string false "ID of the channel, all team members when empty"
 // @Router /team [get]
 func (a Analyser) GetTeamMembers(c *gin.Context) {
-	answers, err := a.ESClient.GetTeamMembers()
+	answers, err := a.ESClient.GetTeamMembers(c.Query("channel"))
 	if err != nil {
 		c.JSON(500, gin.H{
 			"error": err.Error(),
@@ -32,6 +33,7 @@ func (a Analyser) GetTeamMembers(c *gin.Context) {
 // @Produce  json
 // @Param slack_id body object true "ID of the user in slack"
 // @Param name body object true "The name of the user only used in frontend display"
+// @Param channel body string false "ID of the channel the member supports, every channel when empty"
 // @Router /team/new [post]
 func (a Analyser) AddTeamMember(c *gin.Context) {
 	var eventRequest globals.TeamMember
@@ -61,6 +63,7 @@ func (a Analyser) AddTeamMember(c *gin.Context) {
 // @Param team_member query string true "Team member id to update"
 // @Param slack_id body object true "ID of the user in slack"
 // @Param name body object true "The name of the user only used in frontend display"
+// @Param channel body string false "ID of the channel the member supports, every channel when empty"
 // @Router /team/:team_member [put]
 func (a Analyser) EditTeamMember(c *gin.Context) {
 	documentID := c.Param("team_member")
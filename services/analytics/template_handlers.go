@@ -0,0 +1,96 @@
+package analytics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+)
+
+// GetMessageTemplates godoc
+// @Summary Get the templates of the messages of the bot
+// @Description Returns the template of every message of the bot in the locale, with the default one it replaces
+// @Description and the variables it can use. The messages missing in the locale fall back on another locale.
+// @Description No authentication required
+// @Tags Templates
+// @ID get-message-templates
+// @Produce  json
+// @Param locale query string false "Locale of the templates, every locale when empty"
+// @Router /templates [get]
+func (a Analyser) GetMessageTemplates(c *gin.Context) {
+	locales := a.Messages.Locales()
+	if locale := c.Query("locale"); locale != "" {
+		locales = []string{locale}
+	}
+	entries := []i18n.Entry{}
+	for _, locale := range locales {
+		entries = append(entries, a.Messages.Entries(locale)...)
+	}
+	c.JSON(200, entries)
+}
+
+// EditMessageTemplate godoc
+// @Summary Edit the template of a message of the bot
+// @Description Replaces the template of the message in the locale, applied right away by this instance
+// @Description and within templates_refresh_interval by the others.
+// @Description The template is a go text/template, using the variables of the message.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Templates
+// @ID edit-message-template
+// @Produce  json
+// @Param locale query string true "Locale of the template"
+// @Param key query string true "Key of the message"
+// @Param text body string true "Template of the message"
+// @Router /templates/:locale/:key [put]
+func (a Analyser) EditMessageTemplate(c *gin.Context) {
+	var template globals.MessageTemplate
+	if err := c.BindJSON(&template); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	template.Locale = c.Param("locale")
+	template.Key = c.Param("key")
+	if err := i18n.ValidateTemplate(template); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.ESClient.SetMessageTemplate(template); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.Messages.Override(template); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{})
+}
+
+// DeleteMessageTemplate godoc
+// @Summary Restore the default template of a message of the bot
+// @Description Deletes the template edited for the message in the locale, the default one is applied right away
+// @Description by this instance and within templates_refresh_interval by the others.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Templates
+// @ID delete-message-template
+// @Produce  json
+// @Param locale query string true "Locale of the template"
+// @Param key query string true "Key of the message"
+// @Router /templates/:locale/:key [delete]
+func (a Analyser) DeleteMessageTemplate(c *gin.Context) {
+	locale, key := c.Param("locale"), c.Param("key")
+	if err := a.ESClient.DeleteMessageTemplate(locale, key); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	a.Messages.Restore(locale, key)
+	c.JSON(204, gin.H{})
+}
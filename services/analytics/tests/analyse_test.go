@@ -0,0 +1,93 @@
+package analytics_test
+
+import (
+	"time"
+
+	"github.com/leboncoin/subot/pkg/calendar"
+	es "github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/services/analytics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type analyseMockedStorage struct {
+	es.Interface
+}
+
+func (m analyseMockedStorage) QueryRangeFireman(_ string, _ string, _ string) ([]globals.Message, error) {
+	return []globals.Message{}, nil
+}
+
+func (m analyseMockedStorage) AggregateMessages(_ string, _ string, _ string, closing []globals.MessageStatus) (globals.Aggregations, error) {
+	Expect(closing).To(Equal([]globals.MessageStatus{globals.StatusWontFix, globals.StatusFixed}))
+	return globals.Aggregations{
+		Count: 4,
+		Statuses: map[globals.MessageStatus]int{
+			globals.StatusFixed:       1,
+			globals.StatusWontFix:     1,
+			globals.StatusInProgress:  1,
+			globals.StatusUnresponded: 1,
+		},
+		Distributions: map[string]globals.Distribution{
+			"response_time":            {Count: 1, Mean: 900},
+			"business_response_time":   {Count: 1, Mean: 30},
+			"resolution_time":          {Count: 2, Mean: 1930, Percentiles: globals.Percentiles{P50: 20, P90: 3840, P99: 3840}},
+			"business_resolution_time": {Count: 2, Mean: 40},
+		},
+	}, nil
+}
+
+func (m analyseMockedStorage) QueryTimeSeries(query globals.TimeSeriesQuery) (globals.TimeSeries, error) {
+	Expect(query.Start).To(Equal("1590969600"))
+	Expect(query.TimeZone).To(Equal("Europe/Paris"))
+	Expect(query.Closing).To(Equal([]globals.MessageStatus{globals.StatusWontFix, globals.StatusFixed}))
+	return globals.TimeSeries{Interval: query.Interval, GroupBy: query.GroupBy, Buckets: []globals.TimeBucket{
+		{Date: "2020-06-01", Count: 4, Groups: []globals.TimeGroup{{Key: "vault", Count: 4}}},
+	}}, nil
+}
+
+func (m analyseMockedStorage) GetWorkflow(_ string) (globals.Workflow, error) {
+	return globals.Workflow{
+		{Emoji: "eyes", Status: globals.StatusInProgress},
+		{Emoji: "x", Status: globals.StatusWontFix, Closes: true},
+		{Emoji: "heavy_check_mark", Status: globals.StatusFixed, Closes: true},
+	}, nil
+}
+
+var _ = Describe("In", func() {
+
+	Describe("Test analyse of the messages", func() {
+		It("Should count the closing statuses of the workflow as resolved and report the times", func() {
+			a := analytics.Analyser{ESClient: analyseMockedStorage{}}
+			stats, err := a.Analyse("CLK7MCUS3", "2020-06-01", "2020-06-30")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stats.Count).To(Equal(4))
+			Expect(stats.ResolutionRate).To(Equal(50))
+			Expect(stats.ResolutionTime).To(Equal(time.Duration(1930)))
+			Expect(stats.BusinessResolutionTime).To(Equal(time.Duration(40)))
+			Expect(stats.ResponseTime).To(Equal(time.Duration(900)))
+			Expect(stats.BusinessResponseTime).To(Equal(time.Duration(30)))
+			Expect(stats.Distributions["resolution_time"].P90).To(Equal(time.Duration(3840)))
+			Expect(stats.Statuses).To(Equal(map[globals.MessageStatus]int{
+				globals.StatusFixed:       1,
+				globals.StatusWontFix:     1,
+				globals.StatusInProgress:  1,
+				globals.StatusUnresponded: 1,
+			}))
+		})
+
+		It("Should bucket the messages in the timezone of the business calendar", func() {
+			businessCalendar, err := calendar.New("Europe/Paris", calendar.DefaultHours, nil)
+			Expect(err).To(Not(HaveOccurred()))
+			a := analytics.Analyser{ESClient: analyseMockedStorage{}, Calendar: businessCalendar}
+			series, err := a.TimeSeries("CLK7MCUS3", "2020-06-01", "2020-06-30", "week", "tool")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(series.Interval).To(Equal("week"))
+			Expect(series.GroupBy).To(Equal("tool"))
+			Expect(series.Buckets).To(HaveLen(1))
+			Expect(series.Buckets[0].Groups[0].Key).To(Equal("vault"))
+		})
+	})
+})
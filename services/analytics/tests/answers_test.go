@@ -0,0 +1,145 @@
+package analytics_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test ranking and effectiveness of the answers", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+		var ids map[string]string
+		var previous int64
+		now := time.Now().Unix()
+		texts := map[string]string{
+			"login":     "Relance ton login vault",
+			"duplicate": "relance  ton login VAULT",
+			"doc":       "Consulte la documentation de vault",
+			"token":     "Vérifie ton token vault",
+		}
+		message := globals.Message{
+			Type:      globals.NewMessage,
+			Channel:   "CLK7MCUS3",
+			Text:      "Mon login vault ne marche plus",
+			UserID:    "UUSER",
+			Timestamp: strconv.FormatInt(now, 10) + ".000100",
+		}
+
+		// sent stores the previous messages which got the answer, with their feedback
+		sent := func(name string, feedbacks ...globals.FeedbackStatus) {
+			for _, feedback := range feedbacks {
+				previous++
+				Expect(storage.AddMessage(globals.Message{
+					Type:           globals.NewMessage,
+					Channel:        "CLK7MCUS3",
+					Status:         globals.StatusResponded,
+					Text:           "Question " + name,
+					UserID:         "UOTHER",
+					Timestamp:      strconv.FormatInt(now-previous*3600, 10) + ".000100",
+					Answers:        []string{ids[name]},
+					FeedbackStatus: feedback,
+				})).To(Succeed())
+			}
+		}
+
+		report := func(query string) *httptest.ResponseRecorder {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/admin/answers/report?"+query, nil)
+			a.GetAnswersReport(c)
+			return w
+		}
+
+		BeforeEach(func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3", "max_answers": 2, "disable_similar_threads": true}]`)
+			config.ResetChannels()
+			storage = memory.New()
+			Expect(storage.AddTool(globals.Perco{Name: "vault", Query: globals.Query{Regexp: globals.Regexp{Input: "vault"}}})).To(Succeed())
+			for _, text := range texts {
+				Expect(storage.AddAnswer(globals.Answer{Channel: "CLK7MCUS3", Tool: "vault", Answer: text, Feedback: text == texts["token"]})).To(Succeed())
+			}
+			answers, err := storage.GetAnswers("CLK7MCUS3")
+			Expect(err).To(Not(HaveOccurred()))
+			ids = make(map[string]string)
+			previous = 0
+			for _, answer := range answers {
+				for name, text := range texts {
+					if answer.Answer == text {
+						ids[name] = answer.ID
+					}
+				}
+			}
+			sent("login", globals.UsefulFeedback, globals.UsefulFeedback)
+			sent("token", globals.UselessFeedback, globals.UselessFeedback, globals.UselessFeedback, globals.AskedFeedback, globals.UselessFeedback)
+			a = analytics.Analyser{ESClient: storage, Engine: newMessageMockedEngine{}}
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+		})
+
+		It("Should send the most useful answers only once and store their IDs", func() {
+			responses, err := a.HandleMessage(message)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Text).To(Equal("Merci pour ton message.\n" + texts["login"] + "\n" + texts["doc"]))
+
+			stored, err := storage.GetMessage("CLK7MCUS3", message.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.Answers).To(Equal([]string{ids["login"], ids["doc"]}))
+			Expect(stored.FeedbackStatus).To(Equal(globals.NoFeedback))
+		})
+
+		It("Should ask for a single feedback when the answers sent ask for it", func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3", "max_answers": 4, "disable_similar_threads": true}]`)
+			config.ResetChannels()
+
+			responses, err := a.HandleMessage(message)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(2))
+			Expect(responses[1].Blocks).To(Not(BeEmpty()))
+
+			stored, err := storage.GetMessage("CLK7MCUS3", message.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.Answers).To(HaveLen(3))
+			Expect(stored.Answers[2]).To(Equal(ids["token"]))
+			Expect(stored.FeedbackStatus).To(Equal(globals.AskedFeedback))
+		})
+
+		It("Should report the answers never useful", func() {
+			w := report("channel=CLK7MCUS3")
+			Expect(w.Code).To(Equal(200))
+			var answers []globals.AnswerReport
+			Expect(json.Unmarshal(w.Body.Bytes(), &answers)).To(Succeed())
+			Expect(answers).To(HaveLen(4))
+			Expect(answers[0].Answer.ID).To(Equal(ids["login"]))
+			Expect(answers[0].Useful).To(Equal(2))
+			Expect(answers[0].NeverUseful).To(BeFalse())
+			Expect(answers[3].Answer.ID).To(Equal(ids["token"]))
+			Expect(answers[3].Sent).To(Equal(5))
+			Expect(answers[3].Useless).To(Equal(4))
+			Expect(answers[3].NeverUseful).To(BeTrue())
+
+			Expect(json.Unmarshal(report("channel=CLK7MCUS3&min_sent=6").Body.Bytes(), &answers)).To(Succeed())
+			Expect(answers[3].NeverUseful).To(BeFalse())
+
+			Expect(report("min_sent=none").Code).To(Equal(400))
+		})
+	})
+})
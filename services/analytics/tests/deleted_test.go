@@ -15,7 +15,7 @@ type deletedMockedStorage struct {
 	Client *elastic.Client `json:"client"`
 }
 
-func (m deletedMockedStorage) IsTeamMember(_ string) (teamMember bool, err error) {
+func (m deletedMockedStorage) IsTeamMember(_ string, _ string) (teamMember bool, err error) {
 	return false, nil
 }
 
@@ -23,7 +23,7 @@ func (m deletedMockedStorage) AddMessage(_ globals.Message, _ ...string) (err er
 	return nil
 }
 
-func (m deletedMockedStorage) QueryRangeMessages(_ string, _ string) ([]globals.Message, error) {
+func (m deletedMockedStorage) QueryRangeMessages(_ string, _ string, _ string) ([]globals.Message, error) {
 	return []globals.Message{
 		{
 			ID:       "chuz&fzofzo23R92I",
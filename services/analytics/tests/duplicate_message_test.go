@@ -0,0 +1,88 @@
+package analytics_test
+
+import (
+	es "github.com/leboncoin/subot/pkg/elastic"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/services/analytics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type duplicateMessageMockedStorage struct {
+	es.Interface
+	saved *[]globals.Message
+}
+
+func (m duplicateMessageMockedStorage) GetEscalationPolicies(_ string) ([]globals.EscalationPolicy, error) {
+	return nil, nil
+}
+
+func (m duplicateMessageMockedStorage) QueryRangeMessages(_ string, start string, _ string) ([]globals.Message, error) {
+	var messages []globals.Message
+	for _, message := range *m.saved {
+		if message.Timestamp == start {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (m duplicateMessageMockedStorage) IsTeamMember(_ string, _ string) (teamMember bool, err error) {
+	return false, nil
+}
+
+func (m duplicateMessageMockedStorage) QueryLastUserMessages(_ string, _ string) ([]globals.Message, error) {
+	return []globals.Message{}, nil
+}
+
+func (m duplicateMessageMockedStorage) QueryLabels(_ string, _ string) (hits []string, err error) {
+	return []string{}, nil
+}
+
+func (m duplicateMessageMockedStorage) QueryAnswers(_ string, _ []string, _ []string) (answers []globals.Answer, err error) {
+	return answers, nil
+}
+
+func (m duplicateMessageMockedStorage) QueryTools(_ string, _ string) (hits []string, err error) {
+	return []string{}, nil
+}
+
+func (m duplicateMessageMockedStorage) QuerySimilarMessages(_ string, _ string, _ int, _ float64) ([]globals.SimilarMessage, error) {
+	return nil, nil
+}
+
+func (m duplicateMessageMockedStorage) AddMessage(message globals.Message, _ ...string) (err error) {
+	*m.saved = append(*m.saved, message)
+	return nil
+}
+
+var _ = Describe("In", func() {
+
+	Describe("Test handler for messages received twice", func() {
+		It("Should store and reply to the message only once", func() {
+			var saved []globals.Message
+			client := duplicateMessageMockedStorage{saved: &saved}
+			engine := newMessageMockedEngine{}
+			message := globals.Message{
+				Channel:   "CLK7MCUS3",
+				Text:      "Hello",
+				UserID:    "UB210NGRK",
+				Timestamp: "123456789.000000",
+			}
+
+			a := analytics.Analyser{ESClient: client, Engine: engine}
+			response, err := a.HandleMessage(message)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Action).To(Equal(globals.ReplyMessage))
+			Expect(saved).To(HaveLen(1))
+
+			response, err = a.HandleMessage(message)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Action).To(Equal(globals.Nothing))
+			Expect(saved).To(HaveLen(1))
+		})
+	})
+})
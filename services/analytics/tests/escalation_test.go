@@ -0,0 +1,146 @@
+package analytics_test
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test handler for the escalation of the unanswered threads", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+		var thread globals.Message
+		now := time.Now().Unix()
+
+		BeforeEach(func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3"}]`)
+			config.ResetChannels()
+			storage = memory.New()
+			Expect(storage.AddTeamMember(globals.TeamMember{SlackID: "UALICE", Name: "alice"})).To(Succeed())
+			Expect(storage.AddRotation(globals.Rotation{
+				Roster: []string{"UALICE"},
+				Start:  time.Now().Format(globals.DateLayout),
+			})).To(Succeed())
+			Expect(storage.AddEscalationPolicy(globals.EscalationPolicy{
+				Tool: "vault",
+				Steps: []globals.EscalationStep{
+					{After: "1h", Target: globals.EscalateFireman},
+					{After: "4h", Target: globals.EscalateGroup, SlackID: "STEAM"},
+					{After: "24h", Target: globals.EscalateDirect, SlackID: "ULEAD"},
+				},
+			})).To(Succeed())
+			a = analytics.Analyser{ESClient: storage}
+
+			thread = globals.Message{
+				Type:      globals.NewMessage,
+				Channel:   "CLK7MCUS3",
+				Status:    globals.StatusUnresponded,
+				Tools:     []string{"vault"},
+				Text:      "Mon token vault a expiré",
+				UserID:    "UUSER",
+				Timestamp: strconv.FormatInt(now-3600, 10) + ".000100",
+				RemindAt:  strconv.FormatInt(now, 10),
+			}
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+		})
+
+		// remind makes the thread due and returns the reminders sent
+		remind := func() []globals.SlackResponse {
+			stored, err := storage.GetMessage("CLK7MCUS3", thread.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			stored.RemindAt = strconv.FormatInt(now, 10)
+			Expect(storage.AddMessage(stored, stored.ID)).To(Succeed())
+			responses, err := a.HandleRemindersRequest()
+			Expect(err).To(Not(HaveOccurred()))
+			return responses
+		}
+
+		It("Should follow every step of the policy once", func() {
+			Expect(storage.AddMessage(thread)).To(Succeed())
+
+			responses, err := a.HandleRemindersRequest()
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Action).To(Equal(globals.ReplyMessage))
+			Expect(responses[0].Text).To(Equal("Du nouveau <@UALICE> ?"))
+			Expect(responses[0].Ts).To(Equal(thread.Timestamp))
+			Expect(responses[0].ChanID).To(Equal("CLK7MCUS3"))
+			Expect(responses[0].Blocks).To(HaveLen(2))
+			stored, err := storage.GetMessage("CLK7MCUS3", thread.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.EscalationStep).To(Equal(1))
+			Expect(stored.RemindAt).To(Equal(strconv.FormatInt(now+3*3600, 10)))
+
+			responses = remind()
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Text).To(Equal("Du nouveau <!subteam^STEAM> ? Ce fil attend toujours une réponse."))
+
+			responses = remind()
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Action).To(Equal(globals.ChannelMessage))
+			Expect(responses[0].ChanID).To(Equal("ULEAD"))
+			Expect(responses[0].Text).To(ContainSubstring("Mon token vault a expiré"))
+			Expect(responses[0].Text).To(ContainSubstring("<#CLK7MCUS3>"))
+
+			stored, err = storage.GetMessage("CLK7MCUS3", thread.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.EscalationStep).To(Equal(3))
+			Expect(stored.RemindAt).To(BeEmpty())
+		})
+
+		It("Should remind the fireman only once without policy", func() {
+			thread.Tools = nil
+			Expect(storage.AddMessage(thread)).To(Succeed())
+
+			responses, err := a.HandleRemindersRequest()
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Text).To(Equal("Du nouveau <@UALICE> ?"))
+			Expect(remind()).To(BeEmpty())
+		})
+
+		It("Should restart the escalation on the replies of the users only", func() {
+			Expect(storage.AddMessage(thread)).To(Succeed())
+			_, err := a.HandleRemindersRequest()
+			Expect(err).To(Not(HaveOccurred()))
+
+			_, err = a.HandleReplies(globals.Reply{
+				Channel:   "CLK7MCUS3",
+				ThreadTs:  thread.Timestamp,
+				Timestamp: strconv.FormatInt(now, 10) + ".000200",
+				Text:      "Du nouveau <@UALICE> ?",
+				FromBot:   true,
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			stored, err := storage.GetMessage("CLK7MCUS3", thread.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.EscalationStep).To(Equal(1))
+
+			_, err = a.HandleReplies(globals.Reply{
+				Channel:   "CLK7MCUS3",
+				ThreadTs:  thread.Timestamp,
+				Timestamp: strconv.FormatInt(now, 10) + ".000300",
+				UserID:    "UUSER",
+				Text:      "Toujours bloqué",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			stored, err = storage.GetMessage("CLK7MCUS3", thread.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.EscalationStep).To(Equal(0))
+			Expect(globals.ParseDuration(stored.RemindAt)).To(BeNumerically(">=", now+3600))
+		})
+	})
+})
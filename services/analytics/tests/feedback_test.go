@@ -0,0 +1,86 @@
+package analytics_test
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test handler for the feedback on the answers", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+		var thread globals.Message
+		now := time.Now().Unix()
+
+		BeforeEach(func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3"}]`)
+			config.ResetChannels()
+			storage = memory.New()
+			a = analytics.Analyser{ESClient: storage}
+
+			thread = globals.Message{
+				Type:           globals.NewMessage,
+				Channel:        "CLK7MCUS3",
+				Status:         globals.StatusUnresponded,
+				Text:           "Mon token vault a expiré",
+				UserID:         "UUSER",
+				Timestamp:      strconv.FormatInt(now-3600, 10) + ".000100",
+				FeedbackStatus: globals.AskedFeedback,
+			}
+			Expect(storage.AddMessage(thread)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+		})
+
+		useful := func() []globals.SlackResponse {
+			replies, err := a.HandleFeedback(globals.Interaction{
+				Channel:      "CLK7MCUS3",
+				ThreadTs:     thread.Timestamp,
+				ActionUserID: "UUSER",
+				ActionValue:  string(globals.UsefulFeedback),
+				ActionTs:     strconv.FormatInt(now, 10) + ".000100",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			return replies
+		}
+
+		reaction := func(replies []globals.SlackResponse) string {
+			for _, reply := range replies {
+				if reply.Action == globals.React {
+					return reply.Text
+				}
+			}
+			return ""
+		}
+
+		It("Should close the thread with the default closing emoji", func() {
+			Expect(reaction(useful())).To(Equal("heavy_check_mark"))
+			stored, err := storage.GetMessage("CLK7MCUS3", thread.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.Status).To(Equal(globals.StatusFixed))
+			Expect(stored.FeedbackStatus).To(Equal(globals.UsefulFeedback))
+		})
+
+		It("Should close the thread with the first closing rule of the channel", func() {
+			Expect(storage.AddStatusRule(globals.StatusRule{Channel: "CLK7MCUS3", Emoji: "white_check_mark", Status: globals.StatusFixed, Closes: true})).To(Succeed())
+			Expect(storage.AddStatusRule(globals.StatusRule{Emoji: "eyes", Status: globals.StatusInProgress})).To(Succeed())
+
+			Expect(reaction(useful())).To(Equal("white_check_mark"))
+			stored, err := storage.GetMessage("CLK7MCUS3", thread.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.Status).To(Equal(globals.StatusFixed))
+		})
+	})
+})
@@ -0,0 +1,128 @@
+package analytics_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test handover digest on a fireman change", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+		var topic globals.Message
+
+		addThread := func(age time.Duration, text string, message globals.Message) {
+			ts := time.Now().Add(-age).Unix()
+			message.Type = globals.NewMessage
+			message.Channel = "CLK7MCUS3"
+			message.Text = text
+			message.UserID = "UUSER"
+			message.Timestamp = strconv.FormatInt(ts, 10) + ".000100"
+			Expect(storage.AddMessage(message)).To(Succeed())
+		}
+
+		digestText := func(response globals.SlackResponse) string {
+			b, err := json.Marshal(response.Blocks)
+			Expect(err).To(Not(HaveOccurred()))
+			return string(b)
+		}
+
+		BeforeEach(func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3"}]`)
+			config.ResetChannels()
+			viper.Set("slack_workspace_url", "https://subot.slack.com/")
+			storage = memory.New()
+			Expect(storage.AddTeamMember(globals.TeamMember{SlackID: "UALICE", Name: "alice"})).To(Succeed())
+
+			addThread(3*24*time.Hour, "Mon déploiement échoue", globals.Message{Status: globals.StatusUnresponded})
+			addThread(2*time.Hour, "Accès à la base", globals.Message{Status: globals.StatusInProgress, RemindAt: strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)})
+			addThread(5*time.Hour, "Pipeline en erreur", globals.Message{Status: globals.StatusResponded, FeedbackStatus: globals.AskedFeedback})
+			addThread(time.Hour, "Problème résolu", globals.Message{Status: globals.StatusFixed})
+			addThread(40*24*time.Hour, "Vieux fil", globals.Message{Status: globals.StatusUnresponded})
+
+			topic = globals.Message{
+				Type:     globals.TopicChange,
+				Channel:  "CLK7MCUS3",
+				UserID:   "UALICE",
+				UserInfo: globals.User{ID: "UBOB"},
+			}
+			a = analytics.Analyser{ESClient: storage}
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+			viper.Set("slack_workspace_url", nil)
+		})
+
+		It("Should send the open threads to the new fireman", func() {
+			responses, err := a.HandleFiremanChange(topic)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Action).To(Equal(globals.ChannelMessage))
+			Expect(responses[0].ChanID).To(Equal("UBOB"))
+
+			digest := digestText(responses[0])
+			Expect(digest).To(ContainSubstring("3 fil(s) ouvert(s)"))
+			Expect(digest).To(ContainSubstring("*unresponded* (1)"))
+			Expect(digest).To(ContainSubstring("Mon déploiement échoue\\u003e ouvert depuis 3 j"))
+			Expect(digest).To(ContainSubstring("*in_progress* (1)"))
+			Expect(digest).To(ContainSubstring("*En attente d'un retour utilisateur* (1)"))
+			Expect(digest).To(ContainSubstring("*Relances prévues* (1)"))
+			Expect(digest).To(ContainSubstring("https://subot.slack.com/archives/CLK7MCUS3/p"))
+			Expect(digest).To(Not(ContainSubstring("Problème résolu")))
+			Expect(digest).To(Not(ContainSubstring("Vieux fil")))
+		})
+
+		It("Should link the threads stored without their channel to the channel of the digest", func() {
+			legacy := globals.Message{
+				Type:      globals.NewMessage,
+				Status:    globals.StatusUnresponded,
+				Text:      "Fil sans canal",
+				UserID:    "UUSER",
+				Timestamp: strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10) + ".000200",
+			}
+			Expect(storage.AddMessage(legacy)).To(Succeed())
+
+			responses, err := a.HandleFiremanChange(topic)
+			Expect(err).To(Not(HaveOccurred()))
+			digest := digestText(responses[0])
+			Expect(digest).To(ContainSubstring("https://subot.slack.com/archives/CLK7MCUS3/p" + legacy.Timestamp[:10] + "000200|Fil sans canal"))
+			Expect(digest).To(Not(ContainSubstring("/archives//")))
+		})
+
+		It("Should post the digest in the channel when configured", func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3", "handover_digest": "channel"}]`)
+			config.ResetChannels()
+			responses, err := a.HandleFiremanChange(topic)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].ChanID).To(Equal("CLK7MCUS3"))
+		})
+
+		It("Should not send any digest when disabled", func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3", "handover_digest": "none"}]`)
+			config.ResetChannels()
+			responses, err := a.HandleFiremanChange(topic)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(Equal([]globals.SlackResponse{{Action: globals.Nothing}}))
+		})
+
+		It("Should not send any digest for a foreign fireman change", func() {
+			topic.UserID = "UUSER"
+			responses, err := a.HandleFiremanChange(topic)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(Equal([]globals.SlackResponse{{Action: globals.Nothing}}))
+		})
+	})
+})
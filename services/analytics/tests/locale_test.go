@@ -0,0 +1,95 @@
+package analytics_test
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test the locale of the messages", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+		var thread globals.Message
+		now := time.Now().Unix()
+
+		BeforeEach(func() {
+			storage = memory.New()
+			Expect(storage.AddTeamMember(globals.TeamMember{SlackID: "UALICE", Name: "alice"})).To(Succeed())
+			a = analytics.Analyser{ESClient: storage, Messages: i18n.New()}
+
+			thread = globals.Message{
+				Type:      globals.NewMessage,
+				Channel:   "CLK7MCUS3",
+				Status:    globals.StatusResponded,
+				Text:      "Mon token vault a expiré",
+				UserID:    "UUSER",
+				UserInfo:  globals.User{ID: "UUSER", Locale: "en-US"},
+				Timestamp: strconv.FormatInt(now-3600, 10) + ".000100",
+				RemindAt:  strconv.FormatInt(now+3600, 10),
+			}
+			Expect(storage.AddMessage(thread)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+		})
+
+		action := func(value string, user string) []globals.SlackResponse {
+			responses, err := a.HandleThreadAction(globals.Interaction{
+				MessageTs:    thread.Timestamp,
+				ActionUserID: user,
+				ActionValue:  value,
+				Channel:      "CLK7MCUS3",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			return responses
+		}
+
+		It("Should answer the team in the locale of the channel", func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3", "locale": "en"}]`)
+			config.ResetChannels()
+
+			responses := action(globals.SnoozeAction, "UALICE")
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Text).To(Equal("The reminders of this thread are postponed by 1 d."))
+		})
+
+		It("Should answer the requester in their own locale when the channel allows it", func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3", "user_locale": true}]`)
+			config.ResetChannels()
+
+			responses := action(globals.WaitingUserAction, "UALICE")
+			Expect(responses[0].Text).To(Equal("Waiting for an answer of <@UUSER>. Without news, this thread will be closed in 3 d."))
+			responses = action(globals.WaitingUserAction, "UALICE")
+			Expect(responses[0].Text).To(Equal("Ce fil attend déjà un retour du demandeur."))
+		})
+
+		It("Should use the templates edited through the api", func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3"}]`)
+			config.ResetChannels()
+			Expect(a.Messages.Override(globals.MessageTemplate{
+				Locale: "fr",
+				Key:    "thread.snoozed",
+				Text:   "Relances reportées de {{.Delay}}, merci {{mention \"UALICE\"}} !",
+			})).To(Succeed())
+
+			responses := action(globals.SnoozeAction, "UALICE")
+			Expect(responses[0].Text).To(Equal("Relances reportées de 1 j, merci <@UALICE> !"))
+
+			a.Messages.Restore("fr", "thread.snoozed")
+			responses = action(globals.SnoozeAction, "UALICE")
+			Expect(responses[0].Text).To(Equal("Les relances de ce fil sont reportées de 1 j."))
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package analytics_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test handlers against the in-memory storage", func() {
+		It("Should answer, reply and close a user message", func() {
+			storage := memory.New()
+			Expect(storage.AddLabel(globals.Perco{Name: "rights", Query: globals.Query{Regexp: globals.Regexp{Input: "rights?"}}})).To(Succeed())
+			Expect(storage.AddTool(globals.Perco{Name: "vault", Query: globals.Query{Regexp: globals.Regexp{Input: "vault"}}})).To(Succeed())
+			Expect(storage.AddAnswer(globals.Answer{Tool: "vault", Label: "rights", Answer: "Ask your manager.", Feedback: true})).To(Succeed())
+			Expect(storage.AddTeamMember(globals.TeamMember{SlackID: "UB210NGRK", Name: "clement.mondion"})).To(Succeed())
+
+			a := analytics.Analyser{ESClient: storage, Engine: newMessageMockedEngine{}}
+			now := time.Now().Unix()
+			ts := fmt.Sprintf("%d.000100", now-600)
+
+			responses, err := a.HandleMessage(globals.Message{Type: globals.NewMessage, UserID: "U1", Text: "please give me the rights on vault", Timestamp: ts})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(2))
+			Expect(responses[0].Text).To(Equal("Merci pour ton message.\nAsk your manager."))
+
+			responses, err = a.HandleMessage(globals.Message{Type: globals.NewMessage, UserID: "U1", Text: "please give me the rights on vault", Timestamp: ts})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses[0].Action).To(Equal(globals.Nothing))
+
+			_, err = a.HandleReplies(globals.Reply{UserID: "UB210NGRK", Text: "done", ThreadTs: ts, Timestamp: fmt.Sprintf("%d.000100", now-300)})
+			Expect(err).To(Not(HaveOccurred()))
+			message, err := storage.GetMessage("", ts)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Labels).To(Equal([]string{"rights"}))
+			Expect(message.Tools).To(Equal([]string{"vault"}))
+			Expect(message.FeedbackStatus).To(Equal(globals.AskedFeedback))
+			Expect(message.Status).To(Equal(globals.StatusResponded))
+			Expect(message.ResponseTime).To(Equal(time.Duration(5)))
+			Expect(message.Replies).To(HaveLen(1))
+
+			_, err = a.HandleReaction(globals.Reaction{Name: "heavy_check_mark", Users: []string{"UB210NGRK"}, MessageTs: ts, Timestamp: fmt.Sprintf("%d.000100", now)})
+			Expect(err).To(Not(HaveOccurred()))
+			message, err = storage.GetMessage("", ts)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusFixed))
+			Expect(message.ResolutionTime).To(Equal(time.Duration(10)))
+			Expect(message.RemindAt).To(BeEmpty())
+		})
+	})
+})
@@ -2,7 +2,7 @@ package analytics_test
 
 import (
 	elastic "github.com/elastic/go-elasticsearch/v6"
-	new_elastic "github.com/olivere/elastic"
+	new_elastic "github.com/olivere/elastic/v7"
 	es "github.com/leboncoin/subot/pkg/elastic"
 	engine "github.com/leboncoin/subot/pkg/engine_grpc_client"
 	pb "github.com/leboncoin/subot/pkg/engine_grpc_client/engine"
@@ -19,26 +19,34 @@ type newMessageMockedStorage struct {
 	NewClient *new_elastic.Client `json:"new_client"`
 }
 
-func (m newMessageMockedStorage) IsTeamMember(_ string) (teamMember bool, err error) {
+func (m newMessageMockedStorage) GetEscalationPolicies(_ string) ([]globals.EscalationPolicy, error) {
+	return nil, nil
+}
+
+func (m newMessageMockedStorage) IsTeamMember(_ string, _ string) (teamMember bool, err error) {
 	return false, nil
 }
 
-func (m newMessageMockedStorage) QueryLastUserMessages(userID string) ([]globals.Message, error) {
+func (m newMessageMockedStorage) QueryLastUserMessages(_ string, userID string) ([]globals.Message, error) {
 	return []globals.Message{}, nil
 }
 
-func (m newMessageMockedStorage) QueryLabels(_ string) (hits []string, err error) {
+func (m newMessageMockedStorage) QueryLabels(_ string, _ string) (hits []string, err error) {
 	return []string{"rights"}, nil
 }
 
-func (m newMessageMockedStorage) QueryAnswers(_ []string, _ []string) (answers []globals.Answer, err error) {
+func (m newMessageMockedStorage) QueryAnswers(_ string, _ []string, _ []string) (answers []globals.Answer, err error) {
 	return answers, nil
 }
 
-func (m newMessageMockedStorage) QueryTools(_ string) (hits []string, err error) {
+func (m newMessageMockedStorage) QueryTools(_ string, _ string) (hits []string, err error) {
 	return []string{"mock0", "mock1", "mock2"}, nil
 }
 
+func (m newMessageMockedStorage) QueryRangeMessages(_ string, _ string, _ string) ([]globals.Message, error) {
+	return []globals.Message{}, nil
+}
+
 func (m newMessageMockedStorage) AddMessage(_ globals.Message, _ ...string) (err error) {
 	return nil
 }
@@ -17,27 +17,35 @@ type teamMemberMessageMockedStorage struct {
 	Client *elastic.Client `json:"client"`
 }
 
-func (m teamMemberMessageMockedStorage) IsTeamMember(_ string) (teamMember bool, err error) {
+func (m teamMemberMessageMockedStorage) GetEscalationPolicies(_ string) ([]globals.EscalationPolicy, error) {
+	return nil, nil
+}
+
+func (m teamMemberMessageMockedStorage) IsTeamMember(_ string, _ string) (teamMember bool, err error) {
 	return true, nil
 }
 
+func (m teamMemberMessageMockedStorage) QueryRangeMessages(_ string, _ string, _ string) ([]globals.Message, error) {
+	return []globals.Message{}, nil
+}
+
 func (m teamMemberMessageMockedStorage) AddMessage(_ globals.Message, _ ...string) (err error) {
 	return nil
 }
 
-func (m teamMemberMessageMockedStorage) QueryLastUserMessages(userID string) ([]globals.Message, error) {
+func (m teamMemberMessageMockedStorage) QueryLastUserMessages(_ string, userID string) ([]globals.Message, error) {
 	return []globals.Message{}, nil
 }
 
-func (m teamMemberMessageMockedStorage) QueryLabels(_ string) (hits []string, err error) {
+func (m teamMemberMessageMockedStorage) QueryLabels(_ string, _ string) (hits []string, err error) {
 	return []string{"rights"}, nil
 }
 
-func (m teamMemberMessageMockedStorage) QueryAnswers(_ []string, _ []string) (answers []globals.Answer, err error) {
+func (m teamMemberMessageMockedStorage) QueryAnswers(_ string, _ []string, _ []string) (answers []globals.Answer, err error) {
 	return answers, nil
 }
 
-func (m teamMemberMessageMockedStorage) QueryTools(_ string) (hits []string, err error) {
+func (m teamMemberMessageMockedStorage) QueryTools(_ string, _ string) (hits []string, err error) {
 	return []string{"mock0", "mock1", "mock2"}, nil
 }
 
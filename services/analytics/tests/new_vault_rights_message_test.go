@@ -17,19 +17,23 @@ type vaultRightsMockedStorage struct {
 	Client *elastic.Client `json:"client"`
 }
 
-func (m vaultRightsMockedStorage) IsTeamMember(_ string) (teamMember bool, err error) {
+func (m vaultRightsMockedStorage) GetEscalationPolicies(_ string) ([]globals.EscalationPolicy, error) {
+	return nil, nil
+}
+
+func (m vaultRightsMockedStorage) IsTeamMember(_ string, _ string) (teamMember bool, err error) {
 	return false, nil
 }
 
-func (m vaultRightsMockedStorage) QueryLastUserMessages(userID string) (hits []globals.Message, err error) {
+func (m vaultRightsMockedStorage) QueryLastUserMessages(_ string, userID string) (hits []globals.Message, err error) {
 	return []globals.Message{}, nil
 }
 
-func (m vaultRightsMockedStorage) QueryLabels(_ string) (hits []string, err error) {
+func (m vaultRightsMockedStorage) QueryLabels(_ string, _ string) (hits []string, err error) {
 	return []string{"rights"}, nil
 }
 
-func (m vaultRightsMockedStorage) QueryAnswers(_ []string, _ []string) (answers []globals.Answer, err error) {
+func (m vaultRightsMockedStorage) QueryAnswers(_ string, _ []string, _ []string) (answers []globals.Answer, err error) {
 	return []globals.Answer{
 		{
 			Tool:   "vault",
@@ -39,10 +43,14 @@ func (m vaultRightsMockedStorage) QueryAnswers(_ []string, _ []string) (answers
 	}, nil
 }
 
-func (m vaultRightsMockedStorage) QueryTools(_ string) (hits []string, err error) {
+func (m vaultRightsMockedStorage) QueryTools(_ string, _ string) (hits []string, err error) {
 	return []string{"mock0", "mock1", "mock2"}, nil
 }
 
+func (m vaultRightsMockedStorage) QueryRangeMessages(_ string, _ string, _ string) ([]globals.Message, error) {
+	return []globals.Message{}, nil
+}
+
 func (m vaultRightsMockedStorage) AddMessage(_ globals.Message, _ ...string) (err error) {
 	return nil
 }
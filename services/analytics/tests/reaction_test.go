@@ -1,7 +1,10 @@
 package analytics_test
 
 import (
+	"time"
+
 	elastic "github.com/elastic/go-elasticsearch/v6"
+	"github.com/leboncoin/subot/pkg/calendar"
 	es "github.com/leboncoin/subot/pkg/elastic"
 	"github.com/leboncoin/subot/pkg/globals"
 	"github.com/leboncoin/subot/services/analytics"
@@ -15,15 +18,19 @@ type reactionMockedStorage struct {
 	Client *elastic.Client `json:"client"`
 }
 
-func (m reactionMockedStorage) IsTeamMember(_ string) (teamMember bool, err error) {
+func (m reactionMockedStorage) IsTeamMember(_ string, _ string) (teamMember bool, err error) {
 	return false, nil
 }
 
+func (m reactionMockedStorage) GetWorkflow(_ string) (globals.Workflow, error) {
+	return globals.DefaultWorkflow, nil
+}
+
 func (m reactionMockedStorage) AddMessage(_ globals.Message, _ ...string) (err error) {
 	return nil
 }
 
-func (m reactionMockedStorage) QueryRangeMessages(_ string, _ string) (hits []globals.Message, err error) {
+func (m reactionMockedStorage) QueryRangeMessages(_ string, _ string, _ string) (hits []globals.Message, err error) {
 	return []globals.Message{
 		{
 			ID:       "chuz&fzofzo23R92I",
@@ -66,7 +73,228 @@ func (m reactionMockedStorage) QueryRangeMessages(_ string, _ string) (hits []gl
 	}, nil
 }
 
+type statefulReactionMockedStorage struct {
+	es.Interface
+	message  *globals.Message
+	workflow globals.Workflow
+}
+
+func (m statefulReactionMockedStorage) GetEscalationPolicies(_ string) ([]globals.EscalationPolicy, error) {
+	return nil, nil
+}
+
+func (m statefulReactionMockedStorage) GetWorkflow(_ string) (globals.Workflow, error) {
+	if m.workflow == nil {
+		return globals.DefaultWorkflow, nil
+	}
+	return m.workflow, nil
+}
+
+func (m statefulReactionMockedStorage) IsTeamMember(_ string, userID string) (teamMember bool, err error) {
+	return userID == "UTEAMMEMBER", nil
+}
+
+func (m statefulReactionMockedStorage) AddMessage(message globals.Message, _ ...string) (err error) {
+	*m.message = message
+	return nil
+}
+
+func (m statefulReactionMockedStorage) QueryRangeMessages(_ string, _ string, _ string) (hits []globals.Message, err error) {
+	return []globals.Message{*m.message}, nil
+}
+
 var _ = Describe("In", func() {
+	Describe("Test handler for removed reactions", func() {
+		var message globals.Message
+		var a analytics.Analyser
+		var checkMark globals.Reaction
+
+		BeforeEach(func() {
+			message = globals.Message{
+				ID:        "chuz&fzofzo23R92I",
+				Type:      "user",
+				Status:    "unresponded",
+				UserID:    "UB210NGRK",
+				Timestamp: "1592208201.000100",
+			}
+			a = analytics.Analyser{ESClient: statefulReactionMockedStorage{message: &message}}
+			checkMark = globals.Reaction{
+				Name:      "heavy_check_mark",
+				Users:     []string{"UB210NGRK"},
+				MessageTs: "1592208201.000100",
+				Timestamp: "1592208502",
+			}
+		})
+
+		It("Should revert the status when :heavy_check_mark: is removed then added again", func() {
+			_, err := a.HandleReaction(checkMark)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusFixed))
+			Expect(message.ResolutionTime).To(Equal(time.Duration(5)))
+			Expect(message.RemindAt).To(BeEmpty())
+			Expect(message.Reactions).To(HaveLen(1))
+
+			removed := checkMark
+			removed.Removed = true
+			_, err = a.HandleReaction(removed)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusUnresponded))
+			Expect(message.ResolutionTime).To(Equal(time.Duration(0)))
+			Expect(message.RemindAt).ToNot(BeEmpty())
+			Expect(message.Reactions).To(BeEmpty())
+
+			checkMark.Timestamp = "1592209402"
+			_, err = a.HandleReaction(checkMark)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusFixed))
+			Expect(message.ResolutionTime).To(Equal(time.Duration(20)))
+			Expect(message.RemindAt).To(BeEmpty())
+			Expect(message.Reactions).To(HaveLen(1))
+			Expect(message.Reactions[0].Count).To(Equal(1))
+		})
+
+		It("Should revert to responded when a team member replied", func() {
+			message.Status = "responded"
+			message.Replies = []globals.Reply{{UserID: "UTEAMMEMBER", ThreadTs: "1592208201.000100", Timestamp: "1592208301.000100"}}
+
+			_, err := a.HandleReaction(checkMark)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusFixed))
+
+			checkMark.Removed = true
+			_, err = a.HandleReaction(checkMark)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusResponded))
+		})
+
+		It("Should stay fixed while another user keeps its :heavy_check_mark:", func() {
+			_, err := a.HandleReaction(checkMark)
+			Expect(err).To(Not(HaveOccurred()))
+			other := checkMark
+			other.Users = []string{"UTEAMMEMBER"}
+			_, err = a.HandleReaction(other)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Reactions).To(HaveLen(1))
+			Expect(message.Reactions[0].Count).To(Equal(2))
+
+			checkMark.Removed = true
+			_, err = a.HandleReaction(checkMark)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusFixed))
+			Expect(message.Reactions[0].Users).To(Equal([]string{"UTEAMMEMBER"}))
+		})
+
+		It("Should keep the status when another reaction is removed", func() {
+			_, err := a.HandleReaction(checkMark)
+			Expect(err).To(Not(HaveOccurred()))
+			eyes := globals.Reaction{Name: "eyes", Users: []string{"UTEAMMEMBER"}, MessageTs: "1592208201.000100"}
+			_, err = a.HandleReaction(eyes)
+			Expect(err).To(Not(HaveOccurred()))
+
+			eyes.Removed = true
+			_, err = a.HandleReaction(eyes)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusFixed))
+			Expect(message.Reactions).To(HaveLen(1))
+			Expect(message.Reactions[0].Name).To(Equal("heavy_check_mark"))
+		})
+	})
+
+	Describe("Test handler for configured status workflow", func() {
+		var message globals.Message
+		var a analytics.Analyser
+
+		BeforeEach(func() {
+			message = globals.Message{
+				ID:        "chuz&fzofzo23R92I",
+				Type:      "user",
+				Status:    globals.StatusUnresponded,
+				UserID:    "UB210NGRK",
+				Timestamp: "1592208201.000100",
+			}
+			workflow := globals.Workflow{
+				{ID: "1", Emoji: "eyes", Status: globals.StatusInProgress},
+				{ID: "2", Emoji: "x", Status: globals.StatusWontFix, Closes: true},
+				{ID: "3", Emoji: "heavy_check_mark", Status: globals.StatusFixed, Closes: true},
+			}
+			a = analytics.Analyser{ESClient: statefulReactionMockedStorage{message: &message, workflow: workflow}}
+		})
+
+		It("Should set the status of the emoji and close the message with a closing emoji", func() {
+			eyes := globals.Reaction{Name: "eyes", Users: []string{"UTEAMMEMBER"}, MessageTs: "1592208201.000100", Timestamp: "1592208301"}
+			_, err := a.HandleReaction(eyes)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusInProgress))
+			Expect(message.ResolutionTime).To(Equal(time.Duration(0)))
+
+			x := globals.Reaction{Name: "x", Users: []string{"UTEAMMEMBER"}, MessageTs: "1592208201.000100", Timestamp: "1592208502"}
+			_, err = a.HandleReaction(x)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusWontFix))
+			Expect(message.ResolutionTime).To(Equal(time.Duration(5)))
+			Expect(message.RemindAt).To(BeEmpty())
+		})
+
+		It("Should not reopen a closed message with a non closing emoji", func() {
+			checkMark := globals.Reaction{Name: "heavy_check_mark", Users: []string{"UB210NGRK"}, MessageTs: "1592208201.000100", Timestamp: "1592208502"}
+			_, err := a.HandleReaction(checkMark)
+			Expect(err).To(Not(HaveOccurred()))
+
+			eyes := globals.Reaction{Name: "eyes", Users: []string{"UTEAMMEMBER"}, MessageTs: "1592208201.000100"}
+			_, err = a.HandleReaction(eyes)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusFixed))
+			Expect(message.RemindAt).To(BeEmpty())
+		})
+
+		It("Should fall back on the remaining emojis when a status emoji is removed", func() {
+			eyes := globals.Reaction{Name: "eyes", Users: []string{"UTEAMMEMBER"}, MessageTs: "1592208201.000100"}
+			_, err := a.HandleReaction(eyes)
+			Expect(err).To(Not(HaveOccurred()))
+			x := globals.Reaction{Name: "x", Users: []string{"UTEAMMEMBER"}, MessageTs: "1592208201.000100", Timestamp: "1592208502"}
+			_, err = a.HandleReaction(x)
+			Expect(err).To(Not(HaveOccurred()))
+
+			x.Removed = true
+			_, err = a.HandleReaction(x)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusInProgress))
+			Expect(message.ResolutionTime).To(Equal(time.Duration(0)))
+			Expect(message.RemindAt).ToNot(BeEmpty())
+
+			eyes.Removed = true
+			_, err = a.HandleReaction(eyes)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.Status).To(Equal(globals.StatusUnresponded))
+		})
+	})
+
+	Describe("Test handler for reactions out of business hours", func() {
+		It("Should store the resolution time in wall-clock and business minutes", func() {
+			// asked on friday 17:30 and fixed on monday 09:30, Paris time
+			message := globals.Message{
+				ID:        "chuz&fzofzo23R92I",
+				Type:      "user",
+				Status:    globals.StatusResponded,
+				UserID:    "UB210NGRK",
+				Timestamp: "1591975800.000100",
+			}
+			businessCalendar, err := calendar.New("Europe/Paris", "mon-fri 09:00-18:00", nil)
+			Expect(err).To(Not(HaveOccurred()))
+			a := analytics.Analyser{ESClient: statefulReactionMockedStorage{message: &message}, Calendar: businessCalendar}
+
+			_, err = a.HandleReaction(globals.Reaction{
+				Name:      "heavy_check_mark",
+				Users:     []string{"UTEAMMEMBER"},
+				MessageTs: "1591975800.000100",
+				Timestamp: "1592206200",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(message.ResolutionTime).To(Equal(time.Duration(3839)))
+			Expect(message.BusinessResolutionTime).To(Equal(time.Duration(59)))
+		})
+	})
+
 	Describe("Test handler for new reactions", func() {
 		It("Should save the new status when reaction is :heavy_check_mark:", func() {
 			client := reactionMockedStorage{
@@ -17,11 +17,15 @@ type repetitiveMockedStorage struct {
 	Client *elastic.Client `json:"client"`
 }
 
-func (m repetitiveMockedStorage) IsTeamMember(_ string) (teamMember bool, err error) {
+func (m repetitiveMockedStorage) GetEscalationPolicies(_ string) ([]globals.EscalationPolicy, error) {
+	return nil, nil
+}
+
+func (m repetitiveMockedStorage) IsTeamMember(_ string, _ string) (teamMember bool, err error) {
 	return false, nil
 }
 
-func (m repetitiveMockedStorage) QueryLastUserMessages(userID string) ([]globals.Message, error) {
+func (m repetitiveMockedStorage) QueryLastUserMessages(_ string, userID string) ([]globals.Message, error) {
 	return []globals.Message{
 		{
 			ID:    "chuz&fzofzo23R92I",
@@ -64,18 +68,22 @@ func (m repetitiveMockedStorage) QueryLastUserMessages(userID string) ([]globals
 	}, nil
 }
 
-func (m repetitiveMockedStorage) QueryLabels(_ string) (hits []string, err error) {
+func (m repetitiveMockedStorage) QueryLabels(_ string, _ string) (hits []string, err error) {
 	return []string{"rights"}, nil
 }
 
-func (m repetitiveMockedStorage) QueryAnswers(_ []string, _ []string) (answers []globals.Answer, err error) {
+func (m repetitiveMockedStorage) QueryAnswers(_ string, _ []string, _ []string) (answers []globals.Answer, err error) {
 	return answers, nil
 }
 
-func (m repetitiveMockedStorage) QueryTools(_ string) (hits []string, err error) {
+func (m repetitiveMockedStorage) QueryTools(_ string, _ string) (hits []string, err error) {
 	return []string{"mock0", "mock1", "mock2"}, nil
 }
 
+func (m repetitiveMockedStorage) QueryRangeMessages(_ string, _ string, _ string) ([]globals.Message, error) {
+	return []globals.Message{}, nil
+}
+
 func (m repetitiveMockedStorage) AddMessage(_ globals.Message, _ ...string) (err error) {
 	return nil
 }
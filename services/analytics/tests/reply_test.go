@@ -15,15 +15,23 @@ type replyMockedStorage struct {
 	Client *elastic.Client `json:"client"`
 }
 
-func (m replyMockedStorage) IsTeamMember(_ string) (teamMember bool, err error) {
+func (m replyMockedStorage) GetEscalationPolicies(_ string) ([]globals.EscalationPolicy, error) {
+	return nil, nil
+}
+
+func (m replyMockedStorage) IsTeamMember(_ string, _ string) (teamMember bool, err error) {
 	return false, nil
 }
 
+func (m replyMockedStorage) GetWorkflow(_ string) (globals.Workflow, error) {
+	return globals.DefaultWorkflow, nil
+}
+
 func (m replyMockedStorage) AddMessage(_ globals.Message, _ ...string) (err error) {
 	return nil
 }
 
-func (m replyMockedStorage) QueryRangeMessages(_ string, _ string) ([]globals.Message, error) {
+func (m replyMockedStorage) QueryRangeMessages(_ string, _ string, _ string) ([]globals.Message, error) {
 	return []globals.Message{
 		{
 			ID:       "chuz&fzofzo23R92I",
@@ -0,0 +1,188 @@
+package analytics_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test handlers for the resolution notes of the threads", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+		var thread globals.Message
+		now := time.Now().Unix()
+
+		BeforeEach(func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3"}]`)
+			config.ResetChannels()
+			storage = memory.New()
+			Expect(storage.AddTeamMember(globals.TeamMember{SlackID: "UALICE", Name: "alice"})).To(Succeed())
+			Expect(storage.AddTool(globals.Perco{Name: "vault", Query: globals.Query{Regexp: globals.Regexp{Input: "vault"}}})).To(Succeed())
+			Expect(storage.AddLabel(globals.Perco{Name: "token", Query: globals.Query{Regexp: globals.Regexp{Input: "token"}}})).To(Succeed())
+			a = analytics.Analyser{ESClient: storage}
+
+			thread = globals.Message{
+				Type:      globals.NewMessage,
+				Channel:   "CLK7MCUS3",
+				Status:    globals.StatusResponded,
+				Text:      "Mon token vault a expiré",
+				UserID:    "UUSER",
+				Tools:     []string{"vault"},
+				Labels:    []string{"token"},
+				Timestamp: strconv.FormatInt(now-3600, 10) + ".000100",
+			}
+			Expect(storage.AddMessage(thread)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+		})
+
+		stored := func() globals.Message {
+			message, err := storage.GetMessage("CLK7MCUS3", thread.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			return message
+		}
+
+		submit := func(note string) []globals.SlackResponse {
+			responses, err := a.HandleViewSubmission(globals.Interaction{
+				Channel:      "CLK7MCUS3",
+				MessageTs:    thread.Timestamp,
+				ActionUserID: "UALICE",
+				ActionValue:  globals.ResolutionNoteAction,
+				Values:       map[string]string{"note": note},
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			return responses
+		}
+
+		promote := func(body string) *httptest.ResponseRecorder {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "message_ts", Value: thread.Timestamp}}
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/messages/"+thread.Timestamp+"/answers?channel=CLK7MCUS3", strings.NewReader(body))
+			a.PromoteResolutionNote(c)
+			return w
+		}
+
+		It("Should ask the user closing the thread for a resolution note", func() {
+			responses, err := a.HandleReaction(globals.Reaction{
+				Name:      "heavy_check_mark",
+				Users:     []string{"UALICE"},
+				Channel:   "CLK7MCUS3",
+				MessageTs: thread.Timestamp,
+				Timestamp: strconv.FormatInt(now, 10) + ".000200",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(2))
+			Expect(responses[1].Action).To(Equal(globals.Ephemeral))
+			Expect(responses[1].UserID).To(Equal("UALICE"))
+			Expect(responses[1].Text).To(HavePrefix("Ce fil est résolu"))
+			b, err := json.Marshal(responses[1].Blocks)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(string(b)).To(ContainSubstring(`"action_id":"resolution_note","value":"` + thread.Timestamp + `"`))
+		})
+
+		It("Should not ask again when the thread has a note", func() {
+			thread.ResolutionNote = "Le token a été renouvelé"
+			Expect(storage.AddMessage(thread)).To(Succeed())
+
+			responses, err := a.HandleReaction(globals.Reaction{
+				Name:      "heavy_check_mark",
+				Users:     []string{"UALICE"},
+				Channel:   "CLK7MCUS3",
+				MessageTs: thread.Timestamp,
+				Timestamp: strconv.FormatInt(now, 10) + ".000200",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+		})
+
+		It("Should open the modal of the note on the button", func() {
+			responses, err := a.HandleResolutionNoteAction(globals.Interaction{
+				Channel:      "CLK7MCUS3",
+				ActionUserID: "UALICE",
+				ActionValue:  thread.Timestamp,
+				TriggerID:    "12345.98765",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Action).To(Equal(globals.OpenModal))
+			Expect(responses[0].TriggerID).To(Equal("12345.98765"))
+			view := responses[0].View.(map[string]interface{})
+			Expect(view).To(HaveKeyWithValue("callback_id", globals.ResolutionNoteAction))
+
+			var metadata globals.Interaction
+			Expect(json.Unmarshal([]byte(view["private_metadata"].(string)), &metadata)).To(Succeed())
+			Expect(metadata.Channel).To(Equal("CLK7MCUS3"))
+			Expect(metadata.MessageTs).To(Equal(thread.Timestamp))
+		})
+
+		It("Should store the submitted note and make it searchable", func() {
+			responses := submit("  Le token a été\nrenouvelé  ")
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Action).To(Equal(globals.ReplyMessage))
+			Expect(responses[0].Ts).To(Equal(thread.Timestamp))
+			Expect(responses[0].Text).To(Equal(":memo: Note de résolution de <@UALICE> : Le token a été renouvelé"))
+
+			message := stored()
+			Expect(message.ResolutionNote).To(Equal("Le token a été renouvelé"))
+			Expect(message.ResolutionNoteUser).To(Equal("UALICE"))
+
+			page, err := storage.QueryMessagesPage(globals.MessageQuery{
+				Channel: "CLK7MCUS3",
+				Start:   strconv.FormatInt(now-7200, 10),
+				End:     strconv.FormatInt(now, 10),
+				Search:  "renouvelé",
+				Size:    10,
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(page.Messages).To(HaveLen(1))
+		})
+
+		It("Should refuse an empty note", func() {
+			_, err := a.HandleViewSubmission(globals.Interaction{
+				Channel:     "CLK7MCUS3",
+				MessageTs:   thread.Timestamp,
+				ActionValue: globals.ResolutionNoteAction,
+				Values:      map[string]string{"note": "  "},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should promote the note to an answer for the tools and labels of the thread", func() {
+			Expect(promote("").Code).To(Equal(400))
+
+			submit("Le token a été renouvelé")
+			w := promote("")
+			Expect(w.Code).To(Equal(201))
+
+			answers, err := storage.QueryAnswers("CLK7MCUS3", []string{"vault"}, []string{"token"})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(answers).To(HaveLen(1))
+			Expect(answers[0].Answer).To(Equal("Le token a été renouvelé"))
+			Expect(answers[0].Channel).To(Equal("CLK7MCUS3"))
+
+			Expect(promote(`{"answer": "Renouvelle ton token avec vault login", "feedback": true}`).Code).To(Equal(201))
+			answers, err = storage.GetAnswers("CLK7MCUS3")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(answers).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package analytics_test
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test handler for the fireman rotation", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+
+		BeforeEach(func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3"}]`)
+			config.ResetChannels()
+			storage = memory.New()
+			Expect(storage.AddTeamMember(globals.TeamMember{SlackID: "UALICE", Name: "alice"})).To(Succeed())
+			Expect(storage.AddTeamMember(globals.TeamMember{SlackID: "UBOB", Name: "bob"})).To(Succeed())
+			Expect(storage.AddRotation(globals.Rotation{
+				Channel: "CLK7MCUS3",
+				Roster:  []string{"UALICE", "UBOB"},
+				Start:   time.Now().AddDate(0, 0, -7).Format(globals.DateLayout),
+				Topic:   "Pompier : {fireman}",
+			})).To(Succeed())
+			a = analytics.Analyser{ESClient: storage}
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+		})
+
+		It("Should announce the new fireman once and set the topic", func() {
+			responses, err := a.HandleRotationRequest()
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(3))
+			Expect(responses[:2]).To(Equal([]globals.SlackResponse{
+				{Action: globals.ChannelMessage, Text: "C'est au tour de <@UBOB> d'être pompier.", ChanID: "CLK7MCUS3"},
+				{Action: globals.SetTopic, Text: "Pompier : <@UBOB>", ChanID: "CLK7MCUS3"},
+			}))
+			Expect(responses[2].Action).To(Equal(globals.ChannelMessage))
+			Expect(responses[2].ChanID).To(Equal("UBOB"))
+
+			today := time.Now().Format(globals.DateLayout)
+			tomorrow := time.Now().AddDate(0, 0, 1).Format(globals.DateLayout)
+			firemen, err := storage.QueryRangeFireman("CLK7MCUS3", today, tomorrow)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(firemen).To(HaveLen(1))
+			Expect(firemen[0].UserInfo).To(Equal(globals.User{ID: "UBOB", Name: "bob"}))
+
+			responses, err = a.HandleRotationRequest()
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(BeEmpty())
+		})
+
+		It("Should remind the fireman of the rotation", func() {
+			now := strconv.FormatInt(time.Now().Unix(), 10)
+			Expect(storage.AddMessage(globals.Message{
+				Type:      globals.NewMessage,
+				Channel:   "CLK7MCUS3",
+				Status:    globals.StatusUnresponded,
+				Timestamp: now + ".000100",
+				RemindAt:  now,
+			})).To(Succeed())
+
+			responses, err := a.HandleRemindersRequest()
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Text).To(Equal("Du nouveau <@UBOB> ?"))
+		})
+	})
+})
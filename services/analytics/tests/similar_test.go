@@ -0,0 +1,160 @@
+package analytics_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test suggestions of the similar resolved threads", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+		now := time.Now().Unix()
+		resolved := globals.Message{
+			Type:           globals.NewMessage,
+			Channel:        "CLK7MCUS3",
+			Status:         globals.StatusFixed,
+			Text:           "Mon token vault a expiré",
+			UserID:         "UOTHER",
+			Timestamp:      strconv.FormatInt(now-7*24*3600, 10) + ".000100",
+			ResolutionNote: "Le token a été renouvelé",
+		}
+		open := globals.Message{
+			Type:      globals.NewMessage,
+			Channel:   "CLK7MCUS3",
+			Status:    globals.StatusUnresponded,
+			Text:      "Le token vault de ma CI a expiré",
+			UserID:    "UOTHER",
+			Timestamp: strconv.FormatInt(now-3600, 10) + ".000100",
+		}
+		message := globals.Message{
+			Type:      globals.NewMessage,
+			Channel:   "CLK7MCUS3",
+			Text:      "Bonjour, mon token vault a expiré",
+			UserID:    "UUSER",
+			Timestamp: strconv.FormatInt(now, 10) + ".000100",
+		}
+
+		BeforeEach(func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3"}]`)
+			config.ResetChannels()
+			storage = memory.New()
+			Expect(storage.AddTeamMember(globals.TeamMember{SlackID: "UALICE", Name: "alice"})).To(Succeed())
+			Expect(storage.AddMessage(resolved)).To(Succeed())
+			Expect(storage.AddMessage(open)).To(Succeed())
+			a = analytics.Analyser{ESClient: storage, Engine: newMessageMockedEngine{}}
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+		})
+
+		It("Should link the resolved threads similar to the message and ask for a feedback", func() {
+			responses, err := a.HandleMessage(message)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(2))
+			Expect(responses[0].Text).To(Equal("Merci pour ton message.\nCes fils résolus ressemblent à ta demande :\n" +
+				"• <https://slack.com/archives/CLK7MCUS3/p" + resolved.Timestamp[:10] + "000100|Mon token vault a expiré> : _Le token a été renouvelé_"))
+			Expect(responses[1].Action).To(Equal(globals.ReplyMessage))
+			b, err := json.Marshal(responses[1].Blocks)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(string(b)).To(ContainSubstring(`"action_id":"similar_feedback","style":"primary","value":"feedback_useful"`))
+
+			stored, err := storage.GetMessage("CLK7MCUS3", message.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.SimilarThreads).To(Equal([]string{resolved.Timestamp}))
+			Expect(stored.SimilarFeedback).To(Equal(globals.AskedFeedback))
+			Expect(stored.FeedbackStatus).To(Equal(globals.NoFeedback), "no answer was sent to the message")
+		})
+
+		It("Should store the feedback on the similar threads without closing the message", func() {
+			_, err := a.HandleMessage(message)
+			Expect(err).To(Not(HaveOccurred()))
+
+			responses, err := a.HandleSimilarFeedback(globals.Interaction{
+				Channel:      "CLK7MCUS3",
+				ThreadTs:     message.Timestamp,
+				ActionUserID: "UUSER",
+				ActionValue:  string(globals.UsefulFeedback),
+				ResponseURL:  "https://hooks.slack.com/actions/1",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Action).To(Equal(globals.UpdateBlockKit))
+			Expect(responses[0].ResponseURL).To(Equal("https://hooks.slack.com/actions/1"))
+
+			stored, err := storage.GetMessage("CLK7MCUS3", message.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.SimilarFeedback).To(Equal(globals.UsefulFeedback))
+			Expect(stored.Status).To(Equal(globals.StatusUnresponded))
+			Expect(stored.FeedbackStatus).To(Equal(globals.NoFeedback))
+		})
+
+		It("Should only accept the feedback on the similar threads of the requester", func() {
+			_, err := a.HandleMessage(message)
+			Expect(err).To(Not(HaveOccurred()))
+
+			_, err = a.HandleSimilarFeedback(globals.Interaction{
+				Channel:      "CLK7MCUS3",
+				ThreadTs:     message.Timestamp,
+				ActionUserID: "UALICE",
+				ActionValue:  string(globals.UselessFeedback),
+			})
+			Expect(err).To(HaveOccurred())
+
+			stored, err := storage.GetMessage("CLK7MCUS3", message.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.SimilarFeedback).To(Equal(globals.AskedFeedback))
+		})
+
+		It("Should not suggest the threads sharing a single word with an unrelated message", func() {
+			unrelated := message
+			unrelated.Text = "Bonjour, le build de ma CI a expiré"
+
+			responses, err := a.HandleMessage(unrelated)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Text).To(Equal("Merci pour ton message."))
+
+			stored, err := storage.GetMessage("CLK7MCUS3", unrelated.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.SimilarThreads).To(BeEmpty())
+			Expect(stored.SimilarFeedback).To(BeEmpty())
+		})
+
+		It("Should not suggest the threads below the similarity score of the channel", func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3", "similar_score": 10}]`)
+			config.ResetChannels()
+
+			responses, err := a.HandleMessage(message)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Text).To(Equal("Merci pour ton message."))
+		})
+
+		It("Should not suggest anything when the channel disables it", func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3", "disable_similar_threads": true}]`)
+			config.ResetChannels()
+
+			responses, err := a.HandleMessage(message)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+
+			stored, err := storage.GetMessage("CLK7MCUS3", message.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored.SimilarThreads).To(BeEmpty())
+			Expect(stored.SimilarFeedback).To(BeEmpty())
+		})
+	})
+})
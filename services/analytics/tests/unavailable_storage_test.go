@@ -0,0 +1,74 @@
+package analytics_test
+
+import (
+	"errors"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var errUnavailable = errors.New("storage unavailable")
+
+// unavailableStorage fails the queries and the writes of the messages, like an elasticsearch down
+type unavailableStorage struct {
+	*memory.Memory
+	failQueries bool
+}
+
+func (m unavailableStorage) QueryRangeMessages(channel string, start string, end string) ([]globals.Message, error) {
+	if m.failQueries {
+		return nil, errUnavailable
+	}
+	return m.Memory.QueryRangeMessages(channel, start, end)
+}
+
+func (m unavailableStorage) QueryLabels(channel string, text string) ([]string, error) {
+	if m.failQueries {
+		return nil, errUnavailable
+	}
+	return m.Memory.QueryLabels(channel, text)
+}
+
+func (m unavailableStorage) AddMessage(_ globals.Message, _ ...string) error {
+	return errUnavailable
+}
+
+var _ = Describe("In", func() {
+	Describe("Test handlers when the storage is unavailable", func() {
+		message := globals.Message{
+			Type:      globals.NewMessage,
+			Channel:   "CLK7MCUS3",
+			Text:      "Hello",
+			UserID:    "UUSER",
+			Timestamp: "1592208201.000100",
+		}
+
+		It("Should fail the message when it cannot be analysed, for the event to be retried", func() {
+			a := analytics.Analyser{ESClient: unavailableStorage{Memory: memory.New(), failQueries: true}, Engine: newMessageMockedEngine{}}
+			_, err := a.HandleMessage(message)
+			Expect(err).To(MatchError(errUnavailable))
+		})
+
+		It("Should fail the message when it cannot be saved", func() {
+			a := analytics.Analyser{ESClient: unavailableStorage{Memory: memory.New()}, Engine: newMessageMockedEngine{}}
+			_, err := a.HandleMessage(message)
+			Expect(err).To(MatchError(errUnavailable))
+		})
+
+		It("Should fail the reaction when its message cannot be looked up", func() {
+			a := analytics.Analyser{ESClient: unavailableStorage{Memory: memory.New(), failQueries: true}}
+			_, err := a.HandleReaction(globals.Reaction{Channel: "CLK7MCUS3", MessageTs: message.Timestamp, Name: "heavy_check_mark"})
+			Expect(err).To(MatchError(errUnavailable))
+		})
+
+		It("Should ignore the reaction to a message never stored", func() {
+			a := analytics.Analyser{ESClient: unavailableStorage{Memory: memory.New()}}
+			_, err := a.HandleReaction(globals.Reaction{Channel: "CLK7MCUS3", MessageTs: message.Timestamp, Name: "heavy_check_mark"})
+			Expect(err).To(Not(HaveOccurred()))
+		})
+	})
+})
@@ -0,0 +1,189 @@
+package analytics_test
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test handler for the threads waiting for their requester", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+		var thread globals.Message
+		now := time.Now().Unix()
+
+		BeforeEach(func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3"}]`)
+			config.ResetChannels()
+			storage = memory.New()
+			Expect(storage.AddTeamMember(globals.TeamMember{SlackID: "UALICE", Name: "alice"})).To(Succeed())
+			a = analytics.Analyser{ESClient: storage}
+
+			thread = globals.Message{
+				Type:      globals.NewMessage,
+				Channel:   "CLK7MCUS3",
+				Status:    globals.StatusResponded,
+				Text:      "Mon token vault a expiré",
+				UserID:    "UUSER",
+				Timestamp: strconv.FormatInt(now-73*3600, 10) + ".000100",
+				RemindAt:  strconv.FormatInt(now+3600, 10),
+			}
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+		})
+
+		stored := func() globals.Message {
+			message, err := storage.GetMessage("CLK7MCUS3", thread.Timestamp)
+			Expect(err).To(Not(HaveOccurred()))
+			return message
+		}
+
+		action := func(value string, user string) []globals.SlackResponse {
+			responses, err := a.HandleThreadAction(globals.Interaction{
+				MessageTs:    thread.Timestamp,
+				ActionUserID: user,
+				ActionValue:  value,
+				Channel:      "CLK7MCUS3",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			return responses
+		}
+
+		It("Should wait for the requester on the action of a team member", func() {
+			Expect(storage.AddMessage(thread)).To(Succeed())
+
+			responses := action(globals.WaitingUserAction, "UALICE")
+			Expect(responses).To(HaveLen(2))
+			Expect(responses[0].Action).To(Equal(globals.ReplyMessage))
+			Expect(responses[0].Text).To(Equal("En attente d'un retour de <@UUSER>. Sans nouvelles, ce fil sera fermé dans 3 j."))
+			Expect(responses[1]).To(Equal(globals.SlackResponse{
+				Action: globals.React, Text: "hourglass_flowing_sand", Ts: thread.Timestamp, ChanID: "CLK7MCUS3",
+			}))
+
+			message := stored()
+			Expect(message.Status).To(Equal(globals.StatusWaitingUser))
+			Expect(message.WaitingSince).To(Not(BeEmpty()))
+			Expect(globals.ParseDuration(message.RemindAt)).To(BeNumerically(">=", now+24*3600))
+
+			responses = action(globals.WaitingUserAction, "UALICE")
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Action).To(Equal(globals.Ephemeral))
+		})
+
+		It("Should refuse the actions of the other users", func() {
+			Expect(storage.AddMessage(thread)).To(Succeed())
+
+			responses := action(globals.WaitingUserAction, "UUSER")
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Action).To(Equal(globals.Ephemeral))
+			Expect(responses[0].UserID).To(Equal("UUSER"))
+			Expect(stored().Status).To(Equal(globals.StatusResponded))
+		})
+
+		It("Should wait for the requester on the status reaction", func() {
+			Expect(storage.AddMessage(thread)).To(Succeed())
+
+			_, err := a.HandleReaction(globals.Reaction{
+				Name:      "hourglass_flowing_sand",
+				Users:     []string{"UALICE"},
+				Channel:   "CLK7MCUS3",
+				MessageTs: thread.Timestamp,
+				Timestamp: strconv.FormatInt(now, 10) + ".000200",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored().Status).To(Equal(globals.StatusWaitingUser))
+		})
+
+		It("Should remind the requester, then close the thread without the waiting time", func() {
+			thread.Status = globals.StatusWaitingUser
+			thread.WaitingSince = strconv.FormatInt(now-72*3600, 10)
+			thread.RemindAt = strconv.FormatInt(now, 10)
+			Expect(storage.AddMessage(thread)).To(Succeed())
+
+			responses, err := a.HandleRemindersRequest()
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Text).To(HavePrefix("Du nouveau <@UUSER> ? Sans nouvelles de ta part, ce fil sera fermé dans"))
+			message := stored()
+			Expect(message.Status).To(Equal(globals.StatusWaitingUser))
+			Expect(message.EscalationStep).To(Equal(1))
+
+			message.RemindAt = strconv.FormatInt(now, 10)
+			Expect(storage.AddMessage(message, message.ID)).To(Succeed())
+			responses, err = a.HandleRemindersRequest()
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(2))
+			Expect(responses[0].Text).To(HavePrefix("Sans nouvelles de <@UUSER>, je ferme ce fil."))
+			Expect(responses[1].Action).To(Equal(globals.React))
+			Expect(responses[1].Text).To(Equal("heavy_check_mark"))
+
+			message = stored()
+			Expect(message.Status).To(Equal(globals.StatusFixed))
+			Expect(message.RemindAt).To(BeEmpty())
+			Expect(message.WaitingTime).To(BeNumerically(">=", 72*60))
+			Expect(message.ResolutionTime).To(BeNumerically("~", 60, 1))
+		})
+
+		It("Should remind the team again when the requester answers only", func() {
+			thread.Status = globals.StatusWaitingUser
+			thread.WaitingSince = strconv.FormatInt(now-3600, 10)
+			Expect(storage.AddMessage(thread)).To(Succeed())
+
+			_, err := a.HandleReplies(globals.Reply{
+				Channel:   "CLK7MCUS3",
+				ThreadTs:  thread.Timestamp,
+				Timestamp: strconv.FormatInt(now, 10) + ".000200",
+				UserID:    "UALICE",
+				Text:      "Tu peux réessayer ?",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(stored().Status).To(Equal(globals.StatusWaitingUser))
+
+			_, err = a.HandleReplies(globals.Reply{
+				Channel:   "CLK7MCUS3",
+				ThreadTs:  thread.Timestamp,
+				Timestamp: strconv.FormatInt(now, 10) + ".000300",
+				UserID:    "UUSER",
+				Text:      "Toujours bloqué",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			message := stored()
+			Expect(message.Status).To(Equal(globals.StatusResponded))
+			Expect(message.WaitingSince).To(BeEmpty())
+			Expect(message.WaitingTime).To(BeNumerically("~", 60, 1))
+			Expect(message.EscalationStep).To(Equal(0))
+		})
+
+		It("Should snooze the reminders of the thread", func() {
+			Expect(storage.AddMessage(thread)).To(Succeed())
+
+			responses := action(globals.SnoozeAction, "UALICE")
+			Expect(responses).To(Equal([]globals.SlackResponse{{
+				Action: globals.Ephemeral,
+				Text:   "Les relances de ce fil sont reportées de 1 j.",
+				ChanID: "CLK7MCUS3",
+				UserID: "UALICE",
+			}}))
+			Expect(globals.ParseDuration(stored().RemindAt)).To(BeNumerically(">=", now+24*3600))
+
+			message := stored()
+			message.RemindAt = ""
+			Expect(storage.AddMessage(message, message.ID)).To(Succeed())
+			responses = action(globals.SnoozeAction, "UALICE")
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Text).To(Equal("Aucune relance n'est prévue sur ce fil."))
+		})
+	})
+})
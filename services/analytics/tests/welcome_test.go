@@ -0,0 +1,115 @@
+package analytics_test
+
+import (
+	"encoding/json"
+
+	"github.com/leboncoin/subot/pkg/config"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/memory"
+	"github.com/leboncoin/subot/services/analytics"
+	"github.com/spf13/viper"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test handler for the newcomers of the channel", func() {
+		var storage *memory.Memory
+		var a analytics.Analyser
+		newcomer := globals.Message{Channel: "CLK7MCUS3", UserID: "UNEW"}
+
+		BeforeEach(func() {
+			viper.Set("slack_channels", `[{"id": "CLK7MCUS3"}]`)
+			config.ResetChannels()
+			storage = memory.New()
+			a = analytics.Analyser{ESClient: storage}
+		})
+
+		AfterEach(func() {
+			viper.Set("slack_channels", nil)
+			config.ResetChannels()
+		})
+
+		join := func() globals.SlackResponse {
+			responses, err := a.HandleJoinMessage(newcomer)
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			return responses[0]
+		}
+
+		It("Should send the default welcome message of the locale", func() {
+			response := join()
+			Expect(response.Action).To(Equal(globals.Ephemeral))
+			Expect(response.UserID).To(Equal("UNEW"))
+			Expect(response.ChanID).To(Equal("CLK7MCUS3"))
+			Expect(response.Text).To(HavePrefix(":wave: _Bienvenue sur <#CLK7MCUS3>_"))
+		})
+
+		It("Should not welcome the newcomers of a channel which is not configured", func() {
+			responses, err := a.HandleJoinMessage(globals.Message{Channel: "COTHER", UserID: "UNEW"})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(BeEmpty())
+		})
+
+		It("Should send the welcome message shared by every channel", func() {
+			Expect(storage.SetWelcomeMessage(globals.WelcomeMessage{
+				Text:   "Bienvenue {{mention .User}}",
+				Blocks: json.RawMessage(`[{"type": "section", "text": {"type": "mrkdwn", "text": "Bienvenue {{mention .User}} sur {{channel .Channel}}"}}]`),
+			})).To(Succeed())
+
+			response := join()
+			Expect(response.Action).To(Equal(globals.Ephemeral))
+			Expect(response.Text).To(Equal("Bienvenue <@UNEW>"))
+			Expect(response.Blocks).To(HaveLen(1))
+			section := response.Blocks[0].(map[string]interface{})
+			Expect(section["text"]).To(HaveKeyWithValue("text", "Bienvenue <@UNEW> sur <#CLK7MCUS3>"))
+		})
+
+		It("Should send the rules of the channel in a direct message and record their acknowledgement", func() {
+			Expect(storage.SetWelcomeMessage(globals.WelcomeMessage{Text: "Bienvenue"})).To(Succeed())
+			Expect(storage.SetWelcomeMessage(globals.WelcomeMessage{
+				Channel:     "CLK7MCUS3",
+				Text:        "Les règles de {{channel .Channel}}",
+				Direct:      true,
+				Acknowledge: true,
+			})).To(Succeed())
+
+			response := join()
+			Expect(response.Action).To(Equal(globals.ChannelMessage))
+			Expect(response.ChanID).To(Equal("UNEW"))
+			Expect(response.Text).To(Equal("Les règles de <#CLK7MCUS3>"))
+			Expect(response.Blocks).To(HaveLen(2))
+			b, err := json.Marshal(response.Blocks[1])
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(string(b)).To(ContainSubstring(`"action_id":"welcome_ack","value":"CLK7MCUS3"`))
+			Expect(string(b)).To(ContainSubstring("J'ai lu les règles"))
+
+			responses, err := a.HandleWelcomeAcknowledgement(globals.Interaction{
+				Channel:      "DNEW",
+				ActionUserID: "UNEW",
+				ActionValue:  "CLK7MCUS3",
+				ResponseURL:  "https://hooks.slack.com/actions/T0/1/2",
+			})
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(responses).To(HaveLen(1))
+			Expect(responses[0].Action).To(Equal(globals.UpdateBlockKit))
+			Expect(responses[0].ResponseURL).To(Equal("https://hooks.slack.com/actions/T0/1/2"))
+			Expect(responses[0].Blocks).To(HaveLen(2))
+			Expect(responses[0].Text).To(ContainSubstring("Merci <@UNEW>"))
+
+			acknowledgements, err := storage.GetWelcomeAcknowledgements("CLK7MCUS3")
+			Expect(err).To(Not(HaveOccurred()))
+			Expect(acknowledgements).To(HaveLen(1))
+			Expect(acknowledgements[0].UserID).To(Equal("UNEW"))
+		})
+
+		It("Should fall back on the default welcome message when the stored one is invalid", func() {
+			Expect(storage.SetWelcomeMessage(globals.WelcomeMessage{Text: "Bienvenue {{.Unknown}}"})).To(Succeed())
+
+			response := join()
+			Expect(response.Action).To(Equal(globals.Ephemeral))
+			Expect(response.Text).To(HavePrefix(":wave: _Bienvenue sur <#CLK7MCUS3>_"))
+		})
+	})
+})
@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"github.com/leboncoin/subot/pkg/globals"
+	log "github.com/sirupsen/logrus"
+)
+
+// TimeSeries godoc
+// @Summary Retrieve analytics for the period, by date
+// @Description buckets the user messages of the period by day, week or month,
+// @Description with the p50, p90 and p99 of their response and resolution times.
+// @Description Each bucket can be split by label, tool, status or user.
+// @Description Dates are computed in the timezone of the business calendar
+// @Tags Analytics
+// @ID analyse-time-series
+// @Produce  json
+// @Param channel query string false "ID of the channel to analyse, all channels when empty"
+// @Param start query string true "Start date of the period to analyse (format 2020-12-31)"
+// @Param end query string true "End date of the period to analyse (format 2020-12-31)"
+// @Param interval query string false "Size of the buckets: day, week or month (default day)"
+// @Param group_by query string false "Split the buckets by label, tool, status or user"
+// @Success 200 {object} globals.TimeSeries
+// @Success 400 {string} Error
+// @Success 500 {string} Error
+// @Router /analytics/timeseries [get]
+func (a Analyser) TimeSeries(channel string, start string, end string, interval string, groupBy string) (globals.TimeSeries, error) {
+	startTs, err := globals.ParseDate(start)
+	if err != nil {
+		return globals.TimeSeries{}, err
+	}
+	endTs, err := globals.ParseDate(end)
+	if err != nil {
+		return globals.TimeSeries{}, err
+	}
+	workflow, err := a.ESClient.GetWorkflow(channel)
+	if err != nil {
+		return globals.TimeSeries{}, err
+	}
+
+	query := globals.TimeSeriesQuery{
+		Channel:  channel,
+		Start:    startTs,
+		End:      endTs,
+		Interval: interval,
+		GroupBy:  groupBy,
+		Closing:  workflow.ClosingStatuses(),
+	}
+	if a.Calendar != nil {
+		query.TimeZone = a.Calendar.Location.String()
+	}
+	log.WithFields(log.Fields{"query": query}).Debug("Starting fetch of the time series")
+	return a.ESClient.QueryTimeSeries(query)
+}
+
+// isValidTimeSeries checks the interval and the grouping requested for a time series
+func isValidTimeSeries(interval string, groupBy string) bool {
+	return contains(globals.TimeSeriesIntervals, interval) && (groupBy == "" || contains(globals.TimeSeriesGroups, groupBy))
+}
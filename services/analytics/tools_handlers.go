@@ -11,9 +11,10 @@ import (
 // @Tags Tools
 // @ID get-tools
 // @Produce  json
+// @Param channel query string false "ID of the channel, all tools when empty"
 // @Router /tools [get]
 func (a Analyser) GetTools(c *gin.Context) {
-	answers, err := a.ESClient.GetTools()
+	answers, err := a.ESClient.GetTools(c.Query("channel"))
 	if err != nil {
 		c.JSON(500, gin.H{
 			"error": err.Error(),
@@ -31,6 +32,7 @@ func (a Analyser) GetTools(c *gin.Context) {
 // @ID add-tool
 // @Produce  json
 // @Param name body string true "Name of the tool to add"
+// @Param channel body string false "ID of the channel of this tool, shared by every channel when empty"
 // @Param query body object true "The percolator query it shall match"
 // @Router /tools/new [post]
 func (a Analyser) AddTool(c *gin.Context) {
@@ -60,6 +62,7 @@ func (a Analyser) AddTool(c *gin.Context) {
 // @Produce  json
 // @Param tool query string true "Tool id to update"
 // @Param name body string true "Name of the tool to add"
+// @Param channel body string false "ID of the channel of this tool, shared by every channel when empty"
 // @Param query body object true "The percolator query it shall match"
 // @Router /tools/:tool [put]
 func (a Analyser) EditTool(c *gin.Context) {
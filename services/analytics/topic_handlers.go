@@ -7,7 +7,7 @@ import (
 
 // HandleFiremanChange godoc
 // @Summary Handles topic changes looking for a new fireman
-// @Description returns an empty reply and stores the new fireman in storage
+// @Description stores the new fireman in storage and returns the handover digest of the channel for the new fireman
 // @Tags Analytics
 // @ID handle-fireman-change
 // @Accept  json
@@ -18,7 +18,7 @@ func (a Analyser) HandleFiremanChange(message globals.Message) (replies []global
 	var reply globals.SlackResponse
 	reply.Action = globals.Nothing
 	log.Debug("Check if message comes from team member")
-	isTeamMessage, err := a.ESClient.IsTeamMember(message.UserID)
+	isTeamMessage, err := a.ESClient.IsTeamMember(message.Channel, message.UserID)
 	if err != nil {
 		return
 	}
@@ -32,7 +32,10 @@ func (a Analyser) HandleFiremanChange(message globals.Message) (replies []global
 	err = a.ESClient.AddFireman(message)
 	if err != nil {
 		log.Error("Got an error while saving fireman", err)
-		return []globals.SlackResponse{reply}, nil
+		return []globals.SlackResponse{reply}, err
+	}
+	if digest := a.handoverDigest(channelSettings(message.Channel), message.UserInfo.ID); len(digest) > 0 {
+		return digest, nil
 	}
 	return []globals.SlackResponse{reply}, nil
 }
@@ -22,7 +22,7 @@ func (a Analyser) HandleUpdatedMessage(message globals.Message) (replies []globa
 
 	log.Debug("Look for message to update")
 	originalTs := message.Timestamp
-	storedMessages, err := a.ESClient.QueryRangeMessages(originalTs, originalTs)
+	storedMessages, err := a.ESClient.QueryRangeMessages(message.Channel, originalTs, originalTs)
 	log.WithFields(log.Fields{"messages": storedMessages}).Debug("Found stored message for this timestamp")
 	if err != nil {
 		log.Error("Failed to fetch last messages", err)
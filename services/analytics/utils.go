@@ -3,13 +3,12 @@ package analytics
 import (
 	"time"
 
+	"github.com/leboncoin/subot/pkg/config"
 	"github.com/leboncoin/subot/pkg/globals"
 
 	log "github.com/sirupsen/logrus"
 )
 
-const reminderInterval = 1 * time.Hour
-
 func subSevenDays(date string) string {
 	parsedDate, err := time.Parse(globals.DateLayout, date)
 	if err != nil {
@@ -20,10 +19,68 @@ func subSevenDays(date string) string {
 	return sub.Format(globals.DateLayout)
 }
 
-func (a Analyser) getFiremanID() string {
+// channelID returns the ID of the channel to reply to.
+// Messages stored before multi-channel support have no channel, they belong to the default one
+func channelID(channel string) string {
+	return channelSettings(channel).ID
+}
+
+// channelSettings returns the settings of the channel, the default ones when it is not configured
+func channelSettings(channel string) config.Channel {
+	settings, _ := config.GetChannel(channel)
+	return settings
+}
+
+// locale returns the locale of the messages of the bot addressed to the user in the channel.
+// They follow the slack locale of the user when the channel allows it, the locale of the channel otherwise
+func locale(channel string, user globals.User) string {
+	settings := channelSettings(channel)
+	if settings.UserLocale && user.Locale != "" {
+		return user.Locale
+	}
+	return settings.Locale
+}
+
+// resetReminder restarts the escalation of the thread from its first step, from now.
+// The thread is not reminded when reminders are disabled for this channel
+func (a Analyser) resetReminder(message *globals.Message) {
+	message.EscalationStep = 0
+	message.RemindAt = ""
+	if channelSettings(message.Channel).DisableReminders {
+		return
+	}
+	message.RemindAt = a.escalationPolicy(*message).NextReminder(time.Now(), 0)
+}
+
+// escalationPolicy returns the escalation policy of the thread.
+// Without any policy matching it, the fireman is reminded once after the reminder interval of the channel
+func (a Analyser) escalationPolicy(message globals.Message) globals.EscalationPolicy {
+	policies, err := a.ESClient.GetEscalationPolicies(message.Channel)
+	if err != nil {
+		log.WithFields(log.Fields{"channel": message.Channel, "error": err}).Error("Could not fetch the escalation policies")
+	}
+	if policy, ok := globals.EscalationPolicyFor(policies, message); ok {
+		return policy
+	}
+	return globals.EscalationPolicy{Steps: []globals.EscalationStep{{
+		After:  channelSettings(message.Channel).ReminderInterval.String(),
+		Target: globals.EscalateFireman,
+	}}}
+}
+
+// businessTime returns the working time between two slack timestamps, in minutes like the raw response and resolution times
+func (a Analyser) businessTime(from string, to string) time.Duration {
+	return a.Calendar.Duration(globals.ParseTimestamp(from), globals.ParseTimestamp(to)) / time.Minute
+}
+
+// getFiremanID returns the fireman of the channel, from its rotation or else from the last topic change of the week
+func (a Analyser) getFiremanID(channel string) string {
+	if fireman := a.rotationFireman(channel); fireman != "" {
+		return fireman
+	}
 	startOfWeek := time.Now().AddDate(0, 0, -int(time.Now().Weekday())+1).Format(globals.DateLayout)
 	endOfWeek := time.Now().AddDate(0, 0, 1).Format(globals.DateLayout)
-	fireman, err := a.ESClient.QueryRangeFireman(startOfWeek, endOfWeek)
+	fireman, err := a.ESClient.QueryRangeFireman(channel, startOfWeek, endOfWeek)
 	if err != nil {
 		return ""
 	}
@@ -32,3 +89,12 @@ func (a Analyser) getFiremanID() string {
 	}
 	return fireman[0].UserInfo.ID
 }
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,255 @@
+package analytics
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+	log "github.com/sirupsen/logrus"
+)
+
+type threadActionElement struct {
+	Type     string                     `json:"type"`
+	ActionID string                     `json:"action_id"`
+	Style    string                     `json:"style,omitempty"`
+	Value    string                     `json:"value"`
+	Text     feedbackElementTextSection `json:"text"`
+}
+
+type threadActionsSection struct {
+	Type     string                `json:"type"`
+	Elements []threadActionElement `json:"elements"`
+}
+
+// threadActionsTemplate is the reminder of a thread followed by the buttons changing its state
+func (a Analyser) threadActionsTemplate(locale string, text string, ts string) []interface{} {
+	button := func(action string, label string) threadActionElement {
+		return threadActionElement{
+			Type:     "button",
+			ActionID: action,
+			Value:    ts,
+			Text:     feedbackElementTextSection{Type: "plain_text", Text: label, Emoji: true},
+		}
+	}
+	return []interface{}{
+		feedbackTextSection{
+			Type: "section",
+			Text: map[string]string{
+				"type": "mrkdwn",
+				"text": text,
+			},
+		},
+		threadActionsSection{
+			Type: "actions",
+			Elements: []threadActionElement{
+				button(globals.WaitingUserAction, a.Messages.Render(locale, "thread.waiting_button", nil)),
+				button(globals.SnoozeAction, a.Messages.Render(locale, "thread.snooze_button", nil)),
+			},
+		},
+	}
+}
+
+// startWaiting pauses the clocks of the thread until its requester answers.
+// The requester is reminded after the waiting reminder of the channel instead of the team
+func (a Analyser) startWaiting(message *globals.Message) {
+	settings := channelSettings(message.Channel)
+	now := time.Now()
+	message.Status = globals.StatusWaitingUser
+	message.WaitingSince = strconv.FormatInt(now.Unix(), 10)
+	message.EscalationStep = 0
+	message.RemindAt = strconv.FormatInt(now.Add(settings.WaitingReminder).Unix(), 10)
+}
+
+// stopWaiting adds the time spent waiting for the requester until the timestamp to the waiting time of the thread
+func (a Analyser) stopWaiting(message *globals.Message, ts string) {
+	if message.WaitingSince == "" {
+		return
+	}
+	waitingTime := (globals.ParseDuration(ts) - globals.ParseDuration(message.WaitingSince)) / 60
+	if waitingTime > 0 {
+		message.WaitingTime += time.Duration(waitingTime)
+		message.BusinessWaitingTime += a.businessTime(message.WaitingSince, ts)
+	}
+	message.WaitingSince = ""
+}
+
+// resolve stores the resolution time of the thread closed at the timestamp,
+// without the time spent waiting for the requester, and stops its reminders
+func (a Analyser) resolve(message *globals.Message, ts string) {
+	a.stopWaiting(message, ts)
+	resolutionTime := (globals.ParseDuration(ts) - globals.ParseDuration(message.Timestamp)) / 60
+	message.ResolutionTime = time.Duration(resolutionTime) - message.WaitingTime
+	message.BusinessResolutionTime = a.businessTime(message.Timestamp, ts) - message.BusinessWaitingTime
+	message.RemindAt = ""
+}
+
+// remindRequester reminds the requester of the thread waiting for them once,
+// then closes the thread when they still did not answer after the waiting timeout of the channel
+func (a Analyser) remindRequester(message *globals.Message) ([]globals.SlackResponse, error) {
+	settings := channelSettings(message.Channel)
+	channel := channelID(message.Channel)
+	userLocale := locale(message.Channel, message.UserInfo)
+	now := time.Now()
+
+	if message.EscalationStep == 0 {
+		closeAt := globals.ParseTimestamp(message.WaitingSince).Add(settings.WaitingTimeout)
+		if closeAt.Before(now.Add(time.Minute)) {
+			closeAt = now.Add(time.Minute)
+		}
+		message.EscalationStep = 1
+		message.RemindAt = strconv.FormatInt(closeAt.Unix(), 10)
+		return []globals.SlackResponse{{
+			Action: globals.ReplyMessage,
+			Text: a.Messages.Render(userLocale, "waiting.reminder", i18n.Vars{
+				"User":  message.UserID,
+				"Delay": a.formatDelay(userLocale, closeAt.Sub(now)),
+			}),
+			Ts:     message.Timestamp,
+			ChanID: channel,
+		}}, nil
+	}
+
+	workflow, err := a.ESClient.GetWorkflow(message.Channel)
+	if err != nil {
+		return nil, err
+	}
+	rule, ok := closingRule(workflow)
+	if !ok {
+		log.WithFields(log.Fields{"channel": message.Channel}).Warn("No closing status in the workflow, the thread stays open")
+		message.RemindAt = ""
+		return nil, nil
+	}
+	message.Status = rule.Status
+	a.resolve(message, strconv.FormatInt(now.Unix(), 10))
+	return []globals.SlackResponse{
+		{
+			Action: globals.ReplyMessage,
+			Text:   a.Messages.Render(userLocale, "waiting.closed", i18n.Vars{"User": message.UserID}),
+			Ts:     message.Timestamp,
+			ChanID: channel,
+		},
+		{
+			Action: globals.React,
+			Text:   rule.Emoji,
+			Ts:     message.Timestamp,
+			ChanID: channel,
+		},
+	}, nil
+}
+
+// closingRule returns the first rule of the workflow closing the threads
+func closingRule(workflow globals.Workflow) (globals.StatusRule, bool) {
+	for _, rule := range workflow {
+		if rule.Closes {
+			return rule, true
+		}
+	}
+	return globals.StatusRule{}, false
+}
+
+// formatDelay returns the delay rounded in days, hours or minutes
+func (a Analyser) formatDelay(locale string, delay time.Duration) string {
+	if delay < time.Hour {
+		return a.Messages.Render(locale, "duration.minutes", i18n.Vars{"Count": int(delay.Round(time.Minute).Minutes())})
+	}
+	delay = delay.Round(time.Hour)
+	if delay%(24*time.Hour) == 0 {
+		return a.Messages.Render(locale, "duration.days", i18n.Vars{"Count": int(delay.Hours() / 24)})
+	}
+	return a.Messages.Render(locale, "duration.hours", i18n.Vars{"Count": int(delay.Hours())})
+}
+
+// HandleThreadAction godoc
+// @Summary Puts a thread in the waiting state or snoozes its reminders
+// @Description Handles the buttons of the reminders and the message shortcuts on a thread.
+// @Description The waiting_user action pauses the clocks of the thread until its requester answers,
+// @Description the snooze action postpones the next reminder of the thread by the snooze duration of the channel.
+// @Description Only the members of the team are allowed to complete the interaction
+// @Tags Analytics
+// @ID handle-thread-action
+// @Accept  json
+// @Produce  json
+// @Param interaction body object true "Interaction object sent by slack"
+// @Router /analytics/thread_action [post]
+func (a Analyser) HandleThreadAction(interaction globals.Interaction) (replies []globals.SlackResponse, err error) {
+	channel := channelID(interaction.Channel)
+	teamLocale := channelSettings(interaction.Channel).Locale
+	ephemeral := func(key string, vars i18n.Vars) []globals.SlackResponse {
+		return []globals.SlackResponse{{
+			Action: globals.Ephemeral,
+			Text:   a.Messages.Render(teamLocale, key, vars),
+			ChanID: channel,
+			UserID: interaction.ActionUserID,
+		}}
+	}
+
+	ts := interaction.ThreadTs
+	if ts == "" {
+		ts = interaction.MessageTs
+	}
+	messages, err := a.ESClient.QueryRangeMessages(interaction.Channel, ts, ts)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 || messages[0].Type != globals.NewMessage {
+		return ephemeral("thread.not_support", nil), nil
+	}
+	message := messages[0]
+
+	isTeamMember, err := a.ESClient.IsTeamMember(interaction.Channel, interaction.ActionUserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isTeamMember {
+		return ephemeral("thread.not_team", nil), nil
+	}
+	workflow, err := a.ESClient.GetWorkflow(interaction.Channel)
+	if err != nil {
+		return nil, err
+	}
+	if workflow.IsClosing(message.Status) {
+		return ephemeral("thread.closed", nil), nil
+	}
+
+	settings := channelSettings(message.Channel)
+	userLocale := locale(message.Channel, message.UserInfo)
+	switch interaction.ActionValue {
+	case globals.WaitingUserAction:
+		if message.Status == globals.StatusWaitingUser {
+			return ephemeral("thread.already_waiting", nil), nil
+		}
+		a.startWaiting(&message)
+		replies = append(replies, globals.SlackResponse{
+			Action: globals.ReplyMessage,
+			Text: a.Messages.Render(userLocale, "waiting.started", i18n.Vars{
+				"User":  message.UserID,
+				"Delay": a.formatDelay(userLocale, settings.WaitingTimeout),
+			}),
+			Ts:     message.Timestamp,
+			ChanID: channel,
+		})
+		if emoji := workflow.EmojiFor(globals.StatusWaitingUser); emoji != "" {
+			replies = append(replies, globals.SlackResponse{
+				Action: globals.React,
+				Text:   emoji,
+				Ts:     message.Timestamp,
+				ChanID: channel,
+			})
+		}
+	case globals.SnoozeAction:
+		if message.RemindAt == "" {
+			return ephemeral("thread.no_reminder", nil), nil
+		}
+		message.RemindAt = strconv.FormatInt(time.Now().Add(settings.SnoozeDuration).Unix(), 10)
+		replies = ephemeral("thread.snoozed", i18n.Vars{"Delay": a.formatDelay(teamLocale, settings.SnoozeDuration)})
+	default:
+		return nil, fmt.Errorf("unknown thread action %q", interaction.ActionValue)
+	}
+
+	if err := a.ESClient.AddMessage(message, message.ID); err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
@@ -0,0 +1,174 @@
+package analytics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/i18n"
+)
+
+// maxBlocks is the number of blocks slack accepts in a message
+const maxBlocks = 50
+
+type welcomeContextSection struct {
+	Type     string              `json:"type"`
+	Elements []map[string]string `json:"elements"`
+}
+
+// welcomeMessage returns the welcome message of the channel, or else the one shared by every channel
+func (a Analyser) welcomeMessage(channel string) (globals.WelcomeMessage, bool, error) {
+	messages, err := a.ESClient.GetWelcomeMessages(channel)
+	if err != nil {
+		return globals.WelcomeMessage{}, false, err
+	}
+	var shared *globals.WelcomeMessage
+	for i, welcome := range messages {
+		if welcome.Channel == channel {
+			return welcome, true, nil
+		}
+		if welcome.Channel == "" {
+			shared = &messages[i]
+		}
+	}
+	if shared != nil {
+		return *shared, true, nil
+	}
+	return globals.WelcomeMessage{}, false, nil
+}
+
+// renderWelcome renders the text and the blocks of the welcome message for the newcomer of the channel
+func renderWelcome(welcome globals.WelcomeMessage, user string, channel string) (string, []interface{}, error) {
+	if welcome.Text == "" && len(welcome.Blocks) == 0 {
+		return "", nil, errors.New("text or blocks are required")
+	}
+	vars := i18n.Vars{"User": user, "Channel": channel}
+	text, err := i18n.Execute("text", welcome.Text, vars)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid text : %s", err)
+	}
+	if len(welcome.Blocks) == 0 {
+		return text, nil, nil
+	}
+
+	rendered, err := i18n.Execute("blocks", string(welcome.Blocks), vars)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid blocks : %s", err)
+	}
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(rendered), &blocks); err != nil {
+		return "", nil, fmt.Errorf("blocks shall be a JSON array of Block Kit blocks : %s", err)
+	}
+	for i, block := range blocks {
+		if fields, ok := block.(map[string]interface{}); !ok || fields["type"] == nil {
+			return "", nil, fmt.Errorf("block %d shall be an object with a type", i+1)
+		}
+	}
+	if len(blocks) == 0 || len(blocks) > maxBlocks-1 {
+		return "", nil, fmt.Errorf("blocks shall hold between 1 and %d blocks", maxBlocks-1)
+	}
+	return text, blocks, nil
+}
+
+// welcomeBlocks returns the blocks of the welcome message, its text in a section when it has no blocks
+func welcomeBlocks(text string, blocks []interface{}) []interface{} {
+	if len(blocks) > 0 {
+		return blocks
+	}
+	return []interface{}{
+		feedbackTextSection{
+			Type: "section",
+			Text: map[string]string{
+				"type": "mrkdwn",
+				"text": text,
+			},
+		},
+	}
+}
+
+// welcomeReply returns the welcome message of the newcomer of the channel, as an ephemeral message in the channel
+// or as a direct message. The rules are followed by a button acknowledging them when the message requires it
+func (a Analyser) welcomeReply(welcome globals.WelcomeMessage, userLocale string, user string, channel string) (globals.SlackResponse, error) {
+	text, blocks, err := renderWelcome(welcome, user, channel)
+	if err != nil {
+		return globals.SlackResponse{}, err
+	}
+	if welcome.Acknowledge {
+		blocks = append(welcomeBlocks(text, blocks), threadActionsSection{
+			Type: "actions",
+			Elements: []threadActionElement{{
+				Type:     "button",
+				ActionID: globals.WelcomeAckAction,
+				Value:    channel,
+				Text: feedbackElementTextSection{
+					Type:  "plain_text",
+					Text:  a.Messages.Render(userLocale, "welcome.acknowledge", nil),
+					Emoji: true,
+				},
+			}},
+		})
+	}
+
+	reply := globals.SlackResponse{
+		Action: globals.Ephemeral,
+		Text:   text,
+		Blocks: blocks,
+		ChanID: channel,
+		UserID: user,
+	}
+	if welcome.Direct {
+		reply.Action = globals.ChannelMessage
+		reply.ChanID = user
+		reply.UserID = ""
+	}
+	return reply, nil
+}
+
+// HandleWelcomeAcknowledgement godoc
+// @Summary Records that a newcomer read the rules of the channel
+// @Description Handles the button of the welcome messages requiring an acknowledgement.
+// @Description The acknowledgement of the user is stored and the button of the message is replaced
+// @Tags Analytics
+// @ID handle-welcome-acknowledgement
+// @Accept  json
+// @Produce  json
+// @Param interaction body object true "Interaction object sent by slack, its action value being the channel of the rules"
+// @Router /analytics/welcome_ack [post]
+func (a Analyser) HandleWelcomeAcknowledgement(interaction globals.Interaction) ([]globals.SlackResponse, error) {
+	channel := interaction.ActionValue
+	if channel == "" {
+		return nil, errors.New("the channel of the rules is required")
+	}
+	err := a.ESClient.AddWelcomeAcknowledgement(globals.WelcomeAcknowledgement{
+		Channel: channel,
+		UserID:  interaction.ActionUserID,
+		Date:    strconv.FormatInt(time.Now().Unix(), 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	acknowledged := a.Messages.Render(channelSettings(channel).Locale, "welcome.acknowledged", i18n.Vars{
+		"User":    interaction.ActionUserID,
+		"Channel": channel,
+	})
+	var blocks []interface{}
+	if welcome, found, err := a.welcomeMessage(channel); err == nil && found {
+		if text, rendered, err := renderWelcome(welcome, interaction.ActionUserID, channel); err == nil {
+			blocks = welcomeBlocks(text, rendered)
+		}
+	}
+	blocks = append(blocks, welcomeContextSection{
+		Type:     "context",
+		Elements: []map[string]string{{"type": "mrkdwn", "text": acknowledged}},
+	})
+	return []globals.SlackResponse{{
+		Action:      globals.UpdateBlockKit,
+		Text:        acknowledged,
+		Blocks:      blocks,
+		ResponseURL: interaction.ResponseURL,
+	}}, nil
+}
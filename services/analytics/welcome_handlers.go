@@ -0,0 +1,158 @@
+package analytics
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// blockKitBuilderURL is the url of the slack tool displaying Block Kit blocks
+const blockKitBuilderURL = "https://app.slack.com/block-kit-builder#"
+
+// previewUser is the user the welcome messages are previewed for, when none is given
+const previewUser = "USLACKBOT"
+
+// GetWelcomeMessages godoc
+// @Summary Get the welcome messages
+// @Description Returns the welcome messages sent to the newcomers, the one of the channel and the one shared by every channel.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Welcome
+// @ID get-welcome-messages
+// @Produce  json
+// @Param channel query string false "ID of the channel, all welcome messages when empty"
+// @Router /admin/welcome [get]
+func (a Analyser) GetWelcomeMessages(c *gin.Context) {
+	messages, err := a.ESClient.GetWelcomeMessages(c.Query("channel"))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if messages == nil {
+		messages = []globals.WelcomeMessage{}
+	}
+	c.JSON(200, messages)
+}
+
+// SetWelcomeMessage godoc
+// @Summary Set the welcome message of a channel
+// @Description Stores the welcome message of the channel, replacing the previous one. Its text and its Block Kit blocks
+// @Description are go text/template templates given the User and the Channel, with the mention and channel functions.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Welcome
+// @ID set-welcome-message
+// @Produce  json
+// @Param channel body string false "ID of the channel of the welcome message, shared by every channel when empty"
+// @Param text body string false "Text of the welcome message, the fallback of the blocks in the notifications"
+// @Param blocks body array false "Block Kit blocks of the welcome message"
+// @Param direct body boolean false "Send the welcome message in a direct message rather than an ephemeral message"
+// @Param acknowledge body boolean false "Add a button to the welcome message recording that the newcomer read the rules"
+// @Router /admin/welcome [put]
+func (a Analyser) SetWelcomeMessage(c *gin.Context) {
+	var welcome globals.WelcomeMessage
+	if err := c.BindJSON(&welcome); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if _, _, err := renderWelcome(welcome, previewUser, channelSettings(welcome.Channel).ID); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.ESClient.SetWelcomeMessage(welcome); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{})
+}
+
+// DeleteWelcomeMessage godoc
+// @Summary Delete the welcome message of a channel
+// @Description Deletes the welcome message of the channel, its newcomers receive the shared one or the default one.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Welcome
+// @ID delete-welcome-message
+// @Produce  json
+// @Param channel query string false "ID of the channel, the welcome message shared by every channel when empty"
+// @Router /admin/welcome [delete]
+func (a Analyser) DeleteWelcomeMessage(c *gin.Context) {
+	if err := a.ESClient.DeleteWelcomeMessage(c.Query("channel")); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(204, gin.H{})
+}
+
+// PreviewWelcomeMessage godoc
+// @Summary Preview a welcome message
+// @Description Renders the welcome message for a newcomer without storing it. Returns the slack message
+// @Description that would be sent, with a link displaying its blocks in the Block Kit Builder.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Welcome
+// @ID preview-welcome-message
+// @Produce  json
+// @Param user query string false "ID of the newcomer, slackbot when empty"
+// @Param welcome body object true "Welcome message, as set"
+// @Router /admin/welcome/preview [post]
+func (a Analyser) PreviewWelcomeMessage(c *gin.Context) {
+	var welcome globals.WelcomeMessage
+	if err := c.BindJSON(&welcome); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	user := c.DefaultQuery("user", previewUser)
+	settings := channelSettings(welcome.Channel)
+	reply, err := a.welcomeReply(welcome, settings.Locale, user, settings.ID)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	builder, err := json.Marshal(gin.H{"blocks": welcomeBlocks(reply.Text, reply.Blocks)})
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{
+		"message":           reply,
+		"block_kit_builder": blockKitBuilderURL + url.PathEscape(string(builder)),
+	})
+}
+
+// GetWelcomeAcknowledgements godoc
+// @Summary Get the acknowledgements of the rules
+// @Description Returns the users who acknowledged the rules of the welcome message, with the date they did.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Welcome
+// @ID get-welcome-acknowledgements
+// @Produce  json
+// @Param channel query string false "ID of the channel, the acknowledgements of every channel when empty"
+// @Router /admin/welcome/acknowledgements [get]
+func (a Analyser) GetWelcomeAcknowledgements(c *gin.Context) {
+	acknowledgements, err := a.ESClient.GetWelcomeAcknowledgements(c.Query("channel"))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if acknowledgements == nil {
+		acknowledgements = []globals.WelcomeAcknowledgement{}
+	}
+	c.JSON(200, acknowledgements)
+}
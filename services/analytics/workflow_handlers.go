@@ -0,0 +1,143 @@
+package analytics
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/globals"
+)
+
+// GetWorkflow godoc
+// @Summary Get the status workflow
+// @Description Returns the status rules, mapping an emoji to the status it sets.
+// @Description The default workflow is returned when no rule is configured.
+// @Description No authentication required
+// @Tags Workflow
+// @ID get-workflow
+// @Produce  json
+// @Param channel query string false "ID of the channel, all rules when empty"
+// @Router /workflow [get]
+func (a Analyser) GetWorkflow(c *gin.Context) {
+	workflow, err := a.ESClient.GetWorkflow(c.Query("channel"))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, workflow)
+}
+
+// AddStatusRule godoc
+// @Summary Create new status rule
+// @Description Stores a new status rule into the database with the given information.
+// @Description An emoji can only be used once per channel.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Workflow
+// @ID add-status-rule
+// @Produce  json
+// @Param emoji body string true "Name of the emoji, without colons"
+// @Param status body string true "Status set by the emoji"
+// @Param closes body boolean false "Whether the status closes the message"
+// @Param channel body string false "ID of the channel of this rule, shared by every channel when empty"
+// @Router /workflow/new [post]
+func (a Analyser) AddStatusRule(c *gin.Context) {
+	var rule globals.StatusRule
+	if err := c.BindJSON(&rule); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if status, err := a.checkStatusRule("", rule); err != nil {
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.ESClient.AddStatusRule(rule); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(201, gin.H{})
+}
+
+// EditStatusRule godoc
+// @Summary Edit specified status rule
+// @Description Modify the data stored in the database
+// @Description for the document having the given rule ID.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Workflow
+// @ID edit-status-rule
+// @Produce  json
+// @Param rule query string true "Status rule id to update"
+// @Param emoji body string true "Name of the emoji, without colons"
+// @Param status body string true "Status set by the emoji"
+// @Param closes body boolean false "Whether the status closes the message"
+// @Param channel body string false "ID of the channel of this rule, shared by every channel when empty"
+// @Router /workflow/:rule [put]
+func (a Analyser) EditStatusRule(c *gin.Context) {
+	id := c.Param("rule")
+	var rule globals.StatusRule
+	if err := c.BindJSON(&rule); err != nil {
+		c.JSON(400, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if status, err := a.checkStatusRule(id, rule); err != nil {
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := a.ESClient.EditStatusRule(id, rule); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{})
+}
+
+// DeleteStatusRule godoc
+// @Summary Delete specified status rule
+// @Description Deletes the entry matching the ID.
+// @Description Authentication and admin access are required for this endpoint
+// @Tags Workflow
+// @ID delete-status-rule
+// @Produce  json
+// @Param rule query string true "Status rule id to delete"
+// @Router /workflow/:rule [delete]
+func (a Analyser) DeleteStatusRule(c *gin.Context) {
+	if err := a.ESClient.DeleteStatusRule(c.Param("rule")); err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(204, gin.H{})
+}
+
+// checkStatusRule validates the rule and makes sure that its emoji is not used by another rule of the channel.
+// It returns the http status to answer with when the rule is invalid
+func (a Analyser) checkStatusRule(id string, rule globals.StatusRule) (int, error) {
+	if rule.Emoji == "" || rule.Status == "" {
+		return 400, fmt.Errorf("emoji and status are required")
+	}
+	if rule.Status == globals.StatusDeleted {
+		return 400, fmt.Errorf("status %s is reserved to deleted messages", rule.Status)
+	}
+	workflow, err := a.ESClient.GetWorkflow(rule.Channel)
+	if err != nil {
+		return 500, err
+	}
+	for _, r := range workflow {
+		if r.ID != "" && r.ID != id && r.Emoji == rule.Emoji && r.Channel == rule.Channel {
+			return 409, fmt.Errorf("emoji %s is already used by status %s", rule.Emoji, r.Status)
+		}
+	}
+	return 200, nil
+}
@@ -29,6 +29,17 @@ import (
 // @schemes https
 // @BasePath /v1
 func runAPI(instance *Handler) {
+	r := NewRouter(instance)
+	err := r.Run() // listen and serve on 0.0.0.0:8080
+	if err != nil {
+		log.Fatalf("Could not serve server : %s", err)
+	}
+}
+
+// NewRouter returns the router serving the replier endpoints.
+// The endpoints called by slack are only served to signed requests,
+// and are not served at all in socket mode
+func NewRouter(instance *Handler) *gin.Engine {
 	r := gin.Default()
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -41,16 +52,42 @@ func runAPI(instance *Handler) {
 
 	// Slack webhook endpoint
 	r.GET("/catchup", func(c *gin.Context) {
+		channel := c.Query("channel")
 		start := c.DefaultQuery("start", "2019-01-01")
 		end := c.DefaultQuery("end", time.Now().Format(globals.DateLayout))
-		go instance.CatchUp(start, end)
+		go instance.CatchUp(channel, start, end)
 		c.JSON(200, gin.H{
 			"status": "ok",
 		})
 	})
 
+	r.GET("/report", func(c *gin.Context) {
+		instance.SendWeeklyReport()
+		c.JSON(200, gin.H{
+			"report": "okay",
+		})
+	})
+
+	r.GET("/remind", func(c *gin.Context) {
+		instance.SendReminders()
+		c.JSON(200, gin.H{
+			"remind": "okay",
+		})
+	})
+
+	// Failed events endpoints, they expose the payloads of slack and are only served to the admins
+	admin := r.Group("/queue", instance.verifyAdminToken)
+	admin.GET("/failed", instance.GetFailedEvents)
+	admin.POST("/failed/:id/replay", instance.ReplayFailedEvent)
+
+	if instance.SocketMode {
+		return r
+	}
+
+	signed := r.Group("/", instance.verifySignature)
+
 	// Slack webhook endpoint
-	r.POST("/event", func(c *gin.Context) {
+	signed.POST("/event", func(c *gin.Context) {
 		var eventRequest slack.EventRequest
 		log.WithFields(log.Fields{"event": eventRequest.Event, "type": eventRequest.Type}).Debug("Event received")
 		if err := c.BindJSON(&eventRequest); err == nil {
@@ -60,7 +97,14 @@ func runAPI(instance *Handler) {
 					"challenge": eventRequest.Challenge,
 				})
 			} else {
-				go instance.HandleNewEvent(eventRequest)
+				if retry := c.GetHeader("X-Slack-Retry-Num"); retry != "" {
+					log.WithFields(log.Fields{
+						"event_id": eventRequest.EventID,
+						"retry":    retry,
+						"reason":   c.GetHeader("X-Slack-Retry-Reason"),
+					}).Debug("Event sent again by slack")
+				}
+				instance.EnqueueEvent(eventRequest)
 				c.JSON(200, gin.H{
 					"status": "ok",
 				})
@@ -74,13 +118,18 @@ func runAPI(instance *Handler) {
 	})
 
 	// Slack interactivity endpoint
-	r.POST("/interactivity", func(c *gin.Context) {
+	signed.POST("/interactivity", func(c *gin.Context) {
 		var interactivityRequest slack.InteractivityRequest
 		log.Debug("Interactivity event received")
 		payload := c.PostForm("payload")
 
 		if err := json.Unmarshal([]byte(payload), &interactivityRequest); err == nil {
-			go instance.HandleNewInteraction(interactivityRequest)
+			instance.EnqueueInteraction(interactivityRequest)
+			if interactivityRequest.Type == "view_submission" {
+				// an empty response closes the modal
+				c.Status(200)
+				return
+			}
 			c.JSON(200, gin.H{
 				"status": "ok",
 			})
@@ -93,7 +142,7 @@ func runAPI(instance *Handler) {
 	})
 
 	// [POC] slack command endpoint to manage incidents
-	r.POST("/commands/incident", func(c *gin.Context) {
+	signed.POST("/commands/incident", func(c *gin.Context) {
 		var commandRequest slack.CommandRequest
 		if err := c.Bind(&commandRequest); err != nil {
 			log.Error("error parsing command", err)
@@ -115,7 +164,7 @@ func runAPI(instance *Handler) {
 	})
 
 	// [POC] slack command endpoint to manage repositories
-	r.POST("/commands/repo", func(c *gin.Context) {
+	signed.POST("/commands/repo", func(c *gin.Context) {
 		var commandRequest slack.CommandRequest
 		if err := c.BindJSON(&commandRequest); err != nil {
 			log.Error("error parsing command", err)
@@ -134,22 +183,5 @@ func runAPI(instance *Handler) {
 		}
 	})
 
-	r.GET("/report", func(c *gin.Context) {
-		instance.SendWeeklyReport()
-		c.JSON(200, gin.H{
-			"report": "okay",
-		})
-	})
-
-	r.GET("/remind", func(c *gin.Context) {
-		instance.SendReminders()
-		c.JSON(200, gin.H{
-			"remind": "okay",
-		})
-	})
-
-	err := r.Run() // listen and serve on 0.0.0.0:8080
-	if err != nil {
-		log.Fatalf("Could not serve server : %s", err)
-	}
+	return r
 }
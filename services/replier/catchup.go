@@ -12,17 +12,25 @@ import (
 
 // CatchUp godoc
 // @Summary Backport messages for the given period
-// @Description Curls the slack to retrieve all the messages
+// @Description Curls the slack to retrieve all the messages of the channel
+// @Description (or of every watched channel when empty)
 // @Description for the period (by batch of 10 messages)
 // @Description For every batch, it then calls CatchUpBatch to
 // @Description analyse the batch of messages
 // @ID catchup
 // @Produce  json
+// @Param channel query string false "ID of the channel to backport"
 // @Param start query string true "Start of the"
 // @Param end query string true "End of the period"
 // @Router /catchup [get]
-func (h Handler) CatchUp(start string, end string) {
-	log.Debugf("Catching up messages from %s to %s", start, end)
+func (h Handler) CatchUp(channel string, start string, end string) {
+	if channel == "" {
+		for _, c := range h.Slack.GetChannels() {
+			h.CatchUp(c.ID, start, end)
+		}
+		return
+	}
+	log.Debugf("Catching up messages of %s from %s to %s", channel, start, end)
 	cursor := ""
 	hasMore := true
 	for hasMore {
@@ -34,11 +42,11 @@ func (h Handler) CatchUp(start string, end string) {
 		if err != nil {
 			return
 		}
-		slackResponse, err := h.Slack.ReadMessages(s, e, cursor, 10)
+		slackResponse, err := h.Slack.ReadMessages(channel, s, e, cursor, 10)
 		if err != nil {
 			return
 		}
-		go h.CatchUpBatch(slackResponse.Messages)
+		go h.CatchUpBatch(channel, slackResponse.Messages)
 
 		hasMore = slackResponse.HasMore
 		cursor = slackResponse.Metadata.NextCursor
@@ -46,12 +54,14 @@ func (h Handler) CatchUp(start string, end string) {
 	return
 }
 
-// CatchUpBatch runs the data consolidation and message analysis + storage for a batch of events
-func (h Handler) CatchUpBatch(events []slack.Event) {
+// CatchUpBatch runs the data consolidation and message analysis + storage for a batch of events of the channel
+func (h Handler) CatchUpBatch(channel string, events []slack.Event) {
 	time.Sleep(time.Duration(1) * time.Minute)
 	var messages []globals.Message
 
 	for _, event := range events {
+		// conversations.history does not repeat the channel in each message
+		event.Channel = channel
 		message := h.Slack.GetMessage(event)
 		messages = append(messages, message)
 	}
@@ -0,0 +1,42 @@
+package replier
+
+import (
+	"sync"
+	"time"
+)
+
+// EventCache remembers the IDs of the events received recently,
+// so that the events slack sends again are handled only once
+type EventCache struct {
+	TTL time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewEventCache returns a cache keeping the event IDs for the given duration
+func NewEventCache(ttl time.Duration) *EventCache {
+	return &EventCache{TTL: ttl, seen: map[string]time.Time{}}
+}
+
+// Seen records the event ID and returns true if it was already recorded.
+// Events without ID are never considered as seen
+func (c *EventCache) Seen(id string) bool {
+	if c == nil || id == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, at := range c.seen {
+		if now.Sub(at) > c.TTL {
+			delete(c.seen, key)
+		}
+	}
+	if _, ok := c.seen[id]; ok {
+		return true
+	}
+	c.seen[id] = now
+	return false
+}
@@ -2,6 +2,8 @@ package replier
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/leboncoin/subot/pkg/globals"
@@ -17,10 +19,10 @@ import (
 //// @Produce  json
 //// @Param request query object true "The original slack request"
 //// @Router /event [post]
-func (h Handler) HandleNewEvent(request slack.EventRequest) {
+func (h Handler) HandleNewEvent(request slack.EventRequest) error {
 	if !h.isAuthorizedEvent(request) {
 		log.Debug("Not authorized request")
-		return
+		return nil
 	}
 
 	event := h.Slack.GetEvent(request.Event)
@@ -28,49 +30,90 @@ func (h Handler) HandleNewEvent(request slack.EventRequest) {
 	res, err := h.callAnalyticsAPI("POST", string(event.GetType()), bytes.NewReader(jsonBody))
 	if err != nil {
 		log.Errorf("Error while fetching analytics api for %s endpoint: %s", string(event.GetType()), err)
+		return err
 	}
 	log.WithFields(log.Fields{"res": res}).Debugf("Got results from analytics api %s endpoint", string(event.GetType()))
 	for _, reply := range res {
 		h.executeSlackAction(reply)
 	}
-	return
+	return nil
 }
 
 // HandleNewInteraction godoc
 // @Summary Pass the interaction to the analytics api
+// @Description The buttons and message shortcuts changing the state of a thread
+// @Description are passed to the thread_action endpoint, the acknowledgements of the rules of a channel
+// @Description to the welcome_ack endpoint, the buttons of the resolution notes to the resolution_note endpoint
+// @Description and the submitted modals to the view_submission endpoint, the other actions to the feedback endpoint
 // @ID handle-new-interaction
 // @Produce  json
 // @Param request query object true "The original slack request"
 // @Router /interactivity [post]
-func (h Handler) HandleNewInteraction(request slack.InteractivityRequest) {
+func (h Handler) HandleNewInteraction(request slack.InteractivityRequest) error {
 	log.WithFields(log.Fields{"request": request}).Debug("Handle new interaction")
 
-	// if !h.isAuthorizedEvent(request) {
-	// 	log.Debug("Not authorized request")
-	// 	return
-	// }
+	payload := globals.Interaction{
+		MessageTs:    request.Message.Timestamp,
+		ActionUserID: request.User.ID,
+		ThreadTs:     request.Message.ThreadTs,
+		Channel:      request.Channel.ID,
+		ResponseURL:  request.ResponseURL,
+		TriggerID:    request.TriggerID,
+	}
+	if request.Type == "view_submission" {
+		// the private metadata of the modals is the interaction they were opened from
+		if err := json.Unmarshal([]byte(request.View.PrivateMetadata), &payload); err != nil {
+			return fmt.Errorf("invalid private metadata of the modal %s : %s", request.View.CallbackID, err)
+		}
+		payload.ActionUserID = request.User.ID
+		payload.ActionValue = request.View.CallbackID
+		payload.Values = request.View.State.Inputs()
+		return h.callInteractionEndpoint("view_submission", payload)
+	}
+	if request.Type == "message_action" {
+		payload.ActionTs = request.ActionTs
+		payload.ActionValue = request.CallbackID
+		return h.callInteractionEndpoint("thread_action", payload)
+	}
+
 	for _, action := range request.Actions {
 		endpoint := "feedback"
-		payload := globals.Interaction{
-			MessageTs:    request.Message.Timestamp,
-			ActionTs:     action.ActionTs,
-			ActionUserID: request.User.ID,
-			ActionValue:  action.Value,
-			ThreadTs:     request.Message.ThreadTs,
-			ResponseURL:  request.ResponseURL,
+		payload.ActionTs = action.ActionTs
+		payload.ActionValue = action.Value
+		if globals.IsThreadAction(action.ActionID) {
+			endpoint = "thread_action"
+			payload.ActionValue = action.ActionID
 		}
-		log.WithFields(log.Fields{"payload": payload}).Debug("Payload for analytics")
-		jsonBody := payload.JSONData()
-		res, err := h.callAnalyticsAPI("POST", endpoint, bytes.NewReader(jsonBody))
-		if err != nil {
-			log.Error("Error while fetching analytics interaction endpoint: ", err)
+		if action.ActionID == globals.WelcomeAckAction {
+			endpoint = "welcome_ack"
+		}
+		if action.ActionID == globals.ResolutionNoteAction {
+			endpoint = "resolution_note"
 		}
-		log.WithFields(log.Fields{"res": res}).Debug("Got results from analytics interaction endpoint")
-		for _, reply := range res {
-			h.executeSlackAction(reply)
+		if action.ActionID == globals.SimilarFeedbackAction {
+			endpoint = "similar_feedback"
 		}
+		if err := h.callInteractionEndpoint(endpoint, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callInteractionEndpoint passes the interaction to the analytics endpoint and executes the returned actions
+func (h Handler) callInteractionEndpoint(endpoint string, payload globals.Interaction) error {
+	log.WithFields(log.Fields{"payload": payload}).Debug("Payload for analytics")
+	jsonBody := payload.JSONData()
+	res, err := h.callAnalyticsAPI("POST", endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		log.Error("Error while fetching analytics interaction endpoint: ", err)
+		return err
+	}
+	log.WithFields(log.Fields{"res": res}).Debug("Got results from analytics interaction endpoint")
+	for _, reply := range res {
+		h.executeSlackAction(reply)
 	}
-	return
+	return nil
 }
 
 // executeSlackAction executes the action described in given response
@@ -78,7 +121,7 @@ func (h Handler) executeSlackAction(response globals.SlackResponse) {
 	log.WithFields(log.Fields{"res": response}).Debug("Reply to message if necessary")
 	if response.Action == globals.ChannelMessage {
 		log.WithFields(log.Fields{"res": response}).Debug("Sending channel message")
-		err := h.Slack.SendMessage(response.Text, response.Blocks)
+		err := h.Slack.SendMessage(response.ChanID, response.Text, response.Blocks)
 		if err != nil {
 			log.Error("Error while sending message to channel: ", err)
 		}
@@ -86,7 +129,7 @@ func (h Handler) executeSlackAction(response globals.SlackResponse) {
 	}
 
 	if response.Action == globals.ReplyMessage {
-		err := h.Slack.ReplyToMessage(response.Ts, response.Text, response.Blocks)
+		err := h.Slack.ReplyToMessage(response.ChanID, response.Ts, response.Text, response.Blocks)
 		if err != nil {
 			log.Error("Error while sending message to thread: ", err)
 		}
@@ -94,7 +137,7 @@ func (h Handler) executeSlackAction(response globals.SlackResponse) {
 	}
 
 	if response.Action == globals.Ephemeral {
-		err := h.Slack.SendEphemeralMessage(response.UserID, response.Text)
+		err := h.Slack.SendEphemeralMessage(response.ChanID, response.UserID, response.Text, response.Blocks)
 		if err != nil {
 			log.Error("Error while sending message: ", err)
 		}
@@ -103,7 +146,7 @@ func (h Handler) executeSlackAction(response globals.SlackResponse) {
 
 	if response.Action == globals.DeleteMessage {
 		log.WithFields(log.Fields{"res": response}).Debug("Delete message reply")
-		err := h.Slack.DeleteResponseToMessage(response.Ts)
+		err := h.Slack.DeleteResponseToMessage(response.ChanID, response.Ts)
 		if err != nil {
 			log.Error("Error while sending message to channel: ", err)
 		}
@@ -111,7 +154,7 @@ func (h Handler) executeSlackAction(response globals.SlackResponse) {
 	}
 
 	if response.Action == globals.UpdateBlockKit {
-		err := h.Slack.PostResponseURLPayload(response.ResponseURL, response.Text)
+		err := h.Slack.PostResponseURLPayload(response.ResponseURL, response.Text, response.Blocks)
 		if err != nil {
 			log.Error("Error while updating block kit: ", err)
 		}
@@ -120,12 +163,53 @@ func (h Handler) executeSlackAction(response globals.SlackResponse) {
 
 	if response.Action == globals.React {
 		log.WithFields(log.Fields{"res": response}).Debug("Add reaction to message")
-		err := h.Slack.AddReaction(response.Ts, response.Text)
+		err := h.Slack.AddReaction(response.ChanID, response.Ts, response.Text)
 		if err != nil {
 			log.Error("Error while adding reaction to message: ", err)
 		}
 		return
 	}
+
+	if response.Action == globals.OpenModal {
+		log.WithFields(log.Fields{"res": response}).Debug("Open modal")
+		err := h.Slack.OpenView(response.TriggerID, response.View)
+		if err != nil {
+			log.Error("Error while opening modal: ", err)
+		}
+		return
+	}
+
+	if response.Action == globals.SetTopic {
+		log.WithFields(log.Fields{"res": response}).Debug("Set channel topic")
+		err := h.Slack.SetTopic(response.ChanID, response.Text)
+		if err != nil {
+			log.Error("Error while setting channel topic: ", err)
+		}
+		return
+	}
+}
+
+// HandleNewCommand calls the handler matching the slash command received over socket mode
+func (h Handler) HandleNewCommand(request slack.CommandRequest) {
+	switch request.Command {
+	case "/incident":
+		h.HandleNewIncident(request)
+	case "/repo":
+		h.HandleNewRepo(request)
+	default:
+		log.WithFields(log.Fields{"command": request.Command}).Debug("Unknown command")
+	}
+}
+
+// SocketModeClient returns the socket mode client dispatching slack requests to the handler
+func (h Handler) SocketModeClient(apiURL string, appToken string) slack.SocketMode {
+	return slack.SocketMode{
+		APIURL:        apiURL,
+		AppToken:      appToken,
+		OnEvent:       h.EnqueueEvent,
+		OnInteraction: h.EnqueueInteraction,
+		OnCommand:     h.HandleNewCommand,
+	}
 }
 
 // HandleNewIncident is not implemented yet
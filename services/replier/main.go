@@ -1,6 +1,8 @@
 package replier
 
 import (
+	"context"
+
 	"github.com/spf13/viper"
 	_ "github.com/spf13/viper/remote" // blank import for remote
 	"github.com/leboncoin/subot/pkg/config"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/robfig/cron/v3"
 	log "github.com/sirupsen/logrus"
+	"github.com/leboncoin/subot/pkg/queue"
 	"github.com/leboncoin/subot/pkg/slack"
 	"github.com/leboncoin/subot/pkg/vault"
 )
@@ -28,25 +31,71 @@ func Run() {
 	}
 
 	// Init slack client
+	var channels []slack.Chan
+	for _, channel := range config.Channels() {
+		channels = append(channels, slack.Chan{
+			ID:      channel.ID,
+			Webhook: channel.Webhook,
+		})
+	}
 	s := slack.Slack{
-		Host:     "slack.com",
-		Channel:  slack.Chan{
-			ID:      viper.GetString("slack_id"),
-			Webhook: viper.GetString("slack_webhook"),
-		},
-		Token:    viper.GetString("slack_oauth_access_token"),
-		BotToken: viper.GetString("slack_bot_user_oauth_access_token"),
-		BotID:    viper.GetString("slack_bot_id"),
+		Host:          "slack.com",
+		Channels:      channels,
+		Token:         viper.GetString("slack_oauth_access_token"),
+		BotToken:      viper.GetString("slack_bot_user_oauth_access_token"),
+		BotID:         viper.GetString("slack_bot_id"),
+		SigningSecret: viper.GetString("slack_signing_secret"),
+	}
+	if s.SigningSecret == "" && viper.GetString("slack_mode") != "socket" {
+		log.Warn("slack_signing_secret is not set, every slack request will be rejected")
 	}
 
 	// Init replier
-	replier := &Handler{Slack: &s, ApiUrl: viper.GetString("analytics_url")}
+	replier := &Handler{
+		Slack:      &s,
+		ApiUrl:     viper.GetString("analytics_url"),
+		SocketMode: viper.GetString("slack_mode") == "socket",
+		// slack stops sending an event again after about one hour
+		Seen:       NewEventCache(2 * time.Hour),
+		AdminToken: viper.GetString("replier_admin_token"),
+	}
+	if replier.AdminToken == "" {
+		log.Warn("replier_admin_token is not set, the failed events endpoints will reject every request")
+	}
+
+	// Init durable queue, only when a directory is given for it
+	viper.SetDefault("queue_max_attempts", 10)
+	viper.SetDefault("queue_retry_delay", "2s")
+	viper.SetDefault("queue_max_retry_delay", "10m")
+	if path := viper.GetString("queue_path"); path != "" {
+		q, err := queue.New(path, viper.GetInt("queue_max_attempts"), viper.GetDuration("queue_retry_delay"), viper.GetDuration("queue_max_retry_delay"))
+		if err != nil {
+			log.Fatal("Could not initialize queue: ", err)
+		}
+		replier.Queue = q
+		go q.Run(context.Background(), time.Second, replier.ProcessQueueItem)
+	} else {
+		log.Warn("queue_path is not set, the slack events are handled right away and lost when they fail")
+	}
 
 	runReportCron(replier)
 	runReminderCron(replier)
+	runRotationCron(replier)
+	if replier.SocketMode {
+		go runSocketMode(replier)
+	}
 	runAPI(replier)
 }
 
+func runSocketMode(instance *Handler) {
+	appToken := viper.GetString("slack_app_token")
+	if appToken == "" {
+		log.Fatal("slack_app_token is required in socket mode")
+	}
+	socket := instance.SocketModeClient(viper.GetString("slack_socket_mode_url"), appToken)
+	socket.Run(context.Background())
+}
+
 func runReportCron(instance *Handler) {
 	paris, _ := time.LoadLocation("Europe/Paris")
 	c := cron.New(
@@ -71,3 +120,17 @@ func runReminderCron(instance *Handler) {
 
 	c.Start()
 }
+
+// runRotationCron checks the rotations for a new fireman, every morning unless rotation_cron is set
+func runRotationCron(instance *Handler) {
+	viper.SetDefault("rotation_cron", "0 9 * * *")
+	paris, _ := time.LoadLocation("Europe/Paris")
+	c := cron.New(
+		cron.WithLocation(paris),
+	)
+
+	if _, err := c.AddFunc(viper.GetString("rotation_cron"), instance.SendRotationHandover); err != nil {
+		log.Fatal("could not start cron job: ", err)
+	}
+	c.Start()
+}
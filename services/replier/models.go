@@ -1,9 +1,20 @@
 package replier
 
-import "github.com/leboncoin/subot/pkg/slack"
+import (
+	"github.com/leboncoin/subot/pkg/queue"
+	"github.com/leboncoin/subot/pkg/slack"
+)
 
 // Handler is the main app struct
 type Handler struct {
 	Slack  slack.Interface `json:"slack"`
 	ApiUrl string          `json:"api_url"`
+	// SocketMode is true when slack events are received over socket mode instead of webhooks
+	SocketMode bool `json:"socket_mode"`
+	// Queue stores the slack requests until they are handled, they are handled right away when nil
+	Queue *queue.Queue `json:"-"`
+	// Seen holds the IDs of the events received recently
+	Seen *EventCache `json:"-"`
+	// AdminToken is the shared secret of the failed events endpoints, which are refused to everyone when empty
+	AdminToken string `json:"-"`
 }
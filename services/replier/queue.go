@@ -0,0 +1,153 @@
+package replier
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/queue"
+	"github.com/leboncoin/subot/pkg/slack"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	eventKind       = "event"
+	interactionKind = "interaction"
+)
+
+// EnqueueEvent stores the event in the durable queue, it is then handled by the queue worker.
+// The event is handled right away when the queue is disabled.
+// Events already received, which slack sends again when it did not get our answer in time, are ignored
+func (h Handler) EnqueueEvent(request slack.EventRequest) {
+	if h.Seen.Seen(request.EventID) || (h.Queue != nil && request.EventID != "" && h.Queue.Has(request.EventID)) {
+		log.WithFields(log.Fields{"event_id": request.EventID}).Debug("Event already received")
+		return
+	}
+	if h.Queue != nil {
+		_, err := h.Queue.Push(eventKind, request.EventID, request)
+		if err == nil {
+			return
+		}
+		log.Errorf("Could not queue event, handling it right away: %s", err)
+	}
+	go func() {
+		_ = h.HandleNewEvent(request)
+	}()
+}
+
+// EnqueueInteraction stores the interaction in the durable queue, it is then handled by the queue worker.
+// Each action of the interaction is queued on its own, so that only the failed ones are retried.
+// The interaction is handled right away when the queue is disabled
+func (h Handler) EnqueueInteraction(request slack.InteractivityRequest) {
+	if h.Queue != nil {
+		requests := singleActions(request)
+		for i, single := range requests {
+			if _, err := h.Queue.Push(interactionKind, "", single); err != nil {
+				log.Errorf("Could not queue interaction, handling it right away: %s", err)
+				go func(requests []slack.InteractivityRequest) {
+					for _, request := range requests {
+						_ = h.HandleNewInteraction(request)
+					}
+				}(requests[i:])
+				return
+			}
+		}
+		return
+	}
+	go func() {
+		_ = h.HandleNewInteraction(request)
+	}()
+}
+
+// singleActions splits the interaction into one interaction for each of its actions
+func singleActions(request slack.InteractivityRequest) []slack.InteractivityRequest {
+	if len(request.Actions) <= 1 {
+		return []slack.InteractivityRequest{request}
+	}
+	requests := make([]slack.InteractivityRequest, 0, len(request.Actions))
+	for _, action := range request.Actions {
+		single := request
+		single.Actions = []slack.InteractivityAction{action}
+		requests = append(requests, single)
+	}
+	return requests
+}
+
+// ProcessQueueItem handles an event or an interaction read from the queue.
+// An error is returned when the item has to be retried, a permanent one when it never can be handled
+func (h Handler) ProcessQueueItem(item queue.Item) error {
+	switch item.Kind {
+	case eventKind:
+		var request slack.EventRequest
+		if err := json.Unmarshal(item.Payload, &request); err != nil {
+			return queue.Permanent(err)
+		}
+		return h.HandleNewEvent(request)
+	case interactionKind:
+		var request slack.InteractivityRequest
+		if err := json.Unmarshal(item.Payload, &request); err != nil {
+			return queue.Permanent(err)
+		}
+		return h.HandleNewInteraction(request)
+	default:
+		return queue.Permanent(fmt.Errorf("unknown queue item kind %s", item.Kind))
+	}
+}
+
+// GetFailedEvents godoc
+// @Summary List the failed events
+// @Description Returns the slack events and interactions which could not be
+// @Description handled after every retry, along with their last error.
+// @Description The replier admin token is required as a bearer token
+// @ID get-failed-events
+// @Produce  json
+// @Router /queue/failed [get]
+func (h Handler) GetFailedEvents(c *gin.Context) {
+	if h.Queue == nil {
+		c.JSON(404, gin.H{
+			"error": "queue is disabled",
+		})
+		return
+	}
+	items, err := h.Queue.Dead()
+	if err != nil {
+		c.JSON(500, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if items == nil {
+		items = []queue.Item{}
+	}
+	c.JSON(200, items)
+}
+
+// ReplayFailedEvent godoc
+// @Summary Replay a failed event
+// @Description Puts the failed event back in the queue, its attempts are reset.
+// @Description The replier admin token is required as a bearer token
+// @ID replay-failed-event
+// @Produce  json
+// @Param id path string true "ID of the failed event"
+// @Router /queue/failed/{id}/replay [post]
+func (h Handler) ReplayFailedEvent(c *gin.Context) {
+	if h.Queue == nil {
+		c.JSON(404, gin.H{
+			"error": "queue is disabled",
+		})
+		return
+	}
+	if err := h.Queue.Replay(c.Param("id")); err != nil {
+		status := 500
+		if err == queue.ErrNotFound {
+			status = 404
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{
+		"status": "ok",
+	})
+}
@@ -8,25 +8,31 @@ import (
 	"github.com/leboncoin/subot/pkg/globals"
 )
 
-// SendWeeklyReport builds the report and sends it
+// SendWeeklyReport builds the report of every watched channel and sends it
 func (h Handler) SendWeeklyReport() {
 	today := time.Now()
 	startOfWeek := today.AddDate(0, 0, -int(today.Weekday())+1).Format(globals.DateLayout)
 	endOfWeek := today.AddDate(0, 0, 1).Format(globals.DateLayout)
-	statsResponse, err := h.getStats(startOfWeek, endOfWeek)
-	if err != nil {
-		log.Fatal(err)
-		return
+	for _, channel := range h.Slack.GetChannels() {
+		statsResponse, err := h.getStats(channel.ID, startOfWeek, endOfWeek)
+		if err != nil {
+			log.WithFields(log.Fields{"channel": channel.ID, "error": err}).Error("Could not build weekly report")
+			continue
+		}
+		h.executeSlackAction(statsResponse)
 	}
-	h.executeSlackAction(statsResponse)
 	return
 }
 
-func (h Handler) getStats(start string, end string) (response globals.SlackResponse, err error) {
-	responses, err := h.callAnalyticsAPI("GET", fmt.Sprintf("report?start=%s&end=%s", start, end), nil)
+func (h Handler) getStats(channel string, start string, end string) (response globals.SlackResponse, err error) {
+	responses, err := h.callAnalyticsAPI("GET", fmt.Sprintf("report?channel=%s&start=%s&end=%s", channel, start, end), nil)
 	if err != nil {
 		log.Error("Error while fetching analytics api for report endpoint: ", err)
+		return
 	}
 	log.WithFields(log.Fields{"res": response}).Debug("Got results from analytics api report endpoint")
+	if len(responses) == 0 {
+		return response, fmt.Errorf("no report returned for channel %s", channel)
+	}
 	return responses[0], err
 }
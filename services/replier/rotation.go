@@ -0,0 +1,19 @@
+package replier
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// SendRotationHandover announces the new firemen of the rotations and sets the topic of their channel
+func (h Handler) SendRotationHandover() {
+	log.Debug("Looking for fireman handovers")
+	handovers, err := h.callAnalyticsAPI("GET", "rotation", nil)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("Error occurred while calling rotation endpoint of the analytics service")
+		return
+	}
+	log.WithFields(log.Fields{"handovers": handovers}).Debug("Got fireman handovers to send")
+	for _, handover := range handovers {
+		h.executeSlackAction(handover)
+	}
+}
@@ -30,7 +30,7 @@ func (m deletedMockedSender) GetEvent(e slack.Event) globals.Event {
 	return m.GetMessage(e)
 }
 
-func (m deletedMockedSender) DeleteResponseToMessage(_ string) error {
+func (m deletedMockedSender) DeleteResponseToMessage(_ string, _ string) error {
 	return nil
 }
 
@@ -55,8 +55,8 @@ func (m deletedMockedSender) GetMessage(_ slack.Event) globals.Message {
 	}
 }
 
-func (m deletedMockedSender) IsValidToken(_ slack.EventRequest) bool {
-	return true
+func (m deletedMockedSender) VerifySignature(timestamp string, signature string, body []byte) error {
+	return slack.Slack{SigningSecret: signingSecret}.VerifySignature(timestamp, signature, body)
 }
 
 func (m deletedMockedSender) IsWatchedChannel(_ slack.Event) bool {
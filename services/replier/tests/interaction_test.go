@@ -0,0 +1,128 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/slack"
+	"github.com/leboncoin/subot/services/replier"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In", func() {
+	Describe("Test routing of the interactions", func() {
+		var mockAnalyticsServer *httptest.Server
+		var paths []string
+		var interactions []globals.Interaction
+		var h replier.Handler
+
+		BeforeEach(func() {
+			paths = nil
+			interactions = nil
+			mockAnalyticsServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				var interaction globals.Interaction
+				Expect(json.NewDecoder(req.Body).Decode(&interaction)).To(Succeed())
+				paths = append(paths, req.URL.Path)
+				interactions = append(interactions, interaction)
+				_, _ = res.Write([]byte("[]"))
+			}))
+			h = replier.Handler{Slack: newMessageMockedSender{}, ApiUrl: mockAnalyticsServer.URL}
+		})
+
+		AfterEach(func() {
+			mockAnalyticsServer.Close()
+		})
+
+		It("Should pass the feedback and the thread buttons to their endpoint", func() {
+			request := slack.InteractivityRequest{
+				Type:    "block_actions",
+				User:    globals.User{ID: "UALICE"},
+				Channel: slack.InteractivityChannel{ID: "CLK7MCUS3"},
+				Message: globals.Reply{Timestamp: "1600000100.000200", ThreadTs: "1600000000.000100"},
+				Actions: []slack.InteractivityAction{
+					{ActionID: "Ks2b", Value: string(globals.UsefulFeedback), ActionTs: "1600000200.000300"},
+					{ActionID: globals.SnoozeAction, Value: "1600000000.000100", ActionTs: "1600000200.000300"},
+				},
+			}
+			Expect(h.HandleNewInteraction(request)).To(Succeed())
+			Expect(paths).To(Equal([]string{"/v1/analytics/feedback", "/v1/analytics/thread_action"}))
+			Expect(interactions[0].ActionValue).To(Equal(string(globals.UsefulFeedback)))
+			Expect(interactions[1].ActionValue).To(Equal(globals.SnoozeAction))
+			Expect(interactions[1].ThreadTs).To(Equal("1600000000.000100"))
+			Expect(interactions[1].ActionUserID).To(Equal("UALICE"))
+		})
+
+		It("Should pass the feedback on the similar threads to their own endpoint", func() {
+			request := slack.InteractivityRequest{
+				Type:    "block_actions",
+				User:    globals.User{ID: "UUSER"},
+				Channel: slack.InteractivityChannel{ID: "CLK7MCUS3"},
+				Message: globals.Reply{Timestamp: "1600000100.000200", ThreadTs: "1600000000.000100"},
+				Actions: []slack.InteractivityAction{
+					{ActionID: globals.SimilarFeedbackAction, Value: string(globals.UselessFeedback), ActionTs: "1600000200.000300"},
+				},
+			}
+			Expect(h.HandleNewInteraction(request)).To(Succeed())
+			Expect(paths).To(Equal([]string{"/v1/analytics/similar_feedback"}))
+			Expect(interactions[0].ActionValue).To(Equal(string(globals.UselessFeedback)))
+			Expect(interactions[0].ThreadTs).To(Equal("1600000000.000100"))
+		})
+
+		It("Should pass the acknowledgement of the rules to the welcome endpoint", func() {
+			request := slack.InteractivityRequest{
+				Type:    "block_actions",
+				User:    globals.User{ID: "UNEW"},
+				Channel: slack.InteractivityChannel{ID: "DNEW"},
+				Message: globals.Reply{Timestamp: "1600000100.000200"},
+				Actions: []slack.InteractivityAction{
+					{ActionID: globals.WelcomeAckAction, Value: "CLK7MCUS3", ActionTs: "1600000200.000300"},
+				},
+			}
+			Expect(h.HandleNewInteraction(request)).To(Succeed())
+			Expect(paths).To(Equal([]string{"/v1/analytics/welcome_ack"}))
+			Expect(interactions[0].ActionValue).To(Equal("CLK7MCUS3"))
+			Expect(interactions[0].ActionUserID).To(Equal("UNEW"))
+		})
+
+		It("Should pass the submitted modals with the interaction they were opened from", func() {
+			request := slack.InteractivityRequest{
+				Type: "view_submission",
+				User: globals.User{ID: "UALICE"},
+				View: slack.InteractivityView{
+					CallbackID:      globals.ResolutionNoteAction,
+					PrivateMetadata: `{"channel": "CLK7MCUS3", "message_ts": "1600000000.000100"}`,
+					State: slack.InteractivityState{Values: map[string]map[string]slack.InteractivityValue{
+						"note": {"note": {Type: "plain_text_input", Value: "Le token a été renouvelé"}},
+					}},
+				},
+			}
+			Expect(h.HandleNewInteraction(request)).To(Succeed())
+			Expect(paths).To(Equal([]string{"/v1/analytics/view_submission"}))
+			Expect(interactions[0].ActionValue).To(Equal(globals.ResolutionNoteAction))
+			Expect(interactions[0].ActionUserID).To(Equal("UALICE"))
+			Expect(interactions[0].Channel).To(Equal("CLK7MCUS3"))
+			Expect(interactions[0].MessageTs).To(Equal("1600000000.000100"))
+			Expect(interactions[0].Values).To(HaveKeyWithValue("note", "Le token a été renouvelé"))
+		})
+
+		It("Should pass the message shortcuts to the thread action endpoint", func() {
+			request := slack.InteractivityRequest{
+				Type:       "message_action",
+				CallbackID: globals.WaitingUserAction,
+				ActionTs:   "1600000200.000300",
+				User:       globals.User{ID: "UALICE"},
+				Channel:    slack.InteractivityChannel{ID: "CLK7MCUS3"},
+				Message:    globals.Reply{Timestamp: "1600000000.000100"},
+			}
+			Expect(h.HandleNewInteraction(request)).To(Succeed())
+			Expect(paths).To(Equal([]string{"/v1/analytics/thread_action"}))
+			Expect(interactions[0].ActionValue).To(Equal(globals.WaitingUserAction))
+			Expect(interactions[0].MessageTs).To(Equal("1600000000.000100"))
+			Expect(interactions[0].ActionTs).To(Equal("1600000200.000300"))
+		})
+	})
+})
@@ -26,7 +26,7 @@ func (m newMessageMockedSender) GetMessageType(_ slack.Event) globals.MessageTyp
 	return globals.NewMessage
 }
 
-func (m newMessageMockedSender) ReplyToMessage(_ string, _ string, _ []interface{}) error {
+func (m newMessageMockedSender) ReplyToMessage(_ string, _ string, _ string, _ []interface{}) error {
 	return nil
 }
 
@@ -55,8 +55,8 @@ func (m newMessageMockedSender) GetMessage(_ slack.Event) globals.Message {
 	}
 }
 
-func (m newMessageMockedSender) IsValidToken(_ slack.EventRequest) bool {
-	return true
+func (m newMessageMockedSender) VerifySignature(timestamp string, signature string, body []byte) error {
+	return slack.Slack{SigningSecret: signingSecret}.VerifySignature(timestamp, signature, body)
 }
 
 func (m newMessageMockedSender) IsWatchedChannel(_ slack.Event) bool {
@@ -0,0 +1,231 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/globals"
+	"github.com/leboncoin/subot/pkg/queue"
+	"github.com/leboncoin/subot/pkg/slack"
+	"github.com/leboncoin/subot/services/replier"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Queue", func() {
+	var mockAnalyticsServer *httptest.Server
+	var analyticsCalls int32
+	var failures int32
+	var dir string
+	var h replier.Handler
+
+	BeforeEach(func() {
+		var err error
+		atomic.StoreInt32(&analyticsCalls, 0)
+		atomic.StoreInt32(&failures, 0)
+		mockAnalyticsServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&analyticsCalls, 1) <= atomic.LoadInt32(&failures) {
+				res.WriteHeader(503)
+				return
+			}
+			_, _ = res.Write([]byte("[]"))
+		}))
+
+		dir, err = ioutil.TempDir("", "replier-queue")
+		Expect(err).ToNot(HaveOccurred())
+		q, err := queue.New(dir, 3, time.Millisecond, time.Millisecond)
+		Expect(err).ToNot(HaveOccurred())
+
+		h = replier.Handler{Slack: newMessageMockedSender{}, ApiUrl: mockAnalyticsServer.URL, Queue: q, AdminToken: "admin-token"}
+	})
+
+	AfterEach(func() {
+		mockAnalyticsServer.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	adminRequest := func(method string, target string) *http.Request {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer admin-token")
+		return req
+	}
+
+	Describe("Test deduplication of the events", func() {
+		It("Should queue the events sent again by slack only once", func() {
+			h.Seen = replier.NewEventCache(time.Hour)
+			gin.SetMode(gin.TestMode)
+			router := replier.NewRouter(&h)
+
+			body, err := json.Marshal(slack.EventRequest{Type: "event_callback", EventID: "Ev01SBMF9K8C"})
+			Expect(err).ToNot(HaveOccurred())
+			for retry := 0; retry < 3; retry++ {
+				req := newSignedRequest("/event", "application/json", body, time.Now())
+				if retry > 0 {
+					req.Header.Set("X-Slack-Retry-Num", strconv.Itoa(retry))
+					req.Header.Set("X-Slack-Retry-Reason", "http_timeout")
+				}
+				res := httptest.NewRecorder()
+				router.ServeHTTP(res, req)
+				Expect(res.Code).To(Equal(200))
+			}
+
+			pending, err := h.Queue.Pending()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pending).To(HaveLen(1))
+			Expect(pending[0].ID).To(Equal("Ev01SBMF9K8C"))
+
+			Expect(h.Queue.Process(h.ProcessQueueItem)).To(Equal(1))
+			Expect(atomic.LoadInt32(&analyticsCalls)).To(Equal(int32(1)))
+		})
+
+		It("Should not queue an event which is still in the queue", func() {
+			atomic.StoreInt32(&failures, 100)
+			h.EnqueueEvent(slack.EventRequest{Type: "event_callback", EventID: "Ev01SBMF9K8C"})
+			h.Queue.Process(h.ProcessQueueItem)
+
+			// the cache is lost on restart, the queue still knows the event
+			h.EnqueueEvent(slack.EventRequest{Type: "event_callback", EventID: "Ev01SBMF9K8C"})
+			pending, err := h.Queue.Pending()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pending).To(HaveLen(1))
+			Expect(pending[0].Attempts).To(Equal(1))
+		})
+	})
+
+	Describe("Test retries of the events", func() {
+		It("Should retry the event until analytics answers", func() {
+			atomic.StoreInt32(&failures, 2)
+			h.EnqueueEvent(slack.EventRequest{Type: "event_callback"})
+
+			Eventually(func() int32 {
+				h.Queue.Process(h.ProcessQueueItem)
+				return atomic.LoadInt32(&analyticsCalls)
+			}, time.Second).Should(Equal(int32(3)))
+
+			pending, err := h.Queue.Pending()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+			failed, err := h.Queue.Dead()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(failed).To(BeEmpty())
+		})
+
+		It("Should only retry the failed action of an interaction", func() {
+			var paths []string
+			failing := true
+			mockActionsServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				paths = append(paths, req.URL.Path)
+				if req.URL.Path == "/v1/analytics/thread_action" && failing {
+					failing = false
+					res.WriteHeader(503)
+					return
+				}
+				_, _ = res.Write([]byte("[]"))
+			}))
+			defer mockActionsServer.Close()
+			h.ApiUrl = mockActionsServer.URL
+
+			h.EnqueueInteraction(slack.InteractivityRequest{
+				Type:    "block_actions",
+				Channel: slack.InteractivityChannel{ID: "CLK7MCUS3"},
+				Actions: []slack.InteractivityAction{
+					{ActionID: "Ks2b", Value: string(globals.UsefulFeedback), ActionTs: "1600000200.000300"},
+					{ActionID: globals.SnoozeAction, Value: "1600000000.000100", ActionTs: "1600000200.000300"},
+				},
+			})
+			pending, err := h.Queue.Pending()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pending).To(HaveLen(2))
+
+			Eventually(func() []string {
+				h.Queue.Process(h.ProcessQueueItem)
+				return paths
+			}, time.Second).Should(Equal([]string{"/v1/analytics/feedback", "/v1/analytics/thread_action", "/v1/analytics/thread_action"}))
+			pending, err = h.Queue.Pending()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+		})
+
+		It("Should list and replay the failed events", func() {
+			atomic.StoreInt32(&failures, 100)
+			h.EnqueueEvent(slack.EventRequest{Type: "event_callback"})
+
+			Eventually(func() int {
+				h.Queue.Process(h.ProcessQueueItem)
+				failed, _ := h.Queue.Dead()
+				return len(failed)
+			}, time.Second).Should(Equal(1))
+			Expect(atomic.LoadInt32(&analyticsCalls)).To(Equal(int32(3)))
+
+			gin.SetMode(gin.TestMode)
+			router := replier.NewRouter(&h)
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, adminRequest("GET", "/queue/failed"))
+			Expect(res.Code).To(Equal(200))
+			var failed []queue.Item
+			Expect(json.Unmarshal(res.Body.Bytes(), &failed)).To(Succeed())
+			Expect(failed).To(HaveLen(1))
+			Expect(failed[0].Kind).To(Equal("event"))
+			Expect(failed[0].LastError).To(ContainSubstring("503"))
+
+			res = httptest.NewRecorder()
+			router.ServeHTTP(res, adminRequest("POST", "/queue/failed/unknown/replay"))
+			Expect(res.Code).To(Equal(404))
+
+			atomic.StoreInt32(&failures, 0)
+			res = httptest.NewRecorder()
+			router.ServeHTTP(res, adminRequest("POST", "/queue/failed/"+failed[0].ID+"/replay"))
+			Expect(res.Code).To(Equal(200))
+
+			Expect(h.Queue.Process(h.ProcessQueueItem)).To(Equal(1))
+			Expect(atomic.LoadInt32(&analyticsCalls)).To(Equal(int32(4)))
+			pending, err := h.Queue.Pending()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+		})
+
+		It("Should not retry an event which cannot be read", func() {
+			_, err := h.Queue.Push("event", "", "not an event")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(h.Queue.Process(h.ProcessQueueItem)).To(Equal(1))
+			failed, err := h.Queue.Dead()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(failed).To(HaveLen(1))
+			Expect(failed[0].Attempts).To(Equal(1))
+			Expect(atomic.LoadInt32(&analyticsCalls)).To(Equal(int32(0)))
+		})
+
+		It("Should refuse the failed events to the requests without the admin token", func() {
+			gin.SetMode(gin.TestMode)
+			router := replier.NewRouter(&h)
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, httptest.NewRequest("GET", "/queue/failed", nil))
+			Expect(res.Code).To(Equal(401))
+
+			req := adminRequest("POST", "/queue/failed/unknown/replay")
+			req.Header.Set("Authorization", "Bearer wrong-token")
+			res = httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+			Expect(res.Code).To(Equal(401))
+
+			h.AdminToken = ""
+			router = replier.NewRouter(&h)
+			req = adminRequest("GET", "/queue/failed")
+			req.Header.Set("Authorization", "Bearer ")
+			res = httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+			Expect(res.Code).To(Equal(401))
+		})
+	})
+})
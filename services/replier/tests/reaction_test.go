@@ -40,8 +40,8 @@ func (m reactionMockedSender) GetReaction(_ slack.Event) globals.Reaction {
 	}
 }
 
-func (m reactionMockedSender) IsValidToken(_ slack.EventRequest) bool {
-	return true
+func (m reactionMockedSender) VerifySignature(timestamp string, signature string, body []byte) error {
+	return slack.Slack{SigningSecret: signingSecret}.VerifySignature(timestamp, signature, body)
 }
 
 func (m reactionMockedSender) IsWatchedChannel(_ slack.Event) bool {
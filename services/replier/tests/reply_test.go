@@ -42,8 +42,8 @@ func (m replyMockedSender) GetReply(_ slack.Event) globals.Reply {
 	}
 }
 
-func (m replyMockedSender) IsValidToken(_ slack.EventRequest) bool {
-	return true
+func (m replyMockedSender) VerifySignature(timestamp string, signature string, body []byte) error {
+	return slack.Slack{SigningSecret: signingSecret}.VerifySignature(timestamp, signature, body)
 }
 
 func (m replyMockedSender) IsWatchedChannel(_ slack.Event) bool {
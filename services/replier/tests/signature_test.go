@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/leboncoin/subot/pkg/slack"
+	"github.com/leboncoin/subot/services/replier"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Signature", func() {
+	var router *gin.Engine
+	var challenge []byte
+
+	BeforeEach(func() {
+		var err error
+		gin.SetMode(gin.TestMode)
+		router = replier.NewRouter(&replier.Handler{Slack: newMessageMockedSender{}})
+
+		challenge, err = json.Marshal(slack.EventRequest{Type: "url_verification", Challenge: "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P"})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("Test signature of the slack events", func() {
+		It("Should accept signed requests", func() {
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, newSignedRequest("/event", "application/json", challenge, time.Now()))
+
+			Expect(res.Code).To(Equal(200))
+			Expect(res.Body.String()).To(ContainSubstring("3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P"))
+		})
+
+		It("Should reject unsigned requests", func() {
+			req := newSignedRequest("/event", "application/json", challenge, time.Now())
+			req.Header.Del(slack.SignatureHeader)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			Expect(res.Code).To(Equal(401))
+		})
+
+		It("Should reject stale requests", func() {
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, newSignedRequest("/event", "application/json", challenge, time.Now().Add(-10*time.Minute)))
+
+			Expect(res.Code).To(Equal(401))
+		})
+
+		It("Should reject requests whose body was altered", func() {
+			req := newSignedRequest("/event", "application/json", challenge, time.Now())
+			req.Header.Set(slack.SignatureHeader, slack.ComputeSignature(signingSecret, req.Header.Get(slack.TimestampHeader), []byte("{}")))
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			Expect(res.Code).To(Equal(401))
+		})
+	})
+
+	Describe("Test signature of the slack interactions and commands", func() {
+		It("Should accept signed interactions", func() {
+			body := []byte(url.Values{"payload": {`{"type": "block_actions"}`}}.Encode())
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, newSignedRequest("/interactivity", "application/x-www-form-urlencoded", body, time.Now()))
+
+			Expect(res.Code).To(Equal(200))
+		})
+
+		It("Should reject stale interactions", func() {
+			body := []byte(url.Values{"payload": {`{"type": "block_actions"}`}}.Encode())
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, newSignedRequest("/interactivity", "application/x-www-form-urlencoded", body, time.Now().Add(-time.Hour)))
+
+			Expect(res.Code).To(Equal(401))
+		})
+
+		It("Should reject unsigned commands", func() {
+			req := httptest.NewRequest("POST", "/commands/incident", nil)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			Expect(res.Code).To(Equal(401))
+		})
+	})
+})
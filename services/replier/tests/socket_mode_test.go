@@ -0,0 +1,85 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/leboncoin/subot/pkg/slack"
+	"github.com/leboncoin/subot/services/replier"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Socket mode", func() {
+	var mockAnalyticsServer *httptest.Server
+	var mockSlackServer *httptest.Server
+	var analyticsCalls chan string
+
+	BeforeEach(func() {
+		analyticsCalls = make(chan string, 1)
+		mockAnalyticsServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			analyticsCalls <- req.RequestURI
+			_, _ = res.Write([]byte("[]"))
+		}))
+
+		payload, err := json.Marshal(slack.EventRequest{Type: "event_callback", Event: slack.Event{Type: "message", Channel: "CLK7MCUS3"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		upgrader := websocket.Upgrader{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/apps.connections.open", func(res http.ResponseWriter, req *http.Request) {
+			wsURL := "ws" + strings.TrimPrefix(mockSlackServer.URL, "http") + "/link"
+			_, _ = res.Write([]byte(`{"ok": true, "url": "` + wsURL + `"}`))
+		})
+		mux.HandleFunc("/link", func(res http.ResponseWriter, req *http.Request) {
+			conn, err := upgrader.Upgrade(res, req, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"envelope_id": "1", "type": "events_api", "payload": `+string(payload)+`}`))
+			// wait for the ack then for the client to leave
+			_, _, _ = conn.ReadMessage()
+			_, _, _ = conn.ReadMessage()
+		})
+		mockSlackServer = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		mockSlackServer.Close()
+		mockAnalyticsServer.Close()
+	})
+
+	Describe("Test socket mode transport", func() {
+		It("Should not serve the slack endpoints", func() {
+			gin.SetMode(gin.TestMode)
+			router := replier.NewRouter(&replier.Handler{Slack: newMessageMockedSender{}, SocketMode: true})
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, newSignedRequest("/event", "application/json", []byte("{}"), time.Now()))
+			Expect(res.Code).To(Equal(404))
+
+			res = httptest.NewRecorder()
+			router.ServeHTTP(res, httptest.NewRequest("GET", "/ping", nil))
+			Expect(res.Code).To(Equal(200))
+		})
+
+		It("Should pass the events to the analytics api", func() {
+			h := replier.Handler{Slack: newMessageMockedSender{}, ApiUrl: mockAnalyticsServer.URL, SocketMode: true}
+			socket := h.SocketModeClient(mockSlackServer.URL, "xapp-token")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go socket.Run(ctx)
+
+			Eventually(analyticsCalls, 2*time.Second).Should(Receive(HavePrefix("/v1/analytics/")))
+		})
+	})
+})
@@ -1,7 +1,13 @@
 package handler_test
 
 import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/leboncoin/subot/pkg/slack"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -11,3 +17,14 @@ func TestReplierHandler(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "ReplierHandler Suite")
 }
+
+// signingSecret is the secret shared by the mocked senders to verify the requests
+const signingSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// newSignedRequest builds a request to the replier signed at the given time
+func newSignedRequest(target string, contentType string, body []byte, at time.Time) *http.Request {
+	req := httptest.NewRequest("POST", target, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	slack.SignRequest(req, signingSecret, body, at)
+	return req
+}
@@ -30,7 +30,7 @@ func (m updatedMockedSender) GetEvent(e slack.Event) globals.Event {
 	return m.GetUpdatedMessage(e)
 }
 
-func (m updatedMockedSender) DeleteResponseToMessage(_ string) error {
+func (m updatedMockedSender) DeleteResponseToMessage(_ string, _ string) error {
 	return nil
 }
 
@@ -55,8 +55,8 @@ func (m updatedMockedSender) GetUpdatedMessage(_ slack.Event) globals.Message {
 	}
 }
 
-func (m updatedMockedSender) IsValidToken(_ slack.EventRequest) bool {
-	return true
+func (m updatedMockedSender) VerifySignature(timestamp string, signature string, body []byte) error {
+	return slack.Slack{SigningSecret: signingSecret}.VerifySignature(timestamp, signature, body)
 }
 
 func (m updatedMockedSender) IsWatchedChannel(_ slack.Event) bool {
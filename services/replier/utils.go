@@ -1,12 +1,16 @@
 package replier
 
 import (
+	"bytes"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
+	"github.com/gin-gonic/gin"
 	"github.com/leboncoin/subot/pkg/slack"
 
 	log "github.com/sirupsen/logrus"
@@ -20,11 +24,6 @@ type AnalyticsAPIResponse struct {
 }
 
 func (h Handler) isAuthorizedEvent(request slack.EventRequest) bool {
-	// Verify token presence and consistency
-	if !h.Slack.IsValidToken(request) {
-		return false
-	}
-
 	// Check Channel ID
 	if !h.Slack.IsWatchedChannel(request.Event) {
 		log.Debug("Not from watched channel")
@@ -34,6 +33,38 @@ func (h Handler) isAuthorizedEvent(request slack.EventRequest) bool {
 	return true
 }
 
+// verifySignature rejects the requests which were not signed by slack
+func (h Handler) verifySignature(c *gin.Context) {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		log.Errorf("Could not read request body: %s", err)
+		c.AbortWithStatusJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	// put the body back for the handlers to bind it
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	timestamp := c.GetHeader(slack.TimestampHeader)
+	signature := c.GetHeader(slack.SignatureHeader)
+	if err := h.Slack.VerifySignature(timestamp, signature, body); err != nil {
+		log.WithFields(log.Fields{"path": c.Request.URL.Path}).Debugf("Not authorized request: %s", err)
+		c.AbortWithStatusJSON(401, gin.H{"error": "unauthorized"})
+		return
+	}
+	c.Next()
+}
+
+// verifyAdminToken rejects the requests which do not carry the admin token as a bearer token
+func (h Handler) verifyAdminToken(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if h.AdminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.AdminToken)) != 1 {
+		log.WithFields(log.Fields{"path": c.Request.URL.Path}).Debug("Not authorized admin request")
+		c.AbortWithStatusJSON(401, gin.H{"error": "unauthorized"})
+		return
+	}
+	c.Next()
+}
+
 func (h Handler) callAnalyticsAPI(method string, endpoint string, body io.Reader) (responses []globals.SlackResponse, err error) {
 	url := h.ApiUrl + "/v1/analytics/" + endpoint
 	req, err := http.NewRequest(method, url, body)
@@ -2,6 +2,7 @@ package replier
 
 import (
 	"testing"
+	"time"
 
 	"github.com/leboncoin/subot/pkg/slack"
 )
@@ -14,20 +15,44 @@ func TestIsAuthorizedEvent(t *testing.T) {
 
 	s := slack.Slack{
 		Host:     "",
-		Channel:  channel,
+		Channels: []slack.Chan{channel},
 		Token:    "token",
 		BotToken: "botToken",
 		BotID:    "botId",
 	}
 
 	h := &Handler{Slack: &s, ApiUrl: "http://analytics:8080"}
-	result := h.isAuthorizedEvent(slack.EventRequest{Token: "fake token"})
+	result := h.isAuthorizedEvent(slack.EventRequest{Event: slack.Event{Channel: "other"}})
 	if result == true {
-		t.Errorf("Wrong token was accepted")
+		t.Errorf("Event from unwatched channel was accepted")
 	}
 
-	result2 := h.isAuthorizedEvent(slack.EventRequest{Token: "token", Event: slack.Event{Channel: "dummy"}})
+	result2 := h.isAuthorizedEvent(slack.EventRequest{Event: slack.Event{Channel: "dummy"}})
 	if result2 != true {
-		t.Errorf("Right token was not accepted")
+		t.Errorf("Event from watched channel was not accepted")
+	}
+}
+
+func TestEventCache(t *testing.T) {
+	cache := NewEventCache(time.Hour)
+	if cache.Seen("Ev01") {
+		t.Errorf("New event was seen")
+	}
+	if !cache.Seen("Ev01") {
+		t.Errorf("Event received twice was not seen")
+	}
+	if cache.Seen("") || cache.Seen("") {
+		t.Errorf("Event without ID was seen")
+	}
+
+	expired := NewEventCache(-time.Second)
+	expired.Seen("Ev01")
+	if expired.Seen("Ev01") {
+		t.Errorf("Expired event was seen")
+	}
+
+	var disabled *EventCache
+	if disabled.Seen("Ev01") || disabled.Seen("Ev01") {
+		t.Errorf("Event was seen without cache")
 	}
 }